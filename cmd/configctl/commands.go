@@ -0,0 +1,622 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/client"
+	"github.com/Titonu/configuration-management-service/pkg/format"
+	"github.com/Titonu/configuration-management-service/pkg/jsondiff"
+)
+
+// context returns the context a command's requests run in
+func (a *app) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
+
+// get shows a configuration, optionally at a version, tag or point in time
+func (a *app) get(args []string) error {
+	fs := a.flagSet("get")
+	version := fs.Int("version", 0, "version to show")
+	tag := fs.String("tag", "", "tag of the version to show")
+	asOf := fs.String("as-of", "", "show the version effective at this RFC 3339 time")
+	dataFormat := fs.String("format", "", "print only the data, as json, yaml, toml, env or properties")
+	positional, err := parseArgs(fs, args, 1, 1, "get <name> [--version n | --tag tag | --as-of time] [--format format]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+
+	selectors := 0
+	for _, set := range []bool{*version != 0, *tag != "", *asOf != ""} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		return usageErrorf("--version, --tag and --as-of cannot be combined")
+	}
+	var f format.Format
+	if *dataFormat != "" {
+		if f, err = format.Parse(*dataFormat); err != nil {
+			return usageErrorf("%v", err)
+		}
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	name := positional[0]
+	var config *client.Configuration
+	switch {
+	case *version != 0:
+		config, err = c.GetConfigurationVersion(ctx, name, *version)
+	case *tag != "":
+		config, err = c.GetConfigurationByTag(ctx, name, *tag)
+	case *asOf != "":
+		at, parseErr := time.Parse(time.RFC3339, *asOf)
+		if parseErr != nil {
+			return usageErrorf("--as-of must be an RFC 3339 time, such as 2024-01-02T15:04:05Z")
+		}
+		config, err = c.GetConfigurationAsOf(ctx, name, at)
+	default:
+		config, err = c.GetConfiguration(ctx, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if f != "" {
+		rendered, err := format.FromJSON(f, config.Data)
+		if err != nil {
+			return err
+		}
+		if f == format.JSON {
+			return a.printData(rendered)
+		}
+		_, err = a.stdout.Write(rendered)
+		return err
+	}
+	if a.output == outputJSON {
+		return a.printJSON(config)
+	}
+
+	t := a.table("NAME", "VERSION", "SCHEMA VERSION", "CREATED AT")
+	t.row(config.Name, strconv.Itoa(config.Version), optionalInt(config.SchemaVersion), formatTime(config.CreatedAt))
+	if err := t.flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout)
+	return a.printData(config.Data)
+}
+
+// put creates a configuration or stores the next version of it
+func (a *app) put(args []string) error {
+	fs := a.flagSet("put")
+	file := fs.String("f", "-", "file holding the data, or - for stdin")
+	dataFormat := fs.String("format", "", "format of the data: json, yaml or toml (default: from the file extension)")
+	positional, err := parseArgs(fs, args, 1, 1, "put <name> [-f file] [--format format]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	data, _, err := a.readDocument(*file, *dataFormat)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	name := positional[0]
+	config, err := c.UpdateConfiguration(ctx, name, data)
+	if client.IsNotFound(err) {
+		config, err = c.CreateConfiguration(ctx, name, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.output == outputJSON {
+		return a.printJSON(config)
+	}
+	fmt.Fprintf(a.stdout, "Configuration %q is at version %d\n", config.Name, config.Version)
+	return nil
+}
+
+// versions lists the versions of a configuration
+func (a *app) versions(args []string) error {
+	fs := a.flagSet("versions")
+	positional, err := parseArgs(fs, args, 1, 1, "versions <name>")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	list, err := c.ListConfigurationVersions(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return a.printJSON(list)
+	}
+
+	t := a.table("VERSION", "CREATED AT", "SCHEMA VERSION", "TAGS", "CHANGESET", "NOTES")
+	for _, v := range list.Versions {
+		var notes []string
+		if v.IsRollback {
+			notes = append(notes, "rollback")
+		}
+		if v.Compacted {
+			notes = append(notes, "compacted")
+		}
+		t.row(strconv.Itoa(v.Version), formatTime(v.CreatedAt), optionalInt(v.SchemaVersion),
+			strings.Join(v.Tags, ","), v.ChangesetID, strings.Join(notes, ","))
+	}
+	return t.flush()
+}
+
+// diff shows the changes between two versions of a configuration
+func (a *app) diff(args []string) error {
+	fs := a.flagSet("diff")
+	positional, err := parseArgs(fs, args, 2, 3, "diff <name> <from-version> [<to-version>]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	versions := make([]int, len(positional)-1)
+	for i, arg := range positional[1:] {
+		if versions[i], err = strconv.Atoi(arg); err != nil || versions[i] < 1 {
+			return usageErrorf("invalid version %q", arg)
+		}
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	name := positional[0]
+	from, err := c.GetConfigurationVersion(ctx, name, versions[0])
+	if err != nil {
+		return err
+	}
+	var to *client.Configuration
+	if len(versions) > 1 {
+		to, err = c.GetConfigurationVersion(ctx, name, versions[1])
+	} else {
+		to, err = c.GetConfiguration(ctx, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes, err := jsondiff.Diff(from.Data, to.Data)
+	if err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return a.printJSON(map[string]interface{}{
+			"name":         name,
+			"from_version": from.Version,
+			"to_version":   to.Version,
+			"changes":      changes,
+		})
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(a.stdout, "No changes between versions %d and %d\n", from.Version, to.Version)
+		return nil
+	}
+	t := a.table("OP", "PATH", "OLD VALUE", "NEW VALUE")
+	for _, change := range changes {
+		t.row(string(change.Op), change.Path, formatValue(change.OldValue), formatValue(change.NewValue))
+	}
+	return t.flush()
+}
+
+// rollback restores the data of a previous version as the next version
+func (a *app) rollback(args []string) error {
+	fs := a.flagSet("rollback")
+	tag := fs.String("tag", "", "roll back to the version this tag points at")
+	positional, err := parseArgs(fs, args, 1, 2, "rollback <name> (<version> | --tag tag)")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	if (len(positional) == 2) == (*tag != "") {
+		return usageErrorf("usage: configctl rollback <name> (<version> | --tag tag)")
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	var config *client.Configuration
+	if *tag != "" {
+		config, err = c.RollbackConfigurationToTag(ctx, positional[0], *tag)
+	} else {
+		version, convErr := strconv.Atoi(positional[1])
+		if convErr != nil || version < 1 {
+			return usageErrorf("invalid version %q", positional[1])
+		}
+		config, err = c.RollbackConfiguration(ctx, positional[0], version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.output == outputJSON {
+		return a.printJSON(config)
+	}
+	fmt.Fprintf(a.stdout, "Configuration %q rolled back to version %d as version %d\n", config.Name, config.RollbackTo, config.Version)
+	return nil
+}
+
+// schema manages the schema of a configuration
+func (a *app) schema(args []string) error {
+	return a.subcommand("schema", args, map[string]command{
+		"get":      (*app).schemaGet,
+		"set":      (*app).schemaSet,
+		"validate": (*app).schemaValidate,
+	})
+}
+
+func (a *app) schemaGet(args []string) error {
+	fs := a.flagSet("schema get")
+	version := fs.Int("version", 0, "schema version to show")
+	positional, err := parseArgs(fs, args, 1, 1, "schema get <name> [--version n]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	if *version != 0 {
+		schemaVersion, err := c.GetSchemaVersion(ctx, positional[0], *version)
+		if err != nil {
+			return err
+		}
+		if a.output == outputJSON {
+			return a.printJSON(schemaVersion)
+		}
+		return a.printData(schemaVersion.Schema)
+	}
+
+	schema, err := c.GetSchema(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return a.printData(schema)
+}
+
+func (a *app) schemaSet(args []string) error {
+	fs := a.flagSet("schema set")
+	file := fs.String("f", "-", "file holding the schema, or - for stdin")
+	dataFormat := fs.String("format", "", "format of the schema: json, yaml or toml (default: from the file extension)")
+	force := fs.Bool("force", false, "skip the compatibility and existing data checks")
+	checkVersions := fs.Int("check-versions", 0, "number of recent configuration versions that must conform")
+	positional, err := parseArgs(fs, args, 1, 1, "schema set <name> [-f file] [--force] [--check-versions n]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	schema, _, err := a.readDocument(*file, *dataFormat)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	version, err := c.RegisterSchema(ctx, positional[0], schema, client.SchemaOptions{Force: *force, CheckVersions: *checkVersions})
+	if err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return a.printJSON(map[string]interface{}{"name": positional[0], "version": version})
+	}
+	fmt.Fprintf(a.stdout, "Registered schema version %d for %q\n", version, positional[0])
+	return nil
+}
+
+// schemaValidate validates a document against the schema and rules of a configuration
+// without storing it, exiting with exitValidation when it is invalid
+func (a *app) schemaValidate(args []string) error {
+	fs := a.flagSet("schema validate")
+	file := fs.String("f", "-", "file holding the document, or - for stdin")
+	dataFormat := fs.String("format", "", "format of the document: json, yaml or toml (default: from the file extension)")
+	positional, err := parseArgs(fs, args, 1, 1, "schema validate <name> [-f file] [--format format]")
+	if err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	// JSON documents are sent as they are, so that reported positions refer to the file
+	data, raw, err := a.readDocument(*file, *dataFormat)
+	if err != nil {
+		return err
+	}
+	if raw != nil {
+		data = raw
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	report, err := c.ValidateDocument(ctx, positional[0], data)
+	if err != nil {
+		return err
+	}
+
+	if a.output == outputJSON {
+		if err := a.printJSON(report); err != nil {
+			return err
+		}
+	} else if report.Valid {
+		fmt.Fprintln(a.stdout, "Document is valid")
+	} else {
+		t := a.table("SOURCE", "FIELD", "LINE", "REASON")
+		for _, issue := range report.Errors {
+			t.row(string(issue.Source), issue.Field, optionalInt(issue.Line), issue.Reason)
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+
+	if !report.Valid {
+		return errInvalidDocument
+	}
+	return nil
+}
+
+// export writes an archive of every configuration to a file or stdout
+func (a *app) export(args []string) error {
+	fs := a.flagSet("export")
+	file := fs.String("f", "-", "file to write the archive to, or - for stdout")
+	compress := fs.Bool("gzip", false, "gzip-compress the archive")
+	if _, err := parseArgs(fs, args, 0, 0, "export [-f file] [--gzip]"); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	if *file == "-" {
+		return c.Export(ctx, a.stdout, *compress)
+	}
+
+	// The archive is written next to its destination and renamed into place, so that a
+	// failed export never leaves a truncated archive behind
+	tmp, err := os.CreateTemp(filepath.Dir(*file), filepath.Base(*file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := c.Export(ctx, tmp, *compress); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), *file)
+}
+
+// importArchive restores an archive read from a file or stdin
+func (a *app) importArchive(args []string) error {
+	fs := a.flagSet("import")
+	file := fs.String("f", "-", "file to read the archive from, or - for stdin")
+	mode := fs.String("mode", "", "how to handle existing configurations: skip_existing, overwrite or merge")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without importing anything")
+	if _, err := parseArgs(fs, args, 0, 0, "import [-f file] [--mode mode] [--dry-run]"); err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+
+	in, err := a.open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+
+	report, err := c.Import(ctx, in, client.ImportOptions{Mode: client.ImportMode(*mode), DryRun: *dryRun})
+	if err != nil {
+		// A rejected archive carries the report with the errors of each invalid item
+		var apiErr *client.Error
+		var rejected client.ImportReport
+		if client.IsValidationFailed(err) && stdErrors.As(err, &apiErr) && apiErr.DecodeDetails(&rejected) == nil {
+			_ = a.printImportReport(&rejected)
+		}
+		return err
+	}
+	return a.printImportReport(report)
+}
+
+func (a *app) printImportReport(report *client.ImportReport) error {
+	if a.output == outputJSON {
+		return a.printJSON(report)
+	}
+
+	t := a.table("KIND", "NAME", "ACTION", "CONFLICT", "ERRORS")
+	for _, item := range report.Items {
+		conflict := ""
+		if item.Conflict {
+			conflict = "yes"
+		}
+		reasons := make([]string, len(item.Errors))
+		for i, itemErr := range item.Errors {
+			reasons[i] = itemErr.Field + ": " + itemErr.Reason
+		}
+		t.row(string(item.Kind), item.Name, string(item.Action), conflict, strings.Join(reasons, "; "))
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	s := report.Summary
+	state := "Imported"
+	if !report.Applied {
+		state = "Not imported"
+	}
+	fmt.Fprintf(a.stdout, "\n%s: %d created, %d overwritten, %d merged, %d skipped, %d unchanged, %d conflicts, %d invalid\n",
+		state, s.Created, s.Overwritten, s.Merged, s.Skipped, s.Unchanged, s.Conflicts, s.Invalid)
+	return nil
+}
+
+// open opens a file for reading, or stdin for "-"
+func (a *app) open(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(a.stdin), nil
+	}
+	return os.Open(path)
+}
+
+// readDocument reads a JSON, YAML or TOML document from a file or stdin and converts it
+// to JSON. The format is taken from formatName, the file extension or, failing both, the
+// content: documents starting with { or [ are JSON and others YAML. raw holds the
+// document as read when it was JSON already.
+func (a *app) readDocument(path, formatName string) (data json.RawMessage, raw []byte, err error) {
+	in, err := a.open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer in.Close()
+	content, err := io.ReadAll(in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := documentFormat(path, formatName, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f == format.JSON {
+		if !json.Valid(content) {
+			return nil, nil, usageErrorf("%s is not valid JSON", describe(path))
+		}
+		return content, content, nil
+	}
+
+	data, err = format.ToJSON(f, content)
+	if err != nil {
+		return nil, nil, usageErrorf("%s is not valid %s: %v", describe(path), strings.ToUpper(string(f)), err)
+	}
+	return data, nil, nil
+}
+
+// documentFormat picks the format a document is read in
+func documentFormat(path, formatName string, content []byte) (format.Format, error) {
+	if formatName != "" {
+		f, err := format.Parse(formatName)
+		if err != nil {
+			return "", usageErrorf("%v", err)
+		}
+		if !f.CanDecode() {
+			return "", usageErrorf("%s documents can only be written, not read", f)
+		}
+		return f, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return format.JSON, nil
+	case ".yaml", ".yml":
+		return format.YAML, nil
+	case ".toml":
+		return format.TOML, nil
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return format.JSON, nil
+	}
+	return format.YAML, nil
+}
+
+func describe(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+// optionalInt formats a number for a table, leaving zero blank
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
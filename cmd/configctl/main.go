@@ -0,0 +1,258 @@
+// Command configctl operates the configuration management service from the command line.
+//
+// It reads, writes, diffs and rolls back configurations, manages their schemas, and
+// exports and imports archives, using the HTTP API through pkg/client. Servers and API
+// keys are kept in named profiles. The exit code tells apart the failures scripts
+// usually need to handle: see the exit* constants.
+package main
+
+import (
+	stdErrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/client"
+)
+
+// Exit codes
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitValidation = 4
+	exitAuth       = 5
+	exitConflict   = 6
+)
+
+// Output modes
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const usage = `Usage: configctl [global flags] <command> [arguments]
+
+Commands:
+  get <name>                      Show a configuration (--version, --tag, --as-of, --format)
+  put <name> [-f file]            Create or update a configuration from a JSON, YAML or TOML file or stdin
+  versions <name>                 List the versions of a configuration
+  diff <name> <from> [<to>]       Show the changes between two versions (default: to the current one)
+  rollback <name> <version>       Roll a configuration back to a version (or --tag)
+  schema get <name>               Show the schema of a configuration (--version)
+  schema set <name> [-f file]     Register a schema (--force, --check-versions)
+  schema validate <name> [-f file]
+                                  Validate a document against a configuration's schema and rules
+  export [-f file]                Export every configuration with its history (--gzip)
+  import [-f file]                Import an archive (--mode, --dry-run)
+  profile list|set|use|delete     Manage server profiles
+
+Global flags:
+  --profile name   Profile to use (default: the current profile, or $CONFIGCTL_PROFILE)
+  --server url     Server URL, overriding the profile (or $CONFIGCTL_SERVER)
+  --api-key key    API key, overriding the profile (or $CONFIGCTL_API_KEY)
+  --config path    Profiles file (default: $CONFIGCTL_CONFIG or <user config dir>/configctl/config.yaml)
+  -o, --output     Output format: table or json (default table)
+
+Exit codes: 0 success, 1 error, 2 usage error, 3 not found, 4 validation failed,
+5 authentication or authorization failed, 6 conflict.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// app holds the streams and global flags shared by every command
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	profile    string
+	server     string
+	apiKey     string
+	configPath string
+	output     string
+}
+
+// command runs a subcommand with the arguments that follow its name
+type command func(a *app, args []string) error
+
+var commands = map[string]command{
+	"get":      (*app).get,
+	"put":      (*app).put,
+	"versions": (*app).versions,
+	"diff":     (*app).diff,
+	"rollback": (*app).rollback,
+	"schema":   (*app).schema,
+	"export":   (*app).export,
+	"import":   (*app).importArchive,
+	"profile":  (*app).profileCommand,
+}
+
+// run executes configctl with the given arguments and returns its exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, getenv: getenv, output: outputTable}
+
+	fs := a.flagSet("configctl")
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		if stdErrors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	if name == "help" {
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "Error: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+
+	if err := cmd(a, fs.Args()[1:]); err != nil {
+		return a.fail(err)
+	}
+	return exitOK
+}
+
+// usageError is an error in the command line itself; reported is set when the flag
+// package already printed it
+type usageError struct {
+	message  string
+	reported bool
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// errInvalidDocument is returned when a validated document has validation errors, after
+// they have been printed
+var errInvalidDocument = stdErrors.New("document is not valid")
+
+// fail reports err on stderr and returns the exit code for it
+func (a *app) fail(err error) int {
+	if stdErrors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	var usageErr *usageError
+	if err != errInvalidDocument && !(stdErrors.As(err, &usageErr) && usageErr.reported) {
+		fmt.Fprintf(a.stderr, "Error: %v\n", err)
+	}
+
+	var apiErr *client.Error
+	if stdErrors.As(err, &apiErr) {
+		for _, validationErr := range apiErr.ValidationErrors() {
+			fmt.Fprintf(a.stderr, "  %s: %s\n", validationErr.Field, validationErr.Reason)
+		}
+	}
+
+	return exitCode(err)
+}
+
+// exitCode maps an error to the exit code scripts can branch on
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case stdErrors.As(err, &usageErr):
+		return exitUsage
+	case err == errInvalidDocument, client.IsValidationFailed(err):
+		return exitValidation
+	case client.IsNotFound(err), client.IsVersionCompacted(err):
+		return exitNotFound
+	case client.IsUnauthorized(err), client.IsForbidden(err):
+		return exitAuth
+	case client.IsConflict(err), client.IsAlreadyExists(err):
+		return exitConflict
+	}
+	return exitError
+}
+
+// flagSet creates a flag set holding the global flags. The flags are registered with
+// their current values as defaults, so global flags given before the command name are
+// kept when the command parses its own.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.profile, "profile", a.profile, "profile to use")
+	fs.StringVar(&a.server, "server", a.server, "server URL")
+	fs.StringVar(&a.apiKey, "api-key", a.apiKey, "API key")
+	fs.StringVar(&a.configPath, "config", a.configPath, "profiles file")
+	fs.StringVar(&a.output, "output", a.output, "output format: table or json")
+	fs.StringVar(&a.output, "o", a.output, "output format: table or json")
+	return fs
+}
+
+// parseArgs parses flags given anywhere among the arguments and returns the positional
+// arguments, checking that there are between min and max of them
+func parseArgs(fs *flag.FlagSet, args []string, min, max int, synopsis string) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: configctl %s\n", synopsis)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if stdErrors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{message: err.Error(), reported: true}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) < min || len(positional) > max {
+		return nil, usageErrorf("usage: configctl %s", synopsis)
+	}
+	return positional, nil
+}
+
+// checkOutput rejects an unknown output format
+func (a *app) checkOutput() error {
+	switch a.output {
+	case outputTable, outputJSON:
+		return nil
+	}
+	return usageErrorf("unknown output format %q, expected table or json", a.output)
+}
+
+// subcommand dispatches to one of a group of subcommands
+func (a *app) subcommand(group string, args []string, subcommands map[string]command) error {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(args) == 0 {
+		return usageErrorf("usage: configctl %s %s", group, strings.Join(names, "|"))
+	}
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		return usageErrorf("unknown command %q, expected configctl %s %s", args[0], group, strings.Join(names, "|"))
+	}
+	return cmd(a, args[1:])
+}
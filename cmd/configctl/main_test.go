@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	httpDelivery "github.com/Titonu/configuration-management-service/internal/delivery/http"
+	"github.com/Titonu/configuration-management-service/internal/delivery/http/handler"
+	"github.com/Titonu/configuration-management-service/internal/delivery/http/middleware"
+	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	"github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	adminKey  = "admin-key"
+	viewerKey = "viewer-key"
+)
+
+// cli runs configctl against an in-process server built from SetupRoutes, with its own
+// database and profiles file
+type cli struct {
+	t       *testing.T
+	server  string
+	env     map[string]string
+	workDir string
+}
+
+func newCLI(t *testing.T) *cli {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	repo, err := sqlite.NewConfigurationRepository(filepath.Join(dir, "config.db"))
+	require.NoError(t, err)
+	configHandler := handler.NewConfigurationHandler(usecase.NewConfigurationUseCase(repo))
+	authMiddleware := middleware.NewAuthMiddleware(map[string]string{adminKey: "admin", viewerKey: "viewer"})
+	roleMiddleware := middleware.NewRoleMiddleware(map[string][]string{"admin": {middleware.RoleAdmin}})
+
+	router := gin.New()
+	httpDelivery.SetupRoutes(router, configHandler, authMiddleware, roleMiddleware)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &cli{
+		t:       t,
+		server:  server.URL,
+		workDir: dir,
+		env: map[string]string{
+			"CONFIGCTL_CONFIG":  filepath.Join(dir, "configctl.yaml"),
+			"CONFIGCTL_SERVER":  server.URL,
+			"CONFIGCTL_API_KEY": adminKey,
+		},
+	}
+}
+
+// run runs configctl with stdin and returns its exit code and output
+func (c *cli) run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr, func(key string) string { return c.env[key] })
+	return code, stdout.String(), stderr.String()
+}
+
+// mustRun runs configctl and fails the test unless it succeeds
+func (c *cli) mustRun(stdin string, args ...string) string {
+	code, stdout, stderr := c.run(stdin, args...)
+	require.Equal(c.t, exitOK, code, "configctl %s: %s", strings.Join(args, " "), stderr)
+	return stdout
+}
+
+// writeFile writes a file in the working directory and returns its path
+func (c *cli) writeFile(name, content string) string {
+	path := filepath.Join(c.workDir, name)
+	require.NoError(c.t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestPutGetVersionsDiffRollback(t *testing.T) {
+	c := newCLI(t)
+
+	// Create from YAML on stdin, update from a JSON file
+	out := c.mustRun("max_limit: 1000\nenabled: true\n", "put", "payments", "--format", "yaml")
+	assert.Contains(t, out, `"payments" is at version 1`)
+	out = c.mustRun("", "put", "payments", "-f", c.writeFile("payments.json", `{"max_limit": 2000, "enabled": true}`))
+	assert.Contains(t, out, `"payments" is at version 2`)
+
+	// Get as JSON, as a table and as YAML data only
+	var config struct {
+		Name    string                 `json:"name"`
+		Version int                    `json:"version"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.mustRun("", "get", "payments", "-o", "json")), &config))
+	assert.Equal(t, 2, config.Version)
+	assert.Equal(t, float64(2000), config.Data["max_limit"])
+
+	out = c.mustRun("", "get", "payments")
+	assert.Contains(t, out, "VERSION")
+	assert.Contains(t, out, `"max_limit": 2000`)
+
+	out = c.mustRun("", "get", "payments", "--version", "1", "--format", "yaml")
+	assert.Equal(t, "max_limit: 1000\nenabled: true\n", out)
+
+	// Diff the versions
+	out = c.mustRun("", "diff", "payments", "1")
+	assert.Contains(t, out, "replace  /max_limit  1000       2000")
+
+	// Roll back and list the versions
+	out = c.mustRun("", "rollback", "payments", "1")
+	assert.Contains(t, out, "as version 3")
+
+	var versions struct {
+		Versions []struct {
+			Version    int  `json:"version"`
+			IsRollback bool `json:"is_rollback"`
+		} `json:"versions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.mustRun("", "versions", "payments", "-o", "json")), &versions))
+	require.Len(t, versions.Versions, 3)
+
+	out = c.mustRun("", "versions", "payments")
+	assert.Contains(t, out, "rollback")
+
+	out = c.mustRun("", "diff", "payments", "1", "3")
+	assert.Contains(t, out, "No changes between versions 1 and 3")
+}
+
+func TestSchemaCommands(t *testing.T) {
+	c := newCLI(t)
+	c.mustRun(`{"max_limit": 1000}`, "put", "payments")
+
+	schema := c.writeFile("schema.yaml", "type: object\nproperties:\n  max_limit:\n    type: integer\nrequired: [max_limit]\n")
+	out := c.mustRun("", "schema", "set", "payments", "-f", schema)
+	assert.Contains(t, out, "Registered schema version 1")
+
+	out = c.mustRun("", "schema", "get", "payments")
+	assert.Contains(t, out, `"required"`)
+
+	out = c.mustRun(`{"max_limit": 5}`, "schema", "validate", "payments")
+	assert.Contains(t, out, "Document is valid")
+
+	code, out, _ := c.run("{\n  \"max_limit\": \"many\"\n}", "schema", "validate", "payments")
+	assert.Equal(t, exitValidation, code)
+	assert.Contains(t, out, "max_limit")
+
+	// Writes that do not conform exit with the validation code and list the errors
+	code, _, stderr := c.run(`{"max_limit": "many"}`, "put", "payments")
+	assert.Equal(t, exitValidation, code)
+	assert.Contains(t, stderr, "max_limit")
+}
+
+func TestExitCodes(t *testing.T) {
+	c := newCLI(t)
+
+	code, _, stderr := c.run("", "get", "missing")
+	assert.Equal(t, exitNotFound, code)
+	assert.Contains(t, stderr, "Error:")
+
+	code, _, _ = c.run("", "get", "missing", "--api-key", "wrong-key")
+	assert.Equal(t, exitAuth, code)
+
+	code, _, _ = c.run("", "import", "--api-key", viewerKey, "-f", c.writeFile("empty.jsonl", ""))
+	assert.Equal(t, exitAuth, code)
+
+	code, _, _ = c.run("", "get")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = c.run("", "get", "payments", "--no-such-flag")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = c.run("", "frobnicate")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = c.run("", "get", "payments", "-o", "xml")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestExportImport(t *testing.T) {
+	source := newCLI(t)
+	source.mustRun(`{"max_limit": 1000}`, "put", "payments")
+	source.mustRun(`{"max_limit": 2000}`, "put", "payments")
+
+	archive := filepath.Join(source.workDir, "backup.jsonl.gz")
+	source.mustRun("", "export", "-f", archive, "--gzip")
+
+	target := newCLI(t)
+	out := target.mustRun("", "import", "-f", archive, "--dry-run")
+	assert.Contains(t, out, "Not imported: 1 created")
+	target.mustRun("", "import", "-f", archive)
+
+	out = target.mustRun("", "get", "payments", "--format", "json")
+	assert.Contains(t, out, `"max_limit": 2000`)
+	out = target.mustRun("", "versions", "payments")
+	assert.Equal(t, 3, strings.Count(out, "\n"))
+}
+
+func TestProfiles(t *testing.T) {
+	c := newCLI(t)
+	delete(c.env, "CONFIGCTL_SERVER")
+	delete(c.env, "CONFIGCTL_API_KEY")
+
+	c.mustRun("", "profile", "set", "local", "--server", c.server, "--api-key", adminKey)
+	c.mustRun("", "profile", "set", "broken", "--server", c.server, "--api-key", "wrong-key")
+
+	out := c.mustRun("", "profile", "list")
+	assert.Contains(t, out, "*        local")
+	assert.NotContains(t, out, adminKey)
+
+	// The current profile is used, and --profile selects another one
+	c.mustRun(`{"max_limit": 1000}`, "put", "payments")
+	code, _, _ := c.run("", "get", "payments", "--profile", "broken")
+	assert.Equal(t, exitAuth, code)
+
+	c.mustRun("", "profile", "use", "broken")
+	code, _, _ = c.run("", "get", "payments")
+	assert.Equal(t, exitAuth, code)
+
+	// Flags override the profile
+	c.mustRun("", "--api-key", adminKey, "get", "payments")
+
+	c.mustRun("", "profile", "delete", "broken")
+	code, _, _ = c.run("", "get", "payments", "--profile", "broken")
+	assert.Equal(t, exitUsage, code)
+}
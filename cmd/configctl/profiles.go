@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultServer  = "http://localhost:8080"
+	requestTimeout = 5 * time.Minute
+)
+
+// profile is a server and the API key to call it with
+type profile struct {
+	Server string `yaml:"server"`
+	APIKey string `yaml:"api_key,omitempty"`
+}
+
+// profileConfig is the profiles file
+type profileConfig struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*profile `yaml:"profiles"`
+}
+
+// profilesPath returns the location of the profiles file
+func (a *app) profilesPath() (string, error) {
+	if a.configPath != "" {
+		return a.configPath, nil
+	}
+	if path := a.getenv("CONFIGCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate profiles file: %w", err)
+	}
+	return filepath.Join(dir, "configctl", "config.yaml"), nil
+}
+
+// loadProfiles reads the profiles file; a missing file holds no profiles
+func (a *app) loadProfiles() (*profileConfig, error) {
+	config := &profileConfig{Profiles: map[string]*profile{}}
+
+	path, err := a.profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(encoded, config); err != nil {
+		return nil, fmt.Errorf("read profiles file %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*profile{}
+	}
+	return config, nil
+}
+
+// saveProfiles writes the profiles file, readable by its owner only since it holds API keys
+func (a *app) saveProfiles(config *profileConfig) error {
+	path, err := a.profilesPath()
+	if err != nil {
+		return err
+	}
+	encoded, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0600)
+}
+
+// client creates an API client for the selected server. Flags take precedence over
+// environment variables, which take precedence over the profile.
+func (a *app) client() (*client.Client, error) {
+	config, err := a.loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	name := firstNonEmpty(a.profile, a.getenv("CONFIGCTL_PROFILE"), config.CurrentProfile)
+	selected := &profile{}
+	if name != "" {
+		p, ok := config.Profiles[name]
+		if !ok {
+			return nil, usageErrorf("profile %q does not exist", name)
+		}
+		selected = p
+	}
+
+	server := firstNonEmpty(a.server, a.getenv("CONFIGCTL_SERVER"), selected.Server, defaultServer)
+	apiKey := firstNonEmpty(a.apiKey, a.getenv("CONFIGCTL_API_KEY"), selected.APIKey)
+	return client.New(server, client.WithAPIKey(apiKey))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// profileCommand manages the profiles file
+func (a *app) profileCommand(args []string) error {
+	return a.subcommand("profile", args, map[string]command{
+		"list":   (*app).profileList,
+		"set":    (*app).profileSet,
+		"use":    (*app).profileUse,
+		"delete": (*app).profileDelete,
+	})
+}
+
+func (a *app) profileList(args []string) error {
+	fs := a.flagSet("profile list")
+	if _, err := parseArgs(fs, args, 0, 0, "profile list"); err != nil {
+		return err
+	}
+	if err := a.checkOutput(); err != nil {
+		return err
+	}
+	config, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	if a.output == outputJSON {
+		// API keys are not printed
+		type listed struct {
+			Name    string `json:"name"`
+			Server  string `json:"server"`
+			Current bool   `json:"current"`
+		}
+		profiles := []listed{}
+		for _, name := range profileNames(config) {
+			profiles = append(profiles, listed{name, config.Profiles[name].Server, name == config.CurrentProfile})
+		}
+		return a.printJSON(map[string]interface{}{"profiles": profiles})
+	}
+
+	t := a.table("CURRENT", "NAME", "SERVER", "API KEY")
+	for _, name := range profileNames(config) {
+		p := config.Profiles[name]
+		current, apiKey := "", ""
+		if name == config.CurrentProfile {
+			current = "*"
+		}
+		if p.APIKey != "" {
+			apiKey = "set"
+		}
+		t.row(current, name, p.Server, apiKey)
+	}
+	return t.flush()
+}
+
+// profileSet creates or changes a profile; --server and --api-key set its values here
+// rather than overriding them
+func (a *app) profileSet(args []string) error {
+	fs := a.flagSet("profile set")
+	positional, err := parseArgs(fs, args, 1, 1, "profile set <name> [--server url] [--api-key key]")
+	if err != nil {
+		return err
+	}
+	server, apiKey := a.server, a.apiKey
+
+	config, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	p, ok := config.Profiles[name]
+	if !ok {
+		p = &profile{Server: defaultServer}
+		config.Profiles[name] = p
+	}
+	if server != "" {
+		if _, err := client.New(server); err != nil {
+			return usageErrorf("%v", err)
+		}
+		p.Server = server
+	}
+	if apiKey != "" {
+		p.APIKey = apiKey
+	}
+	if config.CurrentProfile == "" {
+		config.CurrentProfile = name
+	}
+	if err := a.saveProfiles(config); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Profile %q saved\n", name)
+	return nil
+}
+
+func (a *app) profileUse(args []string) error {
+	fs := a.flagSet("profile use")
+	positional, err := parseArgs(fs, args, 1, 1, "profile use <name>")
+	if err != nil {
+		return err
+	}
+
+	config, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	if _, ok := config.Profiles[name]; !ok {
+		return usageErrorf("profile %q does not exist", name)
+	}
+	config.CurrentProfile = name
+	if err := a.saveProfiles(config); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Using profile %q\n", name)
+	return nil
+}
+
+func (a *app) profileDelete(args []string) error {
+	fs := a.flagSet("profile delete")
+	positional, err := parseArgs(fs, args, 1, 1, "profile delete <name>")
+	if err != nil {
+		return err
+	}
+
+	config, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+	name := positional[0]
+	if _, ok := config.Profiles[name]; !ok {
+		return usageErrorf("profile %q does not exist", name)
+	}
+	delete(config.Profiles, name)
+	if config.CurrentProfile == name {
+		config.CurrentProfile = ""
+	}
+	if err := a.saveProfiles(config); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Profile %q deleted\n", name)
+	return nil
+}
+
+func profileNames(config *profileConfig) []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,16 +1,33 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	grpcDelivery "github.com/Titonu/configuration-management-service/internal/delivery/grpc"
 	"github.com/Titonu/configuration-management-service/internal/delivery/http"
 	"github.com/Titonu/configuration-management-service/internal/delivery/http/handler"
 	"github.com/Titonu/configuration-management-service/internal/delivery/http/middleware"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/metrics"
 	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	"github.com/Titonu/configuration-management-service/internal/scheduler"
+	"github.com/Titonu/configuration-management-service/internal/tracing"
 	"github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"io"
 	"log"
+	"net"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -20,12 +37,46 @@ func main() {
 		ginMode = gin.DebugMode
 	}
 	gin.SetMode(ginMode)
+	// Set up tracing; spans are only recorded when an exporter is configured
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "configuration-management-service"
+	}
+	tracerProvider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:    os.Getenv("TRACING_EXPORTER"),
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		ServiceName: serviceName,
+		SampleRatio: parseRatio(os.Getenv("TRACING_SAMPLE_RATIO"), 1),
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(tracing.Propagator())
+
 	// Initialize router
 	router := gin.Default()
 
-	// Apply CORS middleware
+	// Trace and record metrics for every request, then apply CORS middleware
+	serviceMetrics := metrics.New()
+	router.Use(middleware.TracingMiddleware(tracing.Tracer(tracerProvider), tracing.Propagator()))
+	router.Use(middleware.MetricsMiddleware(serviceMetrics))
 	router.Use(middleware.CORSMiddleware())
 
+	// Give every request a deadline that is passed down to the usecase and repository.
+	// Archive exports stream the whole database and get a deadline of their own.
+	requestTimeout := parseDuration(os.Getenv("REQUEST_TIMEOUT"), 30*time.Second)
+	exportTimeout := parseDuration(os.Getenv("EXPORT_TIMEOUT"), 10*time.Minute)
+	router.Use(middleware.TimeoutMiddleware(requestTimeout, middleware.WithRouteTimeout("/api/v1/export", exportTimeout)))
+	log.Printf("Requests time out after %s, archive exports after %s", requestTimeout, exportTimeout)
+
 	dbPath := os.Getenv("SQLITE_DB_PATH")
 	if dbPath == "" {
 		dbPath = "data/config.db"
@@ -42,9 +93,53 @@ func main() {
 		log.Fatalf("Failed to initialize SQLite repository: %v", err)
 	}
 	log.Printf("Using SQLite storage at %s", dbPath)
+	defer func() {
+		if closer, ok := configRepo.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("Failed to close SQLite repository: %v", err)
+			}
+		}
+	}()
+
+	// Export version counts and connection pool statistics, then instrument the repository
+	serviceMetrics.RegisterVersions(configRepo)
+	if withDB, ok := configRepo.(interface{ DB() *sql.DB }); ok {
+		serviceMetrics.RegisterDB(withDB.DB(), "sqlite")
+	}
+	configRepo = metrics.NewRepository(configRepo, serviceMetrics)
+	configRepo = tracing.NewRepository(configRepo, tracerProvider)
 
 	// Initialize usecase
-	configUseCase := usecase.NewConfigurationUseCase(configRepo)
+	approvalPolicy := parseApprovalPolicy(os.Getenv("APPROVAL_POLICIES"))
+	schemaCompatibility := parseSchemaCompatibility(os.Getenv("SCHEMA_COMPATIBILITY"))
+	strictFormats := parseBool(os.Getenv("SCHEMA_STRICT_FORMATS"), false)
+	configUseCase := usecase.NewConfigurationUseCase(
+		configRepo,
+		usecase.WithApprovalPolicy(approvalPolicy),
+		usecase.WithSchemaCompatibility(schemaCompatibility),
+		usecase.WithStrictFormats(strictFormats),
+		usecase.WithValidatorWrapper(func(v validator.Validator) validator.Validator {
+			return metrics.NewValidator(v, serviceMetrics)
+		}),
+		usecase.WithTracer(tracing.Tracer(tracerProvider)),
+	)
+	configUseCase = tracing.NewUsecase(configUseCase, tracerProvider)
+	for _, rule := range approvalPolicy {
+		log.Printf("Configurations matching %q require %d approvals", rule.Pattern, rule.RequiredApprovals)
+	}
+	log.Printf("Schema compatibility mode: %s", schemaCompatibility)
+
+	// Start the scheduler that applies scheduled configuration changes
+	schedulerInterval := parseDuration(os.Getenv("SCHEDULER_INTERVAL"), 30*time.Second)
+	changeScheduler := scheduler.NewScheduler(configUseCase, schedulerInterval)
+	changeScheduler.Start()
+	log.Printf("Scheduler polling for due changes every %s", schedulerInterval)
+
+	// Start the compactor that enforces version retention policies
+	compactionInterval := parseDuration(os.Getenv("COMPACTION_INTERVAL"), time.Hour)
+	historyCompactor := scheduler.NewCompactor(configUseCase, compactionInterval)
+	historyCompactor.Start()
+	log.Printf("Compactor enforcing retention policies every %s", compactionInterval)
 
 	// Initialize handlers
 	configHandler := handler.NewConfigurationHandler(configUseCase)
@@ -58,20 +153,88 @@ func main() {
 	}
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(apiKeys)
+	authMiddleware := middleware.NewAuthMiddleware(apiKeys, middleware.WithFailureObserver(serviceMetrics.ObserveAuthFailure))
+	roleMiddleware := middleware.NewRoleMiddleware(parseClientRoles(os.Getenv("CLIENT_ROLES")))
 
 	// Set up routes
-	http.SetupRoutes(router, configHandler, authMiddleware)
+	http.SetupRoutes(router, configHandler, authMiddleware, roleMiddleware)
+
+	// Expose metrics for Prometheus, like the health check without authentication
+	router.GET("/metrics", gin.WrapH(serviceMetrics.Handler()))
+
+	// Start the gRPC server alongside the REST API, sharing the usecase and API keys
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	watchInterval := parseDuration(os.Getenv("GRPC_WATCH_INTERVAL"), 2*time.Second)
+	grpcServer := grpcDelivery.NewGRPCServer(
+		grpcDelivery.NewServer(configUseCase,
+			grpcDelivery.WithWatchInterval(watchInterval),
+			grpcDelivery.WithRequestTimeout(requestTimeout),
+			grpcDelivery.WithAuthFailureObserver(serviceMetrics.ObserveAuthFailure),
+		),
+		apiKeys,
+	)
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
+	}
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+	log.Printf("Starting gRPC server on port %s", grpcPort)
 
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
-
+	server := &nethttp.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != nethttp.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
 	log.Printf("Starting server on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+
+	// Serve until interrupted, then let in-flight requests finish before stopping the
+	// background jobs, so that no request or scheduled change is cut off halfway
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	stop()
+
+	shutdownTimeout := parseDuration(os.Getenv("SHUTDOWN_TIMEOUT"), 30*time.Second)
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to finish in-flight requests: %v", err)
+	}
+	changeScheduler.Stop()
+	historyCompactor.Stop()
+	stopGRPC(shutdownCtx, grpcServer)
+	log.Printf("Server stopped")
+}
+
+// stopGRPC stops the gRPC server gracefully, closing the connections still open when ctx
+// ends, such as those of Watch streams that never finish on their own
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+		<-done
 	}
 }
 
@@ -94,3 +257,122 @@ func parseAPIKeys(keysStr string) map[string]string {
 
 	return result
 }
+
+// parseClientRoles parses client roles from environment variable
+// Format: client1:role1|role2,client2:role1
+func parseClientRoles(rolesStr string) map[string][]string {
+	result := make(map[string][]string)
+
+	if rolesStr == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(rolesStr, ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			log.Printf("WARNING: Ignoring invalid client roles %q", pair)
+			continue
+		}
+
+		clientID := strings.TrimSpace(parts[0])
+		for _, role := range strings.Split(parts[1], "|") {
+			if role = strings.TrimSpace(role); role != "" {
+				result[clientID] = append(result[clientID], role)
+			}
+		}
+	}
+
+	return result
+}
+
+// parseApprovalPolicy parses approval rules from environment variable
+// Format: pattern1:approvals1,pattern2:approvals2 (first matching pattern wins)
+func parseApprovalPolicy(policyStr string) entity.ApprovalPolicy {
+	policy := entity.ApprovalPolicy{}
+
+	if policyStr == "" {
+		return policy
+	}
+
+	for _, pair := range strings.Split(policyStr, ",") {
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			log.Printf("WARNING: Ignoring invalid approval rule %q", pair)
+			continue
+		}
+
+		pattern := strings.TrimSpace(pair[:idx])
+		approvals, err := strconv.Atoi(strings.TrimSpace(pair[idx+1:]))
+		if err != nil || approvals < 0 {
+			log.Printf("WARNING: Ignoring invalid approval rule %q", pair)
+			continue
+		}
+
+		policy = append(policy, entity.ApprovalRule{Pattern: pattern, RequiredApprovals: approvals})
+	}
+
+	return policy
+}
+
+// parseSchemaCompatibility parses the schema compatibility mode, defaulting to backward
+func parseSchemaCompatibility(value string) entity.SchemaCompatibility {
+	if value == "" {
+		return entity.SchemaCompatibilityBackward
+	}
+
+	mode := entity.SchemaCompatibility(strings.ToLower(strings.TrimSpace(value)))
+	if !mode.IsValid() {
+		log.Printf("WARNING: Invalid schema compatibility mode %q, using %s", value, entity.SchemaCompatibilityBackward)
+		return entity.SchemaCompatibilityBackward
+	}
+
+	return mode
+}
+
+// parseDuration parses a duration from an environment variable value,
+// falling back to the default when it is empty or invalid
+func parseDuration(value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("WARNING: Invalid duration %q, using default %s", value, defaultValue)
+		return defaultValue
+	}
+
+	return d
+}
+
+// parseBool parses a boolean from an environment variable value, falling back to the
+// default when it is empty or invalid
+func parseBool(value string, defaultValue bool) bool {
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		log.Printf("WARNING: Invalid boolean %q, using default %t", value, defaultValue)
+		return defaultValue
+	}
+
+	return b
+}
+
+// parseRatio parses a ratio between 0 and 1 from an environment variable value,
+// falling back to the default when it is empty or invalid
+func parseRatio(value string, defaultValue float64) float64 {
+	if value == "" {
+		return defaultValue
+	}
+
+	r, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || r < 0 || r > 1 {
+		log.Printf("WARNING: Invalid ratio %q, using default %g", value, defaultValue)
+		return defaultValue
+	}
+
+	return r
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,6 +19,7 @@ func TestServerStartup(t *testing.T) {
 
 	// Set environment variables for testing
 	os.Setenv("PORT", "8082")
+	os.Setenv("GRPC_PORT", "9092")
 
 	// Start server in a goroutine
 	go func() {
@@ -98,16 +100,19 @@ func TestAuthenticationEndpoints(t *testing.T) {
 
 	// Set environment variables for testing
 	originalPort := os.Getenv("PORT")
+	originalGRPCPort := os.Getenv("GRPC_PORT")
 	originalAPIKeys := os.Getenv("API_KEYS")
 
 	// Restore original environment variables after test
 	defer func() {
 		os.Setenv("PORT", originalPort)
+		os.Setenv("GRPC_PORT", originalGRPCPort)
 		os.Setenv("API_KEYS", originalAPIKeys)
 	}()
 
 	// Set test environment variables
 	os.Setenv("PORT", "8083")
+	os.Setenv("GRPC_PORT", "9093")
 	os.Setenv("API_KEYS", "test-api-key:test-client")
 
 	// Start server in a goroutine
@@ -187,3 +192,28 @@ func TestAuthenticationEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestParseApprovalPolicy(t *testing.T) {
+	policy := parseApprovalPolicy("prod-*:2, payments:1,invalid,bad:x")
+
+	assert.Len(t, policy, 2)
+	assert.Equal(t, 2, policy.RequiredApprovals("prod-checkout"))
+	assert.Equal(t, 1, policy.RequiredApprovals("payments"))
+	assert.Equal(t, 0, policy.RequiredApprovals("staging-checkout"))
+	assert.Empty(t, parseApprovalPolicy(""))
+}
+
+func TestParseSchemaCompatibility(t *testing.T) {
+	assert.Equal(t, entity.SchemaCompatibilityBackward, parseSchemaCompatibility(""))
+	assert.Equal(t, entity.SchemaCompatibilityNone, parseSchemaCompatibility("NONE"))
+	assert.Equal(t, entity.SchemaCompatibilityBackward, parseSchemaCompatibility("sideways"))
+}
+
+func TestParseClientRoles(t *testing.T) {
+	roles := parseClientRoles("ops:admin|auditor, development:admin,invalid")
+
+	assert.Equal(t, []string{"admin", "auditor"}, roles["ops"])
+	assert.Equal(t, []string{"admin"}, roles["development"])
+	assert.Len(t, roles, 2)
+	assert.Empty(t, parseClientRoles(""))
+}
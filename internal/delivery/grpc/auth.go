@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/internal/metrics"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// clientIDKey is the context key of the authenticated client ID
+type clientIDKey struct{}
+
+// ClientID returns the ID of the client that made a call, set by the auth interceptor
+func ClientID(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientIDKey{}).(string)
+	return clientID
+}
+
+// AuthInterceptor authenticates gRPC calls with the API keys of the REST API, sent as
+// "authorization: Bearer <key>" metadata
+type AuthInterceptor struct {
+	apiKeys        map[string]string // map of API key to user/client ID
+	observeFailure func(reason string)
+}
+
+// NewAuthInterceptor creates a new authentication interceptor. If observeFailure is not
+// nil, it is called with the reason of every rejected call, one of the
+// metrics.AuthFailure reasons.
+func NewAuthInterceptor(apiKeys map[string]string, observeFailure func(reason string)) *AuthInterceptor {
+	return &AuthInterceptor{
+		apiKeys:        apiKeys,
+		observeFailure: observeFailure,
+	}
+}
+
+// Unary returns an interceptor that authenticates unary calls
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns an interceptor that authenticates streaming calls
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate validates the API key of a call and returns its context with the client ID
+func (i *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		i.failed(metrics.AuthFailureMissingKey)
+		return nil, toStatus(errors.NewAppError("API key is required", errors.ErrorCodeUnauthorized, nil))
+	}
+
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		i.failed(metrics.AuthFailureInvalidFormat)
+		return nil, toStatus(errors.NewAppError("Invalid authorization format", errors.ErrorCodeUnauthorized, nil))
+	}
+
+	clientID, valid := i.apiKeys[parts[1]]
+	if !valid {
+		i.failed(metrics.AuthFailureInvalidKey)
+		return nil, toStatus(errors.NewAppError("Invalid API key", errors.ErrorCodeUnauthorized, nil))
+	}
+
+	return context.WithValue(ctx, clientIDKey{}, clientID), nil
+}
+
+// failed reports a rejected call to the failure observer, if any
+func (i *AuthInterceptor) failed(reason string) {
+	if i.observeFailure != nil {
+		i.observeFailure(reason)
+	}
+}
+
+// authenticatedStream is a server stream whose context carries the client ID
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
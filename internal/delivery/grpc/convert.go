@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	pb "github.com/Titonu/configuration-management-service/pkg/pb/configuration/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoConfiguration(config *entity.Configuration) *pb.Configuration {
+	return &pb.Configuration{
+		Name:            config.Name,
+		Version:         int32(config.Version),
+		Data:            config.Data,
+		CreatedAt:       toProtoTime(config.CreatedAt),
+		UpdatedAt:       toProtoTime(config.UpdatedAt),
+		RollbackFrom:    int32(config.RollbackFrom),
+		RollbackTo:      int32(config.RollbackTo),
+		SchemaVersion:   int32(config.SchemaVersion),
+		DefaultedFields: config.DefaultedFields,
+		ChangesetId:     config.ChangesetID,
+	}
+}
+
+func toProtoVersionList(list *entity.VersionList) *pb.ListConfigurationVersionsResponse {
+	response := &pb.ListConfigurationVersionsResponse{Name: list.Name}
+	for _, v := range list.Versions {
+		response.Versions = append(response.Versions, &pb.VersionInfo{
+			Version:       int32(v.Version),
+			CreatedAt:     toProtoTime(v.CreatedAt),
+			IsRollback:    v.IsRollback,
+			Compacted:     v.Compacted,
+			Tags:          v.Tags,
+			SchemaVersion: int32(v.SchemaVersion),
+			ChangesetId:   v.ChangesetID,
+		})
+	}
+	return response
+}
+
+func toProtoSchemaVersion(schemaVersion *entity.SchemaVersion) *pb.SchemaVersion {
+	return &pb.SchemaVersion{
+		Name:      schemaVersion.Name,
+		Version:   int32(schemaVersion.Version),
+		Schema:    schemaVersion.Schema,
+		CreatedAt: toProtoTime(schemaVersion.CreatedAt),
+	}
+}
+
+func toProtoValidationReport(report *entity.ValidationReport) *pb.ValidationReport {
+	response := &pb.ValidationReport{
+		Valid: report.Valid,
+		Summary: &pb.ValidationSummary{
+			SchemaChecked: report.Summary.SchemaChecked,
+			RulesChecked:  int32(report.Summary.RulesChecked),
+			ErrorCount:    int32(report.Summary.ErrorCount),
+			SyntaxErrors:  int32(report.Summary.SyntaxErrors),
+			SchemaErrors:  int32(report.Summary.SchemaErrors),
+			RuleErrors:    int32(report.Summary.RuleErrors),
+		},
+	}
+	for _, issue := range report.Errors {
+		response.Errors = append(response.Errors, &pb.ValidationIssue{
+			Source:   string(issue.Source),
+			Field:    issue.Field,
+			Reason:   issue.Reason,
+			Pointer:  issue.Pointer,
+			Keyword:  issue.Keyword,
+			Code:     string(issue.Code),
+			Expected: toJSONValue(issue.Expected),
+			Actual:   toJSONValue(issue.Actual),
+			Line:     int32(issue.Line),
+			Column:   int32(issue.Column),
+		})
+	}
+	return response
+}
+
+// toProtoTime converts a timestamp, leaving zero times unset
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toJSONValue encodes an optional value of a validation issue as JSON
+func toJSONValue(v interface{}) []byte {
+	if v == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return encoded
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	stdErrors "errors"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain identifies this service in the ErrorInfo details of a status
+const errorDomain = "configuration-management-service"
+
+// statusCodes maps API error codes to the gRPC codes with the same meaning
+var statusCodes = map[errors.ErrorCode]codes.Code{
+	errors.ErrorCodeInvalidRequest:   codes.InvalidArgument,
+	errors.ErrorCodeValidationFailed: codes.InvalidArgument,
+	errors.ErrorCodeNotAcceptable:    codes.InvalidArgument,
+	errors.ErrorCodeNotFound:         codes.NotFound,
+	errors.ErrorCodeVersionCompacted: codes.NotFound,
+	errors.ErrorCodeAlreadyExists:    codes.AlreadyExists,
+	errors.ErrorCodeConflict:         codes.Aborted,
+	errors.ErrorCodeForbidden:        codes.PermissionDenied,
+	errors.ErrorCodeUnauthorized:     codes.Unauthenticated,
+	errors.ErrorCodeInternalError:    codes.Internal,
+	errors.ErrorCodeRequestCancelled: codes.Canceled,
+	errors.ErrorCodeRequestTimeout:   codes.DeadlineExceeded,
+}
+
+// toStatus converts a usecase error into a gRPC status error. The API error code is
+// kept as the reason of an ErrorInfo detail, since several codes share a gRPC code, and
+// validation errors are attached as BadRequest field violations.
+func toStatus(err error) error {
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) {
+		return status.Error(codes.Internal, "Internal server error")
+	}
+
+	code, ok := statusCodes[appErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, appErr.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(appErr.Code), Domain: errorDomain}}
+	if validationErrors, ok := appErr.Details.([]errors.ValidationError); ok && len(validationErrors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, validationErr := range validationErrors {
+			field := validationErr.Pointer
+			if field == "" {
+				field = validationErr.Field
+			}
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: validationErr.Reason,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,237 @@
+// Package grpc serves the configuration service over gRPC, mirroring the REST API's
+// configuration, schema and validation endpoints on top of the same usecase.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	pb "github.com/Titonu/configuration-management-service/pkg/pb/configuration/v1"
+	"google.golang.org/grpc"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// Server implements the ConfigurationService gRPC service
+type Server struct {
+	pb.UnimplementedConfigurationServiceServer
+
+	configService      usecase.ConfigurationUsecase
+	watchInterval      time.Duration
+	requestTimeout     time.Duration
+	observeAuthFailure func(reason string)
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithWatchInterval sets how often Watch calls check for new versions
+func WithWatchInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		if interval > 0 {
+			s.watchInterval = interval
+		}
+	}
+}
+
+// WithRequestTimeout gives every unary call a deadline of timeout, unless the client set
+// an earlier one. Watch streams are long-lived and have no server-side deadline.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.requestTimeout = timeout
+	}
+}
+
+// WithAuthFailureObserver calls observe with the reason of every call rejected by
+// authentication
+func WithAuthFailureObserver(observe func(reason string)) Option {
+	return func(s *Server) {
+		s.observeAuthFailure = observe
+	}
+}
+
+// NewServer creates a gRPC configuration service backed by a configuration usecase
+func NewServer(configService usecase.ConfigurationUsecase, opts ...Option) *Server {
+	s := &Server{
+		configService: configService,
+		watchInterval: defaultWatchInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewGRPCServer creates a gRPC server exposing the configuration service, authenticating
+// every call with the given API keys (a map of API key to client ID)
+func NewGRPCServer(server *Server, apiKeys map[string]string, opts ...grpc.ServerOption) *grpc.Server {
+	auth := NewAuthInterceptor(apiKeys, server.observeAuthFailure)
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(auth.Unary(), unaryContextInterceptor(server.requestTimeout)),
+		grpc.ChainStreamInterceptor(auth.Stream(), streamContextInterceptor()),
+	)
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterConfigurationServiceServer(grpcServer, server)
+	return grpcServer
+}
+
+// CreateConfiguration creates a configuration at version 1
+func (s *Server) CreateConfiguration(ctx context.Context, req *pb.CreateConfigurationRequest) (*pb.Configuration, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	data, err := jsonDocument(req.GetData(), "data")
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.configService.CreateConfiguration(ctx, req.GetName(), data)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoConfiguration(config), nil
+}
+
+// UpdateConfiguration stores data as the next version of a configuration
+func (s *Server) UpdateConfiguration(ctx context.Context, req *pb.UpdateConfigurationRequest) (*pb.Configuration, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	data, err := jsonDocument(req.GetData(), "data")
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.configService.UpdateConfiguration(ctx, req.GetName(), data)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoConfiguration(config), nil
+}
+
+// GetConfiguration returns the current version of a configuration
+func (s *Server) GetConfiguration(ctx context.Context, req *pb.GetConfigurationRequest) (*pb.Configuration, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+
+	config, err := s.configService.GetConfiguration(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoConfiguration(config), nil
+}
+
+// GetConfigurationVersion returns a specific version of a configuration
+func (s *Server) GetConfigurationVersion(ctx context.Context, req *pb.GetConfigurationVersionRequest) (*pb.Configuration, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	if req.GetVersion() < 1 {
+		return nil, invalidRequest("Version must be a positive number")
+	}
+
+	config, err := s.configService.GetConfigurationVersion(ctx, req.GetName(), int(req.GetVersion()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoConfiguration(config), nil
+}
+
+// ListConfigurationVersions lists the versions of a configuration
+func (s *Server) ListConfigurationVersions(ctx context.Context, req *pb.ListConfigurationVersionsRequest) (*pb.ListConfigurationVersionsResponse, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+
+	versions, err := s.configService.ListConfigurationVersions(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoVersionList(versions), nil
+}
+
+// RollbackConfiguration restores the data of a previous version as the next version
+func (s *Server) RollbackConfiguration(ctx context.Context, req *pb.RollbackConfigurationRequest) (*pb.Configuration, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	if req.GetTargetVersion() < 1 {
+		return nil, invalidRequest("Target version must be a positive number")
+	}
+
+	config, err := s.configService.RollbackConfiguration(ctx, req.GetName(), int(req.GetTargetVersion()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoConfiguration(config), nil
+}
+
+// RegisterSchema stores a JSON schema as the next schema version of a configuration
+func (s *Server) RegisterSchema(ctx context.Context, req *pb.RegisterSchemaRequest) (*pb.SchemaVersion, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	schema, err := jsonDocument(req.GetSchema(), "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := entity.SchemaRegistrationOptions{Force: req.GetForce(), CheckVersions: int(req.GetCheckVersions())}
+	schemaVersion, err := s.configService.RegisterSchema(ctx, req.GetName(), schema, opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoSchemaVersion(schemaVersion), nil
+}
+
+// GetSchema returns the current schema of a configuration
+func (s *Server) GetSchema(ctx context.Context, req *pb.GetSchemaRequest) (*pb.GetSchemaResponse, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+
+	schema, err := s.configService.GetSchema(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GetSchemaResponse{Name: req.GetName(), Schema: schema}, nil
+}
+
+// ValidateDocument validates a document against the schema and rules of a configuration
+// without storing it. Invalid documents are reported in the response rather than as an
+// error, as by the REST endpoint.
+func (s *Server) ValidateDocument(ctx context.Context, req *pb.ValidateDocumentRequest) (*pb.ValidationReport, error) {
+	if req.GetName() == "" {
+		return nil, invalidRequest("Configuration name is required")
+	}
+	if len(req.GetDocument()) == 0 {
+		return nil, invalidRequest("Document is required")
+	}
+
+	report, err := s.configService.ValidateDocument(ctx, req.GetName(), req.GetDocument())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoValidationReport(report), nil
+}
+
+// jsonDocument checks that a required request field holds a JSON document
+func jsonDocument(data []byte, field string) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return nil, invalidRequest("The " + field + " field is required")
+	}
+	if !json.Valid(data) {
+		return nil, toStatus(errors.NewAppError("The "+field+" field must be a JSON document", errors.ErrorCodeInvalidRequest, nil))
+	}
+	return data, nil
+}
+
+// invalidRequest returns the status of a malformed request
+func invalidRequest(message string) error {
+	return toStatus(errors.NewAppError(message, errors.ErrorCodeInvalidRequest, nil))
+}
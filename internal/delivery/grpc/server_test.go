@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
+	"github.com/Titonu/configuration-management-service/internal/metrics"
+	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	implUsecase "github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	pb "github.com/Titonu/configuration-management-service/pkg/pb/configuration/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testAPIKey = "test-api-key"
+
+// setupServer serves the gRPC service over an in-memory connection, backed by a usecase
+// on a temporary database, and returns a client for it and the usecase
+func setupServer(t *testing.T) (pb.ConfigurationServiceClient, usecase.ConfigurationUsecase) {
+	repo, err := sqlite.NewConfigurationRepository(filepath.Join(t.TempDir(), "config.db"))
+	require.NoError(t, err)
+	configService := implUsecase.NewConfigurationUseCase(repo)
+
+	server := NewGRPCServer(NewServer(configService, WithWatchInterval(10*time.Millisecond)), map[string]string{testAPIKey: "test-client"})
+	return dial(t, server), configService
+}
+
+// dial serves a gRPC server over an in-memory connection and returns a client for it
+func dial(t *testing.T, server *grpc.Server) pb.ConfigurationServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewConfigurationServiceClient(conn)
+}
+
+// authContext returns a context carrying the API key, cancelled when the test ends
+func authContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+testAPIKey)
+}
+
+// errorReason returns the API error code carried by a status error
+func errorReason(t *testing.T, err error) string {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestConfigurationRPCs(t *testing.T) {
+	client, _ := setupServer(t)
+	ctx := authContext(t)
+
+	created, err := client.CreateConfiguration(ctx, &pb.CreateConfigurationRequest{Name: "payments", Data: []byte(`{"max_limit": 1000}`)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), created.GetVersion())
+	assert.NotNil(t, created.GetCreatedAt())
+
+	updated, err := client.UpdateConfiguration(ctx, &pb.UpdateConfigurationRequest{Name: "payments", Data: []byte(`{"max_limit": 2000}`)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), updated.GetVersion())
+
+	current, err := client.GetConfiguration(ctx, &pb.GetConfigurationRequest{Name: "payments"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_limit": 2000}`, string(current.GetData()))
+
+	first, err := client.GetConfigurationVersion(ctx, &pb.GetConfigurationVersionRequest{Name: "payments", Version: 1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_limit": 1000}`, string(first.GetData()))
+
+	rolledBack, err := client.RollbackConfiguration(ctx, &pb.RollbackConfigurationRequest{Name: "payments", TargetVersion: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), rolledBack.GetVersion())
+	assert.Equal(t, int32(1), rolledBack.GetRollbackTo())
+
+	versions, err := client.ListConfigurationVersions(ctx, &pb.ListConfigurationVersionsRequest{Name: "payments"})
+	require.NoError(t, err)
+	require.Len(t, versions.GetVersions(), 3)
+	assert.True(t, versions.GetVersions()[2].GetIsRollback())
+}
+
+func TestSchemaAndValidationRPCs(t *testing.T) {
+	client, _ := setupServer(t)
+	ctx := authContext(t)
+
+	schema := []byte(`{"type": "object", "properties": {"max_limit": {"type": "integer", "maximum": 5000}}, "required": ["max_limit"]}`)
+	registered, err := client.RegisterSchema(ctx, &pb.RegisterSchemaRequest{Name: "payments", Schema: schema})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), registered.GetVersion())
+
+	stored, err := client.GetSchema(ctx, &pb.GetSchemaRequest{Name: "payments"})
+	require.NoError(t, err)
+	assert.Contains(t, string(stored.GetSchema()), `"maximum": 5000`)
+
+	report, err := client.ValidateDocument(ctx, &pb.ValidateDocumentRequest{Name: "payments", Document: []byte("{\n  \"max_limit\": 9000\n}")})
+	require.NoError(t, err)
+	assert.False(t, report.GetValid())
+	require.Len(t, report.GetErrors(), 1)
+	assert.Equal(t, "/max_limit", report.GetErrors()[0].GetPointer())
+	assert.Equal(t, int32(2), report.GetErrors()[0].GetLine())
+	assert.Equal(t, "5000", string(report.GetErrors()[0].GetExpected()))
+
+	// Writes that do not conform fail with InvalidArgument and field violations
+	_, err = client.CreateConfiguration(ctx, &pb.CreateConfigurationRequest{Name: "payments", Data: []byte(`{"max_limit": 9000}`)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, string(errors.ErrorCodeValidationFailed), errorReason(t, err))
+
+	st, _ := status.FromError(err)
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	require.Len(t, violations, 1)
+	assert.Equal(t, "/max_limit", violations[0].GetField())
+}
+
+func TestErrorMapping(t *testing.T) {
+	client, _ := setupServer(t)
+	ctx := authContext(t)
+
+	_, err := client.GetConfiguration(ctx, &pb.GetConfigurationRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, string(errors.ErrorCodeNotFound), errorReason(t, err))
+
+	_, err = client.CreateConfiguration(ctx, &pb.CreateConfigurationRequest{Name: "payments", Data: []byte(`{}`)})
+	require.NoError(t, err)
+	_, err = client.CreateConfiguration(ctx, &pb.CreateConfigurationRequest{Name: "payments", Data: []byte(`{}`)})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.CreateConfiguration(ctx, &pb.CreateConfigurationRequest{Name: "broken", Data: []byte(`{not json`)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, string(errors.ErrorCodeInvalidRequest), errorReason(t, err))
+
+	_, err = client.GetConfigurationVersion(ctx, &pb.GetConfigurationVersionRequest{Name: "payments"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.Equal(t, codes.Aborted, status.Code(toStatus(errors.NewAppError("Conflict", errors.ErrorCodeConflict, nil))))
+	assert.Equal(t, codes.PermissionDenied, status.Code(toStatus(errors.NewAppError("Forbidden", errors.ErrorCodeForbidden, nil))))
+	assert.Equal(t, codes.Internal, status.Code(toStatus(assert.AnError)))
+}
+
+// blockingService is a usecase whose reads block until the call's context ends and then
+// fail like a read aborted in the repository
+type blockingService struct {
+	usecase.ConfigurationUsecase
+}
+
+func (blockingService) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
+	<-ctx.Done()
+	return nil, errors.NewNotFoundError("Configuration", name)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := NewServer(blockingService{}, WithRequestTimeout(20*time.Millisecond))
+	client := dial(t, NewGRPCServer(server, map[string]string{testAPIKey: "test-client"}))
+
+	_, err := client.GetConfiguration(authContext(t), &pb.GetConfigurationRequest{Name: "payments"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, string(errors.ErrorCodeRequestTimeout), errorReason(t, err))
+
+	assert.Equal(t, codes.Canceled, status.Code(toStatus(errors.NewContextError(context.Canceled))))
+}
+
+func TestAuthentication(t *testing.T) {
+	client, _ := setupServer(t)
+
+	_, err := client.GetConfiguration(context.Background(), &pb.GetConfigurationRequest{Name: "payments"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong-key")
+	_, err = client.GetConfiguration(ctx, &pb.GetConfigurationRequest{Name: "payments"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, string(errors.ErrorCodeUnauthorized), errorReason(t, err))
+
+	stream, err := client.Watch(context.Background(), &pb.WatchRequest{Names: []string{"payments"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestWatch(t *testing.T) {
+	client, configService := setupServer(t)
+	ctx := authContext(t)
+
+	_, err := configService.CreateConfiguration(context.Background(), "payments", []byte(`{"max_limit": 1000}`))
+	require.NoError(t, err)
+
+	stream, err := client.Watch(ctx, &pb.WatchRequest{Names: []string{"payments", "routing", "payments"}})
+	require.NoError(t, err)
+
+	// The current version is sent first
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, event.GetInitial())
+	assert.Equal(t, "payments", event.GetConfiguration().GetName())
+	assert.Equal(t, int32(1), event.GetConfiguration().GetVersion())
+
+	// Then new versions, including of configurations created after the watch started
+	_, err = configService.UpdateConfiguration(context.Background(), "payments", []byte(`{"max_limit": 2000}`))
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.False(t, event.GetInitial())
+	assert.Equal(t, int32(2), event.GetConfiguration().GetVersion())
+
+	_, err = configService.CreateConfiguration(context.Background(), "routing", []byte(`{"region": "eu"}`))
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "routing", event.GetConfiguration().GetName())
+}
+
+func TestWatchRejectsInvalidRequest(t *testing.T) {
+	client, _ := setupServer(t)
+
+	stream, err := client.Watch(authContext(t), &pb.WatchRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthFailureObserver(t *testing.T) {
+	var reasons []string
+	auth := NewAuthInterceptor(map[string]string{testAPIKey: "test-client"}, func(reason string) { reasons = append(reasons, reason) })
+
+	for _, value := range []string{"", "Basic " + testAPIKey, "Bearer wrong-key", "Bearer " + testAPIKey} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+		auth.authenticate(ctx)
+	}
+
+	// Only rejected calls are reported
+	assert.Equal(t, []string{metrics.AuthFailureMissingKey, metrics.AuthFailureInvalidFormat, metrics.AuthFailureInvalidKey}, reasons)
+}
@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// unaryContextInterceptor applies the request timeout to unary calls and reports calls
+// whose context ended as cancelled or timed out, whatever error the usecase derived from
+// the aborted call
+func unaryContextInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil && ctx.Err() != nil {
+			return nil, toStatus(errors.NewContextError(ctx.Err()))
+		}
+		return resp, err
+	}
+}
+
+// streamContextInterceptor reports streaming calls whose context ended as cancelled or
+// timed out
+func streamContextInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, stream)
+		if err != nil && stream.Context().Err() != nil {
+			return toStatus(errors.NewContextError(stream.Context().Err()))
+		}
+		return err
+	}
+}
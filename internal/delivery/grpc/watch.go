@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	pb "github.com/Titonu/configuration-management-service/pkg/pb/configuration/v1"
+)
+
+// Watch streams the current version of each named configuration, then every new
+// version. Changes are found by polling the bulk read every watch interval, so that
+// versions written by other instances sharing the database are seen as well. Names that
+// do not exist yet are watched until they are created.
+func (s *Server) Watch(req *pb.WatchRequest, stream pb.ConfigurationService_WatchServer) error {
+	refs := make([]entity.ConfigurationRef, 0, len(req.GetNames()))
+	seen := make(map[string]bool, len(req.GetNames()))
+	for _, name := range req.GetNames() {
+		if !seen[name] {
+			seen[name] = true
+			refs = append(refs, entity.ConfigurationRef{Name: name})
+		}
+	}
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	sent := make(map[string]*entity.Configuration, len(refs))
+	for initial := true; ; initial = false {
+		items, err := s.configService.GetConfigurations(stream.Context(), refs)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for _, item := range items {
+			config := item.Configuration
+			if config == nil {
+				continue
+			}
+			if last := sent[item.Name]; last != nil && last.Version == config.Version && bytes.Equal(last.Data, config.Data) {
+				continue
+			}
+			if err := stream.Send(&pb.WatchEvent{Configuration: toProtoConfiguration(config), Initial: initial}); err != nil {
+				return err
+			}
+			sent[item.Name] = config
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
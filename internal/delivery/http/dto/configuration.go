@@ -16,9 +16,11 @@ type ConfigurationUpdateRequest struct {
 	Data json.RawMessage `json:"data" binding:"required"`
 }
 
-// RollbackRequest represents the request body for rolling back a configuration
+// RollbackRequest represents the request body for rolling back a configuration.
+// Exactly one of TargetVersion and TargetTag must be set.
 type RollbackRequest struct {
-	TargetVersion int `json:"target_version" binding:"required"`
+	TargetVersion int    `json:"target_version,omitempty"`
+	TargetTag     string `json:"target_tag,omitempty"`
 }
 
 // VersionInfo represents version metadata for listing versions
@@ -26,6 +28,8 @@ type VersionInfo struct {
 	Version    int       `json:"version"`
 	CreatedAt  time.Time `json:"created_at"`
 	IsRollback bool      `json:"is_rollback,omitempty"`
+	Compacted  bool      `json:"compacted,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
 }
 
 // VersionListResponse represents the response for listing versions
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// archiveMediaType is the media type of an uncompressed archive, one JSON record per line
+	archiveMediaType = "application/x-ndjson"
+	// compressedArchiveMediaType is the media type of a gzip-compressed archive
+	compressedArchiveMediaType = "application/gzip"
+)
+
+// ExportArchive handles streaming every configuration with its full history, the shared
+// schemas and the global retention policy as a JSON Lines archive, gzip-compressed when
+// compression=gzip is given
+func (h *ConfigurationHandler) ExportArchive(c *gin.Context) {
+	compress := false
+	switch compression := c.Query("compression"); compression {
+	case "", "none":
+	case "gzip":
+		compress = true
+	default:
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid compression",
+			errors.ErrorCodeInvalidRequest,
+			fmt.Sprintf("unknown compression %q, use gzip or none", compression),
+		))
+		return
+	}
+
+	filename := "configurations-" + time.Now().UTC().Format("20060102T150405Z") + ".jsonl"
+	contentType := archiveMediaType
+	var out io.Writer = c.Writer
+	if compress {
+		filename += ".gz"
+		contentType = compressedArchiveMediaType
+		zw := gzip.NewWriter(c.Writer)
+		defer zw.Close()
+		out = zw
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	err := h.configService.ExportArchive(c.Request.Context(), func(record *entity.ArchiveRecord) error {
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		// The status line is already sent; the missing footer marks the archive as truncated
+		_ = c.Error(err)
+	}
+}
+
+// ImportArchive handles restoring a JSON Lines archive, gzip-compressed or not. The mode
+// query parameter chooses how existing items are treated and dry_run reports the changes
+// without applying them.
+func (h *ConfigurationHandler) ImportArchive(c *gin.Context) {
+	dryRun, err := parseBoolQuery(c, "dry_run")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid dry_run flag",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	records, err := readArchiveRecords(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid archive",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	opts := entity.ImportOptions{Mode: entity.ImportMode(c.Query("mode")), DryRun: dryRun}
+	report, err := h.configService.ImportArchive(c.Request.Context(), records, opts)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest, errors.ErrorCodeValidationFailed:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeForbidden:
+				c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to import archive",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// readArchiveRecords decodes one archive record per line, decompressing the body first
+// when it starts with the gzip magic number. Blank lines are ignored.
+func readArchiveRecords(body io.Reader) ([]*entity.ArchiveRecord, error) {
+	reader := bufio.NewReader(body)
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = bufio.NewReader(zr)
+	}
+
+	records := []*entity.ArchiveRecord{}
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 {
+			var record entity.ArchiveRecord
+			if err := json.Unmarshal(trimmed, &record); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			records = append(records, &record)
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testArchive = `{"type":"header","header":{"format_version":1,"exported_at":"2024-01-01T00:00:00Z"}}
+{"type":"configuration","configuration":{"name":"payments","created_at":"2024-01-01T00:00:00Z","versions":[{"version":1,"data":{"max_limit":1000},"created_at":"2024-01-01T00:00:00Z"}]}}
+
+{"type":"footer","footer":{"records":1}}
+`
+
+// emitRecords makes a mocked ExportArchive emit the given records
+func emitRecords(records ...*entity.ArchiveRecord) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		emit := args.Get(0).(func(*entity.ArchiveRecord) error)
+		for _, record := range records {
+			_ = emit(record)
+		}
+	}
+}
+
+func TestExportArchive(t *testing.T) {
+	records := []*entity.ArchiveRecord{
+		{Type: entity.ArchiveRecordHeader, Header: &entity.ArchiveHeader{FormatVersion: 1}},
+		{Type: entity.ArchiveRecordFooter, Footer: &entity.ArchiveFooter{}},
+	}
+
+	t.Run("JSONLines", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("ExportArchive", mock.Anything).Run(emitRecords(records...)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/export", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".jsonl\"")
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.JSONEq(t, `{"type":"header","header":{"format_version":1,"exported_at":"0001-01-01T00:00:00Z"}}`, lines[0])
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("ExportArchive", mock.Anything).Run(emitRecords(records...)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/export?compression=gzip", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+		zr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(body), "\n"))
+	})
+
+	t.Run("InvalidCompression", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/export?compression=zip", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ExportArchive", mock.Anything)
+	})
+}
+
+func TestImportArchive(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		report := &entity.ImportReport{Mode: entity.ImportModeMerge, DryRun: true, Items: []entity.ImportItem{}}
+		mockService.On("ImportArchive", mock.MatchedBy(func(records []*entity.ArchiveRecord) bool {
+			return len(records) == 3 && records[1].Configuration.Name == "payments"
+		}), entity.ImportOptions{Mode: entity.ImportModeMerge, DryRun: true}).Return(report, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/import?mode=merge&dry_run=true", strings.NewReader(testArchive))
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.ImportReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.DryRun)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		_, _ = zw.Write([]byte(testArchive))
+		require.NoError(t, zw.Close())
+
+		mockService.On("ImportArchive", mock.MatchedBy(func(records []*entity.ArchiveRecord) bool {
+			return len(records) == 3
+		}), entity.ImportOptions{}).Return(&entity.ImportReport{Applied: true}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/import", &compressed)
+		req.Header.Set("Content-Type", "application/gzip")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MalformedLine", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/import", strings.NewReader(strings.Replace(testArchive, `"records":1}}`, `"records":1`, 1)))
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response errors.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Details, "line 4")
+		mockService.AssertNotCalled(t, "ImportArchive", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ValidationFailed", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		report := &entity.ImportReport{Mode: entity.ImportModeSkipExisting}
+		mockService.On("ImportArchive", mock.Anything, mock.Anything).
+			Return(nil, errors.NewValidationFailedError("Archive does not conform to its schemas", report))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/import", strings.NewReader(testArchive))
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response errors.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, errors.ErrorCodeValidationFailed, response.Code)
+	})
+}
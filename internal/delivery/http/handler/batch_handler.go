@@ -0,0 +1,58 @@
+package handler
+
+import (
+	stdErrors "errors"
+	"net/http"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ApplyBatch handles applying create, update and rollback operations across
+// configurations atomically: either every operation is applied or none is
+func (h *ConfigurationHandler) ApplyBatch(c *gin.Context) {
+	var req struct {
+		ChangesetID string                  `json:"changeset_id"`
+		Operations  []entity.BatchOperation `json:"operations" binding:"required"`
+	}
+
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	result, err := h.configService.ApplyBatch(c.Request.Context(), req.ChangesetID, req.Operations)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest, errors.ErrorCodeValidationFailed:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeAlreadyExists, errors.ErrorCodeConflict:
+				c.JSON(http.StatusConflict, appErr.ToErrorResponse())
+			case errors.ErrorCodeForbidden:
+				c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to apply batch",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
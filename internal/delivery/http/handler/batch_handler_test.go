@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApplyBatch(t *testing.T) {
+	body := `{"changeset_id":"release-42","operations":[{"op":"create","name":"payments","data":{"max_limit":1000}},{"op":"rollback","name":"routing","target_version":2}]}`
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		result := &entity.BatchResult{ChangesetID: "release-42", Applied: true, Results: []entity.BatchOperationResult{}}
+		mockService.On("ApplyBatch", "release-42", mock.MatchedBy(func(ops []entity.BatchOperation) bool {
+			return len(ops) == 2 && ops[0].Op == entity.BatchOperationCreate && ops[1].TargetVersion == 2
+		})).Return(result, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.BatchResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Applied)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingOperations", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/batch", strings.NewReader(`{"changeset_id":"release-42"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ApplyBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OperationFailed", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("ApplyBatch", mock.Anything, mock.Anything).
+			Return(nil, errors.NewAppError("Batch operation 0 failed", errors.ErrorCodeAlreadyExists, &entity.BatchResult{}))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response errors.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, errors.ErrorCodeAlreadyExists, response.Code)
+	})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// getConfigurations handles reading many configurations in one request, named by the
+// comma-separated names query parameter with an optional @version suffix each. The
+// response carries an ETag over its content and If-None-Match is answered with 304.
+func (h *ConfigurationHandler) getConfigurations(c *gin.Context, names string) {
+	refs, err := parseConfigurationRefs(names)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid names",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	items, err := h.configService.GetConfigurations(c.Request.Context(), refs)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get configurations",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	body, err := json.Marshal(gin.H{"configurations": items})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+			"Failed to encode configurations",
+			errors.ErrorCodeInternalError,
+			err.Error(),
+		))
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// parseConfigurationRefs splits a names query parameter such as payments,routing@3 into
+// configuration refs. A suffix after the last @ that is not a number is part of the name.
+func parseConfigurationRefs(names string) ([]entity.ConfigurationRef, error) {
+	parts := strings.Split(names, ",")
+	refs := make([]entity.ConfigurationRef, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, stdErrors.New("names must not contain empty entries")
+		}
+
+		ref := entity.ConfigurationRef{Name: part}
+		if at := strings.LastIndex(part, "@"); at > 0 {
+			if version, err := strconv.Atoi(part[at+1:]); err == nil {
+				if version < 1 {
+					return nil, stdErrors.New("version of " + part[:at] + " must be positive")
+				}
+				ref = entity.ConfigurationRef{Name: part[:at], Version: version}
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// etagMatches reports whether an If-None-Match header lists the given entity tag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConfigurations(t *testing.T) {
+	refs := []entity.ConfigurationRef{{Name: "payments"}, {Name: "routing", Version: 3}, {Name: "missing"}}
+	items := []entity.BulkReadItem{
+		{ConfigurationRef: refs[0], Configuration: &entity.Configuration{Name: "payments", Version: 2, Data: json.RawMessage(`{"max_limit":1000}`)}},
+		{ConfigurationRef: refs[1], Configuration: &entity.Configuration{Name: "routing", Version: 3, Data: json.RawMessage(`{"region":"eu"}`)}},
+		{ConfigurationRef: refs[2], Error: errors.NewNotFoundError("Configuration", "missing").ToErrorResponse()},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("GetConfigurations", refs).Return(items, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations?names=payments,routing@3,missing", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		var response struct {
+			Configurations []entity.BulkReadItem `json:"configurations"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Configurations, 3)
+		assert.Equal(t, 2, response.Configurations[0].Configuration.Version)
+		assert.Equal(t, errors.ErrorCodeNotFound, response.Configurations[2].Error.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("GetConfigurations", refs).Return(items, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations?names=payments,routing@3,missing", nil)
+		router.ServeHTTP(w, req)
+		etag := w.Header().Get("ETag")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/configurations?names=payments,routing@3,missing", nil)
+		req.Header.Set("If-None-Match", etag)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("InvalidNames", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		for _, query := range []string{"names=payments,,routing", "names=routing@0", "names=payments&as_of=2025-01-01T00:00:00Z"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/configurations?"+query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+		mockService.AssertNotCalled(t, "GetConfigurations", mock.Anything)
+	})
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateChangeRequest handles proposing a configuration change for review
+func (h *ConfigurationHandler) CreateChangeRequest(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		Data json.RawMessage `json:"data" binding:"required"`
+	}
+
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	cr, err := h.configService.CreateChangeRequest(c.Request.Context(), name, req.Data, c.GetString("client_id"))
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to create change request")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cr)
+}
+
+// ListChangeRequests handles listing the change requests of a configuration
+func (h *ConfigurationHandler) ListChangeRequests(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	status := entity.ChangeRequestStatus(c.Query("status"))
+	changeRequests, err := h.configService.ListChangeRequests(c.Request.Context(), name, status)
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to list change requests")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":            name,
+		"change_requests": changeRequests,
+	})
+}
+
+// GetChangeRequest handles retrieving a change request
+func (h *ConfigurationHandler) GetChangeRequest(c *gin.Context) {
+	name, id, ok := changeRequestParams(c)
+	if !ok {
+		return
+	}
+
+	cr, err := h.configService.GetChangeRequest(c.Request.Context(), name, id)
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to get change request")
+		return
+	}
+
+	c.JSON(http.StatusOK, cr)
+}
+
+// ApproveChangeRequest handles approving a change request
+func (h *ConfigurationHandler) ApproveChangeRequest(c *gin.Context) {
+	name, id, ok := changeRequestParams(c)
+	if !ok {
+		return
+	}
+
+	cr, err := h.configService.ApproveChangeRequest(c.Request.Context(), name, id, c.GetString("client_id"))
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to approve change request")
+		return
+	}
+
+	c.JSON(http.StatusOK, cr)
+}
+
+// RejectChangeRequest handles rejecting a change request
+func (h *ConfigurationHandler) RejectChangeRequest(c *gin.Context) {
+	name, id, ok := changeRequestParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	// The reason is optional, so an empty body is accepted
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"Invalid request body",
+				errors.ErrorCodeInvalidRequest,
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	cr, err := h.configService.RejectChangeRequest(c.Request.Context(), name, id, c.GetString("client_id"), req.Reason)
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to reject change request")
+		return
+	}
+
+	c.JSON(http.StatusOK, cr)
+}
+
+// WithdrawChangeRequest handles the author withdrawing a change request
+func (h *ConfigurationHandler) WithdrawChangeRequest(c *gin.Context) {
+	name, id, ok := changeRequestParams(c)
+	if !ok {
+		return
+	}
+
+	cr, err := h.configService.WithdrawChangeRequest(c.Request.Context(), name, id, c.GetString("client_id"))
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to withdraw change request")
+		return
+	}
+
+	c.JSON(http.StatusOK, cr)
+}
+
+// CommentOnChangeRequest handles adding a review comment to a change request
+func (h *ConfigurationHandler) CommentOnChangeRequest(c *gin.Context) {
+	name, id, ok := changeRequestParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Body string `json:"body" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	comment, err := h.configService.CommentOnChangeRequest(c.Request.Context(), name, id, c.GetString("client_id"), req.Body)
+	if err != nil {
+		writeChangeRequestError(c, err, "Failed to add comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// changeRequestParams extracts the configuration name and change request ID from the path
+func changeRequestParams(c *gin.Context) (string, int64, bool) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return "", 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid change request ID",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return "", 0, false
+	}
+
+	return name, id, true
+}
+
+// writeChangeRequestError maps change request errors to HTTP responses
+func writeChangeRequestError(c *gin.Context, err error, message string) {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		switch appErr.Code {
+		case errors.ErrorCodeNotFound:
+			c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+		case errors.ErrorCodeValidationFailed, errors.ErrorCodeInvalidRequest:
+			c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+		case errors.ErrorCodeForbidden:
+			c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
+		case errors.ErrorCodeConflict:
+			c.JSON(http.StatusConflict, appErr.ToErrorResponse())
+		default:
+			c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+		}
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+		message,
+		errors.ErrorCodeInternalError,
+		err.Error(),
+	))
+}
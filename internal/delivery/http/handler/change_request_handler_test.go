@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateChangeRequest(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		cr := entity.NewChangeRequest("prod-payments", json.RawMessage(`{"limit":200}`), 3, nil, "test-client", 2)
+		cr.ID = 1
+		mockService.On("CreateChangeRequest", "prod-payments", json.RawMessage(`{"limit":200}`), "test-client").Return(cr, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests", bytes.NewBufferString(`{"data":{"limit":200}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", response["status"])
+		assert.Equal(t, float64(3), response["base_version"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingData", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestApproveChangeRequest(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		cr := entity.NewChangeRequest("prod-payments", json.RawMessage(`{}`), 3, nil, "alice", 1)
+		cr.MarkApplied(4)
+		mockService.On("ApproveChangeRequest", "prod-payments", int64(1), "test-client").Return(cr, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/1/approve", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"applied"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SelfApproval", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("ApproveChangeRequest", "prod-payments", int64(1), "test-client").
+			Return(nil, errors.NewForbiddenError("Change requests cannot be approved by their author", nil))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/1/approve", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("StaleBase", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("ApproveChangeRequest", "prod-payments", int64(1), "test-client").
+			Return(nil, errors.NewConflictError("Configuration changed since the change request was created", nil))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/1/approve", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/abc/approve", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRejectChangeRequest(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	cr := entity.NewChangeRequest("prod-payments", json.RawMessage(`{}`), 3, nil, "alice", 1)
+	cr.MarkRejected("too risky")
+	mockService.On("RejectChangeRequest", "prod-payments", int64(1), "test-client", "too risky").Return(cr, nil)
+	mockService.On("RejectChangeRequest", "prod-payments", int64(2), "test-client", "").Return(cr, nil)
+
+	// Create request with a reason
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/1/reject", bytes.NewBufferString(`{"reason":"too risky"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The reason is optional
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/configurations/prod-payments/change-requests/2/reject", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListChangeRequests(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("ListChangeRequests", "prod-payments", entity.ChangeRequestStatusPending).Return([]*entity.ChangeRequest{}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/configurations/prod-payments/change-requests?status=pending", nil)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"prod-payments","change_requests":[]}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateProtectedConfiguration(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("RollbackConfiguration", "prod-payments", 1).
+		Return(nil, errors.NewForbiddenError("Configuration requires an approved change request", nil))
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/configurations/prod-payments/rollback", bytes.NewBufferString(`{"target_version":1}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
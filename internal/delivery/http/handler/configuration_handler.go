@@ -3,12 +3,21 @@ package handler
 import (
 	"encoding/json"
 	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
 	"github.com/Titonu/configuration-management-service/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"time"
+)
+
+// Pagination limits for list endpoints
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
 )
 
 // ConfigurationHandler handles HTTP requests for configuration management
@@ -30,7 +39,7 @@ func (h *ConfigurationHandler) CreateConfiguration(c *gin.Context) {
 		Data json.RawMessage `json:"data" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindBody(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
 			"Invalid request body",
 			errors.ErrorCodeInvalidRequest,
@@ -39,7 +48,7 @@ func (h *ConfigurationHandler) CreateConfiguration(c *gin.Context) {
 		return
 	}
 
-	config, err := h.configService.CreateConfiguration(req.Name, req.Data)
+	config, err := h.configService.CreateConfiguration(c.Request.Context(), req.Name, req.Data)
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
@@ -49,7 +58,7 @@ func (h *ConfigurationHandler) CreateConfiguration(c *gin.Context) {
 			case errors.ErrorCodeValidationFailed:
 				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
 			default:
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -80,7 +89,7 @@ func (h *ConfigurationHandler) UpdateConfiguration(c *gin.Context) {
 		Data json.RawMessage `json:"data" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindBody(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
 			"Invalid request body",
 			errors.ErrorCodeInvalidRequest,
@@ -89,7 +98,7 @@ func (h *ConfigurationHandler) UpdateConfiguration(c *gin.Context) {
 		return
 	}
 
-	config, err := h.configService.UpdateConfiguration(name, req.Data)
+	config, err := h.configService.UpdateConfiguration(c.Request.Context(), name, req.Data)
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
@@ -98,8 +107,10 @@ func (h *ConfigurationHandler) UpdateConfiguration(c *gin.Context) {
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
 			case errors.ErrorCodeValidationFailed:
 				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeForbidden:
+				c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
 			default:
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -126,14 +137,37 @@ func (h *ConfigurationHandler) GetConfiguration(c *gin.Context) {
 		return
 	}
 
-	config, err := h.configService.GetConfiguration(name)
+	dataFormat, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	var config *entity.Configuration
+	var err error
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		asOf, parseErr := time.Parse(time.RFC3339, asOfStr)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"Invalid as_of format, expected RFC3339",
+				errors.ErrorCodeInvalidRequest,
+				parseErr.Error(),
+			))
+			return
+		}
+		config, err = h.configService.GetConfigurationAsOf(c.Request.Context(), name, asOf)
+	} else {
+		config, err = h.configService.GetConfiguration(c.Request.Context(), name)
+	}
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
-			if appErr.Code == errors.ErrorCodeNotFound {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
-			} else {
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -145,7 +179,71 @@ func (h *ConfigurationHandler) GetConfiguration(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	renderConfiguration(c, dataFormat, config)
+}
+
+// ListConfigurations handles retrieving the effective version of every configuration,
+// optionally as of a point in time, or of the configurations listed in names
+func (h *ConfigurationHandler) ListConfigurations(c *gin.Context) {
+	if names, ok := c.GetQuery("names"); ok {
+		if c.Query("as_of") != "" {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"as_of cannot be combined with names",
+				errors.ErrorCodeInvalidRequest,
+				nil,
+			))
+			return
+		}
+		h.getConfigurations(c, names)
+		return
+	}
+
+	asOf := time.Now().UTC()
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		parsed, err := time.Parse(time.RFC3339, asOfStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"Invalid as_of format, expected RFC3339",
+				errors.ErrorCodeInvalidRequest,
+				err.Error(),
+			))
+			return
+		}
+		asOf = parsed.UTC()
+	}
+
+	items, err := h.configService.ListConfigurationsAsOf(c.Request.Context(), asOf)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list configurations",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	// Configurations whose effective version was compacted are listed apart, so that the
+	// configurations keep the shape of single reads
+	configs := make([]*entity.Configuration, 0, len(items))
+	unavailable := make([]entity.BulkReadItem, 0)
+	for _, item := range items {
+		if item.Configuration != nil {
+			configs = append(configs, item.Configuration)
+		} else {
+			unavailable = append(unavailable, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"as_of":          asOf,
+		"configurations": configs,
+		"unavailable":    unavailable,
+	})
 }
 
 // GetConfigurationVersion handles retrieving a specific version of a configuration
@@ -180,14 +278,22 @@ func (h *ConfigurationHandler) GetConfigurationVersion(c *gin.Context) {
 		return
 	}
 
-	config, err := h.configService.GetConfigurationVersion(name, version)
+	dataFormat, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.GetConfigurationVersion(c.Request.Context(), name, version)
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
-			if appErr.Code == errors.ErrorCodeNotFound {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
-			} else {
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -199,7 +305,7 @@ func (h *ConfigurationHandler) GetConfigurationVersion(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	renderConfiguration(c, dataFormat, config)
 }
 
 // ListConfigurationVersions handles listing all versions of a configuration
@@ -214,14 +320,14 @@ func (h *ConfigurationHandler) ListConfigurationVersions(c *gin.Context) {
 		return
 	}
 
-	versions, err := h.configService.ListConfigurationVersions(name)
+	versions, err := h.configService.ListConfigurationVersions(c.Request.Context(), name)
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
 			if appErr.Code == errors.ErrorCodeNotFound {
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
 			} else {
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -249,7 +355,8 @@ func (h *ConfigurationHandler) RollbackConfiguration(c *gin.Context) {
 	}
 
 	var req struct {
-		TargetVersion int `json:"target_version" binding:"required"`
+		TargetVersion int    `json:"target_version"`
+		TargetTag     string `json:"target_tag"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -261,15 +368,34 @@ func (h *ConfigurationHandler) RollbackConfiguration(c *gin.Context) {
 		return
 	}
 
-	config, err := h.configService.RollbackConfiguration(name, req.TargetVersion)
+	if (req.TargetVersion == 0) == (req.TargetTag == "") {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Exactly one of target_version and target_tag is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var config *entity.Configuration
+	var err error
+	if req.TargetTag != "" {
+		config, err = h.configService.RollbackConfigurationToTag(c.Request.Context(), name, req.TargetTag)
+	} else {
+		config, err = h.configService.RollbackConfiguration(c.Request.Context(), name, req.TargetVersion)
+	}
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
 			switch appErr.Code {
 			case errors.ErrorCodeNotFound:
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			case errors.ErrorCodeForbidden:
+				c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
 			default:
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -306,15 +432,79 @@ func (h *ConfigurationHandler) RegisterSchema(c *gin.Context) {
 		return
 	}
 
-	err := h.configService.RegisterSchema(name, schema)
+	force, err := parseBoolQuery(c, "force")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid force flag",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	dryRun, err := parseBoolQuery(c, "dry_run")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid dry_run flag",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	checkVersions := 1
+	if checkVersionsStr := c.Query("check_versions"); checkVersionsStr != "" {
+		checkVersions, err = strconv.Atoi(checkVersionsStr)
+		if err != nil || checkVersions < 1 {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"check_versions must be a positive integer",
+				errors.ErrorCodeInvalidRequest,
+				checkVersionsStr,
+			))
+			return
+		}
+	}
+
+	// A dry run only reports the impact of the schema
+	if dryRun {
+		impact, err := h.configService.AnalyzeSchemaImpact(c.Request.Context(), name, schema, checkVersions)
+		if err != nil {
+			var appErr *errors.AppError
+			if stdErrors.As(err, &appErr) {
+				switch appErr.Code {
+				case errors.ErrorCodeInvalidRequest:
+					c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+				default:
+					c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+				}
+			} else {
+				c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+					"Failed to analyze schema impact",
+					errors.ErrorCodeInternalError,
+					err.Error(),
+				))
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, impact)
+		return
+	}
+
+	schemaVersion, err := h.configService.RegisterSchema(c.Request.Context(), name, schema, entity.SchemaRegistrationOptions{
+		Force:         force,
+		CheckVersions: checkVersions,
+	})
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
 			switch appErr.Code {
-			case errors.ErrorCodeInvalidRequest:
+			case errors.ErrorCodeInvalidRequest, errors.ErrorCodeValidationFailed:
 				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeConflict:
+				c.JSON(http.StatusConflict, appErr.ToErrorResponse())
 			default:
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -327,8 +517,9 @@ func (h *ConfigurationHandler) RegisterSchema(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"name":   name,
-		"status": "schema registered successfully",
+		"name":    name,
+		"version": schemaVersion.Version,
+		"status":  "schema registered successfully",
 	})
 }
 
@@ -344,14 +535,14 @@ func (h *ConfigurationHandler) GetSchema(c *gin.Context) {
 		return
 	}
 
-	schema, err := h.configService.GetSchema(name)
+	schema, err := h.configService.GetSchema(c.Request.Context(), name)
 	if err != nil {
 		var appErr *errors.AppError
 		if stdErrors.As(err, &appErr) {
 			if appErr.Code == errors.ErrorCodeNotFound {
 				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
 			} else {
-				c.JSON(http.StatusInternalServerError, appErr.ToErrorResponse())
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
 			}
 		} else {
 			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
@@ -376,3 +567,679 @@ func (h *ConfigurationHandler) GetSchema(c *gin.Context) {
 
 	c.JSON(http.StatusOK, schemaObj)
 }
+
+// ListSchemas handles listing the current schemas of all configurations, one page at a time
+func (h *ConfigurationHandler) ListSchemas(c *gin.Context) {
+	limit, err := parseIntQuery(c, "limit", defaultPageLimit)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+			errors.ErrorCodeInvalidRequest,
+			c.Query("limit"),
+		))
+		return
+	}
+
+	offset, err := parseIntQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"offset must be a non-negative integer",
+			errors.ErrorCodeInvalidRequest,
+			c.Query("offset"),
+		))
+		return
+	}
+
+	schemas, err := h.configService.ListSchemas(c.Request.Context(), limit, offset)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list schemas",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas)
+}
+
+// DeleteSchema handles detaching the schema from a configuration
+func (h *ConfigurationHandler) DeleteSchema(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	if err := h.configService.DeleteSchema(c.Request.Context(), name); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to delete schema",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUnvalidatedConfigurations handles reporting configurations whose current data is not validated by a schema
+func (h *ConfigurationHandler) ListUnvalidatedConfigurations(c *gin.Context) {
+	configs, err := h.configService.ListUnvalidatedConfigurations(c.Request.Context())
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list unvalidated configurations",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"configurations": configs,
+	})
+}
+
+// ListSchemaVersions handles listing the schema history of a configuration
+func (h *ConfigurationHandler) ListSchemaVersions(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	versions, err := h.configService.ListSchemaVersions(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list schema versions",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":     name,
+		"versions": versions,
+	})
+}
+
+// GetSchemaVersion handles retrieving a specific version of a configuration's schema
+func (h *ConfigurationHandler) GetSchemaVersion(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid version format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	schemaVersion, err := h.configService.GetSchemaVersion(c.Request.Context(), name, version)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get schema version",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schemaVersion)
+}
+
+// ScheduleConfigurationChange handles staging a configuration change for a future time
+func (h *ConfigurationHandler) ScheduleConfigurationChange(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		Data        json.RawMessage `json:"data" binding:"required"`
+		EffectiveAt time.Time       `json:"effective_at" binding:"required"`
+	}
+
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	change, err := h.configService.ScheduleConfigurationChange(c.Request.Context(), name, req.Data, req.EffectiveAt)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeValidationFailed, errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeForbidden:
+				c.JSON(http.StatusForbidden, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to schedule configuration change",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, change)
+}
+
+// ListScheduledChanges handles listing the scheduled changes of a configuration
+func (h *ConfigurationHandler) ListScheduledChanges(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	changes, err := h.configService.ListScheduledChanges(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list scheduled changes",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"schedules": changes,
+	})
+}
+
+// CancelScheduledChange handles cancelling a pending scheduled change
+func (h *ConfigurationHandler) CancelScheduledChange(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid scheduled change ID",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	change, err := h.configService.CancelScheduledChange(c.Request.Context(), name, id)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to cancel scheduled change",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetRetentionPolicy handles retrieving the retention policy of a configuration
+func (h *ConfigurationHandler) GetRetentionPolicy(c *gin.Context) {
+	h.getRetentionPolicy(c, c.Param("name"))
+}
+
+// SetRetentionPolicy handles creating or replacing the retention policy of a configuration
+func (h *ConfigurationHandler) SetRetentionPolicy(c *gin.Context) {
+	h.setRetentionPolicy(c, c.Param("name"))
+}
+
+// GetGlobalRetentionPolicy handles retrieving the service-wide retention policy
+func (h *ConfigurationHandler) GetGlobalRetentionPolicy(c *gin.Context) {
+	h.getRetentionPolicy(c, entity.GlobalRetentionPolicyName)
+}
+
+// SetGlobalRetentionPolicy handles creating or replacing the service-wide retention policy
+func (h *ConfigurationHandler) SetGlobalRetentionPolicy(c *gin.Context) {
+	h.setRetentionPolicy(c, entity.GlobalRetentionPolicyName)
+}
+
+// DeleteRetentionPolicy handles removing the retention policy of a configuration
+func (h *ConfigurationHandler) DeleteRetentionPolicy(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	if err := h.configService.DeleteRetentionPolicy(c.Request.Context(), name); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to delete retention policy",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CompactConfiguration handles enforcing the retention policy of a configuration immediately
+func (h *ConfigurationHandler) CompactConfiguration(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	result, err := h.configService.CompactConfiguration(c.Request.Context(), name, time.Now().UTC())
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to compact configuration",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getRetentionPolicy writes the retention policy stored under name
+func (h *ConfigurationHandler) getRetentionPolicy(c *gin.Context, name string) {
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	policy, err := h.configService.GetRetentionPolicy(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get retention policy",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// setRetentionPolicy binds a retention policy from the request and stores it under name
+func (h *ConfigurationHandler) setRetentionPolicy(c *gin.Context, name string) {
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		KeepLast int `json:"keep_last"`
+		KeepDays int `json:"keep_days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	policy, err := h.configService.SetRetentionPolicy(c.Request.Context(), &entity.RetentionPolicy{
+		Name:     name,
+		KeepLast: req.KeepLast,
+		KeepDays: req.KeepDays,
+	})
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to set retention policy",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// TagVersion handles attaching a tag to a configuration version
+func (h *ConfigurationHandler) TagVersion(c *gin.Context) {
+	name := c.Param("name")
+	tag := c.Param("tag")
+	if name == "" || tag == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name and tag are required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		Version   int  `json:"version" binding:"required"`
+		Immutable bool `json:"immutable"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	versionTag, err := h.configService.TagVersion(c.Request.Context(), name, tag, req.Version, req.Immutable)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeConflict:
+				c.JSON(http.StatusConflict, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to tag version",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, versionTag)
+}
+
+// ListVersionTags handles listing the tags of a configuration
+func (h *ConfigurationHandler) ListVersionTags(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	tags, err := h.configService.ListVersionTags(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list tags",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name": name,
+		"tags": tags,
+	})
+}
+
+// GetConfigurationByTag handles retrieving the configuration version a tag points at
+func (h *ConfigurationHandler) GetConfigurationByTag(c *gin.Context) {
+	name := c.Param("name")
+	tag := c.Param("tag")
+	if name == "" || tag == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name and tag are required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	dataFormat, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.GetConfigurationByTag(c.Request.Context(), name, tag)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeVersionCompacted:
+				c.JSON(http.StatusGone, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get configuration",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	renderConfiguration(c, dataFormat, config)
+}
+
+// DeleteVersionTag handles removing a movable tag
+func (h *ConfigurationHandler) DeleteVersionTag(c *gin.Context) {
+	name := c.Param("name")
+	tag := c.Param("tag")
+	if name == "" || tag == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name and tag are required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	if err := h.configService.DeleteVersionTag(c.Request.Context(), name, tag); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeConflict:
+				c.JSON(http.StatusConflict, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to remove tag",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseBoolQuery parses an optional boolean query parameter, defaulting to false
+func parseBoolQuery(c *gin.Context, key string) (bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseIntQuery parses an optional integer query parameter, falling back to the default when absent
+func parseIntQuery(c *gin.Context, key string, defaultValue int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
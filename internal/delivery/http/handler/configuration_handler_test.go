@@ -2,10 +2,12 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
 	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockConfigurationService is a mock implementation of service.ConfigurationUsecase
@@ -21,7 +24,7 @@ type MockConfigurationService struct {
 	mock.Mock
 }
 
-func (m *MockConfigurationService) CreateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error) {
+func (m *MockConfigurationService) CreateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error) {
 	args := m.Called(name, data)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -29,7 +32,7 @@ func (m *MockConfigurationService) CreateConfiguration(name string, data json.Ra
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationService) UpdateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error) {
+func (m *MockConfigurationService) UpdateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error) {
 	args := m.Called(name, data)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +40,7 @@ func (m *MockConfigurationService) UpdateConfiguration(name string, data json.Ra
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationService) GetConfiguration(name string) (*entity.Configuration, error) {
+func (m *MockConfigurationService) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -45,7 +48,7 @@ func (m *MockConfigurationService) GetConfiguration(name string) (*entity.Config
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationService) GetConfigurationVersion(name string, version int) (*entity.Configuration, error) {
+func (m *MockConfigurationService) GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error) {
 	args := m.Called(name, version)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -53,7 +56,7 @@ func (m *MockConfigurationService) GetConfigurationVersion(name string, version
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationService) ListConfigurationVersions(name string) (*entity.VersionList, error) {
+func (m *MockConfigurationService) ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -61,7 +64,23 @@ func (m *MockConfigurationService) ListConfigurationVersions(name string) (*enti
 	return args.Get(0).(*entity.VersionList), args.Error(1)
 }
 
-func (m *MockConfigurationService) RollbackConfiguration(name string, targetVersion int) (*entity.Configuration, error) {
+func (m *MockConfigurationService) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error) {
+	args := m.Called(name, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Configuration), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error) {
+	args := m.Called(asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.BulkReadItem), args.Error(1)
+}
+
+func (m *MockConfigurationService) RollbackConfiguration(ctx context.Context, name string, targetVersion int) (*entity.Configuration, error) {
 	args := m.Called(name, targetVersion)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -69,12 +88,15 @@ func (m *MockConfigurationService) RollbackConfiguration(name string, targetVers
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationService) RegisterSchema(name string, schema json.RawMessage) error {
-	args := m.Called(name, schema)
-	return args.Error(0)
+func (m *MockConfigurationService) RegisterSchema(ctx context.Context, name string, schema json.RawMessage, opts entity.SchemaRegistrationOptions) (*entity.SchemaVersion, error) {
+	args := m.Called(name, schema, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaVersion), args.Error(1)
 }
 
-func (m *MockConfigurationService) GetSchema(name string) (json.RawMessage, error) {
+func (m *MockConfigurationService) GetSchema(ctx context.Context, name string) (json.RawMessage, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -82,30 +104,403 @@ func (m *MockConfigurationService) GetSchema(name string) (json.RawMessage, erro
 	return args.Get(0).(json.RawMessage), args.Error(1)
 }
 
-func (m *MockConfigurationService) ValidateConfigurationData(configName string, data json.RawMessage) error {
+func (m *MockConfigurationService) ValidateConfigurationData(ctx context.Context, configName string, data json.RawMessage) error {
 	args := m.Called(configName, data)
 	return args.Error(0)
 }
 
+func (m *MockConfigurationService) ScheduleConfigurationChange(ctx context.Context, name string, data json.RawMessage, effectiveAt time.Time) (*entity.ScheduledChange, error) {
+	args := m.Called(name, data, effectiveAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListScheduledChanges(ctx context.Context, name string) ([]*entity.ScheduledChange, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationService) CancelScheduledChange(ctx context.Context, name string, id int64) (*entity.ScheduledChange, error) {
+	args := m.Called(name, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationService) ApplyDueScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockConfigurationService) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) (*entity.RetentionPolicy, error) {
+	args := m.Called(policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockConfigurationService) DeleteRetentionPolicy(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationService) CompactConfiguration(ctx context.Context, name string, now time.Time) (*entity.CompactionResult, error) {
+	args := m.Called(name, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CompactionResult), args.Error(1)
+}
+
+func (m *MockConfigurationService) CompactAll(ctx context.Context, now time.Time) (*entity.CompactionSummary, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CompactionSummary), args.Error(1)
+}
+
+func (m *MockConfigurationService) TagVersion(ctx context.Context, name, tag string, version int, immutable bool) (*entity.VersionTag, error) {
+	args := m.Called(name, tag, version, immutable)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VersionTag), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.VersionTag), args.Error(1)
+}
+
+func (m *MockConfigurationService) DeleteVersionTag(ctx context.Context, name, tag string) error {
+	args := m.Called(name, tag)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationService) GetConfigurationByTag(ctx context.Context, name, tag string) (*entity.Configuration, error) {
+	args := m.Called(name, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Configuration), args.Error(1)
+}
+
+func (m *MockConfigurationService) RollbackConfigurationToTag(ctx context.Context, name, tag string) (*entity.Configuration, error) {
+	args := m.Called(name, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Configuration), args.Error(1)
+}
+
+func (m *MockConfigurationService) CreateChangeRequest(ctx context.Context, name string, data json.RawMessage, author string) (*entity.ChangeRequest, error) {
+	args := m.Called(name, data, author)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetChangeRequest(ctx context.Context, name string, id int64) (*entity.ChangeRequest, error) {
+	args := m.Called(name, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListChangeRequests(ctx context.Context, name string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error) {
+	args := m.Called(name, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) ApproveChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error) {
+	args := m.Called(name, id, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) RejectChangeRequest(ctx context.Context, name string, id int64, clientID string, reason string) (*entity.ChangeRequest, error) {
+	args := m.Called(name, id, clientID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) WithdrawChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error) {
+	args := m.Called(name, id, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationService) CommentOnChangeRequest(ctx context.Context, name string, id int64, clientID string, body string) (*entity.ChangeRequestComment, error) {
+	args := m.Called(name, id, clientID, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequestComment), args.Error(1)
+}
+
+func (m *MockConfigurationService) ExportArchive(ctx context.Context, emit func(*entity.ArchiveRecord) error) error {
+	args := m.Called(emit)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationService) ImportArchive(ctx context.Context, records []*entity.ArchiveRecord, opts entity.ImportOptions) (*entity.ImportReport, error) {
+	args := m.Called(records, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ImportReport), args.Error(1)
+}
+
+func (m *MockConfigurationService) ApplyBatch(ctx context.Context, changesetID string, operations []entity.BatchOperation) (*entity.BatchResult, error) {
+	args := m.Called(changesetID, operations)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BatchResult), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error) {
+	args := m.Called(refs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.BulkReadItem), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetSchemaVersion(ctx context.Context, name string, version int) (*entity.SchemaVersion, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaVersion), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListSchemaVersions(ctx context.Context, name string) ([]*entity.SchemaVersion, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.SchemaVersion), args.Error(1)
+}
+
+func (m *MockConfigurationService) AnalyzeSchemaImpact(ctx context.Context, name string, schema json.RawMessage, checkVersions int) (*entity.SchemaImpact, error) {
+	args := m.Called(name, schema, checkVersions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaImpact), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListSchemas(ctx context.Context, limit, offset int) (*entity.SchemaList, error) {
+	args := m.Called(limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaList), args.Error(1)
+}
+
+func (m *MockConfigurationService) DeleteSchema(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationService) ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.UnvalidatedConfiguration), args.Error(1)
+}
+
+func (m *MockConfigurationService) RegisterSharedSchema(ctx context.Context, name string, schema json.RawMessage, force bool) (*entity.SharedSchema, error) {
+	args := m.Called(name, schema, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SharedSchema), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SharedSchema), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.SharedSchema), args.Error(1)
+}
+
+func (m *MockConfigurationService) DeleteSharedSchema(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationService) SetRuleSet(ctx context.Context, name string, ruleList []rules.Rule, force bool) (*entity.RuleSet, error) {
+	args := m.Called(name, ruleList, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetRuleSet(ctx context.Context, name string) (*entity.RuleSet, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationService) GetRuleSetVersion(ctx context.Context, name string, version int) (*entity.RuleSet, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationService) ListRuleSetVersions(ctx context.Context, name string) ([]*entity.RuleSet, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationService) TestRules(ctx context.Context, name string, ruleList []rules.Rule, data json.RawMessage) (*entity.RuleTestResult, error) {
+	args := m.Called(name, ruleList, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleTestResult), args.Error(1)
+}
+
+func (m *MockConfigurationService) ValidateDocument(ctx context.Context, name string, data json.RawMessage) (*entity.ValidationReport, error) {
+	args := m.Called(name, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ValidationReport), args.Error(1)
+}
+
+func (m *MockConfigurationService) ValidateAgainstSchema(ctx context.Context, schema json.RawMessage, data json.RawMessage) (*entity.ValidationReport, error) {
+	args := m.Called(schema, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ValidationReport), args.Error(1)
+}
+
 func setupRouter(mockService usecase.ConfigurationUsecase) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
 	handler := NewConfigurationHandler(mockService)
 
+	// Stand in for the authentication middleware, which sets the client ID
+	router.Use(func(c *gin.Context) {
+		c.Set("client_id", "test-client")
+		c.Next()
+	})
+
 	v1 := router.Group("/api/v1")
 	{
 		// Configuration endpoints
 		v1.POST("/configurations", handler.CreateConfiguration)
+		v1.GET("/configurations", handler.ListConfigurations)
 		v1.PUT("/configurations/:name", handler.UpdateConfiguration)
 		v1.GET("/configurations/:name", handler.GetConfiguration)
 		v1.GET("/configurations/:name/versions", handler.ListConfigurationVersions)
 		v1.GET("/configurations/:name/versions/:version", handler.GetConfigurationVersion)
 		v1.POST("/configurations/:name/rollback", handler.RollbackConfiguration)
+		v1.GET("/configurations/:name/tags", handler.ListVersionTags)
+		v1.GET("/configurations/:name/tags/:tag", handler.GetConfigurationByTag)
+		v1.PUT("/configurations/:name/tags/:tag", handler.TagVersion)
+		v1.DELETE("/configurations/:name/tags/:tag", handler.DeleteVersionTag)
+		v1.POST("/configurations/:name/schedules", handler.ScheduleConfigurationChange)
+		v1.GET("/configurations/:name/schedules", handler.ListScheduledChanges)
+		v1.DELETE("/configurations/:name/schedules/:id", handler.CancelScheduledChange)
+		v1.GET("/configurations/:name/retention", handler.GetRetentionPolicy)
+		v1.PUT("/configurations/:name/retention", handler.SetRetentionPolicy)
+		v1.DELETE("/configurations/:name/retention", handler.DeleteRetentionPolicy)
+		v1.POST("/configurations/:name/compact", handler.CompactConfiguration)
+		v1.POST("/configurations/:name/change-requests", handler.CreateChangeRequest)
+		v1.GET("/configurations/:name/change-requests", handler.ListChangeRequests)
+		v1.GET("/configurations/:name/change-requests/:id", handler.GetChangeRequest)
+		v1.POST("/configurations/:name/change-requests/:id/approve", handler.ApproveChangeRequest)
+		v1.POST("/configurations/:name/change-requests/:id/reject", handler.RejectChangeRequest)
+		v1.POST("/configurations/:name/change-requests/:id/withdraw", handler.WithdrawChangeRequest)
+		v1.POST("/configurations/:name/change-requests/:id/comments", handler.CommentOnChangeRequest)
+
+		// Retention endpoints
+		v1.GET("/retention", handler.GetGlobalRetentionPolicy)
+		v1.PUT("/retention", handler.SetGlobalRetentionPolicy)
+
+		// Archive endpoints
+		v1.GET("/export", handler.ExportArchive)
+		v1.POST("/import", handler.ImportArchive)
+
+		// Batch endpoints
+		v1.POST("/batch", handler.ApplyBatch)
 
 		// Schema endpoints
 		v1.POST("/schemas/:name", handler.RegisterSchema)
 		v1.GET("/schemas/:name", handler.GetSchema)
+		v1.GET("/schemas", handler.ListSchemas)
+		v1.DELETE("/schemas/:name", handler.DeleteSchema)
+		v1.GET("/schemas/:name/versions", handler.ListSchemaVersions)
+		v1.GET("/reports/unvalidated", handler.ListUnvalidatedConfigurations)
+		v1.GET("/schemas/:name/versions/:version", handler.GetSchemaVersion)
+		v1.GET("/configurations/:name/rules", handler.GetRuleSet)
+		v1.PUT("/configurations/:name/rules", handler.SetRuleSet)
+		v1.GET("/configurations/:name/rules/versions", handler.ListRuleSetVersions)
+		v1.GET("/configurations/:name/rules/versions/:version", handler.GetRuleSetVersion)
+		v1.POST("/configurations/:name/rules/test", handler.TestRules)
+		v1.POST("/configurations/:name/validate", handler.ValidateDocument)
+		v1.POST("/schemas/validate", handler.ValidateAgainstSchema)
+		v1.GET("/shared-schemas", handler.ListSharedSchemas)
+		v1.GET("/shared-schemas/*name", handler.GetSharedSchema)
+		v1.PUT("/shared-schemas/*name", handler.RegisterSharedSchema)
+		v1.DELETE("/shared-schemas/*name", handler.DeleteSharedSchema)
 	}
 
 	return router
@@ -334,6 +729,29 @@ func TestGetConfiguration(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("ContextEnded", func(t *testing.T) {
+		for _, tc := range []struct {
+			err    error
+			status int
+		}{
+			{context.DeadlineExceeded, http.StatusGatewayTimeout},
+			{context.Canceled, StatusClientClosedRequest},
+		} {
+			mockService := new(MockConfigurationService)
+			router := setupRouter(mockService)
+
+			// The usecase reports that the request's context ended
+			mockService.On("GetConfiguration", "test-config").Return(nil, errors.NewContextError(tc.err))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/configurations/test-config", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code, tc.err.Error())
+			mockService.AssertExpectations(t)
+		}
+	})
 }
 
 func TestGetConfigurationVersion(t *testing.T) {
@@ -556,42 +974,111 @@ func TestRollbackConfiguration(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
-}
-
-func TestRegisterSchema(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
+	t.Run("VersionCompacted", func(t *testing.T) {
 		mockService := new(MockConfigurationService)
 		router := setupRouter(mockService)
 
-		schema := map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"key": map[string]interface{}{
-					"type": "string",
-				},
-			},
-		}
-
-		schemaJSON, _ := json.Marshal(schema)
+		reqJSON := []byte(`{"target_version": 1}`)
 
-		// Mock service response
-		mockService.On("RegisterSchema", "test-config", mock.AnythingOfType("json.RawMessage")).Return(nil)
+		// Mock service error
+		mockService.On("RollbackConfiguration", "test-config", 1).
+			Return(nil, errors.NewVersionCompactedError("test-config", 1))
 
 		// Create request
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config", bytes.NewBuffer(schemaJSON))
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/rollback", bytes.NewBuffer(reqJSON))
 		req.Header.Set("Content-Type", "application/json")
 
 		// Perform request
 		router.ServeHTTP(w, req)
 
 		// Assertions
-		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, http.StatusGone, w.Code)
+		assert.Contains(t, w.Body.String(), "VERSION_COMPACTED")
 
 		mockService.AssertExpectations(t)
 	})
-
-	t.Run("BadRequest", func(t *testing.T) {
+	t.Run("ToTag", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		reqJSON := []byte(`{"target_tag": "last-known-good"}`)
+
+		// Mock service response
+		expectedConfig := &entity.Configuration{
+			Name:       "test-config",
+			Version:    5,
+			RollbackTo: 2,
+		}
+		mockService.On("RollbackConfigurationToTag", "test-config", "last-known-good").Return(expectedConfig, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/rollback", bytes.NewBuffer(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("VersionAndTag", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		reqJSON := []byte(`{"target_version": 1, "target_tag": "last-known-good"}`)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/rollback", bytes.NewBuffer(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRegisterSchema(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		schema := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		}
+
+		schemaJSON, _ := json.Marshal(schema)
+
+		// Mock service response
+		mockService.On("RegisterSchema", "test-config", mock.AnythingOfType("json.RawMessage"), entity.SchemaRegistrationOptions{CheckVersions: 1}).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 1}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config", bytes.NewBuffer(schemaJSON))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
 		mockService := new(MockConfigurationService)
 		router := setupRouter(mockService)
 
@@ -621,8 +1108,8 @@ func TestRegisterSchema(t *testing.T) {
 		schemaJSON, _ := json.Marshal(schema)
 
 		// Mock service error
-		mockService.On("RegisterSchema", "test-config", mock.AnythingOfType("json.RawMessage")).
-			Return(errors.NewInvalidRequestError("Invalid schema", errors.NewValidationError("schema", "invalid schema")))
+		mockService.On("RegisterSchema", "test-config", mock.AnythingOfType("json.RawMessage"), entity.SchemaRegistrationOptions{CheckVersions: 1}).
+			Return(nil, errors.NewInvalidRequestError("Invalid schema", errors.NewValidationError("schema", "invalid schema")))
 
 		// Create request
 		w := httptest.NewRecorder()
@@ -688,3 +1175,770 @@ func TestGetSchema(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestRegisterSchemaCompatibility(t *testing.T) {
+	t.Run("IncompatibleSchema", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service error
+		mockService.On("RegisterSchema", "test-config", mock.Anything, entity.SchemaRegistrationOptions{CheckVersions: 1}).
+			Return(nil, errors.NewConflictError("Schema is not backward compatible with the current schema", []errors.ValidationError{
+				{Field: "limit", Reason: "property became required"},
+			}))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config", bytes.NewBufferString(`{"type":"object","required":["limit"]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("RegisterSchema", "test-config", mock.Anything, entity.SchemaRegistrationOptions{Force: true, CheckVersions: 1}).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 3}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config?force=true", bytes.NewBufferString(`{"type":"object","required":["limit"]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), response["version"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidForceFlag", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config?force=maybe", bytes.NewBufferString(`{"type":"object"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RegisterSchema")
+	})
+}
+
+func TestRegisterSchemaExistingData(t *testing.T) {
+	t.Run("NonConformingData", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service error
+		mockService.On("RegisterSchema", "test-config", mock.Anything, entity.SchemaRegistrationOptions{CheckVersions: 3}).
+			Return(nil, errors.NewValidationFailedError("Existing configuration data does not conform to the schema", []entity.VersionValidation{
+				{Version: 2, Errors: []errors.ValidationError{{Field: "limit", Reason: "Invalid type"}}},
+			}))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config?check_versions=3", bytes.NewBufferString(`{"type":"object"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("AnalyzeSchemaImpact", "test-config", mock.Anything, 1).Return(&entity.SchemaImpact{
+			Name:       "test-config",
+			Compatible: true,
+			Versions:   []entity.VersionValidation{{Version: 4, Valid: true}},
+		}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config?dry_run=true", bytes.NewBufferString(`{"type":"object"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, true, response["compatible"])
+		assert.Len(t, response["versions"], 1)
+
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "RegisterSchema")
+	})
+
+	t.Run("InvalidCheckVersions", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/test-config?check_versions=0", bytes.NewBufferString(`{"type":"object"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListSchemas(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("ListSchemas", 10, 20).Return(&entity.SchemaList{
+			Schemas: []*entity.SchemaVersion{{Name: "test-config", Version: 2}},
+			Total:   21,
+			Limit:   10,
+			Offset:  20,
+		}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas?limit=10&offset=20", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(21), response["total"])
+		assert.Len(t, response["schemas"], 1)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DefaultPage", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("ListSchemas", defaultPageLimit, 0).Return(&entity.SchemaList{Schemas: []*entity.SchemaVersion{}}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas?limit=1000", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ListSchemas")
+	})
+}
+
+func TestDeleteSchema(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("DeleteSchema", "test-config").Return(nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/schemas/test-config", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service error
+		mockService.On("DeleteSchema", "non-existent").Return(errors.NewNotFoundError("Schema", "non-existent"))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/schemas/non-existent", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestListUnvalidatedConfigurations(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	// Mock service response
+	mockService.On("ListUnvalidatedConfigurations").Return([]*entity.UnvalidatedConfiguration{
+		{Name: "feature-flags", Version: 3, Reason: entity.UnvalidatedReasonNoSchema},
+	}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/reports/unvalidated", nil)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"no_schema"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestListSchemaVersions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("ListSchemaVersions", "test-config").Return([]*entity.SchemaVersion{
+			{Name: "test-config", Version: 1, Schema: json.RawMessage(`{"type":"object"}`)},
+			{Name: "test-config", Version: 2, Schema: json.RawMessage(`{"type":"object","properties":{}}`)},
+		}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/test-config/versions", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response["versions"], 2)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service error
+		mockService.On("ListSchemaVersions", "non-existent").Return(nil, errors.NewNotFoundError("Schema", "non-existent"))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/non-existent/versions", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetSchemaVersion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		mockService.On("GetSchemaVersion", "test-config", 1).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 1, Schema: json.RawMessage(`{"type":"object"}`)}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/test-config/versions/1", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), response["version"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/test-config/versions/latest", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestScheduleConfigurationChange(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		effectiveAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		reqJSON := []byte(`{"data":{"key":"scheduled"},"effective_at":"2030-01-01T00:00:00Z"}`)
+
+		// Mock service response
+		expectedChange := &entity.ScheduledChange{
+			ID:          1,
+			Name:        "test-config",
+			Data:        json.RawMessage(`{"key":"scheduled"}`),
+			EffectiveAt: effectiveAt,
+			Status:      entity.ScheduledChangeStatusPending,
+		}
+
+		mockService.On("ScheduleConfigurationChange", "test-config", mock.Anything, effectiveAt).Return(expectedChange, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/schedules", bytes.NewBuffer(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), response["id"])
+		assert.Equal(t, "pending", response["status"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingEffectiveAt", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		reqJSON := []byte(`{"data":{"key":"scheduled"}}`)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/schedules", bytes.NewBuffer(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ScheduleConfigurationChange")
+	})
+}
+
+func TestCancelScheduledChange(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Mock service response
+		cancelled := &entity.ScheduledChange{
+			ID:     3,
+			Name:   "test-config",
+			Status: entity.ScheduledChangeStatusCancelled,
+		}
+		mockService.On("CancelScheduledChange", "test-config", int64(3)).Return(cancelled, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/configurations/test-config/schedules/3", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "cancelled")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/configurations/test-config/schedules/abc", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetConfigurationAsOf(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		asOf := time.Date(2025, 8, 10, 14, 32, 0, 0, time.UTC)
+		expectedConfig := &entity.Configuration{
+			Name:    "test-config",
+			Version: 2,
+			Data:    json.RawMessage(`{"key":"value"}`),
+		}
+
+		mockService.On("GetConfigurationAsOf", "test-config", asOf).Return(expectedConfig, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/test-config?as_of=2025-08-10T14:32:00Z", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":2`)
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "GetConfiguration")
+	})
+
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/test-config?as_of=yesterday", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListConfigurations(t *testing.T) {
+	t.Run("AsOf", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		asOf := time.Date(2025, 8, 10, 14, 32, 0, 0, time.UTC)
+		mockService.On("ListConfigurationsAsOf", asOf).Return([]entity.BulkReadItem{
+			{ConfigurationRef: entity.ConfigurationRef{Name: "a-config", Version: 1}, Configuration: &entity.Configuration{Name: "a-config", Version: 1, Data: json.RawMessage(`{}`)}},
+			{ConfigurationRef: entity.ConfigurationRef{Name: "b-config", Version: 3}, Configuration: &entity.Configuration{Name: "b-config", Version: 3, Data: json.RawMessage(`{}`)}},
+			{ConfigurationRef: entity.ConfigurationRef{Name: "c-config", Version: 2}, Error: errors.NewVersionCompactedError("c-config", 2).ToErrorResponse()},
+		}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations?as_of=2025-08-10T14:32:00Z", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		configs, ok := response["configurations"].([]interface{})
+		assert.True(t, ok)
+		assert.Len(t, configs, 2)
+
+		// The compacted configuration is listed apart instead of failing the request
+		unavailable, ok := response["unavailable"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, unavailable, 1)
+		item := unavailable[0].(map[string]interface{})
+		assert.Equal(t, "c-config", item["name"])
+		assert.Equal(t, string(errors.ErrorCodeVersionCompacted), item["error"].(map[string]interface{})["code"])
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestSetRetentionPolicy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		policy := &entity.RetentionPolicy{Name: "test-config", KeepLast: 5, KeepDays: 30}
+		mockService.On("SetRetentionPolicy", policy).Return(policy, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/test-config/retention", bytes.NewBufferString(`{"keep_last":5,"keep_days":30}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5), response["keep_last"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		policy := &entity.RetentionPolicy{Name: entity.GlobalRetentionPolicyName, KeepLast: 10}
+		mockService.On("SetRetentionPolicy", policy).Return(policy, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/retention", bytes.NewBufferString(`{"keep_last":10}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		policy := &entity.RetentionPolicy{Name: "test-config", KeepLast: -1}
+		mockService.On("SetRetentionPolicy", policy).
+			Return(nil, errors.NewInvalidRequestError("keep_last and keep_days must not be negative", nil))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/test-config/retention", bytes.NewBufferString(`{"keep_last":-1}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestCompactConfiguration(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		result := &entity.CompactionResult{Name: "test-config", CompactedVersions: []int{1, 2}}
+		mockService.On("CompactConfiguration", "test-config", mock.AnythingOfType("time.Time")).Return(result, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/test-config/compact", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"name":"test-config","compacted_versions":[1,2]}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("CompactConfiguration", "non-existent", mock.AnythingOfType("time.Time")).
+			Return(nil, errors.NewNotFoundError("Configuration", "non-existent"))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/non-existent/compact", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestTagVersion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		tag := entity.NewVersionTag("test-config", "v2.3-release", 2, true)
+		mockService.On("TagVersion", "test-config", "v2.3-release", 2, true).Return(tag, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/test-config/tags/v2.3-release", bytes.NewBufferString(`{"version":2,"immutable":true}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "v2.3-release", response["tag"])
+		assert.Equal(t, float64(2), response["version"])
+		assert.Equal(t, true, response["immutable"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("ImmutableConflict", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("TagVersion", "test-config", "v2.3-release", 3, false).
+			Return(nil, errors.NewConflictError("Tag is immutable and cannot be moved", nil))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/test-config/tags/v2.3-release", bytes.NewBufferString(`{"version":3}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingVersion", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/test-config/tags/release", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetConfigurationByTag(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		expectedConfig := &entity.Configuration{
+			Name:    "test-config",
+			Version: 2,
+			Data:    json.RawMessage(`{"key":"v2"}`),
+		}
+		mockService.On("GetConfigurationByTag", "test-config", "release").Return(expectedConfig, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/test-config/tags/release", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":2`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("GetConfigurationByTag", "test-config", "missing").Return(nil, errors.NewNotFoundError("Tag", "missing"))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/test-config/tags/missing", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
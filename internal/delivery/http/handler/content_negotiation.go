@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/format"
+	"github.com/gin-gonic/gin"
+)
+
+// bindBody binds a request body sent as JSON, YAML or TOML. YAML and TOML bodies are
+// converted to JSON first; any other content type is read as JSON.
+func bindBody(c *gin.Context, req interface{}) error {
+	f, ok := format.FromMediaType(c.GetHeader("Content-Type"))
+	if !ok || f == format.JSON {
+		return c.ShouldBindJSON(req)
+	}
+	if !f.CanDecode() {
+		return fmt.Errorf("%s request bodies are not supported, send JSON, YAML or TOML", f)
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	data, err := format.ToJSON(f, body)
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	return c.ShouldBindJSON(req)
+}
+
+// responseFormat determines the format to render configuration data in: the format
+// query parameter if given, otherwise the Accept header
+func responseFormat(c *gin.Context) (format.Format, bool) {
+	name := c.Query("format")
+	if name == "" {
+		return format.Negotiate(c.GetHeader("Accept")), true
+	}
+
+	f, err := format.Parse(name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return "", false
+	}
+	return f, true
+}
+
+// renderConfiguration writes a configuration in the given format. JSON responses hold
+// the whole configuration; other formats hold only its data, with the name and version
+// in the X-Configuration-Name and X-Configuration-Version headers.
+func renderConfiguration(c *gin.Context, f format.Format, config *entity.Configuration) {
+	if f == format.JSON {
+		c.JSON(http.StatusOK, config)
+		return
+	}
+
+	rendered, err := format.FromJSON(f, config.Data)
+	if err != nil {
+		c.JSON(http.StatusNotAcceptable, errors.NewErrorResponse(
+			fmt.Sprintf("Configuration data cannot be rendered as %s", f),
+			errors.ErrorCodeNotAcceptable,
+			err.Error(),
+		))
+		return
+	}
+
+	c.Header("X-Configuration-Name", config.Name)
+	c.Header("X-Configuration-Version", strconv.Itoa(config.Version))
+	c.Data(http.StatusOK, f.MediaType()+"; charset=utf-8", rendered)
+}
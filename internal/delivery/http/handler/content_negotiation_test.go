@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConfigurationRequestFormats(t *testing.T) {
+	t.Run("CreateFromYAML", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("CreateConfiguration", "payment-settings", json.RawMessage(`{"max_limit":1000,"enabled":true}`)).
+			Return(&entity.Configuration{Name: "payment-settings", Version: 1, Data: json.RawMessage(`{"max_limit":1000,"enabled":true}`)}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations", strings.NewReader("name: payment-settings\ndata:\n  max_limit: 1000\n  enabled: true\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("UpdateFromTOML", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("UpdateConfiguration", "payment-settings", json.RawMessage(`{"enabled":false,"max_limit":2000}`)).
+			Return(&entity.Configuration{Name: "payment-settings", Version: 2, Data: json.RawMessage(`{"enabled":false,"max_limit":2000}`)}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/payment-settings", strings.NewReader("[data]\nmax_limit = 2000\nenabled = false\n"))
+		req.Header.Set("Content-Type", "application/toml")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidYAML", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations", strings.NewReader("name: payment-settings\ndata:\n  200: ok\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response errors.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, response.Code)
+		assert.Contains(t, response.Details, "must be a string")
+		mockService.AssertNotCalled(t, "CreateConfiguration", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RenderOnlyFormatRejected", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/payment-settings", strings.NewReader("MAX_LIMIT=1\n"))
+		req.Header.Set("Content-Type", "text/x-env")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateConfiguration", mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigurationResponseFormats(t *testing.T) {
+	config := &entity.Configuration{
+		Name:    "payment-settings",
+		Version: 3,
+		Data:    json.RawMessage(`{"max_limit":1000,"db":{"host":"db.local"}}`),
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"FormatParameter", "/api/v1/configurations/payment-settings?format=yaml", "", "application/yaml; charset=utf-8", "max_limit: 1000\ndb:\n  host: db.local\n"},
+		{"AcceptHeader", "/api/v1/configurations/payment-settings", "application/toml", "application/toml; charset=utf-8", "max_limit = 1000\n\n[db]\n  host = 'db.local'\n"},
+		{"Env", "/api/v1/configurations/payment-settings?format=env", "", "text/x-env; charset=utf-8", "DB_HOST=db.local\nMAX_LIMIT=1000\n"},
+		{"Properties", "/api/v1/configurations/payment-settings", "text/x-java-properties", "text/x-java-properties; charset=utf-8", "db.host=db.local\nmax_limit=1000\n"},
+		{"ParameterOverridesAccept", "/api/v1/configurations/payment-settings?format=env", "application/yaml", "text/x-env; charset=utf-8", "DB_HOST=db.local\nMAX_LIMIT=1000\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockConfigurationService)
+			router := setupRouter(mockService)
+			mockService.On("GetConfiguration", "payment-settings").Return(config, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "payment-settings", w.Header().Get("X-Configuration-Name"))
+			assert.Equal(t, "3", w.Header().Get("X-Configuration-Version"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+
+	t.Run("JSONByDefault", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("GetConfigurationVersion", "payment-settings", 3).Return(config, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/payment-settings/versions/3", nil)
+		req.Header.Set("Accept", "text/html, */*")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.Configuration
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Version)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/payment-settings?format=xml", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetConfiguration", mock.Anything)
+	})
+
+	t.Run("NotRepresentable", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+		mockService.On("GetConfiguration", "payment-settings").
+			Return(&entity.Configuration{Name: "payment-settings", Version: 1, Data: json.RawMessage(`{"fallback":null}`)}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/configurations/payment-settings?format=toml", nil)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		var response errors.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, errors.ErrorCodeNotAcceptable, response.Code)
+		assert.Contains(t, response.Details, "null at /fallback")
+	})
+}
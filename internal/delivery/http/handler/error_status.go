@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// StatusClientClosedRequest is the status of a request cancelled by the client before
+// it completed. No response reaches the client; the status is seen in logs and metrics.
+const StatusClientClosedRequest = 499
+
+// errorStatus returns the status of an error a handler does not map itself: 504 when
+// the request's deadline passed, 499 when the client cancelled it and 500 otherwise
+func errorStatus(appErr *errors.AppError) int {
+	switch appErr.Code {
+	case errors.ErrorCodeRequestTimeout:
+		return http.StatusGatewayTimeout
+	case errors.ErrorCodeRequestCancelled:
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
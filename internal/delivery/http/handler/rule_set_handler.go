@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetRuleSet handles storing a new version of a configuration's validation rules
+func (h *ConfigurationHandler) SetRuleSet(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		Rules []rules.Rule `json:"rules" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	force, err := parseBoolQuery(c, "force")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid force flag",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	ruleSet, err := h.configService.SetRuleSet(c.Request.Context(), name, req.Rules, force)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest, errors.ErrorCodeValidationFailed:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to store rule set",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, ruleSet)
+}
+
+// GetRuleSet handles retrieving the current validation rules of a configuration
+func (h *ConfigurationHandler) GetRuleSet(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	ruleSet, err := h.configService.GetRuleSet(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get rule set",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ruleSet)
+}
+
+// ListRuleSetVersions handles listing the rule set history of a configuration
+func (h *ConfigurationHandler) ListRuleSetVersions(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	versions, err := h.configService.ListRuleSetVersions(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list rule set versions",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":     name,
+		"versions": versions,
+	})
+}
+
+// GetRuleSetVersion handles retrieving a specific rule set version of a configuration
+func (h *ConfigurationHandler) GetRuleSetVersion(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid version format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	ruleSet, err := h.configService.GetRuleSetVersion(c.Request.Context(), name, version)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get rule set version",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ruleSet)
+}
+
+// TestRules handles a dry run of validation rules against configuration data.
+// The rules default to the current rule set and the data to the current configuration data.
+func (h *ConfigurationHandler) TestRules(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var req struct {
+		Rules []rules.Rule    `json:"rules"`
+		Data  json.RawMessage `json:"data"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	result, err := h.configService.TestRules(c.Request.Context(), name, req.Rules, req.Data)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to test rules",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetRuleSet(t *testing.T) {
+	ruleList := []rules.Rule{{Name: "limits", Expression: "max_limit > min_limit"}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("SetRuleSet", "payments", ruleList, false).Return(&entity.RuleSet{Name: "payments", Version: 1, Rules: ruleList}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/payments/rules", bytes.NewBufferString(`{"rules":[{"name":"limits","expression":"max_limit > min_limit"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"version":1`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("SetRuleSet", "payments", mock.Anything, false).Return(nil,
+			errors.NewInvalidRequestError("Invalid validation rules", []errors.ValidationError{{Field: "rules.0", Reason: "invalid expression"}}))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/payments/rules", bytes.NewBufferString(`{"rules":[{"name":"limits","expression":"max_limit >"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("MissingRules", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/configurations/payments/rules", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SetRuleSet", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetRuleSetVersion(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("GetRuleSetVersion", "payments", 4).Return(nil, errors.NewNotFoundError("Rule set version", "payments:4"))
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/configurations/payments/rules/versions/4", nil)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTestRules(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("TestRules", "payments", []rules.Rule(nil), json.RawMessage(`{"max_limit":1,"min_limit":2}`)).Return(&entity.RuleTestResult{
+		Valid:  false,
+		Errors: []errors.ValidationError{{Field: "max_limit", Reason: `rule "limits" failed: max_limit > min_limit`}},
+	}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/rules/test", bytes.NewBufferString(`{"data":{"max_limit":1,"min_limit":2}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"valid":false`)
+	mockService.AssertExpectations(t)
+}
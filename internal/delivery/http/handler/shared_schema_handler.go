@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sharedSchemaName extracts the shared schema name from the catch-all route parameter,
+// since names such as common/endpoint contain slashes
+func sharedSchemaName(c *gin.Context) string {
+	return strings.Trim(c.Param("name"), "/")
+}
+
+// RegisterSharedSchema handles creating or replacing a shared schema fragment
+func (h *ConfigurationHandler) RegisterSharedSchema(c *gin.Context) {
+	name := sharedSchemaName(c)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Shared schema name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var schema json.RawMessage
+	if err := c.ShouldBindJSON(&schema); err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid schema format",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	force, err := parseBoolQuery(c, "force")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid force flag",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	shared, err := h.configService.RegisterSharedSchema(c.Request.Context(), name, schema, force)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest, errors.ErrorCodeValidationFailed:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to register shared schema",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// GetSharedSchema handles retrieving a shared schema fragment
+func (h *ConfigurationHandler) GetSharedSchema(c *gin.Context) {
+	name := sharedSchemaName(c)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Shared schema name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	shared, err := h.configService.GetSharedSchema(c.Request.Context(), name)
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			if appErr.Code == errors.ErrorCodeNotFound {
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			} else {
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to get shared schema",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// ListSharedSchemas handles listing all shared schema fragments
+func (h *ConfigurationHandler) ListSharedSchemas(c *gin.Context) {
+	schemas, err := h.configService.ListSharedSchemas(c.Request.Context())
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to list shared schemas",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shared_schemas": schemas,
+	})
+}
+
+// DeleteSharedSchema handles removing a shared schema fragment
+func (h *ConfigurationHandler) DeleteSharedSchema(c *gin.Context) {
+	name := sharedSchemaName(c)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Shared schema name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	if err := h.configService.DeleteSharedSchema(c.Request.Context(), name); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeNotFound:
+				c.JSON(http.StatusNotFound, appErr.ToErrorResponse())
+			case errors.ErrorCodeConflict:
+				c.JSON(http.StatusConflict, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to delete shared schema",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegisterSharedSchema(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("RegisterSharedSchema", "common/endpoint", mock.Anything, false).Return(&entity.SharedSchema{
+			Name:   "common/endpoint",
+			Schema: json.RawMessage(`{"type":"object"}`),
+		}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/shared-schemas/common/endpoint", bytes.NewBufferString(`{"type":"object"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"common/endpoint"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DependentsInvalid", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("RegisterSharedSchema", "common/endpoint", mock.Anything, false).Return(nil,
+			errors.NewValidationFailedError("Dependent configurations do not conform to the shared schema", []entity.DependentValidation{
+				{Name: "api-config", VersionValidation: entity.VersionValidation{Version: 2}},
+			}))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/shared-schemas/common/endpoint", bytes.NewBufferString(`{"required":["url"]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "api-config")
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("RegisterSharedSchema", "common/endpoint", mock.Anything, true).Return(&entity.SharedSchema{Name: "common/endpoint"}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/shared-schemas/common/endpoint?force=true", bytes.NewBufferString(`{"required":["url"]}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetSharedSchema(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("GetSharedSchema", "common/missing").Return(nil, errors.NewNotFoundError("Shared schema", "common/missing"))
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/shared-schemas/common/missing", nil)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteSharedSchema(t *testing.T) {
+	mockService := new(MockConfigurationService)
+	router := setupRouter(mockService)
+
+	mockService.On("DeleteSharedSchema", "common/endpoint").Return(
+		errors.NewConflictError("Shared schema is still referenced", map[string][]string{"referenced_by": {"api-config"}}))
+
+	// Create request
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/v1/shared-schemas/common/endpoint", nil)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "api-config")
+}
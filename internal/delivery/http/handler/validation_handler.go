@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/format"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ValidateDocument handles a dry run of a configuration's schema and validation rules.
+// The request body is the configuration document itself, in JSON, YAML or TOML as
+// given by Content-Type, so reported line and column positions refer to the submitted
+// file. YAML values are located at the value and TOML values at their key.
+func (h *ConfigurationHandler) ValidateDocument(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Configuration name is required",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil || len(data) == 0 {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Request body must be the configuration document",
+			errors.ErrorCodeInvalidRequest,
+			nil,
+		))
+		return
+	}
+
+	var positions format.SourceMap
+	if f, ok := format.FromMediaType(c.GetHeader("Content-Type")); ok && f != format.JSON {
+		if !f.CanDecode() {
+			c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+				"Request body must be the configuration document",
+				errors.ErrorCodeInvalidRequest,
+				fmt.Sprintf("%s request bodies are not supported, send JSON, YAML or TOML", f),
+			))
+			return
+		}
+
+		converted, sourceMap, err := format.ToJSONWithSourceMap(f, data)
+		if err != nil {
+			// A document that cannot be converted is reported like malformed JSON
+			var syntaxErr *format.SyntaxError
+			if !stdErrors.As(err, &syntaxErr) {
+				syntaxErr = &format.SyntaxError{Position: format.Position{Line: 1, Column: 1}, Message: err.Error()}
+			}
+			report := &entity.ValidationReport{Valid: true, Errors: []entity.ValidationIssue{}}
+			report.AddIssue(entity.ValidationIssue{
+				Source: entity.ValidationSourceSyntax,
+				ValidationError: errors.ValidationError{
+					Field:  validator.RootField,
+					Reason: syntaxErr.Message,
+					Code:   errors.ValidationCodeInvalid,
+				},
+				Line:   syntaxErr.Line,
+				Column: syntaxErr.Column,
+			})
+			c.JSON(http.StatusOK, report)
+			return
+		}
+		data, positions = converted, sourceMap
+	}
+
+	report, err := h.configService.ValidateDocument(c.Request.Context(), name, data)
+	if err == nil && positions != nil {
+		// The usecase locates issues in the converted JSON; move them to the source
+		for i, issue := range report.Errors {
+			position := positions.Locate(issue.Pointer)
+			report.Errors[i].Line, report.Errors[i].Column = position.Line, position.Column
+		}
+	}
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to validate document",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ValidateAgainstSchema handles a dry run of an ad-hoc schema against a document.
+// Reported line and column positions refer to the request body.
+func (h *ConfigurationHandler) ValidateAgainstSchema(c *gin.Context) {
+	var req struct {
+		Schema json.RawMessage `json:"schema" binding:"required"`
+		Data   json.RawMessage `json:"data" binding:"required"`
+	}
+
+	body, err := c.GetRawData()
+	if err == nil {
+		err = binding.JSON.BindBody(body, &req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.NewErrorResponse(
+			"Invalid request body",
+			errors.ErrorCodeInvalidRequest,
+			err.Error(),
+		))
+		return
+	}
+
+	report, err := h.configService.ValidateAgainstSchema(c.Request.Context(), req.Schema, req.Data)
+	if err == nil {
+		// The usecase locates issues within the data value; move them to where that
+		// value starts in the body
+		var doc *validator.Document
+		if doc, err = validator.ParseDocument(body); err == nil {
+			start := doc.LocatePointer("/data")
+			for i, issue := range report.Errors {
+				position := validator.Position{Line: issue.Line, Column: issue.Column}.Offset(start)
+				report.Errors[i].Line, report.Errors[i].Column = position.Line, position.Column
+			}
+		}
+	}
+	if err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			switch appErr.Code {
+			case errors.ErrorCodeInvalidRequest:
+				c.JSON(http.StatusBadRequest, appErr.ToErrorResponse())
+			default:
+				c.JSON(errorStatus(appErr), appErr.ToErrorResponse())
+			}
+		} else {
+			c.JSON(http.StatusInternalServerError, errors.NewErrorResponse(
+				"Failed to validate document",
+				errors.ErrorCodeInternalError,
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
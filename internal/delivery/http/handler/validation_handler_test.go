@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateDocument(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		report := &entity.ValidationReport{Errors: []entity.ValidationIssue{}}
+		report.AddIssue(entity.ValidationIssue{
+			Source:          entity.ValidationSourceSchema,
+			ValidationError: errors.ValidationError{Field: "limit", Pointer: "/limit", Reason: "Must be less than or equal to 100"},
+			Line:            1,
+			Column:          11,
+		})
+		mockService.On("ValidateDocument", "payments", json.RawMessage(`{"limit": 500}`)).Return(report, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/validate", bytes.NewBufferString(`{"limit": 500}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"valid":false`)
+		assert.Contains(t, w.Body.String(), `"pointer":"/limit"`)
+		assert.Contains(t, w.Body.String(), `"error_count":1`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		report := &entity.ValidationReport{Errors: []entity.ValidationIssue{}}
+		report.AddIssue(entity.ValidationIssue{
+			Source:          entity.ValidationSourceSchema,
+			ValidationError: errors.ValidationError{Field: "limits.max", Pointer: "/limits/max", Reason: "Must be less than or equal to 100"},
+			Line:            1,
+			Column:          19,
+		})
+		mockService.On("ValidateDocument", "payments", json.RawMessage(`{"name":"payments","limits":{"max":500}}`)).Return(report, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/validate", bytes.NewBufferString("name: payments\nlimits:\n  max: 500\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions: the issue is located in the YAML source, not the converted JSON
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.ValidationReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Errors, 1) {
+			assert.Equal(t, [2]int{3, 8}, [2]int{response.Errors[0].Line, response.Errors[0].Column})
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("YAMLSyntaxError", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/validate", bytes.NewBufferString("name: payments\n  limit: : 1\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.ValidationReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Valid)
+		assert.Equal(t, 1, response.Summary.SyntaxErrors)
+		if assert.Len(t, response.Errors, 1) {
+			assert.Equal(t, 2, response.Errors[0].Line)
+		}
+		mockService.AssertNotCalled(t, "ValidateDocument", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/validate", bytes.NewBufferString("LIMIT=500\n"))
+		req.Header.Set("Content-Type", "text/x-env")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ValidateDocument", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmptyBody", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/configurations/payments/validate", nil)
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ValidateDocument", mock.Anything, mock.Anything)
+	})
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("ValidateAgainstSchema", mock.Anything, mock.Anything).
+			Return(&entity.ValidationReport{Valid: true, Summary: entity.ValidationSummary{SchemaChecked: true}, Errors: []entity.ValidationIssue{}}, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/validate", bytes.NewBufferString(`{"schema":{"type":"object"},"data":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"valid":true`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PositionsRelativeToBody", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		body := "{\n  \"schema\": {\"type\": \"object\"},\n  \"data\": {\"limit\": 500,\n    \"name\": 1}\n}"
+		report := &entity.ValidationReport{Errors: []entity.ValidationIssue{}}
+		report.AddIssue(entity.ValidationIssue{
+			Source:          entity.ValidationSourceSchema,
+			ValidationError: errors.ValidationError{Field: "limit", Pointer: "/limit", Reason: "Must be less than or equal to 100"},
+			Line:            1,
+			Column:          11,
+		})
+		report.AddIssue(entity.ValidationIssue{
+			Source:          entity.ValidationSourceSchema,
+			ValidationError: errors.ValidationError{Field: "name", Pointer: "/name", Reason: "Invalid type. Expected: string, given: integer"},
+			Line:            2,
+			Column:          13,
+		})
+		mockService.On("ValidateAgainstSchema", json.RawMessage(`{"type": "object"}`), json.RawMessage("{\"limit\": 500,\n    \"name\": 1}")).Return(report, nil)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/validate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response entity.ValidationReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Errors, 2) {
+			assert.Equal(t, [2]int{3, 21}, [2]int{response.Errors[0].Line, response.Errors[0].Column})
+			assert.Equal(t, [2]int{4, 13}, [2]int{response.Errors[1].Line, response.Errors[1].Column})
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidSchema", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		mockService.On("ValidateAgainstSchema", mock.Anything, mock.Anything).
+			Return(nil, errors.NewInvalidRequestError("Invalid JSON Schema", "bad type"))
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/validate", bytes.NewBufferString(`{"schema":{"type":"nonsense"},"data":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("MissingData", func(t *testing.T) {
+		mockService := new(MockConfigurationService)
+		router := setupRouter(mockService)
+
+		// Create request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/schemas/validate", bytes.NewBufferString(`{"schema":{"type":"object"}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ValidateAgainstSchema", mock.Anything, mock.Anything)
+	})
+}
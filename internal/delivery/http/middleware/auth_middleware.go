@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/Titonu/configuration-management-service/internal/metrics"
 	"github.com/Titonu/configuration-management-service/pkg/errors"
 	"net/http"
 	"strings"
@@ -10,14 +11,30 @@ import (
 
 // AuthMiddleware handles authentication for API requests
 type AuthMiddleware struct {
-	apiKeys map[string]string // map of API key to user/client ID
+	apiKeys        map[string]string // map of API key to user/client ID
+	observeFailure func(reason string)
+}
+
+// AuthOption configures an AuthMiddleware
+type AuthOption func(*AuthMiddleware)
+
+// WithFailureObserver calls observe with the reason of every rejected request, one of
+// the metrics.AuthFailure reasons
+func WithFailureObserver(observe func(reason string)) AuthOption {
+	return func(m *AuthMiddleware) {
+		m.observeFailure = observe
+	}
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(apiKeys map[string]string) *AuthMiddleware {
-	return &AuthMiddleware{
+func NewAuthMiddleware(apiKeys map[string]string, opts ...AuthOption) *AuthMiddleware {
+	m := &AuthMiddleware{
 		apiKeys: apiKeys,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // Authenticate returns a middleware function that validates API keys
@@ -28,6 +45,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 
 		// Check if Authorization header exists
 		if authHeader == "" {
+			m.failed(metrics.AuthFailureMissingKey)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.NewErrorResponse(
 				"API key is required",
 				errors.ErrorCodeUnauthorized,
@@ -39,6 +57,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		// Check if it's a Bearer token
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			m.failed(metrics.AuthFailureInvalidFormat)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.NewErrorResponse(
 				"Invalid authorization format",
 				errors.ErrorCodeUnauthorized,
@@ -53,6 +72,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		// Validate API key
 		clientID, valid := m.apiKeys[apiKey]
 		if !valid {
+			m.failed(metrics.AuthFailureInvalidKey)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.NewErrorResponse(
 				"Invalid API key",
 				errors.ErrorCodeUnauthorized,
@@ -68,3 +88,10 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// failed reports a rejected request to the failure observer, if any
+func (m *AuthMiddleware) failed(reason string) {
+	if m.observeFailure != nil {
+		m.observeFailure(reason)
+	}
+}
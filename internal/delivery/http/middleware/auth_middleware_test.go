@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Titonu/configuration-management-service/internal/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -114,3 +115,29 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestAuthMiddlewareFailureObserver(t *testing.T) {
+	var reasons []string
+	authMiddleware := NewAuthMiddleware(
+		map[string]string{"valid-key": "test-client"},
+		WithFailureObserver(func(reason string) { reasons = append(reasons, reason) }),
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authMiddleware.Authenticate())
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, header := range []string{"", "NotBearer token", "Bearer invalid-key", "Bearer valid-key"} {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Only rejected requests are reported
+	assert.Equal(t, []string{metrics.AuthFailureMissingKey, metrics.AuthFailureInvalidFormat, metrics.AuthFailureInvalidKey}, reasons)
+}
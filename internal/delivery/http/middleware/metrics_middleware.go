@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary paths do not
+// create new series
+const unmatchedRoute = "unmatched"
+
+// RequestObserver records served HTTP requests
+type RequestObserver interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// MetricsMiddleware records the method, route, status and latency of every request.
+// Requests are labelled with the route pattern, e.g. /api/v1/configurations/:name,
+// rather than the requested path.
+func MetricsMiddleware(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		observer.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
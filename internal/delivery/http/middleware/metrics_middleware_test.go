@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedRequest is a request reported to a RequestObserver
+type recordedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+// requestRecorder records the requests reported to it
+type requestRecorder struct {
+	requests []recordedRequest
+}
+
+func (r *requestRecorder) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	r.requests = append(r.requests, recordedRequest{method: method, route: route, status: status})
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	recorder := &requestRecorder{}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MetricsMiddleware(recorder))
+	router.GET("/configurations/:name", func(c *gin.Context) {
+		if c.Param("name") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/configurations/payments", "/configurations/missing", "/unknown/path"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Requests are labelled with the route pattern rather than the path
+	assert.Equal(t, []recordedRequest{
+		{method: "GET", route: "/configurations/:name", status: http.StatusOK},
+		{method: "GET", route: "/configurations/:name", status: http.StatusNotFound},
+		{method: "GET", route: unmatchedRoute, status: http.StatusNotFound},
+	}, recorder.requests)
+}
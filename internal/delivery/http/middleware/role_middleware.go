@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleAdmin is the role required for administrative operations such as deleting schemas
+const RoleAdmin = "admin"
+
+// RoleMiddleware restricts routes to authenticated clients holding a role
+type RoleMiddleware struct {
+	clientRoles map[string]map[string]bool // map of client ID to its roles
+}
+
+// NewRoleMiddleware creates a new role middleware from a map of client ID to roles
+func NewRoleMiddleware(clientRoles map[string][]string) *RoleMiddleware {
+	roles := make(map[string]map[string]bool, len(clientRoles))
+	for clientID, clientRoleList := range clientRoles {
+		roles[clientID] = make(map[string]bool, len(clientRoleList))
+		for _, role := range clientRoleList {
+			roles[clientID][role] = true
+		}
+	}
+
+	return &RoleMiddleware{
+		clientRoles: roles,
+	}
+}
+
+// RequireRole returns a middleware function that rejects clients without the given role.
+// It must run after Authenticate, which identifies the client.
+func (m *RoleMiddleware) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID := c.GetString("client_id")
+		if !m.clientRoles[clientID][role] {
+			c.AbortWithStatusJSON(http.StatusForbidden, errors.NewErrorResponse(
+				"Client is not allowed to perform this operation",
+				errors.ErrorCodeForbidden,
+				map[string]string{"required_role": role},
+			))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleMiddleware(t *testing.T) {
+	// Set up test API keys and roles
+	apiKeys := map[string]string{
+		"admin-key": "admin-client",
+		"user-key":  "user-client",
+	}
+	clientRoles := map[string][]string{
+		"admin-client": {RoleAdmin},
+	}
+
+	// Create middleware
+	authMiddleware := NewAuthMiddleware(apiKeys)
+	roleMiddleware := NewRoleMiddleware(clientRoles)
+
+	// Set up Gin router for testing
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authMiddleware.Authenticate())
+	router.DELETE("/test", roleMiddleware.RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	t.Run("ClientWithRole", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/test", nil)
+		req.Header.Set("Authorization", "Bearer admin-key")
+		w := httptest.NewRecorder()
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("ClientWithoutRole", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/test", nil)
+		req.Header.Set("Authorization", "Bearer user-key")
+		w := httptest.NewRecorder()
+
+		// Perform request
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), RoleAdmin)
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutOption configures the TimeoutMiddleware
+type TimeoutOption func(routes map[string]time.Duration)
+
+// WithRouteTimeout gives requests to the route registered at path, such as
+// "/api/v1/export", a deadline of timeout instead of the default one. Streaming routes
+// that legitimately outlast ordinary requests use it.
+func WithRouteTimeout(path string, timeout time.Duration) TimeoutOption {
+	return func(routes map[string]time.Duration) {
+		routes[path] = timeout
+	}
+}
+
+// TimeoutMiddleware gives every request a deadline of timeout, passed down in the request
+// context so that usecase and repository calls stop once it has passed. The usecase
+// reports calls aborted this way as REQUEST_TIMEOUT, or REQUEST_CANCELLED when the client
+// went away first. A zero timeout applies no deadline.
+func TimeoutMiddleware(timeout time.Duration, opts ...TimeoutOption) gin.HandlerFunc {
+	routes := map[string]time.Duration{}
+	for _, opt := range opts {
+		opt(routes)
+	}
+
+	return func(c *gin.Context) {
+		timeout := timeout
+		if routeTimeout, ok := routes[c.FullPath()]; ok {
+			timeout = routeTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
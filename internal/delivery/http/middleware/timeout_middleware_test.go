@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTimeoutRouter creates a router whose handler reports the deadline of its request
+// context
+func newTimeoutRouter(timeout time.Duration, opts ...TimeoutOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutMiddleware(timeout, opts...))
+	router.GET("/deadline", func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		if !ok {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.String(http.StatusOK, deadline.Format(time.RFC3339Nano))
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, errors.NewErrorResponse("Configuration not found", errors.ErrorCodeNotFound, nil))
+	})
+	return router
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Run("SetsDeadline", func(t *testing.T) {
+		router := newTimeoutRouter(time.Minute)
+
+		start := time.Now()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/deadline", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		deadline, err := time.Parse(time.RFC3339Nano, w.Body.String())
+		assert.NoError(t, err)
+		assert.WithinDuration(t, start.Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("RouteTimeout", func(t *testing.T) {
+		router := newTimeoutRouter(time.Second, WithRouteTimeout("/deadline", time.Hour))
+
+		start := time.Now()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/deadline", nil)
+		router.ServeHTTP(w, req)
+
+		// The route's own deadline replaces the default one
+		assert.Equal(t, http.StatusOK, w.Code)
+		deadline, err := time.Parse(time.RFC3339Nano, w.Body.String())
+		assert.NoError(t, err)
+		assert.WithinDuration(t, start.Add(time.Hour), deadline, 5*time.Second)
+	})
+
+	t.Run("NoTimeout", func(t *testing.T) {
+		router := newTimeoutRouter(0)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/deadline", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("CompletedInTime", func(t *testing.T) {
+		router := newTimeoutRouter(time.Second)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/fast", nil)
+		router.ServeHTTP(w, req)
+
+		// Responses of requests that completed in time are not touched
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
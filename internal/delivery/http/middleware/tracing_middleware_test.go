@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TracingMiddleware(provider.Tracer("test"), propagation.TraceContext{}))
+	var handlerSpan trace.SpanContext
+	router.PUT("/configurations/:name", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		if c.Param("name") == "broken" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// The trace of the caller is continued
+	req, _ := http.NewRequest("PUT", "/configurations/payments", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, recorder.Ended(), 1)
+	span := recorder.Ended()[0]
+	assert.Equal(t, "PUT /configurations/:name", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, span.SpanContext(), handlerSpan, "handlers see the request span in the context")
+	assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	// Server errors mark the span as failed
+	req, _ = http.NewRequest("PUT", "/configurations/broken", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, recorder.Ended(), 2)
+	span = recorder.Ended()[1]
+	assert.False(t, span.Parent().IsValid(), "requests without traceparent start a new trace")
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
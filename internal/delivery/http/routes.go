@@ -11,6 +11,7 @@ func SetupRoutes(
 	router *gin.Engine,
 	configHandler *handler.ConfigurationHandler,
 	authMiddleware *middleware.AuthMiddleware,
+	roleMiddleware *middleware.RoleMiddleware,
 ) {
 	// API version group
 	api := router.Group("/api/v1")
@@ -24,6 +25,9 @@ func SetupRoutes(
 		// Create a new configuration
 		config.POST("", configHandler.CreateConfiguration)
 
+		// List the effective version of every configuration (optionally as of a timestamp)
+		config.GET("", configHandler.ListConfigurations)
+
 		// Get a configuration
 		config.GET("/:name", configHandler.GetConfiguration)
 
@@ -36,20 +40,149 @@ func SetupRoutes(
 		// Get a specific version of a configuration
 		config.GET("/:name/versions/:version", configHandler.GetConfigurationVersion)
 
-		// Rollback a configuration to a previous version
+		// Rollback a configuration to a previous version or tag
 		config.POST("/:name/rollback", configHandler.RollbackConfiguration)
+
+		// List the tags of a configuration
+		config.GET("/:name/tags", configHandler.ListVersionTags)
+
+		// Get the configuration version a tag points at
+		config.GET("/:name/tags/:tag", configHandler.GetConfigurationByTag)
+
+		// Attach or move a tag
+		config.PUT("/:name/tags/:tag", configHandler.TagVersion)
+
+		// Remove a movable tag
+		config.DELETE("/:name/tags/:tag", configHandler.DeleteVersionTag)
+
+		// Schedule a configuration change for a future time
+		config.POST("/:name/schedules", configHandler.ScheduleConfigurationChange)
+
+		// List scheduled changes of a configuration
+		config.GET("/:name/schedules", configHandler.ListScheduledChanges)
+
+		// Cancel a pending scheduled change
+		config.DELETE("/:name/schedules/:id", configHandler.CancelScheduledChange)
+
+		// Get the retention policy of a configuration
+		config.GET("/:name/retention", configHandler.GetRetentionPolicy)
+
+		// Create or replace the retention policy of a configuration
+		config.PUT("/:name/retention", configHandler.SetRetentionPolicy)
+
+		// Remove the retention policy of a configuration
+		config.DELETE("/:name/retention", configHandler.DeleteRetentionPolicy)
+
+		// Enforce the retention policy of a configuration immediately
+		config.POST("/:name/compact", configHandler.CompactConfiguration)
+
+		// Get the current validation rules of a configuration
+		config.GET("/:name/rules", configHandler.GetRuleSet)
+
+		// Store a new version of the validation rules of a configuration
+		config.PUT("/:name/rules", configHandler.SetRuleSet)
+
+		// List the validation rule history of a configuration
+		config.GET("/:name/rules/versions", configHandler.ListRuleSetVersions)
+
+		// Get a specific version of the validation rules
+		config.GET("/:name/rules/versions/:version", configHandler.GetRuleSetVersion)
+
+		// Dry-run validation rules against configuration data
+		config.POST("/:name/rules/test", configHandler.TestRules)
+
+		// Dry-run the schema and validation rules of a configuration against a document
+		config.POST("/:name/validate", configHandler.ValidateDocument)
+
+		// Propose a configuration change for review
+		config.POST("/:name/change-requests", configHandler.CreateChangeRequest)
+
+		// List change requests of a configuration
+		config.GET("/:name/change-requests", configHandler.ListChangeRequests)
+
+		// Get a change request
+		config.GET("/:name/change-requests/:id", configHandler.GetChangeRequest)
+
+		// Approve a change request, applying it once enough approvals are collected
+		config.POST("/:name/change-requests/:id/approve", configHandler.ApproveChangeRequest)
+
+		// Reject a change request
+		config.POST("/:name/change-requests/:id/reject", configHandler.RejectChangeRequest)
+
+		// Withdraw a change request (author only)
+		config.POST("/:name/change-requests/:id/withdraw", configHandler.WithdrawChangeRequest)
+
+		// Comment on a change request
+		config.POST("/:name/change-requests/:id/comments", configHandler.CommentOnChangeRequest)
 	}
 
 	// Schema routes
 	schema := api.Group("/schemas")
 	{
-		// Register a schema for a configuration
+		// List the current schemas of all configurations
+		schema.GET("", configHandler.ListSchemas)
+
+		// Dry-run an ad-hoc schema against a document
+		schema.POST("/validate", configHandler.ValidateAgainstSchema)
+
+		// Register a new schema version for a configuration
 		schema.POST("/:name", configHandler.RegisterSchema)
 
 		// Get a schema for a configuration
 		schema.GET("/:name", configHandler.GetSchema)
+
+		// List the schema history of a configuration
+		schema.GET("/:name/versions", configHandler.ListSchemaVersions)
+
+		// Get a specific version of a schema
+		schema.GET("/:name/versions/:version", configHandler.GetSchemaVersion)
+
+		// Detach the schema from a configuration (admin only)
+		schema.DELETE("/:name", roleMiddleware.RequireRole(middleware.RoleAdmin), configHandler.DeleteSchema)
 	}
 
+	// Shared schema routes; names may contain slashes (e.g. common/endpoint)
+	shared := api.Group("/shared-schemas")
+	{
+		// List all shared schema fragments
+		shared.GET("", configHandler.ListSharedSchemas)
+
+		// Get a shared schema fragment
+		shared.GET("/*name", configHandler.GetSharedSchema)
+
+		// Create or replace a shared schema fragment
+		shared.PUT("/*name", configHandler.RegisterSharedSchema)
+
+		// Remove a shared schema fragment that no schema references (admin only)
+		shared.DELETE("/*name", roleMiddleware.RequireRole(middleware.RoleAdmin), configHandler.DeleteSharedSchema)
+	}
+
+	// Report routes
+	reports := api.Group("/reports")
+	{
+		// List configurations whose current data is not validated by a schema
+		reports.GET("/unvalidated", configHandler.ListUnvalidatedConfigurations)
+	}
+
+	// Global retention policy routes
+	retention := api.Group("/retention")
+	{
+		// Get the global retention policy
+		retention.GET("", configHandler.GetGlobalRetentionPolicy)
+
+		// Create or replace the global retention policy
+		retention.PUT("", configHandler.SetGlobalRetentionPolicy)
+	}
+
+	// Export every configuration with its full history, the shared schemas and the global retention policy
+	api.GET("/export", configHandler.ExportArchive)
+
+	// Restore an exported archive (admin only)
+	api.POST("/import", roleMiddleware.RequireRole(middleware.RoleAdmin), configHandler.ImportArchive)
+
+	// Apply create, update and rollback operations across configurations atomically
+	api.POST("/batch", configHandler.ApplyBatch)
+
 	// Health check endpoint (no auth required)
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -0,0 +1,233 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// ArchiveFormatVersion is the version of the export archive format written by this release
+const ArchiveFormatVersion = 1
+
+// ArchiveRecordType identifies what an archive record holds
+type ArchiveRecordType string
+
+const (
+	// ArchiveRecordHeader opens an archive
+	ArchiveRecordHeader ArchiveRecordType = "header"
+	// ArchiveRecordSharedSchema holds a shared schema fragment
+	ArchiveRecordSharedSchema ArchiveRecordType = "shared_schema"
+	// ArchiveRecordRetentionPolicy holds the global retention policy
+	ArchiveRecordRetentionPolicy ArchiveRecordType = "retention_policy"
+	// ArchiveRecordConfiguration holds everything stored for one configuration
+	ArchiveRecordConfiguration ArchiveRecordType = "configuration"
+	// ArchiveRecordFooter closes an archive, so that a truncated archive is detected
+	ArchiveRecordFooter ArchiveRecordType = "footer"
+)
+
+// ArchiveRecord is one entry of an export archive. Exactly one of the payload fields
+// is set, as named by Type.
+type ArchiveRecord struct {
+	Type            ArchiveRecordType     `json:"type"`
+	Header          *ArchiveHeader        `json:"header,omitempty"`
+	SharedSchema    *SharedSchema         `json:"shared_schema,omitempty"`
+	RetentionPolicy *RetentionPolicy      `json:"retention_policy,omitempty"`
+	Configuration   *ConfigurationArchive `json:"configuration,omitempty"`
+	Footer          *ArchiveFooter        `json:"footer,omitempty"`
+}
+
+// ArchiveHeader describes an archive
+type ArchiveHeader struct {
+	FormatVersion int       `json:"format_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// ArchiveFooter counts the records between the header and the footer
+type ArchiveFooter struct {
+	Records int `json:"records"`
+}
+
+// ConfigurationArchive holds the full history stored under a configuration name: its
+// versions, tags, schema versions, rule set versions and retention policy. A name may
+// have a schema or rules without any configuration versions.
+type ConfigurationArchive struct {
+	Name         string            `json:"name"`
+	CreatedAt    time.Time         `json:"created_at"`
+	RollbackFrom int               `json:"rollback_from,omitempty"`
+	RollbackTo   int               `json:"rollback_to,omitempty"`
+	Versions     []ArchivedVersion `json:"versions,omitempty"`
+	Tags         []*VersionTag     `json:"tags,omitempty"`
+	Schemas      []*SchemaVersion  `json:"schemas,omitempty"`
+
+	// SchemaDetached is set when the schema history is kept but no schema is current
+	SchemaDetached  bool             `json:"schema_detached,omitempty"`
+	RuleSets        []*RuleSet       `json:"rule_sets,omitempty"`
+	RetentionPolicy *RetentionPolicy `json:"retention_policy,omitempty"`
+}
+
+// ArchivedVersion is one configuration version in an archive. Data is omitted for
+// versions compacted by a retention policy.
+type ArchivedVersion struct {
+	Version       int             `json:"version"`
+	Data          json.RawMessage `json:"data,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	IsRollback    bool            `json:"is_rollback,omitempty"`
+	Compacted     bool            `json:"compacted,omitempty"`
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	ChangesetID   string          `json:"changeset_id,omitempty"`
+}
+
+// Current returns the latest archived version, or nil when there is none
+func (a *ConfigurationArchive) Current() *ArchivedVersion {
+	if len(a.Versions) == 0 {
+		return nil
+	}
+	return &a.Versions[len(a.Versions)-1]
+}
+
+// CurrentSchema returns the schema that is current for the archived name, or nil
+func (a *ConfigurationArchive) CurrentSchema() *SchemaVersion {
+	if len(a.Schemas) == 0 || a.SchemaDetached {
+		return nil
+	}
+	return a.Schemas[len(a.Schemas)-1]
+}
+
+// CurrentRuleSet returns the latest archived rule set, or nil
+func (a *ConfigurationArchive) CurrentRuleSet() *RuleSet {
+	if len(a.RuleSets) == 0 {
+		return nil
+	}
+	return a.RuleSets[len(a.RuleSets)-1]
+}
+
+// ArchiveImport is everything an import writes, stored in a single transaction so that a
+// failure leaves nothing half restored
+type ArchiveImport struct {
+	SharedSchemas   []*SharedSchema
+	RetentionPolicy *RetentionPolicy
+	// Replaced configurations have everything stored under their name replaced by their
+	// archived history
+	Replaced []*ConfigurationArchive
+	// Merged configurations keep their history and get the archived state appended
+	Merged []*ConfigurationMerge
+}
+
+// ConfigurationMerge is what merging an archived configuration appends to what is
+// stored under its name. Nil fields are left unchanged.
+type ConfigurationMerge struct {
+	Name            string
+	Schema          json.RawMessage
+	RuleSet         *RuleSet
+	RetentionPolicy *RetentionPolicy
+	// Configuration is the new version of the data; version 1 creates the configuration
+	Configuration *Configuration
+}
+
+// ImportMode controls what happens to items of an archive that already exist
+type ImportMode string
+
+const (
+	// ImportModeSkipExisting imports only items that do not exist yet
+	ImportModeSkipExisting ImportMode = "skip_existing"
+	// ImportModeOverwrite replaces existing items, including their history, with the archived ones
+	ImportModeOverwrite ImportMode = "overwrite"
+	// ImportModeMerge keeps the history of existing items and appends the archived
+	// current data, schema and rules as new versions where they differ
+	ImportModeMerge ImportMode = "merge"
+)
+
+// IsValid reports whether the import mode is known
+func (m ImportMode) IsValid() bool {
+	switch m {
+	case ImportModeSkipExisting, ImportModeOverwrite, ImportModeMerge:
+		return true
+	}
+	return false
+}
+
+// ImportOptions controls how an archive is imported
+type ImportOptions struct {
+	Mode ImportMode
+	// DryRun reports what an import would do without changing anything
+	DryRun bool
+}
+
+// ImportItemKind names the kind of an imported item
+type ImportItemKind string
+
+const (
+	ImportItemSharedSchema    ImportItemKind = "shared_schema"
+	ImportItemRetentionPolicy ImportItemKind = "retention_policy"
+	ImportItemConfiguration   ImportItemKind = "configuration"
+)
+
+// ImportAction is what an import does with an item
+type ImportAction string
+
+const (
+	// ImportActionCreate stores an item that does not exist yet
+	ImportActionCreate ImportAction = "create"
+	// ImportActionOverwrite replaces an existing item
+	ImportActionOverwrite ImportAction = "overwrite"
+	// ImportActionMerge appends the archived state to an existing item
+	ImportActionMerge ImportAction = "merge"
+	// ImportActionSkip leaves an existing item that differs from the archive untouched
+	ImportActionSkip ImportAction = "skip"
+	// ImportActionUnchanged leaves an existing item that matches the archive untouched
+	ImportActionUnchanged ImportAction = "unchanged"
+)
+
+// ImportItem reports what an import does with one item of an archive. Conflict is set
+// when the item exists and its current state differs from the archived one.
+type ImportItem struct {
+	Kind     ImportItemKind           `json:"kind"`
+	Name     string                   `json:"name"`
+	Action   ImportAction             `json:"action"`
+	Conflict bool                     `json:"conflict,omitempty"`
+	Errors   []errors.ValidationError `json:"errors,omitempty"`
+}
+
+// ImportSummary counts the items of an import by outcome
+type ImportSummary struct {
+	Created     int `json:"created"`
+	Overwritten int `json:"overwritten"`
+	Merged      int `json:"merged"`
+	Skipped     int `json:"skipped"`
+	Unchanged   int `json:"unchanged"`
+	Conflicts   int `json:"conflicts"`
+	Invalid     int `json:"invalid"`
+}
+
+// ImportReport is the outcome of an import or of its dry run
+type ImportReport struct {
+	Mode    ImportMode    `json:"mode"`
+	DryRun  bool          `json:"dry_run"`
+	Applied bool          `json:"applied"`
+	Summary ImportSummary `json:"summary"`
+	Items   []ImportItem  `json:"items"`
+}
+
+// AddItem records an item and counts it in the summary
+func (r *ImportReport) AddItem(item ImportItem) {
+	r.Items = append(r.Items, item)
+	switch item.Action {
+	case ImportActionCreate:
+		r.Summary.Created++
+	case ImportActionOverwrite:
+		r.Summary.Overwritten++
+	case ImportActionMerge:
+		r.Summary.Merged++
+	case ImportActionSkip:
+		r.Summary.Skipped++
+	case ImportActionUnchanged:
+		r.Summary.Unchanged++
+	}
+	if item.Conflict {
+		r.Summary.Conflicts++
+	}
+	if len(item.Errors) > 0 {
+		r.Summary.Invalid++
+	}
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"encoding/json"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// MaxBatchOperations is the largest number of operations accepted in one batch
+const MaxBatchOperations = 100
+
+// MaxChangesetIDLength is the longest changeset ID accepted for a batch
+const MaxChangesetIDLength = 128
+
+// BatchOperationType is the kind of write a batch operation performs
+type BatchOperationType string
+
+const (
+	// BatchOperationCreate creates a new configuration
+	BatchOperationCreate BatchOperationType = "create"
+	// BatchOperationUpdate stores new data as the next version of a configuration
+	BatchOperationUpdate BatchOperationType = "update"
+	// BatchOperationRollback restores the data of a previous version as the next version
+	BatchOperationRollback BatchOperationType = "rollback"
+)
+
+// BatchOperation is one write of a batch. Data is required for create and update,
+// TargetVersion for rollback.
+type BatchOperation struct {
+	Op            BatchOperationType `json:"op"`
+	Name          string             `json:"name"`
+	Data          json.RawMessage    `json:"data,omitempty"`
+	TargetVersion int                `json:"target_version,omitempty"`
+}
+
+// BatchOperationResult is the outcome of one operation of a batch: the version it
+// wrote, or would have written, or why it failed
+type BatchOperationResult struct {
+	Index         int                   `json:"index"`
+	Op            BatchOperationType    `json:"op"`
+	Name          string                `json:"name"`
+	Configuration *Configuration        `json:"configuration,omitempty"`
+	Error         *errors.ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchResult is the outcome of a batch. Applied is false when any operation failed,
+// in which case nothing was written.
+type BatchResult struct {
+	ChangesetID string                 `json:"changeset_id,omitempty"`
+	Applied     bool                   `json:"applied"`
+	Results     []BatchOperationResult `json:"results"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "github.com/Titonu/configuration-management-service/pkg/errors"
+
+// MaxBulkReadNames is the largest number of configurations read in one request
+const MaxBulkReadNames = 100
+
+// ConfigurationRef names a configuration version to read; Version 0 means the current one
+type ConfigurationRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version,omitempty"`
+}
+
+// BulkReadItem is the outcome of reading one configuration of a bulk read: the
+// configuration, or why it could not be read
+type BulkReadItem struct {
+	ConfigurationRef
+	Configuration *Configuration        `json:"configuration,omitempty"`
+	Error         *errors.ErrorResponse `json:"error,omitempty"`
+}
@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/pkg/jsondiff"
+	"path"
+	"time"
+)
+
+// ChangeRequestStatus represents the lifecycle state of a change request
+type ChangeRequestStatus string
+
+// Change request statuses
+const (
+	ChangeRequestStatusPending   ChangeRequestStatus = "pending"
+	ChangeRequestStatusApplied   ChangeRequestStatus = "applied"
+	ChangeRequestStatusRejected  ChangeRequestStatus = "rejected"
+	ChangeRequestStatusWithdrawn ChangeRequestStatus = "withdrawn"
+	ChangeRequestStatusStale     ChangeRequestStatus = "stale"
+)
+
+// PreviousStatus returns the status a change request must be in to move to s. A change
+// request is applied, rejected, withdrawn or closed as stale only while it is pending.
+func (s ChangeRequestStatus) PreviousStatus() ChangeRequestStatus {
+	return ChangeRequestStatusPending
+}
+
+// ChangeRequest is a proposed configuration update that goes live once it
+// has collected the required number of approvals from clients other than its author
+type ChangeRequest struct {
+	ID                int64                   `json:"id"`
+	Name              string                  `json:"name"`
+	Data              json.RawMessage         `json:"data"`
+	BaseVersion       int                     `json:"base_version"`
+	Diff              []jsondiff.Change       `json:"diff"`
+	Author            string                  `json:"author"`
+	Status            ChangeRequestStatus     `json:"status"`
+	RequiredApprovals int                     `json:"required_approvals"`
+	Approvals         []ChangeRequestApproval `json:"approvals"`
+	Comments          []ChangeRequestComment  `json:"comments"`
+	AppliedVersion    int                     `json:"applied_version,omitempty"`
+	Reason            string                  `json:"reason,omitempty"`
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+}
+
+// ChangeRequestApproval records an approval of a change request
+type ChangeRequestApproval struct {
+	ClientID  string    `json:"client_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChangeRequestComment is a review comment on a change request
+type ChangeRequestComment struct {
+	ID        int64     `json:"id"`
+	ClientID  string    `json:"client_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewChangeRequest creates a new pending ChangeRequest against the given base version
+func NewChangeRequest(name string, data json.RawMessage, baseVersion int, diff []jsondiff.Change, author string, requiredApprovals int) *ChangeRequest {
+	now := time.Now().UTC()
+	return &ChangeRequest{
+		Name:              name,
+		Data:              data,
+		BaseVersion:       baseVersion,
+		Diff:              diff,
+		Author:            author,
+		Status:            ChangeRequestStatusPending,
+		RequiredApprovals: requiredApprovals,
+		Approvals:         []ChangeRequestApproval{},
+		Comments:          []ChangeRequestComment{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// IsPending reports whether the change request is still open for review
+func (cr *ChangeRequest) IsPending() bool {
+	return cr.Status == ChangeRequestStatusPending
+}
+
+// HasApprovalFrom reports whether a client has already approved the change request
+func (cr *ChangeRequest) HasApprovalFrom(clientID string) bool {
+	for _, approval := range cr.Approvals {
+		if approval.ClientID == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsApproved reports whether the change request has collected enough approvals
+func (cr *ChangeRequest) IsApproved() bool {
+	return len(cr.Approvals) >= cr.RequiredApprovals
+}
+
+// MarkApplied records that the change request went live as the given version
+func (cr *ChangeRequest) MarkApplied(version int) {
+	cr.Status = ChangeRequestStatusApplied
+	cr.AppliedVersion = version
+	cr.UpdatedAt = time.Now().UTC()
+}
+
+// MarkRejected records that a reviewer rejected the change request
+func (cr *ChangeRequest) MarkRejected(reason string) {
+	cr.Status = ChangeRequestStatusRejected
+	cr.Reason = reason
+	cr.UpdatedAt = time.Now().UTC()
+}
+
+// MarkWithdrawn records that the author withdrew the change request
+func (cr *ChangeRequest) MarkWithdrawn() {
+	cr.Status = ChangeRequestStatusWithdrawn
+	cr.UpdatedAt = time.Now().UTC()
+}
+
+// MarkStale records that the change request was approved after the configuration moved
+// past its base version, so it can no longer be applied
+func (cr *ChangeRequest) MarkStale(reason string) {
+	cr.Status = ChangeRequestStatusStale
+	cr.AppliedVersion = 0
+	cr.Reason = reason
+	cr.UpdatedAt = time.Now().UTC()
+}
+
+// ApprovalRule requires a number of approvals for configurations whose name matches Pattern
+type ApprovalRule struct {
+	Pattern           string
+	RequiredApprovals int
+}
+
+// ApprovalPolicy is an ordered list of approval rules; the first matching rule wins
+type ApprovalPolicy []ApprovalRule
+
+// RequiredApprovals returns the number of approvals required to change a configuration.
+// Zero means the configuration can be changed directly.
+func (p ApprovalPolicy) RequiredApprovals(name string) int {
+	for _, rule := range p {
+		if matched, err := path.Match(rule.Pattern, name); err == nil && matched {
+			return rule.RequiredApprovals
+		}
+	}
+	return 0
+}
@@ -16,13 +16,26 @@ type Configuration struct {
 	// Fields for rollback operations
 	RollbackFrom int `json:"rollback_from,omitempty"`
 	RollbackTo   int `json:"rollback_to,omitempty"`
+
+	// SchemaVersion is the schema version the data was validated against, if any
+	SchemaVersion int `json:"schema_version,omitempty"`
+
+	// DefaultedFields lists the JSON Pointers of properties filled from schema defaults
+	DefaultedFields []string `json:"defaulted_fields,omitempty"`
+
+	// ChangesetID identifies the batch that wrote the version, if any
+	ChangesetID string `json:"changeset_id,omitempty"`
 }
 
 // VersionInfo represents version metadata for listing versions
 type VersionInfo struct {
-	Version    int       `json:"version"`
-	CreatedAt  time.Time `json:"created_at"`
-	IsRollback bool      `json:"is_rollback,omitempty"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	IsRollback    bool      `json:"is_rollback,omitempty"`
+	Compacted     bool      `json:"compacted,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	SchemaVersion int       `json:"schema_version,omitempty"`
+	ChangesetID   string    `json:"changeset_id,omitempty"`
 }
 
 // VersionList represents the response for listing versions
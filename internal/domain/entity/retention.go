@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+// GlobalRetentionPolicyName is the policy name used for the service-wide default policy
+const GlobalRetentionPolicyName = "*"
+
+// RetentionPolicy describes which historical versions of a configuration are kept.
+// A version is kept if any rule keeps it; a zero value for a rule disables it.
+// The current version and tagged versions are always kept.
+type RetentionPolicy struct {
+	Name     string `json:"name"`
+	KeepLast int    `json:"keep_last"`
+	KeepDays int    `json:"keep_days"`
+}
+
+// CompactionResult describes the outcome of compacting a configuration's history
+type CompactionResult struct {
+	Name              string `json:"name"`
+	CompactedVersions []int  `json:"compacted_versions"`
+}
+
+// CompactionSummary describes the outcome of a compaction run across all configurations
+type CompactionSummary struct {
+	Configurations   []*CompactionResult `json:"configurations"`
+	MigratedPayloads int                 `json:"migrated_payloads"`
+	RemovedPayloads  int                 `json:"removed_payloads"`
+}
+
+// IsDisabled reports whether the policy keeps every version
+func (p *RetentionPolicy) IsDisabled() bool {
+	return p.KeepLast <= 0 && p.KeepDays <= 0
+}
+
+// VersionsToCompact returns the versions whose data may be removed under this policy
+func (p *RetentionPolicy) VersionsToCompact(versions []VersionInfo, currentVersion int, now time.Time) []int {
+	if p.IsDisabled() {
+		return nil
+	}
+
+	// Newest first, so the index is the number of newer versions
+	sorted := make([]VersionInfo, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version > sorted[j].Version
+	})
+
+	cutoff := now.AddDate(0, 0, -p.KeepDays)
+	result := []int{}
+	for i, v := range sorted {
+		if v.Compacted || v.Version == currentVersion || len(v.Tags) > 0 {
+			continue
+		}
+		if p.KeepLast > 0 && i < p.KeepLast {
+			continue
+		}
+		if p.KeepDays > 0 && v.CreatedAt.After(cutoff) {
+			continue
+		}
+		result = append(result, v.Version)
+	}
+
+	sort.Ints(result)
+	return result
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"time"
+)
+
+// RuleSet is one version of the custom validation rules attached to a configuration.
+// Rules run after JSON Schema validation and express constraints JSON Schema cannot,
+// such as relations between fields.
+type RuleSet struct {
+	Name      string       `json:"name"`
+	Version   int          `json:"version"`
+	Rules     []rules.Rule `json:"rules"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// RuleTestResult is the outcome of a dry run of validation rules against configuration data
+type RuleTestResult struct {
+	Valid  bool                     `json:"valid"`
+	Errors []errors.ValidationError `json:"errors,omitempty"`
+}
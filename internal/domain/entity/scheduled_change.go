@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScheduledChangeStatus represents the lifecycle state of a scheduled change
+type ScheduledChangeStatus string
+
+// Scheduled change statuses
+const (
+	ScheduledChangeStatusPending   ScheduledChangeStatus = "pending"
+	ScheduledChangeStatusApplying  ScheduledChangeStatus = "applying"
+	ScheduledChangeStatusApplied   ScheduledChangeStatus = "applied"
+	ScheduledChangeStatusCancelled ScheduledChangeStatus = "cancelled"
+	ScheduledChangeStatusFailed    ScheduledChangeStatus = "failed"
+)
+
+// PreviousStatus returns the status a scheduled change must be in to move to s. A pending
+// change is either cancelled or claimed for applying, and only a claimed change is marked
+// applied or failed.
+func (s ScheduledChangeStatus) PreviousStatus() ScheduledChangeStatus {
+	switch s {
+	case ScheduledChangeStatusApplied, ScheduledChangeStatusFailed:
+		return ScheduledChangeStatusApplying
+	default:
+		return ScheduledChangeStatusPending
+	}
+}
+
+// ScheduledChange represents a configuration update staged to go live at a future time
+type ScheduledChange struct {
+	ID             int64                 `json:"id"`
+	Name           string                `json:"name"`
+	Data           json.RawMessage       `json:"data"`
+	EffectiveAt    time.Time             `json:"effective_at"`
+	Status         ScheduledChangeStatus `json:"status"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	AppliedVersion int                   `json:"applied_version,omitempty"`
+	Error          string                `json:"error,omitempty"`
+}
+
+// NewScheduledChange creates a new pending ScheduledChange
+func NewScheduledChange(name string, data json.RawMessage, effectiveAt time.Time) *ScheduledChange {
+	now := time.Now().UTC()
+	return &ScheduledChange{
+		Name:        name,
+		Data:        data,
+		EffectiveAt: effectiveAt.UTC(),
+		Status:      ScheduledChangeStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// IsDue reports whether a pending change should be applied at the given time
+func (s *ScheduledChange) IsDue(now time.Time) bool {
+	return s.Status == ScheduledChangeStatusPending && !s.EffectiveAt.After(now)
+}
+
+// MarkApplying records that the change has been claimed for applying
+func (s *ScheduledChange) MarkApplying() {
+	s.Status = ScheduledChangeStatusApplying
+	s.UpdatedAt = time.Now().UTC()
+}
+
+// MarkApplied records that the change went live as the given version
+func (s *ScheduledChange) MarkApplied(version int) {
+	s.Status = ScheduledChangeStatusApplied
+	s.AppliedVersion = version
+	s.Error = ""
+	s.UpdatedAt = time.Now().UTC()
+}
+
+// MarkFailed records that the change could not be applied
+func (s *ScheduledChange) MarkFailed(reason string) {
+	s.Status = ScheduledChangeStatusFailed
+	s.Error = reason
+	s.UpdatedAt = time.Now().UTC()
+}
+
+// MarkCancelled records that the change was cancelled before going live
+func (s *ScheduledChange) MarkCancelled() {
+	s.Status = ScheduledChangeStatusCancelled
+	s.UpdatedAt = time.Now().UTC()
+}
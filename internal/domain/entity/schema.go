@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"time"
+)
+
+// SchemaCompatibility controls which schema changes are accepted when a new
+// schema version is registered for a configuration
+type SchemaCompatibility string
+
+const (
+	// SchemaCompatibilityNone accepts any valid schema
+	SchemaCompatibilityNone SchemaCompatibility = "none"
+	// SchemaCompatibilityBackward rejects schemas under which data accepted by
+	// the previous schema version could become invalid
+	SchemaCompatibilityBackward SchemaCompatibility = "backward"
+)
+
+// IsValid reports whether the compatibility mode is known
+func (m SchemaCompatibility) IsValid() bool {
+	switch m {
+	case SchemaCompatibilityNone, SchemaCompatibilityBackward:
+		return true
+	}
+	return false
+}
+
+// DefaultsModeKeyword is the schema annotation that opts a schema into default value
+// injection, e.g. {"x-apply-defaults": "write"}
+const DefaultsModeKeyword = "x-apply-defaults"
+
+// DefaultsMode controls when the default values declared by a schema are applied to
+// configuration data
+type DefaultsMode string
+
+const (
+	// DefaultsModeNone never applies defaults
+	DefaultsModeNone DefaultsMode = ""
+	// DefaultsModeWrite stores defaults in the configuration data when it is written
+	DefaultsModeWrite DefaultsMode = "write"
+	// DefaultsModeRead leaves stored data as written and applies defaults when it is read
+	DefaultsModeRead DefaultsMode = "read"
+)
+
+// IsValid reports whether the defaults mode is known
+func (m DefaultsMode) IsValid() bool {
+	switch m {
+	case DefaultsModeNone, DefaultsModeWrite, DefaultsModeRead:
+		return true
+	}
+	return false
+}
+
+// SchemaVersion is one registered revision of a configuration's JSON schema
+type SchemaVersion struct {
+	Name      string          `json:"name"`
+	Version   int             `json:"version"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// SchemaRef names a schema version of a configuration; Version 0 means the current schema
+type SchemaRef struct {
+	Name    string
+	Version int
+}
+
+// SchemaRegistrationOptions controls the checks run before a new schema version is accepted
+type SchemaRegistrationOptions struct {
+	// Force skips the compatibility and existing data checks
+	Force bool
+	// CheckVersions is how many of the most recent configuration versions must
+	// conform to the new schema; values below 1 check the current version only
+	CheckVersions int
+}
+
+// VersionValidation is the result of validating one configuration version against a schema
+type VersionValidation struct {
+	Version int                      `json:"version"`
+	Valid   bool                     `json:"valid"`
+	Errors  []errors.ValidationError `json:"errors,omitempty"`
+}
+
+// SchemaImpact reports how a proposed schema would affect a configuration
+type SchemaImpact struct {
+	Name                string                   `json:"name"`
+	Compatible          bool                     `json:"compatible"`
+	CompatibilityIssues []errors.ValidationError `json:"compatibility_issues,omitempty"`
+	Versions            []VersionValidation      `json:"versions"`
+}
+
+// InvalidVersions returns the checked versions that do not conform to the proposed schema
+func (i *SchemaImpact) InvalidVersions() []VersionValidation {
+	invalid := []VersionValidation{}
+	for _, v := range i.Versions {
+		if !v.Valid {
+			invalid = append(invalid, v)
+		}
+	}
+	return invalid
+}
+
+// SchemaList is a page of the current schemas of all configurations
+type SchemaList struct {
+	Schemas []*SchemaVersion `json:"schemas"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+// UnvalidatedReason explains why a configuration's current data is not covered by a schema
+type UnvalidatedReason string
+
+const (
+	// UnvalidatedReasonNoSchema means no schema is registered for the configuration
+	UnvalidatedReasonNoSchema UnvalidatedReason = "no_schema"
+	// UnvalidatedReasonNotValidated means a schema exists, but the current version
+	// was written before any schema validated it
+	UnvalidatedReasonNotValidated UnvalidatedReason = "not_validated"
+)
+
+// UnvalidatedConfiguration is a configuration whose current data is not validated by a schema
+type UnvalidatedConfiguration struct {
+	Name    string            `json:"name"`
+	Version int               `json:"version"`
+	Reason  UnvalidatedReason `json:"reason"`
+}
+
+// SharedSchema is a named schema fragment that configuration schemas and other shared
+// schemas reference with {"$ref": "shared://<name>"}
+type SharedSchema struct {
+	Name      string          `json:"name"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// DependentValidation is the result of validating the current data of a configuration
+// whose schema depends on a changed shared schema
+type DependentValidation struct {
+	Name string `json:"name"`
+	VersionValidation
+}
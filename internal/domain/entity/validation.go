@@ -0,0 +1,56 @@
+package entity
+
+import "github.com/Titonu/configuration-management-service/pkg/errors"
+
+// ValidationSource names the check that reported a validation issue
+type ValidationSource string
+
+const (
+	// ValidationSourceSyntax reports a document that is not well-formed JSON
+	ValidationSourceSyntax ValidationSource = "syntax"
+	// ValidationSourceSchema reports a JSON Schema violation
+	ValidationSourceSchema ValidationSource = "schema"
+	// ValidationSourceRule reports a failed validation rule
+	ValidationSourceRule ValidationSource = "rule"
+)
+
+// ValidationIssue is one problem found in a validated document. Line and Column locate
+// the value in the submitted document, or its parent when the value is missing.
+type ValidationIssue struct {
+	Source ValidationSource `json:"source"`
+	errors.ValidationError
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ValidationSummary counts what a validation run checked and found
+type ValidationSummary struct {
+	SchemaChecked bool `json:"schema_checked"`
+	RulesChecked  int  `json:"rules_checked"`
+	ErrorCount    int  `json:"error_count"`
+	SyntaxErrors  int  `json:"syntax_errors"`
+	SchemaErrors  int  `json:"schema_errors"`
+	RuleErrors    int  `json:"rule_errors"`
+}
+
+// ValidationReport is the result of validating a document without storing it
+type ValidationReport struct {
+	Valid   bool              `json:"valid"`
+	Summary ValidationSummary `json:"summary"`
+	Errors  []ValidationIssue `json:"errors"`
+}
+
+// AddIssue records an issue and counts it in the summary
+func (r *ValidationReport) AddIssue(issue ValidationIssue) {
+	r.Errors = append(r.Errors, issue)
+	r.Summary.ErrorCount++
+	switch issue.Source {
+	case ValidationSourceSyntax:
+		r.Summary.SyntaxErrors++
+	case ValidationSourceSchema:
+		r.Summary.SchemaErrors++
+	case ValidationSourceRule:
+		r.Summary.RuleErrors++
+	}
+	r.Valid = false
+}
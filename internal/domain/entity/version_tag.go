@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+)
+
+// versionTagPattern restricts tag names to characters that are safe in URL paths
+var versionTagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// VersionTag is a named label attached to a configuration version.
+// Immutable tags can never be moved or removed; movable tags can be
+// re-pointed at another version (e.g. "last-known-good").
+type VersionTag struct {
+	Name      string    `json:"name"`
+	Tag       string    `json:"tag"`
+	Version   int       `json:"version"`
+	Immutable bool      `json:"immutable"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewVersionTag creates a new tag pointing at a configuration version
+func NewVersionTag(name, tag string, version int, immutable bool) *VersionTag {
+	now := time.Now().UTC()
+	return &VersionTag{
+		Name:      name,
+		Tag:       tag,
+		Version:   version,
+		Immutable: immutable,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsValidTagName reports whether a tag name is acceptable
+func IsValidTagName(tag string) bool {
+	return versionTagPattern.MatchString(tag)
+}
+
+// MoveTo re-points a movable tag at another version
+func (t *VersionTag) MoveTo(version int, immutable bool) {
+	t.Version = version
+	t.Immutable = immutable
+	t.UpdatedAt = time.Now().UTC()
+}
@@ -1,36 +1,172 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"time"
 )
 
 // ConfigurationRepository defines the interface for configuration storage operations
 type ConfigurationRepository interface {
 	// CreateConfiguration creates a new configuration
-	CreateConfiguration(config *entity.Configuration) error
+	CreateConfiguration(ctx context.Context, config *entity.Configuration) error
 
 	// UpdateConfiguration updates an existing configuration
-	UpdateConfiguration(config *entity.Configuration) error
+	UpdateConfiguration(ctx context.Context, config *entity.Configuration) error
 
 	// GetConfiguration retrieves a configuration by name
-	GetConfiguration(name string) (*entity.Configuration, error)
+	GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error)
 
 	// GetConfigurationVersion retrieves a specific version of a configuration
-	GetConfigurationVersion(name string, version int) (*entity.Configuration, error)
+	GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error)
 
 	// ListConfigurationVersions lists all versions of a configuration
-	ListConfigurationVersions(name string) (*entity.VersionList, error)
+	ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error)
 
-	// RegisterSchema registers a JSON schema for a configuration
-	RegisterSchema(configName string, schema json.RawMessage) error
+	// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+	GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error)
 
-	// GetSchema retrieves the JSON schema for a configuration
-	GetSchema(configName string) (json.RawMessage, error)
+	// ListConfigurationsAsOf retrieves the effective version of every configuration at the given
+	// time; versions that were compacted are reported on their item
+	ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error)
+
+	// GetConfigurations reads many configuration versions in a single query; each item
+	// holds the configuration or why it could not be read, in the order of refs
+	GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error)
+
+	// RegisterSchema stores a JSON schema as the next schema version of a configuration
+	RegisterSchema(ctx context.Context, configName string, schema json.RawMessage) (*entity.SchemaVersion, error)
+
+	// GetSchema retrieves the current JSON schema for a configuration
+	GetSchema(ctx context.Context, configName string) (json.RawMessage, error)
+
+	// GetSchemas reads many schemas in a single query, keyed by their ref; refs without
+	// a schema are left out
+	GetSchemas(ctx context.Context, refs []entity.SchemaRef) (map[entity.SchemaRef]json.RawMessage, error)
+
+	// GetSchemaVersion retrieves a specific version of a configuration's schema
+	GetSchemaVersion(ctx context.Context, configName string, version int) (*entity.SchemaVersion, error)
+
+	// ListSchemaVersions lists the schema history of a configuration
+	ListSchemaVersions(ctx context.Context, configName string) ([]*entity.SchemaVersion, error)
+
+	// ListSchemas lists one page of current schemas and the total number of schemas
+	ListSchemas(ctx context.Context, limit, offset int) ([]*entity.SchemaVersion, int, error)
+
+	// DeleteSchema detaches the current schema from a configuration, keeping its history
+	DeleteSchema(ctx context.Context, configName string) error
+
+	// ListUnvalidatedConfigurations lists configurations whose current version is not validated by a schema
+	ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error)
+
+	// SetSharedSchema creates or replaces a shared schema fragment
+	SetSharedSchema(ctx context.Context, schema *entity.SharedSchema) error
+
+	// GetSharedSchema retrieves a shared schema fragment by name
+	GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error)
+
+	// ListSharedSchemas lists all shared schema fragments
+	ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error)
+
+	// DeleteSharedSchema removes a shared schema fragment
+	DeleteSharedSchema(ctx context.Context, name string) error
+
+	// CreateRuleSet stores validation rules as the next rule set version of a configuration
+	CreateRuleSet(ctx context.Context, configName string, ruleList []rules.Rule) (*entity.RuleSet, error)
+
+	// GetRuleSet retrieves the current rule set of a configuration
+	GetRuleSet(ctx context.Context, configName string) (*entity.RuleSet, error)
+
+	// GetRuleSetVersion retrieves a specific rule set version of a configuration
+	GetRuleSetVersion(ctx context.Context, configName string, version int) (*entity.RuleSet, error)
+
+	// ListRuleSetVersions lists the rule set history of a configuration
+	ListRuleSetVersions(ctx context.Context, configName string) ([]*entity.RuleSet, error)
 
 	// StoreVersionData stores the raw data for a specific version
-	StoreVersionData(configName string, version int, data json.RawMessage) error
+	StoreVersionData(ctx context.Context, configName string, version int, data json.RawMessage) error
 
 	// GetVersionData retrieves the raw data for a specific version
-	GetVersionData(configName string, version int) (json.RawMessage, error)
+	GetVersionData(ctx context.Context, configName string, version int) (json.RawMessage, error)
+
+	// CreateScheduledChange persists a new scheduled change and assigns its ID
+	CreateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error
+
+	// GetScheduledChange retrieves a scheduled change by ID
+	GetScheduledChange(ctx context.Context, id int64) (*entity.ScheduledChange, error)
+
+	// ListScheduledChanges lists all scheduled changes for a configuration
+	ListScheduledChanges(ctx context.Context, configName string) ([]*entity.ScheduledChange, error)
+
+	// ListDueScheduledChanges lists pending scheduled changes effective at or before the given time
+	ListDueScheduledChanges(ctx context.Context, before time.Time) ([]*entity.ScheduledChange, error)
+
+	// ListStaleScheduledChanges lists scheduled changes claimed for applying before the
+	// given time and never marked applied or failed
+	ListStaleScheduledChanges(ctx context.Context, claimedBefore time.Time) ([]*entity.ScheduledChange, error)
+
+	// UpdateScheduledChange updates the status of a scheduled change
+	UpdateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error
+
+	// GetRetentionPolicy retrieves the retention policy stored under a name
+	GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error)
+
+	// SetRetentionPolicy creates or replaces a retention policy
+	SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) error
+
+	// DeleteRetentionPolicy removes a retention policy
+	DeleteRetentionPolicy(ctx context.Context, name string) error
+
+	// ListConfigurationNames lists the names of all configurations
+	ListConfigurationNames(ctx context.Context) ([]string, error)
+
+	// CompactVersions removes the data of the given versions, keeping their metadata
+	CompactVersions(ctx context.Context, name string, versions []int) error
+
+	// DeduplicateVersionData migrates inline payloads to content-addressed storage
+	// and removes unreferenced payloads, returning the migrated and removed counts
+	DeduplicateVersionData(ctx context.Context) (int, int, error)
+
+	// SetVersionTag creates or replaces a version tag
+	SetVersionTag(ctx context.Context, tag *entity.VersionTag) error
+
+	// GetVersionTag retrieves a version tag of a configuration
+	GetVersionTag(ctx context.Context, name, tag string) (*entity.VersionTag, error)
+
+	// ListVersionTags lists the tags of a configuration
+	ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error)
+
+	// DeleteVersionTag removes a version tag
+	DeleteVersionTag(ctx context.Context, name, tag string) error
+
+	// CreateChangeRequest persists a new change request and assigns its ID
+	CreateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error
+
+	// GetChangeRequest retrieves a change request with its approvals and comments
+	GetChangeRequest(ctx context.Context, id int64) (*entity.ChangeRequest, error)
+
+	// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+	ListChangeRequests(ctx context.Context, configName string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error)
+
+	// UpdateChangeRequest persists the status of a change request
+	UpdateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error
+
+	// AddChangeRequestApproval records an approval of a change request
+	AddChangeRequestApproval(ctx context.Context, id int64, approval entity.ChangeRequestApproval) error
+
+	// AddChangeRequestComment adds a comment to a change request
+	AddChangeRequestComment(ctx context.Context, id int64, comment *entity.ChangeRequestComment) error
+
+	// ApplyChangeRequest atomically applies an approved change request if its base version is still current
+	ApplyChangeRequest(ctx context.Context, cr *entity.ChangeRequest, config *entity.Configuration) error
+
+	// ImportArchive stores the shared schemas, retention policy and configurations of an
+	// import in a single transaction
+	ImportArchive(ctx context.Context, archive *entity.ArchiveImport) error
+
+	// ApplyBatch stores new configuration versions in a single transaction, or none of them
+	// if any configuration changed since the versions were prepared
+	ApplyBatch(ctx context.Context, configs []*entity.Configuration) error
 }
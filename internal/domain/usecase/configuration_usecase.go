@@ -1,36 +1,176 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"time"
 )
 
 // ConfigurationUsecase defines the interface for configuration business logic
 type ConfigurationUsecase interface {
 	// CreateConfiguration creates a new configuration
-	CreateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error)
+	CreateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error)
 
 	// UpdateConfiguration updates an existing configuration
-	UpdateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error)
+	UpdateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error)
 
 	// GetConfiguration retrieves a configuration by name
-	GetConfiguration(name string) (*entity.Configuration, error)
+	GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error)
 
 	// GetConfigurationVersion retrieves a specific version of a configuration
-	GetConfigurationVersion(name string, version int) (*entity.Configuration, error)
+	GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error)
 
 	// ListConfigurationVersions lists all versions of a configuration
-	ListConfigurationVersions(name string) (*entity.VersionList, error)
+	ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error)
+
+	// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+	GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error)
+
+	// ListConfigurationsAsOf retrieves the effective version of every configuration at the given
+	// time; versions that were compacted are reported on their item
+	ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error)
+
+	// GetConfigurations reads the current or a given version of many configurations at once,
+	// reporting configurations that cannot be read per item
+	GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error)
 
 	// RollbackConfiguration rolls back a configuration to a previous version
-	RollbackConfiguration(name string, targetVersion int) (*entity.Configuration, error)
+	RollbackConfiguration(ctx context.Context, name string, targetVersion int) (*entity.Configuration, error)
+
+	// RegisterSchema registers a JSON schema as the next schema version of a configuration
+	// after checking its compatibility and that existing configuration data conforms to it
+	RegisterSchema(ctx context.Context, configName string, schema json.RawMessage, opts entity.SchemaRegistrationOptions) (*entity.SchemaVersion, error)
+
+	// AnalyzeSchemaImpact reports how a proposed schema would affect a configuration without registering it
+	AnalyzeSchemaImpact(ctx context.Context, configName string, schema json.RawMessage, checkVersions int) (*entity.SchemaImpact, error)
+
+	// GetSchema retrieves the current JSON schema for a configuration
+	GetSchema(ctx context.Context, configName string) (json.RawMessage, error)
+
+	// GetSchemaVersion retrieves a specific version of a configuration's schema
+	GetSchemaVersion(ctx context.Context, configName string, version int) (*entity.SchemaVersion, error)
+
+	// ListSchemaVersions lists the schema history of a configuration
+	ListSchemaVersions(ctx context.Context, configName string) ([]*entity.SchemaVersion, error)
+
+	// ListSchemas lists one page of the current schemas of all configurations
+	ListSchemas(ctx context.Context, limit, offset int) (*entity.SchemaList, error)
+
+	// DeleteSchema detaches the schema from a configuration
+	DeleteSchema(ctx context.Context, configName string) error
+
+	// ListUnvalidatedConfigurations lists configurations whose current data is not validated by a schema
+	ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error)
 
-	// RegisterSchema registers a JSON schema for a configuration
-	RegisterSchema(configName string, schema json.RawMessage) error
+	// RegisterSharedSchema creates or replaces a shared schema fragment, re-validating dependent configurations unless forced
+	RegisterSharedSchema(ctx context.Context, name string, schema json.RawMessage, force bool) (*entity.SharedSchema, error)
 
-	// GetSchema retrieves the JSON schema for a configuration
-	GetSchema(configName string) (json.RawMessage, error)
+	// GetSharedSchema retrieves a shared schema fragment
+	GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error)
+
+	// ListSharedSchemas lists all shared schema fragments
+	ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error)
+
+	// DeleteSharedSchema removes a shared schema fragment that no schema references
+	DeleteSharedSchema(ctx context.Context, name string) error
+
+	// SetRuleSet stores validation rules as the next rule set version of a configuration
+	SetRuleSet(ctx context.Context, configName string, ruleList []rules.Rule, force bool) (*entity.RuleSet, error)
+
+	// GetRuleSet retrieves the current rule set of a configuration
+	GetRuleSet(ctx context.Context, configName string) (*entity.RuleSet, error)
+
+	// GetRuleSetVersion retrieves a specific rule set version of a configuration
+	GetRuleSetVersion(ctx context.Context, configName string, version int) (*entity.RuleSet, error)
+
+	// ListRuleSetVersions lists the rule set history of a configuration
+	ListRuleSetVersions(ctx context.Context, configName string) ([]*entity.RuleSet, error)
+
+	// TestRules evaluates validation rules against configuration data without storing anything
+	TestRules(ctx context.Context, configName string, ruleList []rules.Rule, data json.RawMessage) (*entity.RuleTestResult, error)
 
 	// ValidateConfigurationData validates configuration data against its schema
-	ValidateConfigurationData(configName string, data json.RawMessage) error
+	ValidateConfigurationData(ctx context.Context, configName string, data json.RawMessage) error
+
+	// ValidateDocument reports every schema and rule violation in configuration data without storing anything
+	ValidateDocument(ctx context.Context, configName string, data json.RawMessage) (*entity.ValidationReport, error)
+
+	// ValidateAgainstSchema reports every violation of an ad-hoc schema in a document without storing anything
+	ValidateAgainstSchema(ctx context.Context, schema json.RawMessage, data json.RawMessage) (*entity.ValidationReport, error)
+
+	// ScheduleConfigurationChange stages a configuration update to go live at a future time
+	ScheduleConfigurationChange(ctx context.Context, name string, data json.RawMessage, effectiveAt time.Time) (*entity.ScheduledChange, error)
+
+	// ListScheduledChanges lists the scheduled changes of a configuration
+	ListScheduledChanges(ctx context.Context, name string) ([]*entity.ScheduledChange, error)
+
+	// CancelScheduledChange cancels a pending scheduled change
+	CancelScheduledChange(ctx context.Context, name string, id int64) (*entity.ScheduledChange, error)
+
+	// ApplyDueScheduledChanges applies all pending scheduled changes that are due at the given time
+	ApplyDueScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error)
+
+	// GetRetentionPolicy retrieves a retention policy by configuration name or the global policy name
+	GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error)
+
+	// SetRetentionPolicy creates or replaces a retention policy
+	SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) (*entity.RetentionPolicy, error)
+
+	// DeleteRetentionPolicy removes a retention policy
+	DeleteRetentionPolicy(ctx context.Context, name string) error
+
+	// CompactConfiguration enforces the effective retention policy on a configuration
+	CompactConfiguration(ctx context.Context, name string, now time.Time) (*entity.CompactionResult, error)
+
+	// CompactAll enforces retention policies on every configuration and deduplicates stored payloads
+	CompactAll(ctx context.Context, now time.Time) (*entity.CompactionSummary, error)
+
+	// TagVersion attaches a tag to a configuration version, moving it if it is movable
+	TagVersion(ctx context.Context, name, tag string, version int, immutable bool) (*entity.VersionTag, error)
+
+	// ListVersionTags lists the tags of a configuration
+	ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error)
+
+	// DeleteVersionTag removes a movable tag
+	DeleteVersionTag(ctx context.Context, name, tag string) error
+
+	// GetConfigurationByTag retrieves the configuration version a tag points at
+	GetConfigurationByTag(ctx context.Context, name, tag string) (*entity.Configuration, error)
+
+	// RollbackConfigurationToTag rolls back a configuration to the version a tag points at
+	RollbackConfigurationToTag(ctx context.Context, name, tag string) (*entity.Configuration, error)
+
+	// CreateChangeRequest proposes a configuration update that goes live once approved
+	CreateChangeRequest(ctx context.Context, name string, data json.RawMessage, author string) (*entity.ChangeRequest, error)
+
+	// GetChangeRequest retrieves a change request of a configuration
+	GetChangeRequest(ctx context.Context, name string, id int64) (*entity.ChangeRequest, error)
+
+	// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+	ListChangeRequests(ctx context.Context, name string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error)
+
+	// ApproveChangeRequest approves a change request, applying it once enough approvals are collected
+	ApproveChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error)
+
+	// RejectChangeRequest closes a pending change request without applying it
+	RejectChangeRequest(ctx context.Context, name string, id int64, clientID string, reason string) (*entity.ChangeRequest, error)
+
+	// WithdrawChangeRequest lets the author close a pending change request
+	WithdrawChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error)
+
+	// CommentOnChangeRequest adds a review comment to a change request
+	CommentOnChangeRequest(ctx context.Context, name string, id int64, clientID string, body string) (*entity.ChangeRequestComment, error)
+
+	// ExportArchive emits every configuration with its full history, the shared schemas
+	// and the global retention policy as archive records
+	ExportArchive(ctx context.Context, emit func(*entity.ArchiveRecord) error) error
+
+	// ImportArchive restores the records of an archive, or reports what it would change on a dry run
+	ImportArchive(ctx context.Context, records []*entity.ArchiveRecord, opts entity.ImportOptions) (*entity.ImportReport, error)
+
+	// ApplyBatch validates create, update and rollback operations across configurations and
+	// applies them in a single transaction, or none of them if any operation fails
+	ApplyBatch(ctx context.Context, changesetID string, operations []entity.BatchOperation) (*entity.BatchResult, error)
 }
@@ -0,0 +1,123 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP traffic, repository
+// queries, validation failures, configuration versions, database pool statistics and
+// authentication failures.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric of the service
+const namespace = "config_service"
+
+// Authentication failure reasons
+const (
+	AuthFailureMissingKey    = "missing_key"
+	AuthFailureInvalidFormat = "invalid_format"
+	AuthFailureInvalidKey    = "invalid_key"
+)
+
+// Metrics holds the collectors of the service in their own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests       *prometheus.CounterVec
+	httpDuration       *prometheus.HistogramVec
+	validationFailures *prometheus.CounterVec
+	queryDuration      *prometheus.HistogramVec
+	queryErrors        *prometheus.CounterVec
+	authFailures       *prometheus.CounterVec
+}
+
+// New creates the metrics of the service, along with the Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		validationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Number of documents rejected by the schema of a configuration, by configuration.",
+		}, []string{"config"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Latency of repository operations by method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "repository_query_errors_total",
+			Help:      "Number of repository operations that failed with a storage error, by method.",
+		}, []string{"method"}),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failures_total",
+			Help:      "Number of requests rejected by authentication, by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.validationFailures,
+		m.queryDuration,
+		m.queryErrors,
+		m.authFailures,
+	)
+	return m
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the connection pool statistics of a database
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTPRequest records a served HTTP request
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveAuthFailure records a request rejected by authentication
+func (m *Metrics) ObserveAuthFailure(reason string) {
+	m.authFailures.WithLabelValues(reason).Inc()
+}
+
+// observeValidationFailure records a document rejected by the schema of a configuration
+func (m *Metrics) observeValidationFailure(config string) {
+	m.validationFailures.WithLabelValues(config).Inc()
+}
+
+// observeQuery records a repository operation and whether it failed
+func (m *Metrics) observeQuery(method string, duration time.Duration, failed bool) {
+	m.queryDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if failed {
+		m.queryErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	"github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape returns the metrics in the Prometheus text format
+func scrape(t *testing.T, m *Metrics) string {
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// newRepository creates a repository on a temporary database
+func newRepository(t *testing.T) repository.ConfigurationRepository {
+	repo, err := sqlite.NewConfigurationRepository(filepath.Join(t.TempDir(), "config.db"))
+	require.NoError(t, err)
+	return repo
+}
+
+// failingValidator rejects every document as not conforming
+type failingValidator struct{}
+
+func (failingValidator) ValidateJSON(ctx context.Context, schema json.RawMessage, data json.RawMessage) error {
+	return errors.NewAppError("Validation failed", errors.ErrorCodeValidationFailed, []errors.ValidationError{{Field: "max_limit", Reason: "too large"}})
+}
+
+func (failingValidator) ValidateSchemaDefinition(ctx context.Context, schema json.RawMessage) error {
+	return nil
+}
+
+func TestHTTPAndAuthMetrics(t *testing.T) {
+	m := New()
+	m.ObserveHTTPRequest("GET", "/api/v1/configurations/:name", 200, 20*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/configurations/:name", 200, 30*time.Millisecond)
+	m.ObserveAuthFailure(AuthFailureInvalidKey)
+
+	output := scrape(t, m)
+	assert.Contains(t, output, `config_service_http_requests_total{method="GET",route="/api/v1/configurations/:name",status="200"} 2`)
+	assert.Contains(t, output, `config_service_http_request_duration_seconds_count{method="GET",route="/api/v1/configurations/:name",status="200"} 2`)
+	assert.Contains(t, output, `config_service_auth_failures_total{reason="invalid_key"} 1`)
+	assert.Contains(t, output, "go_goroutines")
+}
+
+func TestRepository(t *testing.T) {
+	m := New()
+	repo := NewRepository(newRepository(t), m)
+
+	require.NoError(t, repo.CreateConfiguration(context.Background(), entity.NewConfiguration("payments", json.RawMessage(`{"max_limit": 1000}`))))
+
+	// A configuration that does not exist is an expected outcome, not a failed query
+	_, err := repo.GetConfiguration(context.Background(), "missing")
+	require.Error(t, err)
+	_, err = repo.GetConfiguration(context.Background(), "missing")
+	require.Error(t, err)
+
+	output := scrape(t, m)
+	assert.Contains(t, output, `config_service_repository_query_duration_seconds_count{method="CreateConfiguration"} 1`)
+	assert.Contains(t, output, `config_service_repository_query_duration_seconds_count{method="GetConfiguration"} 2`)
+	assert.NotContains(t, output, `config_service_repository_query_errors_total{method="GetConfiguration"}`)
+}
+
+func TestRepositoryCountsStorageErrors(t *testing.T) {
+	m := New()
+	inner := newRepository(t)
+	repo := NewRepository(inner, m)
+	require.NoError(t, inner.(interface{ Close() error }).Close())
+
+	_, err := repo.ListConfigurationNames(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, scrape(t, m), `config_service_repository_query_errors_total{method="ListConfigurationNames"} 1`)
+}
+
+func TestRepositoryIgnoresCancelledRequests(t *testing.T) {
+	m := New()
+	repo := NewRepository(newRepository(t), m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := repo.ListConfigurationNames(ctx)
+	require.Error(t, err)
+
+	output := scrape(t, m)
+	assert.Contains(t, output, `config_service_repository_query_duration_seconds_count{method="ListConfigurationNames"} 1`)
+	assert.NotContains(t, output, `config_service_repository_query_errors_total{method="ListConfigurationNames"}`)
+}
+
+func TestValidator(t *testing.T) {
+	m := New()
+	cached := NewValidator(validator.NewJSONSchemaValidator(), m)
+	schema := json.RawMessage(`{"type": "object", "properties": {"max_limit": {"maximum": 5000}}}`)
+
+	require.NoError(t, cached.ValidateConfiguration(context.Background(), "payments", schema, json.RawMessage(`{"max_limit": 1000}`)))
+	require.Error(t, cached.ValidateConfiguration(context.Background(), "payments", schema, json.RawMessage(`{"max_limit": 9000}`)))
+	require.Error(t, cached.ValidateConfiguration(context.Background(), "payments", schema, json.RawMessage(`{"max_limit": 9000}`)))
+
+	// Validators that do not cache schemas are used through ValidateJSON
+	plain := NewValidator(failingValidator{}, m)
+	require.Error(t, plain.ValidateConfiguration(context.Background(), "routing", schema, json.RawMessage(`{}`)))
+
+	output := scrape(t, m)
+	assert.Contains(t, output, `config_service_validation_failures_total{config="payments"} 2`)
+	assert.Contains(t, output, `config_service_validation_failures_total{config="routing"} 1`)
+}
+
+func TestInstrumentedUsecase(t *testing.T) {
+	m := New()
+	repo := newRepository(t)
+	m.RegisterVersions(repo)
+	m.RegisterDB(repo.(interface{ DB() *sql.DB }).DB(), "sqlite")
+	configService := usecase.NewConfigurationUseCase(
+		NewRepository(repo, m),
+		usecase.WithValidatorWrapper(func(v validator.Validator) validator.Validator { return NewValidator(v, m) }),
+	)
+
+	_, err := configService.CreateConfiguration(context.Background(), "payments", json.RawMessage(`{"max_limit": 1000}`))
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration(context.Background(), "payments", json.RawMessage(`{"max_limit": 2000}`))
+	require.NoError(t, err)
+	_, err = configService.RegisterSchema(context.Background(), "payments", json.RawMessage(`{"type": "object", "properties": {"max_limit": {"maximum": 5000}}}`), entity.SchemaRegistrationOptions{})
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration(context.Background(), "payments", json.RawMessage(`{"max_limit": 9000}`))
+	require.Error(t, err)
+
+	output := scrape(t, m)
+	assert.Contains(t, output, `config_service_configuration_versions{config="payments"} 2`)
+	assert.Contains(t, output, `config_service_validation_failures_total{config="payments"} 1`)
+	assert.Contains(t, output, `config_service_repository_query_duration_seconds_count{method="StoreVersionData"} 2`)
+	assert.Contains(t, output, `go_sql_open_connections{db_name="sqlite"}`)
+}
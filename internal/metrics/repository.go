@@ -0,0 +1,344 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+)
+
+// Repository is a configuration repository that records the latency of every operation
+// and the operations that failed. Errors the repository reports as API errors, such as
+// a configuration that does not exist, are expected outcomes rather than failures.
+type Repository struct {
+	repo    repository.ConfigurationRepository
+	metrics *Metrics
+}
+
+// NewRepository instruments a configuration repository
+func NewRepository(repo repository.ConfigurationRepository, m *Metrics) repository.ConfigurationRepository {
+	return &Repository{repo: repo, metrics: m}
+}
+
+// observe records an operation that started at start and returned *err. Operations
+// aborted because their request was cancelled or timed out are not failures either.
+func (r *Repository) observe(ctx context.Context, method string, start time.Time, err *error) {
+	var appErr *errors.AppError
+	failed := *err != nil && !stdErrors.As(*err, &appErr) && ctx.Err() == nil
+	r.metrics.observeQuery(method, time.Since(start), failed)
+}
+
+// CreateConfiguration creates a new configuration
+func (r *Repository) CreateConfiguration(ctx context.Context, config *entity.Configuration) (err error) {
+	defer r.observe(ctx, "CreateConfiguration", time.Now(), &err)
+	return r.repo.CreateConfiguration(ctx, config)
+}
+
+// UpdateConfiguration updates an existing configuration
+func (r *Repository) UpdateConfiguration(ctx context.Context, config *entity.Configuration) (err error) {
+	defer r.observe(ctx, "UpdateConfiguration", time.Now(), &err)
+	return r.repo.UpdateConfiguration(ctx, config)
+}
+
+// GetConfiguration retrieves a configuration by name
+func (r *Repository) GetConfiguration(ctx context.Context, name string) (result *entity.Configuration, err error) {
+	defer r.observe(ctx, "GetConfiguration", time.Now(), &err)
+	return r.repo.GetConfiguration(ctx, name)
+}
+
+// GetConfigurationVersion retrieves a specific version of a configuration
+func (r *Repository) GetConfigurationVersion(ctx context.Context, name string, version int) (result *entity.Configuration, err error) {
+	defer r.observe(ctx, "GetConfigurationVersion", time.Now(), &err)
+	return r.repo.GetConfigurationVersion(ctx, name, version)
+}
+
+// ListConfigurationVersions lists all versions of a configuration
+func (r *Repository) ListConfigurationVersions(ctx context.Context, name string) (result *entity.VersionList, err error) {
+	defer r.observe(ctx, "ListConfigurationVersions", time.Now(), &err)
+	return r.repo.ListConfigurationVersions(ctx, name)
+}
+
+// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+func (r *Repository) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (result *entity.Configuration, err error) {
+	defer r.observe(ctx, "GetConfigurationAsOf", time.Now(), &err)
+	return r.repo.GetConfigurationAsOf(ctx, name, asOf)
+}
+
+// ListConfigurationsAsOf retrieves the effective version of every configuration at the given time
+func (r *Repository) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) (result []entity.BulkReadItem, err error) {
+	defer r.observe(ctx, "ListConfigurationsAsOf", time.Now(), &err)
+	return r.repo.ListConfigurationsAsOf(ctx, asOf)
+}
+
+// GetConfigurations reads many configuration versions in a single query; each item
+// holds the configuration or why it could not be read, in the order of refs
+func (r *Repository) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) (result []entity.BulkReadItem, err error) {
+	defer r.observe(ctx, "GetConfigurations", time.Now(), &err)
+	return r.repo.GetConfigurations(ctx, refs)
+}
+
+// RegisterSchema stores a JSON schema as the next schema version of a configuration
+func (r *Repository) RegisterSchema(ctx context.Context, configName string, schema json.RawMessage) (result *entity.SchemaVersion, err error) {
+	defer r.observe(ctx, "RegisterSchema", time.Now(), &err)
+	return r.repo.RegisterSchema(ctx, configName, schema)
+}
+
+// GetSchema retrieves the current JSON schema for a configuration
+func (r *Repository) GetSchema(ctx context.Context, configName string) (result json.RawMessage, err error) {
+	defer r.observe(ctx, "GetSchema", time.Now(), &err)
+	return r.repo.GetSchema(ctx, configName)
+}
+
+// GetSchemas reads many schemas in a single query
+func (r *Repository) GetSchemas(ctx context.Context, refs []entity.SchemaRef) (result map[entity.SchemaRef]json.RawMessage, err error) {
+	defer r.observe(ctx, "GetSchemas", time.Now(), &err)
+	return r.repo.GetSchemas(ctx, refs)
+}
+
+// GetSchemaVersion retrieves a specific version of a configuration's schema
+func (r *Repository) GetSchemaVersion(ctx context.Context, configName string, version int) (result *entity.SchemaVersion, err error) {
+	defer r.observe(ctx, "GetSchemaVersion", time.Now(), &err)
+	return r.repo.GetSchemaVersion(ctx, configName, version)
+}
+
+// ListSchemaVersions lists the schema history of a configuration
+func (r *Repository) ListSchemaVersions(ctx context.Context, configName string) (result []*entity.SchemaVersion, err error) {
+	defer r.observe(ctx, "ListSchemaVersions", time.Now(), &err)
+	return r.repo.ListSchemaVersions(ctx, configName)
+}
+
+// ListSchemas lists one page of current schemas and the total number of schemas
+func (r *Repository) ListSchemas(ctx context.Context, limit, offset int) (schemas []*entity.SchemaVersion, total int, err error) {
+	defer r.observe(ctx, "ListSchemas", time.Now(), &err)
+	return r.repo.ListSchemas(ctx, limit, offset)
+}
+
+// DeleteSchema detaches the current schema from a configuration, keeping its history
+func (r *Repository) DeleteSchema(ctx context.Context, configName string) (err error) {
+	defer r.observe(ctx, "DeleteSchema", time.Now(), &err)
+	return r.repo.DeleteSchema(ctx, configName)
+}
+
+// ListUnvalidatedConfigurations lists configurations whose current version is not validated by a schema
+func (r *Repository) ListUnvalidatedConfigurations(ctx context.Context) (result []*entity.UnvalidatedConfiguration, err error) {
+	defer r.observe(ctx, "ListUnvalidatedConfigurations", time.Now(), &err)
+	return r.repo.ListUnvalidatedConfigurations(ctx)
+}
+
+// SetSharedSchema creates or replaces a shared schema fragment
+func (r *Repository) SetSharedSchema(ctx context.Context, schema *entity.SharedSchema) (err error) {
+	defer r.observe(ctx, "SetSharedSchema", time.Now(), &err)
+	return r.repo.SetSharedSchema(ctx, schema)
+}
+
+// GetSharedSchema retrieves a shared schema fragment by name
+func (r *Repository) GetSharedSchema(ctx context.Context, name string) (result *entity.SharedSchema, err error) {
+	defer r.observe(ctx, "GetSharedSchema", time.Now(), &err)
+	return r.repo.GetSharedSchema(ctx, name)
+}
+
+// ListSharedSchemas lists all shared schema fragments
+func (r *Repository) ListSharedSchemas(ctx context.Context) (result []*entity.SharedSchema, err error) {
+	defer r.observe(ctx, "ListSharedSchemas", time.Now(), &err)
+	return r.repo.ListSharedSchemas(ctx)
+}
+
+// DeleteSharedSchema removes a shared schema fragment
+func (r *Repository) DeleteSharedSchema(ctx context.Context, name string) (err error) {
+	defer r.observe(ctx, "DeleteSharedSchema", time.Now(), &err)
+	return r.repo.DeleteSharedSchema(ctx, name)
+}
+
+// CreateRuleSet stores validation rules as the next rule set version of a configuration
+func (r *Repository) CreateRuleSet(ctx context.Context, configName string, ruleList []rules.Rule) (result *entity.RuleSet, err error) {
+	defer r.observe(ctx, "CreateRuleSet", time.Now(), &err)
+	return r.repo.CreateRuleSet(ctx, configName, ruleList)
+}
+
+// GetRuleSet retrieves the current rule set of a configuration
+func (r *Repository) GetRuleSet(ctx context.Context, configName string) (result *entity.RuleSet, err error) {
+	defer r.observe(ctx, "GetRuleSet", time.Now(), &err)
+	return r.repo.GetRuleSet(ctx, configName)
+}
+
+// GetRuleSetVersion retrieves a specific rule set version of a configuration
+func (r *Repository) GetRuleSetVersion(ctx context.Context, configName string, version int) (result *entity.RuleSet, err error) {
+	defer r.observe(ctx, "GetRuleSetVersion", time.Now(), &err)
+	return r.repo.GetRuleSetVersion(ctx, configName, version)
+}
+
+// ListRuleSetVersions lists the rule set history of a configuration
+func (r *Repository) ListRuleSetVersions(ctx context.Context, configName string) (result []*entity.RuleSet, err error) {
+	defer r.observe(ctx, "ListRuleSetVersions", time.Now(), &err)
+	return r.repo.ListRuleSetVersions(ctx, configName)
+}
+
+// StoreVersionData stores the raw data for a specific version
+func (r *Repository) StoreVersionData(ctx context.Context, configName string, version int, data json.RawMessage) (err error) {
+	defer r.observe(ctx, "StoreVersionData", time.Now(), &err)
+	return r.repo.StoreVersionData(ctx, configName, version, data)
+}
+
+// GetVersionData retrieves the raw data for a specific version
+func (r *Repository) GetVersionData(ctx context.Context, configName string, version int) (result json.RawMessage, err error) {
+	defer r.observe(ctx, "GetVersionData", time.Now(), &err)
+	return r.repo.GetVersionData(ctx, configName, version)
+}
+
+// CreateScheduledChange persists a new scheduled change and assigns its ID
+func (r *Repository) CreateScheduledChange(ctx context.Context, change *entity.ScheduledChange) (err error) {
+	defer r.observe(ctx, "CreateScheduledChange", time.Now(), &err)
+	return r.repo.CreateScheduledChange(ctx, change)
+}
+
+// GetScheduledChange retrieves a scheduled change by ID
+func (r *Repository) GetScheduledChange(ctx context.Context, id int64) (result *entity.ScheduledChange, err error) {
+	defer r.observe(ctx, "GetScheduledChange", time.Now(), &err)
+	return r.repo.GetScheduledChange(ctx, id)
+}
+
+// ListScheduledChanges lists all scheduled changes for a configuration
+func (r *Repository) ListScheduledChanges(ctx context.Context, configName string) (result []*entity.ScheduledChange, err error) {
+	defer r.observe(ctx, "ListScheduledChanges", time.Now(), &err)
+	return r.repo.ListScheduledChanges(ctx, configName)
+}
+
+// ListDueScheduledChanges lists pending scheduled changes effective at or before the given time
+func (r *Repository) ListDueScheduledChanges(ctx context.Context, before time.Time) (result []*entity.ScheduledChange, err error) {
+	defer r.observe(ctx, "ListDueScheduledChanges", time.Now(), &err)
+	return r.repo.ListDueScheduledChanges(ctx, before)
+}
+
+// ListStaleScheduledChanges lists scheduled changes claimed for applying before the given
+// time and never marked applied or failed
+func (r *Repository) ListStaleScheduledChanges(ctx context.Context, claimedBefore time.Time) (result []*entity.ScheduledChange, err error) {
+	defer r.observe(ctx, "ListStaleScheduledChanges", time.Now(), &err)
+	return r.repo.ListStaleScheduledChanges(ctx, claimedBefore)
+}
+
+// UpdateScheduledChange updates the status of a scheduled change
+func (r *Repository) UpdateScheduledChange(ctx context.Context, change *entity.ScheduledChange) (err error) {
+	defer r.observe(ctx, "UpdateScheduledChange", time.Now(), &err)
+	return r.repo.UpdateScheduledChange(ctx, change)
+}
+
+// GetRetentionPolicy retrieves the retention policy stored under a name
+func (r *Repository) GetRetentionPolicy(ctx context.Context, name string) (result *entity.RetentionPolicy, err error) {
+	defer r.observe(ctx, "GetRetentionPolicy", time.Now(), &err)
+	return r.repo.GetRetentionPolicy(ctx, name)
+}
+
+// SetRetentionPolicy creates or replaces a retention policy
+func (r *Repository) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) (err error) {
+	defer r.observe(ctx, "SetRetentionPolicy", time.Now(), &err)
+	return r.repo.SetRetentionPolicy(ctx, policy)
+}
+
+// DeleteRetentionPolicy removes a retention policy
+func (r *Repository) DeleteRetentionPolicy(ctx context.Context, name string) (err error) {
+	defer r.observe(ctx, "DeleteRetentionPolicy", time.Now(), &err)
+	return r.repo.DeleteRetentionPolicy(ctx, name)
+}
+
+// ListConfigurationNames lists the names of all configurations
+func (r *Repository) ListConfigurationNames(ctx context.Context) (result []string, err error) {
+	defer r.observe(ctx, "ListConfigurationNames", time.Now(), &err)
+	return r.repo.ListConfigurationNames(ctx)
+}
+
+// CompactVersions removes the data of the given versions, keeping their metadata
+func (r *Repository) CompactVersions(ctx context.Context, name string, versions []int) (err error) {
+	defer r.observe(ctx, "CompactVersions", time.Now(), &err)
+	return r.repo.CompactVersions(ctx, name, versions)
+}
+
+// DeduplicateVersionData migrates inline payloads to content-addressed storage
+// and removes unreferenced payloads, returning the migrated and removed counts
+func (r *Repository) DeduplicateVersionData(ctx context.Context) (migrated int, removed int, err error) {
+	defer r.observe(ctx, "DeduplicateVersionData", time.Now(), &err)
+	return r.repo.DeduplicateVersionData(ctx)
+}
+
+// SetVersionTag creates or replaces a version tag
+func (r *Repository) SetVersionTag(ctx context.Context, tag *entity.VersionTag) (err error) {
+	defer r.observe(ctx, "SetVersionTag", time.Now(), &err)
+	return r.repo.SetVersionTag(ctx, tag)
+}
+
+// GetVersionTag retrieves a version tag of a configuration
+func (r *Repository) GetVersionTag(ctx context.Context, name, tag string) (result *entity.VersionTag, err error) {
+	defer r.observe(ctx, "GetVersionTag", time.Now(), &err)
+	return r.repo.GetVersionTag(ctx, name, tag)
+}
+
+// ListVersionTags lists the tags of a configuration
+func (r *Repository) ListVersionTags(ctx context.Context, name string) (result []*entity.VersionTag, err error) {
+	defer r.observe(ctx, "ListVersionTags", time.Now(), &err)
+	return r.repo.ListVersionTags(ctx, name)
+}
+
+// DeleteVersionTag removes a version tag
+func (r *Repository) DeleteVersionTag(ctx context.Context, name, tag string) (err error) {
+	defer r.observe(ctx, "DeleteVersionTag", time.Now(), &err)
+	return r.repo.DeleteVersionTag(ctx, name, tag)
+}
+
+// CreateChangeRequest persists a new change request and assigns its ID
+func (r *Repository) CreateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) (err error) {
+	defer r.observe(ctx, "CreateChangeRequest", time.Now(), &err)
+	return r.repo.CreateChangeRequest(ctx, cr)
+}
+
+// GetChangeRequest retrieves a change request with its approvals and comments
+func (r *Repository) GetChangeRequest(ctx context.Context, id int64) (result *entity.ChangeRequest, err error) {
+	defer r.observe(ctx, "GetChangeRequest", time.Now(), &err)
+	return r.repo.GetChangeRequest(ctx, id)
+}
+
+// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+func (r *Repository) ListChangeRequests(ctx context.Context, configName string, status entity.ChangeRequestStatus) (result []*entity.ChangeRequest, err error) {
+	defer r.observe(ctx, "ListChangeRequests", time.Now(), &err)
+	return r.repo.ListChangeRequests(ctx, configName, status)
+}
+
+// UpdateChangeRequest persists the status of a change request
+func (r *Repository) UpdateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) (err error) {
+	defer r.observe(ctx, "UpdateChangeRequest", time.Now(), &err)
+	return r.repo.UpdateChangeRequest(ctx, cr)
+}
+
+// AddChangeRequestApproval records an approval of a change request
+func (r *Repository) AddChangeRequestApproval(ctx context.Context, id int64, approval entity.ChangeRequestApproval) (err error) {
+	defer r.observe(ctx, "AddChangeRequestApproval", time.Now(), &err)
+	return r.repo.AddChangeRequestApproval(ctx, id, approval)
+}
+
+// AddChangeRequestComment adds a comment to a change request
+func (r *Repository) AddChangeRequestComment(ctx context.Context, id int64, comment *entity.ChangeRequestComment) (err error) {
+	defer r.observe(ctx, "AddChangeRequestComment", time.Now(), &err)
+	return r.repo.AddChangeRequestComment(ctx, id, comment)
+}
+
+// ApplyChangeRequest atomically applies an approved change request if its base version is still current
+func (r *Repository) ApplyChangeRequest(ctx context.Context, cr *entity.ChangeRequest, config *entity.Configuration) (err error) {
+	defer r.observe(ctx, "ApplyChangeRequest", time.Now(), &err)
+	return r.repo.ApplyChangeRequest(ctx, cr, config)
+}
+
+// ImportArchive stores everything an import writes in a single transaction
+func (r *Repository) ImportArchive(ctx context.Context, archive *entity.ArchiveImport) (err error) {
+	defer r.observe(ctx, "ImportArchive", time.Now(), &err)
+	return r.repo.ImportArchive(ctx, archive)
+}
+
+// ApplyBatch stores new configuration versions in a single transaction, or none of them
+// if any configuration changed since the versions were prepared
+func (r *Repository) ApplyBatch(ctx context.Context, configs []*entity.Configuration) (err error) {
+	defer r.observe(ctx, "ApplyBatch", time.Now(), &err)
+	return r.repo.ApplyBatch(ctx, configs)
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+)
+
+// Validator is a schema validator that counts the documents rejected by the schema of
+// each configuration. It caches compiled schemas when the validator it wraps does.
+type Validator struct {
+	validator validator.Validator
+	metrics   *Metrics
+}
+
+// NewValidator instruments a schema validator
+func NewValidator(v validator.Validator, m *Metrics) validator.CachingValidator {
+	return &Validator{validator: v, metrics: m}
+}
+
+// ValidateJSON validates data against a schema that belongs to no configuration;
+// failures are not counted
+func (v *Validator) ValidateJSON(ctx context.Context, schema json.RawMessage, data json.RawMessage) error {
+	return v.validator.ValidateJSON(ctx, schema, data)
+}
+
+// ValidateSchemaDefinition checks that a schema is a valid JSON schema
+func (v *Validator) ValidateSchemaDefinition(ctx context.Context, schema json.RawMessage) error {
+	return v.validator.ValidateSchemaDefinition(ctx, schema)
+}
+
+// ValidateConfiguration validates the data of a configuration against its schema and
+// counts the failure if the data does not conform
+func (v *Validator) ValidateConfiguration(ctx context.Context, name string, schema json.RawMessage, data json.RawMessage) error {
+	var err error
+	if cached, ok := v.validator.(validator.CachingValidator); ok {
+		err = cached.ValidateConfiguration(ctx, name, schema, data)
+	} else {
+		err = v.validator.ValidateJSON(ctx, schema, data)
+	}
+
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeValidationFailed {
+		v.metrics.observeValidationFailure(name)
+	}
+	return err
+}
+
+// InvalidateSchema drops the compiled schema of a configuration
+func (v *Validator) InvalidateSchema(name string) {
+	if cached, ok := v.validator.(validator.CachingValidator); ok {
+		cached.InvalidateSchema(name)
+	}
+}
+
+// InvalidateAllSchemas drops every compiled schema
+func (v *Validator) InvalidateAllSchemas() {
+	if cached, ok := v.validator.(validator.CachingValidator); ok {
+		cached.InvalidateAllSchemas()
+	}
+}
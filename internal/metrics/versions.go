@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// versionCollector reports the number of versions of every configuration. It reads
+// them from the repository at scrape time, so the counts include versions written by
+// other instances sharing the database.
+type versionCollector struct {
+	repo     repository.ConfigurationRepository
+	versions *prometheus.Desc
+}
+
+// RegisterVersions exports the number of versions of every configuration in a repository
+func (m *Metrics) RegisterVersions(repo repository.ConfigurationRepository) {
+	m.registry.MustRegister(&versionCollector{
+		repo: repo,
+		versions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "configuration_versions"),
+			"Number of versions of each configuration, including compacted versions.",
+			[]string{"config"}, nil,
+		),
+	})
+}
+
+// Describe implements prometheus.Collector
+func (c *versionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.versions
+}
+
+// Collect implements prometheus.Collector
+func (c *versionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	names, err := c.repo.ListConfigurationNames(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.versions, err)
+		return
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	refs := make([]entity.ConfigurationRef, len(names))
+	for i, name := range names {
+		refs[i] = entity.ConfigurationRef{Name: name}
+	}
+	items, err := c.repo.GetConfigurations(ctx, refs)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.versions, err)
+		return
+	}
+
+	for _, item := range items {
+		if item.Configuration == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.versions, prometheus.GaugeValue, float64(item.Configuration.Version), item.Name)
+	}
+}
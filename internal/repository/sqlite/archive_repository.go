@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// ImportArchive stores everything an import writes in a single transaction: shared
+// schemas, the global retention policy, configurations replaced by their archived
+// history and configurations merged with it. A failure leaves nothing written.
+func (r *ConfigurationRepository) ImportArchive(ctx context.Context, archive *entity.ArchiveImport) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, shared := range archive.SharedSchemas {
+		if err := setSharedSchema(ctx, tx, shared); err != nil {
+			return err
+		}
+	}
+	if policy := archive.RetentionPolicy; policy != nil {
+		if err := setRetentionPolicy(ctx, tx, policy); err != nil {
+			return err
+		}
+	}
+	for _, replaced := range archive.Replaced {
+		if err := importConfiguration(ctx, tx, replaced); err != nil {
+			return err
+		}
+	}
+	for _, merge := range archive.Merged {
+		if err := mergeConfiguration(ctx, tx, merge); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// importConfiguration replaces everything stored under a configuration name with an
+// archived history within a transaction: versions and their data, tags, schema
+// versions, rule set versions and the retention policy
+func importConfiguration(ctx context.Context, tx *sql.Tx, archive *entity.ConfigurationArchive) error {
+	var err error
+	name := archive.Name
+	for _, table := range []string{"configurations", "versions", "version_data", "version_tags", "schemas", "schema_versions", "rule_sets", "retention_policies"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE name = ?", name); err != nil {
+			return err
+		}
+	}
+
+	if current := archive.Current(); current != nil {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO configurations (name, version, created_at, updated_at, rollback_from, rollback_to) VALUES (?, ?, ?, ?, ?, ?)",
+			name, current.Version, archive.CreatedAt, current.CreatedAt, nullableInt(archive.RollbackFrom), nullableInt(archive.RollbackTo),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, version := range archive.Versions {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO versions (name, version, created_at, created_at_ns, is_rollback, compacted, schema_version, changeset_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			name, version.Version, version.CreatedAt, version.CreatedAt.UnixNano(), version.IsRollback, version.Compacted, nullableInt(version.SchemaVersion), nullableString(version.ChangesetID),
+		)
+		if err != nil {
+			return err
+		}
+		if version.Compacted {
+			continue
+		}
+		if err := storeVersionData(ctx, tx, name, version.Version, version.Data); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range archive.Tags {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO version_tags (name, tag, version, immutable, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			name, tag.Tag, tag.Version, tag.Immutable, tag.CreatedAt, tag.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, schema := range archive.Schemas {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schema_versions (name, version, schema, created_at) VALUES (?, ?, ?, ?)",
+			name, schema.Version, string(schema.Schema), schema.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if schema := archive.CurrentSchema(); schema != nil {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schemas (name, schema, version) VALUES (?, ?, ?)",
+			name, string(schema.Schema), schema.Version,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ruleSet := range archive.RuleSets {
+		encoded, err := json.Marshal(ruleSet.Rules)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO rule_sets (name, version, rules, created_at) VALUES (?, ?, ?, ?)",
+			name, ruleSet.Version, string(encoded), ruleSet.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if policy := archive.RetentionPolicy; policy != nil {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO retention_policies (name, keep_last, keep_days) VALUES (?, ?, ?)",
+			name, policy.KeepLast, policy.KeepDays,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeConfiguration appends the archived schema, rules, retention policy and data of a
+// configuration to what is stored under its name within a transaction. The new version
+// must directly follow the stored one, so a merge planned against state that has since
+// changed is rejected.
+func mergeConfiguration(ctx context.Context, tx *sql.Tx, merge *entity.ConfigurationMerge) error {
+	if merge.Schema != nil {
+		if _, err := registerSchema(ctx, tx, merge.Name, merge.Schema); err != nil {
+			return err
+		}
+	}
+	if merge.RuleSet != nil {
+		if _, err := createRuleSet(ctx, tx, merge.Name, merge.RuleSet.Rules); err != nil {
+			return err
+		}
+	}
+	if merge.RetentionPolicy != nil {
+		if err := setRetentionPolicy(ctx, tx, merge.RetentionPolicy); err != nil {
+			return err
+		}
+	}
+
+	config := merge.Configuration
+	if config == nil {
+		return nil
+	}
+	var currentVersion int
+	err := tx.QueryRowContext(ctx, "SELECT version FROM configurations WHERE name = ?", config.Name).Scan(&currentVersion)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if currentVersion != config.Version-1 {
+		return errors.NewConflictError("Configuration changed while the archive was being imported", map[string]interface{}{
+			"id":               config.Name,
+			"expected_version": config.Version - 1,
+			"current_version":  currentVersion,
+		})
+	}
+
+	if config.Version == 1 {
+		err = createConfiguration(ctx, tx, config)
+	} else {
+		err = updateConfiguration(ctx, tx, config)
+	}
+	if err != nil {
+		return err
+	}
+	return storeVersionData(ctx, tx, config.Name, config.Version, config.Data)
+}
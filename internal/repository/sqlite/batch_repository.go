@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// ApplyBatch stores new configuration versions in a single transaction. Each version
+// must directly follow the version that is current when it is written, so a batch
+// validated against state that has since changed is rejected as a whole.
+func (r *ConfigurationRepository) ApplyBatch(ctx context.Context, configs []*entity.Configuration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, config := range configs {
+		var currentVersion int
+		err := tx.QueryRowContext(ctx, "SELECT version FROM configurations WHERE name = ?", config.Name).Scan(&currentVersion)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if currentVersion != config.Version-1 {
+			return errors.NewConflictError("Configuration changed while the batch was being applied", map[string]interface{}{
+				"id":               config.Name,
+				"expected_version": config.Version - 1,
+				"current_version":  currentVersion,
+			})
+		}
+
+		if config.Version == 1 {
+			err = createConfiguration(ctx, tx, config)
+		} else {
+			err = updateConfiguration(ctx, tx, config)
+		}
+		if err != nil {
+			return err
+		}
+		if err := storeVersionData(ctx, tx, config.Name, config.Version, config.Data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
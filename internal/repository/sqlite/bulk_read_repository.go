@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// GetConfigurations reads many configuration versions in a single query. The requested
+// refs are passed as a VALUES table joined against the configurations, versions and
+// payloads, so a ref without a matching row is reported as not found.
+func (r *ConfigurationRepository) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error) {
+	items := make([]entity.BulkReadItem, len(refs))
+	if len(refs) == 0 {
+		return items, nil
+	}
+
+	values := make([]string, len(refs))
+	args := make([]interface{}, 0, 3*len(refs))
+	for i, ref := range refs {
+		values[i] = "(?, ?, ?)"
+		args = append(args, i, ref.Name, ref.Version)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`WITH refs(idx, name, version) AS (VALUES `+strings.Join(values, ", ")+`)
+		SELECT r.idx, c.created_at, c.updated_at, c.rollback_from, c.rollback_to,
+			v.version, v.created_at, v.compacted, v.schema_version, v.changeset_id, COALESCE(b.data, vd.data)
+		FROM refs r
+		JOIN configurations c ON c.name = r.name
+		JOIN versions v ON v.name = r.name AND v.version = CASE WHEN r.version = 0 THEN c.version ELSE r.version END
+		LEFT JOIN version_data vd ON vd.name = v.name AND vd.version = v.version
+		LEFT JOIN blobs b ON b.hash = vd.content_hash`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make([]bool, len(refs))
+	for rows.Next() {
+		var idx, version int
+		var createdAt, updatedAt, versionCreatedAt time.Time
+		var rollbackFrom, rollbackTo, schemaVersion sql.NullInt64
+		var compacted bool
+		var changesetID, data sql.NullString
+		if err := rows.Scan(
+			&idx, &createdAt, &updatedAt, &rollbackFrom, &rollbackTo,
+			&version, &versionCreatedAt, &compacted, &schemaVersion, &changesetID, &data,
+		); err != nil {
+			return nil, err
+		}
+		found[idx] = true
+
+		ref := refs[idx]
+		items[idx].ConfigurationRef = ref
+		if compacted || !data.Valid {
+			items[idx].Error = errors.NewVersionCompactedError(ref.Name, version).ToErrorResponse()
+			continue
+		}
+
+		// Match GetConfiguration for the current version and GetConfigurationVersion otherwise
+		config := &entity.Configuration{
+			Name:          ref.Name,
+			Version:       version,
+			Data:          json.RawMessage(data.String),
+			CreatedAt:     createdAt,
+			UpdatedAt:     versionCreatedAt,
+			SchemaVersion: int(schemaVersion.Int64),
+			ChangesetID:   changesetID.String,
+		}
+		if ref.Version == 0 {
+			config.UpdatedAt = updatedAt
+			config.RollbackFrom = int(rollbackFrom.Int64)
+			config.RollbackTo = int(rollbackTo.Int64)
+		}
+		items[idx].Configuration = config
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, ref := range refs {
+		if found[i] {
+			continue
+		}
+		items[i].ConfigurationRef = ref
+		if ref.Version == 0 {
+			items[i].Error = errors.NewNotFoundError("Configuration", ref.Name).ToErrorResponse()
+		} else {
+			items[i].Error = errors.NewNotFoundError("Configuration version", fmt.Sprintf("%s:%d", ref.Name, ref.Version)).ToErrorResponse()
+		}
+	}
+
+	return items, nil
+}
+
+// GetSchemas reads many schemas in a single query. Current schemas are read from the
+// schemas table and given versions from the schema history, so a configuration whose
+// schema was deleted has no current schema but keeps its versions.
+func (r *ConfigurationRepository) GetSchemas(ctx context.Context, refs []entity.SchemaRef) (map[entity.SchemaRef]json.RawMessage, error) {
+	schemas := make(map[entity.SchemaRef]json.RawMessage, len(refs))
+	if len(refs) == 0 {
+		return schemas, nil
+	}
+
+	values := make([]string, len(refs))
+	args := make([]interface{}, 0, 2*len(refs))
+	for i, ref := range refs {
+		values[i] = "(?, ?)"
+		args = append(args, ref.Name, ref.Version)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`WITH refs(name, version) AS (VALUES `+strings.Join(values, ", ")+`)
+		SELECT r.name, r.version, COALESCE(s.schema, sv.schema)
+		FROM refs r
+		LEFT JOIN schemas s ON r.version = 0 AND s.name = r.name
+		LEFT JOIN schema_versions sv ON r.version <> 0 AND sv.name = r.name AND sv.version = r.version
+		WHERE s.schema IS NOT NULL OR sv.schema IS NOT NULL`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref entity.SchemaRef
+		var schema string
+		if err := rows.Scan(&ref.Name, &ref.Version, &schema); err != nil {
+			return nil, err
+		}
+		schemas[ref] = json.RawMessage(schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schemas, nil
+}
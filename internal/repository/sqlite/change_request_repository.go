@@ -0,0 +1,302 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+const changeRequestColumns = "id, name, data, base_version, diff, author, status, required_approvals, applied_version, reason, created_at, updated_at"
+
+// CreateChangeRequest persists a new change request and assigns its ID
+func (r *ConfigurationRepository) CreateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error {
+	diff, err := json.Marshal(cr.Diff)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO change_requests (name, data, base_version, diff, author, status, required_approvals, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cr.Name, string(cr.Data), cr.BaseVersion, string(diff), cr.Author, string(cr.Status), cr.RequiredApprovals, cr.CreatedAt, cr.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	cr.ID = id
+
+	return nil
+}
+
+// GetChangeRequest retrieves a change request with its approvals and comments
+func (r *ConfigurationRepository) GetChangeRequest(ctx context.Context, id int64) (*entity.ChangeRequest, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+changeRequestColumns+" FROM change_requests WHERE id = ?",
+		id,
+	)
+
+	cr, err := scanChangeRequest(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Change request", fmt.Sprintf("%d", id))
+		}
+		return nil, err
+	}
+
+	if err := r.loadChangeRequestReviews(ctx, cr); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+func (r *ConfigurationRepository) ListChangeRequests(ctx context.Context, configName string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error) {
+	query := "SELECT " + changeRequestColumns + " FROM change_requests WHERE name = ?"
+	args := []interface{}{configName}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, string(status))
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	changeRequests := []*entity.ChangeRequest{}
+	for rows.Next() {
+		cr, err := scanChangeRequest(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		changeRequests = append(changeRequests, cr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, cr := range changeRequests {
+		if err := r.loadChangeRequestReviews(ctx, cr); err != nil {
+			return nil, err
+		}
+	}
+
+	return changeRequests, nil
+}
+
+// UpdateChangeRequest moves a change request to its new status. The update only applies
+// while the request is still in the status it moves from, so a request rejected or
+// withdrawn while it is being applied ends up either closed or applied, never both.
+func (r *ConfigurationRepository) UpdateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error {
+	from := cr.Status.PreviousStatus()
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE change_requests SET status = ?, applied_version = ?, reason = ?, updated_at = ? WHERE id = ? AND status = ?",
+		string(cr.Status), cr.AppliedVersion, cr.Reason, cr.UpdatedAt, cr.ID, string(from),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var status string
+		err := r.db.QueryRowContext(ctx, "SELECT status FROM change_requests WHERE id = ?", cr.ID).Scan(&status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NewNotFoundError("Change request", fmt.Sprintf("%d", cr.ID))
+			}
+			return err
+		}
+		return errors.NewConflictError(fmt.Sprintf("Change request is no longer %s", from), map[string]string{
+			"status": status,
+		})
+	}
+
+	return nil
+}
+
+// AddChangeRequestApproval records an approval of a change request
+func (r *ConfigurationRepository) AddChangeRequestApproval(ctx context.Context, id int64, approval entity.ChangeRequestApproval) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO change_request_approvals (change_request_id, client_id, created_at) VALUES (?, ?, ?)",
+		id, approval.ClientID, approval.CreatedAt,
+	)
+	return err
+}
+
+// AddChangeRequestComment adds a comment to a change request and assigns its ID
+func (r *ConfigurationRepository) AddChangeRequestComment(ctx context.Context, id int64, comment *entity.ChangeRequestComment) error {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO change_request_comments (change_request_id, client_id, body, created_at) VALUES (?, ?, ?, ?)",
+		id, comment.ClientID, comment.Body, comment.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	commentID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	comment.ID = commentID
+
+	return nil
+}
+
+// ApplyChangeRequest atomically records the approvals of a change request, creates the
+// new configuration version it proposes and marks it applied. If the configuration moved
+// past the request's base version, the approvals are recorded and the request is closed
+// as stale instead, and a conflict error is returned. If the request is no longer
+// pending, nothing is changed and a conflict error is returned.
+func (r *ConfigurationRepository) ApplyChangeRequest(ctx context.Context, cr *entity.ChangeRequest, config *entity.Configuration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM change_requests WHERE id = ?", cr.ID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("Change request", fmt.Sprintf("%d", cr.ID))
+		}
+		return err
+	}
+	if entity.ChangeRequestStatus(status) != entity.ChangeRequestStatusPending {
+		return errors.NewConflictError("Change request is no longer pending", map[string]string{"status": status})
+	}
+
+	for _, approval := range cr.Approvals {
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO change_request_approvals (change_request_id, client_id, created_at) VALUES (?, ?, ?)",
+			cr.ID, approval.ClientID, approval.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	var currentVersion int
+	err = tx.QueryRowContext(ctx, "SELECT version FROM configurations WHERE name = ?", cr.Name).Scan(&currentVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("Configuration", cr.Name)
+		}
+		return err
+	}
+	if currentVersion != cr.BaseVersion {
+		cr.MarkStale(fmt.Sprintf("Configuration moved from version %d to %d before the change request was approved", cr.BaseVersion, currentVersion))
+		_, err := tx.ExecContext(ctx,
+			"UPDATE change_requests SET status = ?, applied_version = ?, reason = ?, updated_at = ? WHERE id = ?",
+			string(cr.Status), cr.AppliedVersion, cr.Reason, cr.UpdatedAt, cr.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return errors.NewConflictError("Configuration changed since the change request was created", map[string]interface{}{
+			"base_version":    cr.BaseVersion,
+			"current_version": currentVersion,
+		})
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE change_requests SET status = ?, applied_version = ?, updated_at = ? WHERE id = ?",
+		string(cr.Status), cr.AppliedVersion, cr.UpdatedAt, cr.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := updateConfiguration(ctx, tx, config); err != nil {
+		return err
+	}
+	if err := storeVersionData(ctx, tx, config.Name, config.Version, config.Data); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadChangeRequestReviews loads the approvals and comments of a change request
+func (r *ConfigurationRepository) loadChangeRequestReviews(ctx context.Context, cr *entity.ChangeRequest) error {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT client_id, created_at FROM change_request_approvals WHERE change_request_id = ? ORDER BY created_at, client_id",
+		cr.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cr.Approvals = []entity.ChangeRequestApproval{}
+	for rows.Next() {
+		var approval entity.ChangeRequestApproval
+		if err := rows.Scan(&approval.ClientID, &approval.CreatedAt); err != nil {
+			return err
+		}
+		cr.Approvals = append(cr.Approvals, approval)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	commentRows, err := r.db.QueryContext(ctx,
+		"SELECT id, client_id, body, created_at FROM change_request_comments WHERE change_request_id = ? ORDER BY id",
+		cr.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer commentRows.Close()
+
+	cr.Comments = []entity.ChangeRequestComment{}
+	for commentRows.Next() {
+		var comment entity.ChangeRequestComment
+		if err := commentRows.Scan(&comment.ID, &comment.ClientID, &comment.Body, &comment.CreatedAt); err != nil {
+			return err
+		}
+		cr.Comments = append(cr.Comments, comment)
+	}
+
+	return commentRows.Err()
+}
+
+// scanChangeRequest scans a change request row without its approvals and comments
+func scanChangeRequest(row rowScanner) (*entity.ChangeRequest, error) {
+	var cr entity.ChangeRequest
+	var data, diff, status string
+	err := row.Scan(
+		&cr.ID, &cr.Name, &data, &cr.BaseVersion, &diff, &cr.Author, &status,
+		&cr.RequiredApprovals, &cr.AppliedVersion, &cr.Reason, &cr.CreatedAt, &cr.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cr.Data = json.RawMessage(data)
+	cr.Status = entity.ChangeRequestStatus(status)
+	if err := json.Unmarshal([]byte(diff), &cr.Diff); err != nil {
+		return nil, err
+	}
+
+	return &cr, nil
+}
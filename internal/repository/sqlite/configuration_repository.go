@@ -1,7 +1,10 @@
 package sqlite
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
@@ -35,6 +38,11 @@ func NewConfigurationRepository(dbPath string) (repository.ConfigurationReposito
 	}, nil
 }
 
+// DB returns the database handle of the repository, e.g. to export its connection pool statistics
+func (r *ConfigurationRepository) DB() *sql.DB {
+	return r.db
+}
+
 // Initialize database schema
 func initSchema(db *sql.DB) error {
 	// Create configurations table
@@ -90,19 +98,274 @@ func initSchema(db *sql.DB) error {
 		return err
 	}
 
+	// Create blobs table holding content-addressed version payloads
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS blobs (
+			hash TEXT PRIMARY KEY,
+			data TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create retention_policies table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS retention_policies (
+			name TEXT PRIMARY KEY,
+			keep_last INTEGER NOT NULL DEFAULT 0,
+			keep_days INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create version_tags table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS version_tags (
+			name TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			immutable BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (name, tag)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create change request tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS change_requests (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			data TEXT NOT NULL,
+			base_version INTEGER NOT NULL,
+			diff TEXT NOT NULL,
+			author TEXT NOT NULL,
+			status TEXT NOT NULL,
+			required_approvals INTEGER NOT NULL,
+			applied_version INTEGER NOT NULL DEFAULT 0,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS change_request_approvals (
+			change_request_id INTEGER NOT NULL,
+			client_id TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (change_request_id, client_id)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS change_request_comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			change_request_id INTEGER NOT NULL,
+			client_id TEXT NOT NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Add columns introduced after the initial schema
+	if err := addColumnIfMissing(db, "versions", "compacted", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "version_data", "content_hash", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "versions", "schema_version", "INTEGER"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "schemas", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "versions", "changeset_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "versions", "created_at_ns", "INTEGER"); err != nil {
+		return err
+	}
+	if err := backfillUnixNanos(db, "versions", "created_at", "created_at_ns"); err != nil {
+		return err
+	}
+
+	// Create schema_versions table keeping every registered schema revision
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_versions (
+			name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			schema TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (name, version)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Schemas registered before versioning become the first revision of their history
+	_, err = db.Exec(
+		"INSERT OR IGNORE INTO schema_versions (name, version, schema, created_at) SELECT name, version, schema, ? FROM schemas",
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Create shared_schemas table holding named fragments that schemas reference with $ref
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS shared_schemas (
+			name TEXT PRIMARY KEY,
+			schema TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create rule_sets table keeping every version of the custom validation rules
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS rule_sets (
+			name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			rules TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (name, version)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create scheduled_changes table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduled_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			data TEXT NOT NULL,
+			effective_at TIMESTAMP NOT NULL,
+			effective_at_ns INTEGER,
+			status TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			applied_version INTEGER,
+			error TEXT
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "scheduled_changes", "effective_at_ns", "INTEGER"); err != nil {
+		return err
+	}
+	if err := backfillUnixNanos(db, "scheduled_changes", "effective_at", "effective_at_ns"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table when upgrading an older database
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// backfillUnixNanos fills a nanosecond Unix time column from the timestamp column it
+// mirrors for rows written before the column was added. The driver stores timestamps as
+// text in the zone they were given in, which does not sort chronologically, so queries
+// compare the integer columns instead.
+func backfillUnixNanos(db *sql.DB, table, column, nanosColumn string) error {
+	rows, err := db.Query(fmt.Sprintf("SELECT rowid, %s FROM %s WHERE %s IS NULL", column, table, nanosColumn))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	nanos := make(map[int64]int64)
+	for rows.Next() {
+		var rowID int64
+		var t time.Time
+		if err := rows.Scan(&rowID, &t); err != nil {
+			return err
+		}
+		nanos[rowID] = t.UnixNano()
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for rowID, n := range nanos {
+		_, err := db.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", table, nanosColumn), n, rowID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // CreateConfiguration creates a new configuration
-func (r *ConfigurationRepository) CreateConfiguration(config *entity.Configuration) error {
-	tx, err := r.db.Begin()
+func (r *ConfigurationRepository) CreateConfiguration(ctx context.Context, config *entity.Configuration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := createConfiguration(ctx, tx, config); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createConfiguration inserts the first version of a configuration within a transaction
+func createConfiguration(ctx context.Context, tx *sql.Tx, config *entity.Configuration) error {
 	// Insert into configurations table
-	_, err = tx.Exec(
+	_, err := tx.ExecContext(ctx,
 		"INSERT INTO configurations (name, version, created_at, updated_at) VALUES (?, ?, ?, ?)",
 		config.Name, config.Version, config.CreatedAt, config.UpdatedAt,
 	)
@@ -110,28 +373,38 @@ func (r *ConfigurationRepository) CreateConfiguration(config *entity.Configurati
 		return err
 	}
 
-	// Insert into versions table
-	_, err = tx.Exec(
-		"INSERT INTO versions (name, version, created_at, is_rollback) VALUES (?, ?, ?, ?)",
-		config.Name, config.Version, config.CreatedAt, false,
+	// Insert into versions table, recording the schema version the data was validated against
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO versions (name, version, created_at, created_at_ns, is_rollback, changeset_id, schema_version) VALUES (?, ?, ?, ?, ?, ?, (SELECT version FROM schemas WHERE name = ?))",
+		config.Name, config.Version, config.CreatedAt, config.CreatedAt.UnixNano(), false, nullableString(config.ChangesetID), config.Name,
 	)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	config.SchemaVersion, err = recordedSchemaVersion(ctx, tx, config.Name, config.Version)
+	return err
 }
 
 // UpdateConfiguration updates an existing configuration
-func (r *ConfigurationRepository) UpdateConfiguration(config *entity.Configuration) error {
-	tx, err := r.db.Begin()
+func (r *ConfigurationRepository) UpdateConfiguration(ctx context.Context, config *entity.Configuration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := updateConfiguration(ctx, tx, config); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateConfiguration moves a configuration to a new version within a transaction
+func updateConfiguration(ctx context.Context, tx *sql.Tx, config *entity.Configuration) error {
 	// Update configurations table
-	_, err = tx.Exec(
+	_, err := tx.ExecContext(ctx,
 		"UPDATE configurations SET version = ?, updated_at = ?, rollback_from = ?, rollback_to = ? WHERE name = ?",
 		config.Version, config.UpdatedAt, config.RollbackFrom, config.RollbackTo, config.Name,
 	)
@@ -139,25 +412,44 @@ func (r *ConfigurationRepository) UpdateConfiguration(config *entity.Configurati
 		return err
 	}
 
-	// Insert into versions table
-	_, err = tx.Exec(
-		"INSERT INTO versions (name, version, created_at, is_rollback) VALUES (?, ?, ?, ?)",
-		config.Name, config.Version, config.UpdatedAt, config.RollbackFrom > 0,
+	// Insert into versions table. New data was validated against the current schema,
+	// while a rollback restores data validated by the schema of its target version.
+	schemaVersion := "(SELECT version FROM schemas WHERE name = ?)"
+	args := []interface{}{config.Name, config.Version, config.UpdatedAt, config.UpdatedAt.UnixNano(), config.RollbackFrom > 0, nullableString(config.ChangesetID), config.Name}
+	if config.RollbackTo > 0 {
+		schemaVersion = "(SELECT schema_version FROM versions WHERE name = ? AND version = ?)"
+		args = append(args, config.RollbackTo)
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO versions (name, version, created_at, created_at_ns, is_rollback, changeset_id, schema_version) VALUES (?, ?, ?, ?, ?, ?, "+schemaVersion+")",
+		args...,
 	)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	config.SchemaVersion, err = recordedSchemaVersion(ctx, tx, config.Name, config.Version)
+	return err
+}
+
+// recordedSchemaVersion returns the schema version stored for a configuration version,
+// or 0 when the data was written without a schema
+func recordedSchemaVersion(ctx context.Context, tx *sql.Tx, name string, version int) (int, error) {
+	var schemaVersion sql.NullInt64
+	err := tx.QueryRowContext(ctx,
+		"SELECT schema_version FROM versions WHERE name = ? AND version = ?",
+		name, version,
+	).Scan(&schemaVersion)
+	return int(schemaVersion.Int64), err
 }
 
 // GetConfiguration retrieves a configuration by name
-func (r *ConfigurationRepository) GetConfiguration(name string) (*entity.Configuration, error) {
+func (r *ConfigurationRepository) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
 	var config entity.Configuration
 	var rollbackFrom, rollbackTo sql.NullInt64
 
 	// Query configurations table
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT name, version, created_at, updated_at, rollback_from, rollback_to FROM configurations WHERE name = ?",
 		name,
 	).Scan(
@@ -183,12 +475,21 @@ func (r *ConfigurationRepository) GetConfiguration(name string) (*entity.Configu
 		config.RollbackTo = int(rollbackTo.Int64)
 	}
 
-	// Get data from version_data table
-	var dataStr string
-	err = r.db.QueryRow(
-		"SELECT data FROM version_data WHERE name = ? AND version = ?",
+	// Get the schema version and changeset recorded for the current version
+	var schemaVersion sql.NullInt64
+	var changesetID sql.NullString
+	err = r.db.QueryRowContext(ctx,
+		"SELECT schema_version, changeset_id FROM versions WHERE name = ? AND version = ?",
 		name, config.Version,
-	).Scan(&dataStr)
+	).Scan(&schemaVersion, &changesetID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	config.SchemaVersion = int(schemaVersion.Int64)
+	config.ChangesetID = changesetID.String
+
+	// Get data from version_data table
+	dataStr, err := r.readVersionData(ctx, name, config.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -198,12 +499,12 @@ func (r *ConfigurationRepository) GetConfiguration(name string) (*entity.Configu
 }
 
 // GetConfigurationVersion retrieves a specific version of a configuration
-func (r *ConfigurationRepository) GetConfigurationVersion(name string, version int) (*entity.Configuration, error) {
+func (r *ConfigurationRepository) GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error) {
 	var config entity.Configuration
 
 	// Check if version exists
 	var exists bool
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT EXISTS(SELECT 1 FROM versions WHERE name = ? AND version = ?)",
 		name, version,
 	).Scan(&exists)
@@ -216,28 +517,29 @@ func (r *ConfigurationRepository) GetConfigurationVersion(name string, version i
 
 	// Get version info
 	var createdAt time.Time
-	var isRollback bool
-	err = r.db.QueryRow(
-		"SELECT created_at, is_rollback FROM versions WHERE name = ? AND version = ?",
+	var isRollback, compacted bool
+	var schemaVersion sql.NullInt64
+	var changesetID sql.NullString
+	err = r.db.QueryRowContext(ctx,
+		"SELECT created_at, is_rollback, compacted, schema_version, changeset_id FROM versions WHERE name = ? AND version = ?",
 		name, version,
-	).Scan(&createdAt, &isRollback)
+	).Scan(&createdAt, &isRollback, &compacted, &schemaVersion, &changesetID)
 	if err != nil {
 		return nil, err
 	}
+	if compacted {
+		return nil, errors.NewVersionCompactedError(name, version)
+	}
 
 	// Get data from version_data table
-	var dataStr string
-	err = r.db.QueryRow(
-		"SELECT data FROM version_data WHERE name = ? AND version = ?",
-		name, version,
-	).Scan(&dataStr)
+	dataStr, err := r.readVersionData(ctx, name, version)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get original creation time
 	var originalCreatedAt time.Time
-	err = r.db.QueryRow(
+	err = r.db.QueryRowContext(ctx,
 		"SELECT created_at FROM configurations WHERE name = ?",
 		name,
 	).Scan(&originalCreatedAt)
@@ -251,16 +553,19 @@ func (r *ConfigurationRepository) GetConfigurationVersion(name string, version i
 		Data:      json.RawMessage(dataStr),
 		CreatedAt: originalCreatedAt,
 		UpdatedAt: createdAt,
+
+		SchemaVersion: int(schemaVersion.Int64),
+		ChangesetID:   changesetID.String,
 	}
 
 	return &config, nil
 }
 
 // ListConfigurationVersions lists all versions of a configuration
-func (r *ConfigurationRepository) ListConfigurationVersions(name string) (*entity.VersionList, error) {
+func (r *ConfigurationRepository) ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error) {
 	// Check if configuration exists
 	var exists bool
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT EXISTS(SELECT 1 FROM configurations WHERE name = ?)",
 		name,
 	).Scan(&exists)
@@ -272,8 +577,8 @@ func (r *ConfigurationRepository) ListConfigurationVersions(name string) (*entit
 	}
 
 	// Query versions
-	rows, err := r.db.Query(
-		"SELECT version, created_at, is_rollback FROM versions WHERE name = ? ORDER BY version",
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT version, created_at, is_rollback, compacted, schema_version, changeset_id FROM versions WHERE name = ? ORDER BY version",
 		name,
 	)
 	if err != nil {
@@ -282,14 +587,34 @@ func (r *ConfigurationRepository) ListConfigurationVersions(name string) (*entit
 	defer rows.Close()
 
 	versions := []entity.VersionInfo{}
+	index := make(map[int]int)
 	for rows.Next() {
 		var version entity.VersionInfo
-		err := rows.Scan(&version.Version, &version.CreatedAt, &version.IsRollback)
+		var schemaVersion sql.NullInt64
+		var changesetID sql.NullString
+		err := rows.Scan(&version.Version, &version.CreatedAt, &version.IsRollback, &version.Compacted, &schemaVersion, &changesetID)
 		if err != nil {
 			return nil, err
 		}
+		version.SchemaVersion = int(schemaVersion.Int64)
+		version.ChangesetID = changesetID.String
+		index[version.Version] = len(versions)
 		versions = append(versions, version)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Attach tags to the versions they point at
+	tags, err := r.ListVersionTags(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if i, ok := index[tag.Version]; ok {
+			versions[i].Tags = append(versions[i].Tags, tag.Tag)
+		}
+	}
 
 	return &entity.VersionList{
 		Name:     name,
@@ -297,39 +622,137 @@ func (r *ConfigurationRepository) ListConfigurationVersions(name string) (*entit
 	}, nil
 }
 
-// RegisterSchema registers a JSON schema for a configuration
-func (r *ConfigurationRepository) RegisterSchema(configName string, schema json.RawMessage) error {
-	// Check if schema already exists
-	var exists bool
-	err := r.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM schemas WHERE name = ?)",
-		configName,
-	).Scan(&exists)
+// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+func (r *ConfigurationRepository) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error) {
+	var version sql.NullInt64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT MAX(version) FROM versions WHERE name = ? AND created_at_ns <= ?",
+		name, asOf.UnixNano(),
+	).Scan(&version)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !version.Valid {
+		return nil, errors.NewNotFoundError("Configuration", name)
 	}
 
-	if exists {
-		// Update existing schema
-		_, err = r.db.Exec(
-			"UPDATE schemas SET schema = ? WHERE name = ?",
-			string(schema), configName,
-		)
-	} else {
-		// Insert new schema
-		_, err = r.db.Exec(
-			"INSERT INTO schemas (name, schema) VALUES (?, ?)",
-			configName, string(schema),
+	return r.GetConfigurationVersion(ctx, name, int(version.Int64))
+}
+
+// ListConfigurationsAsOf retrieves the effective version of every configuration at the
+// given time, selecting the latest version created by then per configuration and its
+// payload in a single query. An effective version that was compacted is reported on its
+// item rather than failing the whole list.
+func (r *ConfigurationRepository) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`WITH effective(name, version) AS (
+			SELECT name, MAX(version) FROM versions WHERE created_at_ns <= ? GROUP BY name
 		)
+		SELECT v.name, v.version, c.created_at, v.created_at, v.compacted, v.schema_version, v.changeset_id, COALESCE(b.data, vd.data)
+		FROM effective e
+		JOIN configurations c ON c.name = e.name
+		JOIN versions v ON v.name = e.name AND v.version = e.version
+		LEFT JOIN version_data vd ON vd.name = v.name AND vd.version = v.version
+		LEFT JOIN blobs b ON b.hash = vd.content_hash
+		ORDER BY v.name`,
+		asOf.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return err
+	items := []entity.BulkReadItem{}
+	for rows.Next() {
+		var config entity.Configuration
+		var schemaVersion sql.NullInt64
+		var compacted bool
+		var changesetID, data sql.NullString
+		if err := rows.Scan(
+			&config.Name, &config.Version, &config.CreatedAt, &config.UpdatedAt,
+			&compacted, &schemaVersion, &changesetID, &data,
+		); err != nil {
+			return nil, err
+		}
+
+		item := entity.BulkReadItem{ConfigurationRef: entity.ConfigurationRef{Name: config.Name, Version: config.Version}}
+		if compacted || !data.Valid {
+			item.Error = errors.NewVersionCompactedError(config.Name, config.Version).ToErrorResponse()
+		} else {
+			config.Data = json.RawMessage(data.String)
+			config.SchemaVersion = int(schemaVersion.Int64)
+			config.ChangesetID = changesetID.String
+			item.Configuration = &config
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// RegisterSchema stores a JSON schema as the next version of a configuration's
+// schema history and makes it the current schema
+func (r *ConfigurationRepository) RegisterSchema(ctx context.Context, configName string, schema json.RawMessage) (*entity.SchemaVersion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schemaVersion, err := registerSchema(ctx, tx, configName, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return schemaVersion, nil
+}
+
+// registerSchema stores the next schema version of a configuration within a transaction
+func registerSchema(ctx context.Context, tx *sql.Tx, configName string, schema json.RawMessage) (*entity.SchemaVersion, error) {
+	var latest int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM schema_versions WHERE name = ?",
+		configName,
+	).Scan(&latest)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion := &entity.SchemaVersion{
+		Name:      configName,
+		Version:   latest + 1,
+		Schema:    schema,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_versions (name, version, schema, created_at) VALUES (?, ?, ?, ?)",
+		configName, schemaVersion.Version, string(schema), schemaVersion.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Point the current schema at the new version
+	_, err = tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO schemas (name, schema, version) VALUES (?, ?, ?)",
+		configName, string(schema), schemaVersion.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemaVersion, nil
 }
 
 // GetSchema retrieves the JSON schema for a configuration
-func (r *ConfigurationRepository) GetSchema(configName string) (json.RawMessage, error) {
+func (r *ConfigurationRepository) GetSchema(ctx context.Context, configName string) (json.RawMessage, error) {
 	var schemaStr string
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT schema FROM schemas WHERE name = ?",
 		configName,
 	).Scan(&schemaStr)
@@ -343,24 +766,54 @@ func (r *ConfigurationRepository) GetSchema(configName string) (json.RawMessage,
 	return json.RawMessage(schemaStr), nil
 }
 
-// StoreVersionData stores the raw data for a specific version
-func (r *ConfigurationRepository) StoreVersionData(configName string, version int, data json.RawMessage) error {
-	_, err := r.db.Exec(
-		"INSERT OR REPLACE INTO version_data (name, version, data) VALUES (?, ?, ?)",
-		configName, version, string(data),
+// StoreVersionData stores the raw data for a specific version.
+// Payloads are content-addressed, so identical data across versions is stored once.
+func (r *ConfigurationRepository) StoreVersionData(ctx context.Context, configName string, version int, data json.RawMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := storeVersionData(ctx, tx, configName, version, data); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeVersionData stores a version payload content-addressed within a transaction
+func storeVersionData(ctx context.Context, tx *sql.Tx, configName string, version int, data json.RawMessage) error {
+	hash := contentHash(data)
+	_, err := tx.ExecContext(ctx,
+		"INSERT OR IGNORE INTO blobs (hash, data) VALUES (?, ?)",
+		hash, string(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO version_data (name, version, data, content_hash) VALUES (?, ?, '', ?)",
+		configName, version, hash,
 	)
 	return err
 }
 
 // GetVersionData retrieves the raw data for a specific version
-func (r *ConfigurationRepository) GetVersionData(configName string, version int) (json.RawMessage, error) {
-	var dataStr string
-	err := r.db.QueryRow(
-		"SELECT data FROM version_data WHERE name = ? AND version = ?",
-		configName, version,
-	).Scan(&dataStr)
+func (r *ConfigurationRepository) GetVersionData(ctx context.Context, configName string, version int) (json.RawMessage, error) {
+	dataStr, err := r.readVersionData(ctx, configName, version)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			// Distinguish versions removed by compaction from versions that never existed
+			var compacted bool
+			cErr := r.db.QueryRowContext(ctx,
+				"SELECT compacted FROM versions WHERE name = ? AND version = ?",
+				configName, version,
+			).Scan(&compacted)
+			if cErr == nil && compacted {
+				return nil, errors.NewVersionCompactedError(configName, version)
+			}
 			return nil, errors.NewNotFoundError("Version data", fmt.Sprintf("%s:%d", configName, version))
 		}
 		return nil, err
@@ -369,6 +822,35 @@ func (r *ConfigurationRepository) GetVersionData(configName string, version int)
 	return json.RawMessage(dataStr), nil
 }
 
+// readVersionData reads a version payload, resolving content-addressed blobs
+// and falling back to inline data written before deduplication was introduced
+func (r *ConfigurationRepository) readVersionData(ctx context.Context, configName string, version int) (string, error) {
+	var dataStr string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(b.data, vd.data) FROM version_data vd
+		LEFT JOIN blobs b ON b.hash = vd.content_hash
+		WHERE vd.name = ? AND vd.version = ?`,
+		configName, version,
+	).Scan(&dataStr)
+	return dataStr, err
+}
+
+// contentHash returns the content address of a payload
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// nullableInt stores zero as NULL, matching columns that are unset for most rows
+func nullableInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
 // Close closes the database connection
 func (r *ConfigurationRepository) Close() error {
 	return r.db.Close()
@@ -1,9 +1,13 @@
 package sqlite
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
 	"os"
 	"testing"
 	"time"
@@ -49,15 +53,15 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
-		err := repo.CreateConfiguration(config)
+		err := repo.CreateConfiguration(context.Background(), config)
 		assert.NoError(t, err)
 
 		// Store version data
-		err = repo.StoreVersionData("test-config", 1, json.RawMessage(`{"key":"value"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"value"}`))
 		assert.NoError(t, err)
 
 		// Test creating a duplicate configuration
-		err = repo.CreateConfiguration(config)
+		err = repo.CreateConfiguration(context.Background(), config)
 		assert.Error(t, err)
 	})
 
@@ -74,11 +78,11 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
-		err := repo.CreateConfiguration(config)
+		err := repo.CreateConfiguration(context.Background(), config)
 		assert.NoError(t, err)
 
 		// Store version data
-		err = repo.StoreVersionData("test-config", 1, json.RawMessage(`{"key":"value"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"value"}`))
 		assert.NoError(t, err)
 
 		// Update configuration
@@ -90,15 +94,15 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
-		err = repo.UpdateConfiguration(updatedConfig)
+		err = repo.UpdateConfiguration(context.Background(), updatedConfig)
 		assert.NoError(t, err)
 
 		// Store updated version data
-		err = repo.StoreVersionData("test-config", 2, json.RawMessage(`{"key":"updated"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 2, json.RawMessage(`{"key":"updated"}`))
 		assert.NoError(t, err)
 
 		// Verify update
-		result, err := repo.GetConfiguration("test-config")
+		result, err := repo.GetConfiguration(context.Background(), "test-config")
 		assert.NoError(t, err)
 		assert.Equal(t, 2, result.Version)
 
@@ -172,17 +176,17 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Store version data
-		err = repo.StoreVersionData("test-config", 1, json.RawMessage(`{"key":"value"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"value"}`))
 		assert.NoError(t, err)
 
 		// Get existing configuration
-		result, err := repo.GetConfiguration("test-config")
+		result, err := repo.GetConfiguration(context.Background(), "test-config")
 		assert.NoError(t, err)
 		assert.Equal(t, "test-config", result.Name)
 		assert.Equal(t, 1, result.Version)
 
 		// Get non-existent configuration
-		_, err = repo.GetConfiguration("non-existent")
+		_, err = repo.GetConfiguration(context.Background(), "non-existent")
 		assert.Error(t, err)
 	})
 
@@ -219,7 +223,7 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Store version data
-		err = repo.StoreVersionData("test-config", 1, json.RawMessage(`{"key":"value"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"value"}`))
 		assert.NoError(t, err)
 
 		// Update configuration
@@ -246,24 +250,24 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Store updated version data
-		err = repo.StoreVersionData("test-config", 2, json.RawMessage(`{"key":"updated"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 2, json.RawMessage(`{"key":"updated"}`))
 		assert.NoError(t, err)
 
 		// Get specific version
-		v1, err := repo.GetConfigurationVersion("test-config", 1)
+		v1, err := repo.GetConfigurationVersion(context.Background(), "test-config", 1)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, v1.Version)
 
-		v2, err := repo.GetConfigurationVersion("test-config", 2)
+		v2, err := repo.GetConfigurationVersion(context.Background(), "test-config", 2)
 		assert.NoError(t, err)
 		assert.Equal(t, 2, v2.Version)
 
 		// Get non-existent version
-		_, err = repo.GetConfigurationVersion("test-config", 3)
+		_, err = repo.GetConfigurationVersion(context.Background(), "test-config", 3)
 		assert.Error(t, err)
 
 		// Get version of non-existent configuration
-		_, err = repo.GetConfigurationVersion("non-existent", 1)
+		_, err = repo.GetConfigurationVersion(context.Background(), "non-existent", 1)
 		assert.Error(t, err)
 	})
 
@@ -300,7 +304,7 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Store version data
-		err = repo.StoreVersionData("test-config", 1, json.RawMessage(`{"key":"value"}`))
+		err = repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"value"}`))
 		assert.NoError(t, err)
 
 		// Update configuration multiple times
@@ -322,37 +326,121 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Store updated version data
-			err = repo.StoreVersionData("test-config", i, json.RawMessage(`{"key":"updated"}`))
+			err = repo.StoreVersionData(context.Background(), "test-config", i, json.RawMessage(`{"key":"updated"}`))
 			assert.NoError(t, err)
 		}
 
 		// List versions
-		versions, err := repo.ListConfigurationVersions("test-config")
+		versions, err := repo.ListConfigurationVersions(context.Background(), "test-config")
 		assert.NoError(t, err)
 		assert.Equal(t, "test-config", versions.Name)
 		assert.Equal(t, 5, len(versions.Versions))
 
 		// List versions for non-existent configuration
-		_, err = repo.ListConfigurationVersions("non-existent")
+		_, err = repo.ListConfigurationVersions(context.Background(), "non-existent")
 		assert.Error(t, err)
 	})
 
+	t.Run("GetConfigurationAsOf", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Create a configuration and update it twice, an hour apart. The updates are written
+		// in a zone whose local times sort before the creation time as text.
+		start := time.Now().UTC().Add(-3 * time.Hour)
+		zone := time.FixedZone("UTC-10", -10*60*60)
+		config := &entity.Configuration{
+			Name:      "test-config",
+			Version:   1,
+			Data:      json.RawMessage(`{"key":"v1"}`),
+			CreatedAt: start,
+			UpdatedAt: start,
+		}
+		require.NoError(t, repo.CreateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 1, config.Data))
+
+		for i := 2; i <= 3; i++ {
+			updated := &entity.Configuration{
+				Name:      "test-config",
+				Version:   i,
+				CreatedAt: start,
+				UpdatedAt: start.Add(time.Duration(i-1) * time.Hour).In(zone),
+			}
+			require.NoError(t, repo.UpdateConfiguration(context.Background(), updated))
+			require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", i, json.RawMessage(fmt.Sprintf(`{"key":"v%d"}`, i))))
+		}
+
+		// Between the first and second update, version 2 was effective
+		result, err := repo.GetConfigurationAsOf(context.Background(), "test-config", start.Add(90*time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Version)
+		assert.JSONEq(t, `{"key":"v2"}`, string(result.Data))
+
+		// Exactly at creation time, version 1 was effective
+		result, err = repo.GetConfigurationAsOf(context.Background(), "test-config", start)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Version)
+
+		// The time may be given in any zone
+		result, err = repo.GetConfigurationAsOf(context.Background(), "test-config", start.Add(150*time.Minute).In(zone))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.Version)
+
+		// Before creation the configuration did not exist
+		_, err = repo.GetConfigurationAsOf(context.Background(), "test-config", start.Add(-time.Minute))
+		assert.Error(t, err)
+
+		// Bulk variant only includes configurations that existed at the time
+		other := &entity.Configuration{
+			Name:      "other-config",
+			Version:   1,
+			CreatedAt: start.Add(2 * time.Hour),
+			UpdatedAt: start.Add(2 * time.Hour),
+		}
+		require.NoError(t, repo.CreateConfiguration(context.Background(), other))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "other-config", 1, json.RawMessage(`{}`)))
+
+		items, err := repo.ListConfigurationsAsOf(context.Background(), start.Add(90*time.Minute))
+		assert.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "test-config", items[0].Name)
+		assert.Equal(t, 2, items[0].Version)
+		assert.JSONEq(t, `{"key":"v2"}`, string(items[0].Configuration.Data))
+
+		items, err = repo.ListConfigurationsAsOf(context.Background(), time.Now().UTC())
+		assert.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, "other-config", items[0].Name)
+		assert.Equal(t, 3, items[1].Configuration.Version)
+
+		// A compacted effective version is reported on its item
+		require.NoError(t, repo.CompactVersions(context.Background(), "test-config", []int{2}))
+		items, err = repo.ListConfigurationsAsOf(context.Background(), start.Add(90*time.Minute))
+		assert.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Nil(t, items[0].Configuration)
+		require.NotNil(t, items[0].Error)
+		assert.Equal(t, errors.ErrorCodeVersionCompacted, items[0].Error.Code)
+	})
+
 	t.Run("RegisterSchema", func(t *testing.T) {
 		repo, cleanup := setupTestDB(t)
 		defer cleanup()
 
 		// Register schema
 		schema := json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"}}}`)
-		err := repo.RegisterSchema("test-config", schema)
+		first, err := repo.RegisterSchema(context.Background(), "test-config", schema)
 		assert.NoError(t, err)
+		assert.Equal(t, 1, first.Version)
 
 		// Register duplicate schema (should update)
 		updatedSchema := json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"},"newProp":{"type":"number"}}}`)
-		err = repo.RegisterSchema("test-config", updatedSchema)
+		second, err := repo.RegisterSchema(context.Background(), "test-config", updatedSchema)
 		assert.NoError(t, err)
+		assert.Equal(t, 2, second.Version)
 
 		// Verify schema was updated
-		result, err := repo.GetSchema("test-config")
+		result, err := repo.GetSchema(context.Background(), "test-config")
 		assert.NoError(t, err)
 		assert.JSONEq(t, string(updatedSchema), string(result))
 	})
@@ -363,17 +451,753 @@ func TestSQLiteConfigurationRepository(t *testing.T) {
 
 		// Register schema
 		schema := json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"}}}`)
-		err := repo.RegisterSchema("test-config", schema)
+		_, err := repo.RegisterSchema(context.Background(), "test-config", schema)
 		assert.NoError(t, err)
 
 		// Get existing schema
-		result, err := repo.GetSchema("test-config")
+		result, err := repo.GetSchema(context.Background(), "test-config")
 		assert.NoError(t, err)
 		assert.JSONEq(t, string(schema), string(result))
 
 		// Get non-existent schema
-		_, err = repo.GetSchema("non-existent")
+		_, err = repo.GetSchema(context.Background(), "non-existent")
+		assert.Error(t, err)
+	})
+
+	t.Run("ScheduledChanges", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		now := time.Now().UTC()
+
+		// Create a change that is already due and one that is still in the future
+		due := entity.NewScheduledChange("test-config", json.RawMessage(`{"key":"due"}`), now.Add(-time.Minute))
+		err := repo.CreateScheduledChange(context.Background(), due)
+		assert.NoError(t, err)
+		assert.NotZero(t, due.ID)
+
+		future := entity.NewScheduledChange("test-config", json.RawMessage(`{"key":"future"}`), now.Add(time.Hour))
+		err = repo.CreateScheduledChange(context.Background(), future)
+		assert.NoError(t, err)
+
+		// A change stored in a zone ahead of UTC is due by its instant, not its local time
+		ahead := entity.NewScheduledChange("test-config", json.RawMessage(`{"key":"ahead"}`), now.Add(time.Minute))
+		ahead.EffectiveAt = ahead.EffectiveAt.In(time.FixedZone("UTC+10", 10*60*60))
+		err = repo.CreateScheduledChange(context.Background(), ahead)
+		assert.NoError(t, err)
+
+		// Get by ID
+		result, err := repo.GetScheduledChange(context.Background(), due.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-config", result.Name)
+		assert.Equal(t, entity.ScheduledChangeStatusPending, result.Status)
+		assert.JSONEq(t, `{"key":"due"}`, string(result.Data))
+
+		// List all changes for the configuration
+		changes, err := repo.ListScheduledChanges(context.Background(), "test-config")
+		assert.NoError(t, err)
+		require.Len(t, changes, 3)
+		assert.Equal(t, []int64{due.ID, ahead.ID, future.ID}, []int64{changes[0].ID, changes[1].ID, changes[2].ID})
+
+		// Only the due change is returned
+		dueChanges, err := repo.ListDueScheduledChanges(context.Background(), now)
+		assert.NoError(t, err)
+		require.Len(t, dueChanges, 1)
+		assert.Equal(t, due.ID, dueChanges[0].ID)
+
+		// A change is claimed before it is applied, after which it can no longer be cancelled
+		due.MarkApplying()
+		require.NoError(t, repo.UpdateScheduledChange(context.Background(), due))
+
+		cancelled := *due
+		cancelled.MarkCancelled()
+		err = repo.UpdateScheduledChange(context.Background(), &cancelled)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+
+		// A claimed change is stale once it has been claimed for longer than the cutoff
+		stale, err := repo.ListStaleScheduledChanges(context.Background(), due.UpdatedAt)
+		assert.NoError(t, err)
+		assert.Empty(t, stale)
+		stale, err = repo.ListStaleScheduledChanges(context.Background(), due.UpdatedAt.Add(time.Second))
+		assert.NoError(t, err)
+		require.Len(t, stale, 1)
+		assert.Equal(t, due.ID, stale[0].ID)
+
+		// Applied changes are no longer due
+		due.MarkApplied(2)
+		err = repo.UpdateScheduledChange(context.Background(), due)
+		assert.NoError(t, err)
+
+		dueChanges, err = repo.ListDueScheduledChanges(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Empty(t, dueChanges)
+
+		result, err = repo.GetScheduledChange(context.Background(), due.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ScheduledChangeStatusApplied, result.Status)
+		assert.Equal(t, 2, result.AppliedVersion)
+
+		// A cancelled change cannot be claimed any more
+		future.MarkCancelled()
+		require.NoError(t, repo.UpdateScheduledChange(context.Background(), future))
+		future.MarkApplying()
+		err = repo.UpdateScheduledChange(context.Background(), future)
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+
+		// Get non-existent change
+		_, err = repo.GetScheduledChange(context.Background(), 9999)
+		assert.Error(t, err)
+	})
+
+	t.Run("RetentionAndCompaction", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Create a configuration with three versions, the third a rollback to the first
+		now := time.Now().UTC()
+		config := &entity.Configuration{Name: "test-config", Version: 1, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, repo.CreateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"v1"}`)))
+		for i := 2; i <= 3; i++ {
+			require.NoError(t, repo.UpdateConfiguration(context.Background(), &entity.Configuration{Name: "test-config", Version: i, CreatedAt: now, UpdatedAt: now}))
+		}
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 2, json.RawMessage(`{"key":"v2"}`)))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 3, json.RawMessage(`{"key":"v1"}`)))
+
+		// Identical payloads share a single blob
+		sqlRepo := repo.(*ConfigurationRepository)
+		var blobs int
+		require.NoError(t, sqlRepo.db.QueryRow("SELECT COUNT(*) FROM blobs").Scan(&blobs))
+		assert.Equal(t, 2, blobs)
+
+		// Retention policies
+		_, err := repo.GetRetentionPolicy(context.Background(), "test-config")
+		assert.Error(t, err)
+		require.NoError(t, repo.SetRetentionPolicy(context.Background(), &entity.RetentionPolicy{Name: "test-config", KeepLast: 1}))
+		policy, err := repo.GetRetentionPolicy(context.Background(), "test-config")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, policy.KeepLast)
+
+		// Compacted versions keep their metadata but not their data
+		require.NoError(t, repo.CompactVersions(context.Background(), "test-config", []int{1, 2}))
+		_, err = repo.GetConfigurationVersion(context.Background(), "test-config", 1)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeVersionCompacted, appErr.Code)
+		_, err = repo.GetVersionData(context.Background(), "test-config", 2)
+		appErr, ok = err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeVersionCompacted, appErr.Code)
+
+		versions, err := repo.ListConfigurationVersions(context.Background(), "test-config")
+		assert.NoError(t, err)
+		assert.Len(t, versions.Versions, 3)
+
+		// The current version still reads the shared payload
+		current, err := repo.GetConfiguration(context.Background(), "test-config")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"key":"v1"}`, string(current.Data))
+
+		// The payload only referenced by version 2 is removed
+		migrated, removed, err := repo.DeduplicateVersionData(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, migrated)
+		assert.Equal(t, 1, removed)
+
+		names, err := repo.ListConfigurationNames(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test-config"}, names)
+
+		require.NoError(t, repo.DeleteRetentionPolicy(context.Background(), "test-config"))
+		assert.Error(t, repo.DeleteRetentionPolicy(context.Background(), "test-config"))
+	})
+
+	t.Run("VersionTags", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Create a configuration with two versions
+		now := time.Now().UTC()
+		require.NoError(t, repo.CreateConfiguration(context.Background(), &entity.Configuration{Name: "test-config", Version: 1, CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"v1"}`)))
+		require.NoError(t, repo.UpdateConfiguration(context.Background(), &entity.Configuration{Name: "test-config", Version: 2, CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 2, json.RawMessage(`{"key":"v2"}`)))
+
+		// Tag the first version
+		require.NoError(t, repo.SetVersionTag(context.Background(), entity.NewVersionTag("test-config", "release", 1, true)))
+		require.NoError(t, repo.SetVersionTag(context.Background(), entity.NewVersionTag("test-config", "last-known-good", 1, false)))
+
+		tag, err := repo.GetVersionTag(context.Background(), "test-config", "release")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tag.Version)
+		assert.True(t, tag.Immutable)
+
+		_, err = repo.GetVersionTag(context.Background(), "test-config", "missing")
+		assert.Error(t, err)
+
+		// An immutable tag is never moved, but setting it to its own version changes nothing
+		var appErr *errors.AppError
+		err = repo.SetVersionTag(context.Background(), entity.NewVersionTag("test-config", "release", 2, false))
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		retag := entity.NewVersionTag("test-config", "release", 1, false)
+		require.NoError(t, repo.SetVersionTag(context.Background(), retag))
+		assert.True(t, retag.Immutable)
+
+		// Tags are listed alongside versions
+		versions, err := repo.ListConfigurationVersions(context.Background(), "test-config")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"last-known-good", "release"}, versions.Versions[0].Tags)
+		assert.Empty(t, versions.Versions[1].Tags)
+
+		// Tagged versions are never compacted
+		require.NoError(t, repo.CompactVersions(context.Background(), "test-config", []int{1}))
+		data, err := repo.GetVersionData(context.Background(), "test-config", 1)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"key":"v1"}`, string(data))
+
+		tags, err := repo.ListVersionTags(context.Background(), "test-config")
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+
+		// Compacted versions cannot be tagged
+		require.NoError(t, repo.CompactVersions(context.Background(), "test-config", []int{2}))
+		err = repo.SetVersionTag(context.Background(), entity.NewVersionTag("test-config", "last-known-good", 2, false))
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeVersionCompacted, appErr.Code)
+
+		require.NoError(t, repo.DeleteVersionTag(context.Background(), "test-config", "last-known-good"))
+		assert.Error(t, repo.DeleteVersionTag(context.Background(), "test-config", "last-known-good"))
+	})
+
+	t.Run("ChangeRequests", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Create a configuration
+		now := time.Now().UTC()
+		require.NoError(t, repo.CreateConfiguration(context.Background(), &entity.Configuration{Name: "prod-payments", Version: 1, CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "prod-payments", 1, json.RawMessage(`{"limit":100}`)))
+
+		// Create three change requests against version 1
+		first := entity.NewChangeRequest("prod-payments", json.RawMessage(`{"limit":200}`), 1, nil, "alice", 1)
+		second := entity.NewChangeRequest("prod-payments", json.RawMessage(`{"limit":300}`), 1, nil, "alice", 1)
+		third := entity.NewChangeRequest("prod-payments", json.RawMessage(`{"limit":400}`), 1, nil, "alice", 2)
+		require.NoError(t, repo.CreateChangeRequest(context.Background(), first))
+		require.NoError(t, repo.CreateChangeRequest(context.Background(), second))
+		require.NoError(t, repo.CreateChangeRequest(context.Background(), third))
+		assert.NotZero(t, first.ID)
+
+		// Reviews are loaded with the request
+		approval := entity.ChangeRequestApproval{ClientID: "bob", CreatedAt: now}
+		require.NoError(t, repo.AddChangeRequestApproval(context.Background(), first.ID, approval))
+		require.NoError(t, repo.AddChangeRequestApproval(context.Background(), first.ID, approval))
+		comment := &entity.ChangeRequestComment{ClientID: "bob", Body: "LGTM", CreatedAt: now}
+		require.NoError(t, repo.AddChangeRequestComment(context.Background(), first.ID, comment))
+		assert.NotZero(t, comment.ID)
+
+		loaded, err := repo.GetChangeRequest(context.Background(), first.ID)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"limit":200}`, string(loaded.Data))
+		assert.Len(t, loaded.Approvals, 1)
+		assert.Len(t, loaded.Comments, 1)
+
+		// Apply the first request
+		current, err := repo.GetConfiguration(context.Background(), "prod-payments")
+		require.NoError(t, err)
+		newConfig := current.UpdateVersion(loaded.Data)
+		loaded.MarkApplied(newConfig.Version)
+		require.NoError(t, repo.ApplyChangeRequest(context.Background(), loaded, newConfig))
+
+		current, err = repo.GetConfiguration(context.Background(), "prod-payments")
+		require.NoError(t, err)
+		assert.Equal(t, 2, current.Version)
+		assert.JSONEq(t, `{"limit":200}`, string(current.Data))
+
+		// Applying it twice is a conflict
+		err = repo.ApplyChangeRequest(context.Background(), loaded, current.UpdateVersion(loaded.Data))
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+
+		// The second request's base version is stale, so nothing is applied and the request
+		// is closed together with recording its approval
+		second.Approvals = []entity.ChangeRequestApproval{{ClientID: "bob", CreatedAt: now}}
+		second.MarkApplied(3)
+		err = repo.ApplyChangeRequest(context.Background(), second, current.UpdateVersion(second.Data))
+		appErr, ok = err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+
+		current, err = repo.GetConfiguration(context.Background(), "prod-payments")
+		require.NoError(t, err)
+		assert.Equal(t, 2, current.Version)
+
+		loaded, err = repo.GetChangeRequest(context.Background(), second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChangeRequestStatusStale, loaded.Status)
+		assert.Zero(t, loaded.AppliedVersion)
+		assert.Len(t, loaded.Approvals, 1)
+
+		// Filter by status
+		pending, err := repo.ListChangeRequests(context.Background(), "prod-payments", entity.ChangeRequestStatusPending)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, third.ID, pending[0].ID)
+
+		all, err := repo.ListChangeRequests(context.Background(), "prod-payments", "")
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		// Withdraw the third request
+		pending[0].MarkWithdrawn()
+		require.NoError(t, repo.UpdateChangeRequest(context.Background(), pending[0]))
+		loaded, err = repo.GetChangeRequest(context.Background(), third.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChangeRequestStatusWithdrawn, loaded.Status)
+
+		// Closed requests cannot be closed again
+		loaded.MarkRejected("too late")
+		err = repo.UpdateChangeRequest(context.Background(), loaded)
+		appErr, ok = err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+	})
+
+	t.Run("SchemaVersions", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Version 1 is written before any schema exists
+		now := time.Now().UTC()
+		config := &entity.Configuration{Name: "test-config", Version: 1, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, repo.CreateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 1, json.RawMessage(`{"key":"a"}`)))
+		assert.Zero(t, config.SchemaVersion)
+
+		// Versions 2 and 3 are written under schema versions 1 and 2
+		v1, err := repo.RegisterSchema(context.Background(), "test-config", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		config = config.UpdateVersion(json.RawMessage(`{"key":"b"}`))
+		require.NoError(t, repo.UpdateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 2, config.Data))
+		assert.Equal(t, v1.Version, config.SchemaVersion)
+
+		v2, err := repo.RegisterSchema(context.Background(), "test-config", json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"}}}`))
+		require.NoError(t, err)
+		config = config.UpdateVersion(json.RawMessage(`{"key":"c"}`))
+		require.NoError(t, repo.UpdateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 3, config.Data))
+
+		// A rollback keeps the schema version of the data it restores
+		rollback := entity.NewVersionFromRollback(config, 2, json.RawMessage(`{"key":"b"}`))
+		require.NoError(t, repo.UpdateConfiguration(context.Background(), rollback))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "test-config", 4, rollback.Data))
+		assert.Equal(t, v1.Version, rollback.SchemaVersion)
+
+		// Schema history is kept
+		versions, err := repo.ListSchemaVersions(context.Background(), "test-config")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.JSONEq(t, `{"type":"object"}`, string(versions[0].Schema))
+
+		loaded, err := repo.GetSchemaVersion(context.Background(), "test-config", v2.Version)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(v2.Schema), string(loaded.Schema))
+
+		_, err = repo.GetSchemaVersion(context.Background(), "test-config", 3)
+		assert.Error(t, err)
+		_, err = repo.ListSchemaVersions(context.Background(), "non-existent")
+		assert.Error(t, err)
+
+		// Configuration reads expose the recorded schema version
+		current, err := repo.GetConfiguration(context.Background(), "test-config")
+		require.NoError(t, err)
+		assert.Equal(t, 1, current.SchemaVersion)
+
+		version3, err := repo.GetConfigurationVersion(context.Background(), "test-config", 3)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version3.SchemaVersion)
+
+		list, err := repo.ListConfigurationVersions(context.Background(), "test-config")
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 1, 2, 1}, []int{
+			list.Versions[0].SchemaVersion,
+			list.Versions[1].SchemaVersion,
+			list.Versions[2].SchemaVersion,
+			list.Versions[3].SchemaVersion,
+		})
+	})
+
+	t.Run("SchemaCatalog", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// "no-schema" has no schema, "late-schema" got its schema after its last write,
+		// "validated" was written under its schema
+		now := time.Now().UTC()
+		for _, name := range []string{"no-schema", "late-schema"} {
+			require.NoError(t, repo.CreateConfiguration(context.Background(), &entity.Configuration{Name: name, Version: 1, CreatedAt: now, UpdatedAt: now}))
+			require.NoError(t, repo.StoreVersionData(context.Background(), name, 1, json.RawMessage(`{}`)))
+		}
+		_, err := repo.RegisterSchema(context.Background(), "late-schema", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		_, err = repo.RegisterSchema(context.Background(), "validated", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		_, err = repo.RegisterSchema(context.Background(), "validated", json.RawMessage(`{"type":"object","properties":{}}`))
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateConfiguration(context.Background(), &entity.Configuration{Name: "validated", Version: 1, CreatedAt: now, UpdatedAt: now}))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "validated", 1, json.RawMessage(`{}`)))
+
+		// Schemas are listed by name with their current version
+		schemas, total, err := repo.ListSchemas(context.Background(), 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, schemas, 1)
+		assert.Equal(t, "validated", schemas[0].Name)
+		assert.Equal(t, 2, schemas[0].Version)
+
+		unvalidated, err := repo.ListUnvalidatedConfigurations(context.Background())
+		require.NoError(t, err)
+		require.Len(t, unvalidated, 2)
+		assert.Equal(t, "late-schema", unvalidated[0].Name)
+		assert.Equal(t, entity.UnvalidatedReasonNotValidated, unvalidated[0].Reason)
+		assert.Equal(t, entity.UnvalidatedReasonNoSchema, unvalidated[1].Reason)
+
+		// Deleting detaches the schema but keeps its history
+		require.NoError(t, repo.DeleteSchema(context.Background(), "validated"))
+		_, err = repo.GetSchema(context.Background(), "validated")
 		assert.Error(t, err)
+		assert.Error(t, repo.DeleteSchema(context.Background(), "validated"))
+
+		history, err := repo.ListSchemaVersions(context.Background(), "validated")
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		next, err := repo.RegisterSchema(context.Background(), "validated", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		assert.Equal(t, 3, next.Version)
+
+		unvalidated, err = repo.ListUnvalidatedConfigurations(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, unvalidated, 2)
+	})
+
+	t.Run("SharedSchemas", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		endpoint := &entity.SharedSchema{Name: "common/endpoint", Schema: json.RawMessage(`{"type":"object"}`)}
+		require.NoError(t, repo.SetSharedSchema(context.Background(), endpoint))
+		require.NoError(t, repo.SetSharedSchema(context.Background(), &entity.SharedSchema{Name: "common/retry-policy", Schema: json.RawMessage(`{"type":"integer"}`)}))
+		created := endpoint.CreatedAt
+
+		// Replacing a fragment keeps its creation time
+		updated := &entity.SharedSchema{Name: "common/endpoint", Schema: json.RawMessage(`{"type":"object","required":["url"]}`)}
+		require.NoError(t, repo.SetSharedSchema(context.Background(), updated))
+		assert.True(t, updated.CreatedAt.Equal(created))
+
+		fetched, err := repo.GetSharedSchema(context.Background(), "common/endpoint")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"object","required":["url"]}`, string(fetched.Schema))
+
+		shared, err := repo.ListSharedSchemas(context.Background())
+		require.NoError(t, err)
+		require.Len(t, shared, 2)
+		assert.Equal(t, "common/endpoint", shared[0].Name)
+
+		require.NoError(t, repo.DeleteSharedSchema(context.Background(), "common/endpoint"))
+		_, err = repo.GetSharedSchema(context.Background(), "common/endpoint")
+		assert.Error(t, err)
+		assert.Error(t, repo.DeleteSharedSchema(context.Background(), "common/endpoint"))
+	})
+
+	t.Run("RuleSets", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		_, err := repo.GetRuleSet(context.Background(), "payments")
+		assert.Error(t, err)
+
+		first, err := repo.CreateRuleSet(context.Background(), "payments", []rules.Rule{{Name: "limits", Expression: "max_limit > min_limit"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, first.Version)
+		second, err := repo.CreateRuleSet(context.Background(), "payments", []rules.Rule{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, second.Version)
+
+		// The current rule set is the latest version
+		current, err := repo.GetRuleSet(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, 2, current.Version)
+		assert.Empty(t, current.Rules)
+
+		previous, err := repo.GetRuleSetVersion(context.Background(), "payments", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "max_limit > min_limit", previous.Rules[0].Expression)
+
+		history, err := repo.ListRuleSetVersions(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		_, err = repo.GetRuleSetVersion(context.Background(), "payments", 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("ImportArchive", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		// Existing state under the name is replaced entirely
+		config := entity.NewConfiguration("payments", json.RawMessage(`{"max_limit":1}`))
+		require.NoError(t, repo.CreateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "payments", 1, config.Data))
+		_, err := repo.CreateRuleSet(context.Background(), "payments", []rules.Rule{{Name: "positive", Expression: "max_limit > 0"}})
+		require.NoError(t, err)
+
+		created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		archive := &entity.ConfigurationArchive{
+			Name:      "payments",
+			CreatedAt: created,
+			Versions: []entity.ArchivedVersion{
+				{Version: 1, CreatedAt: created, Compacted: true, SchemaVersion: 1},
+				{Version: 2, CreatedAt: created.Add(time.Hour), Data: json.RawMessage(`{"max_limit":500}`), SchemaVersion: 1},
+				{Version: 3, CreatedAt: created.Add(2 * time.Hour), Data: json.RawMessage(`{"max_limit":800}`), SchemaVersion: 2},
+			},
+			Tags: []*entity.VersionTag{{Name: "payments", Tag: "stable", Version: 2, Immutable: true, CreatedAt: created, UpdatedAt: created}},
+			Schemas: []*entity.SchemaVersion{
+				{Name: "payments", Version: 1, Schema: json.RawMessage(`{"type":"object"}`), CreatedAt: created},
+				{Name: "payments", Version: 2, Schema: json.RawMessage(`{"type":"object","required":["max_limit"]}`), CreatedAt: created},
+			},
+			RetentionPolicy: &entity.RetentionPolicy{Name: "payments", KeepLast: 5},
+		}
+		shared := &entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type":"number"}`)}
+		global := &entity.RetentionPolicy{Name: entity.GlobalRetentionPolicyName, KeepLast: 10}
+		require.NoError(t, repo.ImportArchive(context.Background(), &entity.ArchiveImport{
+			SharedSchemas:   []*entity.SharedSchema{shared},
+			RetentionPolicy: global,
+			Replaced:        []*entity.ConfigurationArchive{archive},
+		}))
+
+		storedShared, err := repo.GetSharedSchema(context.Background(), "common/money")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"number"}`, string(storedShared.Schema))
+		globalPolicy, err := repo.GetRetentionPolicy(context.Background(), entity.GlobalRetentionPolicyName)
+		require.NoError(t, err)
+		assert.Equal(t, 10, globalPolicy.KeepLast)
+
+		current, err := repo.GetConfiguration(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, 3, current.Version)
+		assert.Equal(t, 2, current.SchemaVersion)
+		assert.JSONEq(t, `{"max_limit":800}`, string(current.Data))
+		assert.True(t, current.CreatedAt.Equal(created))
+
+		_, err = repo.GetConfigurationVersion(context.Background(), "payments", 1)
+		assert.Equal(t, errors.ErrorCodeVersionCompacted, err.(*errors.AppError).Code)
+
+		tag, err := repo.GetVersionTag(context.Background(), "payments", "stable")
+		require.NoError(t, err)
+		assert.Equal(t, 2, tag.Version)
+		assert.True(t, tag.Immutable)
+
+		schema, err := repo.GetSchema(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"object","required":["max_limit"]}`, string(schema))
+
+		_, err = repo.GetRuleSet(context.Background(), "payments")
+		assert.Error(t, err)
+
+		policy, err := repo.GetRetentionPolicy(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, 5, policy.KeepLast)
+
+		// A detached schema keeps its history without a current schema
+		archive.SchemaDetached = true
+		require.NoError(t, repo.ImportArchive(context.Background(), &entity.ArchiveImport{Replaced: []*entity.ConfigurationArchive{archive}}))
+		_, err = repo.GetSchema(context.Background(), "payments")
+		assert.Error(t, err)
+		history, err := repo.ListSchemaVersions(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		// A merge appends a schema version and a version validated by it
+		require.NoError(t, repo.ImportArchive(context.Background(), &entity.ArchiveImport{
+			Merged: []*entity.ConfigurationMerge{{
+				Name:          "payments",
+				Schema:        json.RawMessage(`{"type":"object","required":["max_limit","currency"]}`),
+				Configuration: current.UpdateVersion(json.RawMessage(`{"max_limit":800,"currency":"EUR"}`)),
+			}},
+		}))
+		merged, err := repo.GetConfiguration(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, 4, merged.Version)
+		assert.Equal(t, 3, merged.SchemaVersion)
+
+		// A merge planned against an older version is rejected and nothing is written
+		err = repo.ImportArchive(context.Background(), &entity.ArchiveImport{
+			SharedSchemas: []*entity.SharedSchema{{Name: "common/currency", Schema: json.RawMessage(`{"type":"string"}`)}},
+			Merged: []*entity.ConfigurationMerge{{
+				Name:          "payments",
+				Configuration: current.UpdateVersion(json.RawMessage(`{"max_limit":900}`)),
+			}},
+		})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeConflict, err.(*errors.AppError).Code)
+		_, err = repo.GetSharedSchema(context.Background(), "common/currency")
+		assert.Error(t, err, "shared schemas of a failed import must not be stored")
+	})
+
+	t.Run("ApplyBatch", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		routing := entity.NewConfiguration("routing", json.RawMessage(`{"region":"eu"}`))
+		require.NoError(t, repo.CreateConfiguration(context.Background(), routing))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "routing", 1, routing.Data))
+
+		payments := entity.NewConfiguration("payments", json.RawMessage(`{"max_limit":1000}`))
+		payments.ChangesetID = "release-42"
+		updated := routing.UpdateVersion(json.RawMessage(`{"region":"us"}`))
+		updated.ChangesetID = "release-42"
+		require.NoError(t, repo.ApplyBatch(context.Background(), []*entity.Configuration{payments, updated}))
+
+		current, err := repo.GetConfiguration(context.Background(), "routing")
+		require.NoError(t, err)
+		assert.Equal(t, 2, current.Version)
+		assert.Equal(t, "release-42", current.ChangesetID)
+		versions, err := repo.ListConfigurationVersions(context.Background(), "payments")
+		require.NoError(t, err)
+		require.Len(t, versions.Versions, 1)
+		assert.Equal(t, "release-42", versions.Versions[0].ChangesetID)
+
+		// A version that no longer follows the current one rejects the whole batch
+		stale := routing.UpdateVersion(json.RawMessage(`{"region":"apac"}`))
+		created := entity.NewConfiguration("limits", json.RawMessage(`{}`))
+		err = repo.ApplyBatch(context.Background(), []*entity.Configuration{created, stale})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeConflict, err.(*errors.AppError).Code)
+		_, err = repo.GetConfiguration(context.Background(), "limits")
+		assert.Error(t, err)
+	})
+
+	t.Run("GetConfigurations", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		payments := entity.NewConfiguration("payments", json.RawMessage(`{"max_limit":1}`))
+		require.NoError(t, repo.CreateConfiguration(context.Background(), payments))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "payments", 1, payments.Data))
+		updated := payments.UpdateVersion(json.RawMessage(`{"max_limit":2}`))
+		require.NoError(t, repo.UpdateConfiguration(context.Background(), updated))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "payments", 2, updated.Data))
+
+		items, err := repo.GetConfigurations(context.Background(), []entity.ConfigurationRef{
+			{Name: "payments"},
+			{Name: "payments", Version: 1},
+			{Name: "missing"},
+			{Name: "payments", Version: 9},
+		})
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+
+		assert.Equal(t, 2, items[0].Configuration.Version)
+		assert.JSONEq(t, `{"max_limit":2}`, string(items[0].Configuration.Data))
+		assert.Equal(t, 1, items[1].Configuration.Version)
+		assert.JSONEq(t, `{"max_limit":1}`, string(items[1].Configuration.Data))
+		assert.Equal(t, "missing", items[2].Name)
+		assert.Equal(t, errors.ErrorCodeNotFound, items[2].Error.Code)
+		assert.Equal(t, errors.ErrorCodeNotFound, items[3].Error.Code)
+
+		// Reads match the single-configuration reads
+		current, err := repo.GetConfiguration(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, current.UpdatedAt.Unix(), items[0].Configuration.UpdatedAt.Unix())
+		previous, err := repo.GetConfigurationVersion(context.Background(), "payments", 1)
+		require.NoError(t, err)
+		assert.Equal(t, previous.UpdatedAt.Unix(), items[1].Configuration.UpdatedAt.Unix())
+	})
+
+	t.Run("GetSchemas", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		_, err := repo.RegisterSchema(context.Background(), "payments", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		_, err = repo.RegisterSchema(context.Background(), "payments", json.RawMessage(`{"type":"object","required":["max_limit"]}`))
+		require.NoError(t, err)
+		_, err = repo.RegisterSchema(context.Background(), "routing", json.RawMessage(`{"type":"object"}`))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteSchema(context.Background(), "routing"))
+
+		schemas, err := repo.GetSchemas(context.Background(), []entity.SchemaRef{
+			{Name: "payments"},
+			{Name: "payments", Version: 1},
+			{Name: "payments", Version: 9},
+			{Name: "routing"},
+			{Name: "routing", Version: 1},
+			{Name: "missing"},
+		})
+		require.NoError(t, err)
+
+		assert.Len(t, schemas, 3)
+		assert.JSONEq(t, `{"type":"object","required":["max_limit"]}`, string(schemas[entity.SchemaRef{Name: "payments"}]))
+		assert.JSONEq(t, `{"type":"object"}`, string(schemas[entity.SchemaRef{Name: "payments", Version: 1}]))
+		assert.JSONEq(t, `{"type":"object"}`, string(schemas[entity.SchemaRef{Name: "routing", Version: 1}]), "deleted schemas keep their history")
+	})
+
+	t.Run("UpgradeBackfillsUnixNanos", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		config := entity.NewConfiguration("payments", json.RawMessage(`{}`))
+		require.NoError(t, repo.CreateConfiguration(context.Background(), config))
+		require.NoError(t, repo.StoreVersionData(context.Background(), "payments", 1, config.Data))
+		change := entity.NewScheduledChange("payments", json.RawMessage(`{}`), time.Now().Add(-time.Minute))
+		require.NoError(t, repo.CreateScheduledChange(context.Background(), change))
+
+		// Rows written before the columns existed are filled in when the database is opened
+		db := repo.(*ConfigurationRepository).db
+		_, err := db.Exec("UPDATE versions SET created_at_ns = NULL")
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE scheduled_changes SET effective_at_ns = NULL")
+		require.NoError(t, err)
+		require.NoError(t, initSchema(db))
+
+		result, err := repo.GetConfigurationAsOf(context.Background(), "payments", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Version)
+		due, err := repo.ListDueScheduledChanges(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		repo, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		require.NoError(t, repo.CreateConfiguration(context.Background(), entity.NewConfiguration("payments", json.RawMessage(`{}`))))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Reads and writes stop once the context is done
+		_, err := repo.GetConfiguration(ctx, "payments")
+		assert.ErrorIs(t, err, context.Canceled)
+
+		err = repo.CreateConfiguration(ctx, entity.NewConfiguration("routing", json.RawMessage(`{}`)))
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.GetConfiguration(context.Background(), "routing")
+		assert.Error(t, err, "nothing was written")
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		_, err = repo.ListConfigurationNames(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
 
 	t.Run("TransactionRollback", func(t *testing.T) {
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// GetRetentionPolicy retrieves the retention policy stored under a name
+func (r *ConfigurationRepository) GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error) {
+	policy := entity.RetentionPolicy{Name: name}
+	err := r.db.QueryRowContext(ctx,
+		"SELECT keep_last, keep_days FROM retention_policies WHERE name = ?",
+		name,
+	).Scan(&policy.KeepLast, &policy.KeepDays)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Retention policy", name)
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// SetRetentionPolicy creates or replaces a retention policy
+func (r *ConfigurationRepository) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setRetentionPolicy(ctx, tx, policy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setRetentionPolicy creates or replaces a retention policy within a transaction
+func setRetentionPolicy(ctx context.Context, tx *sql.Tx, policy *entity.RetentionPolicy) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO retention_policies (name, keep_last, keep_days) VALUES (?, ?, ?)",
+		policy.Name, policy.KeepLast, policy.KeepDays,
+	)
+	return err
+}
+
+// DeleteRetentionPolicy removes a retention policy
+func (r *ConfigurationRepository) DeleteRetentionPolicy(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM retention_policies WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("Retention policy", name)
+	}
+
+	return nil
+}
+
+// ListConfigurationNames lists the names of all configurations
+func (r *ConfigurationRepository) ListConfigurationNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM configurations ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// CompactVersions removes the data of the given versions while keeping their
+// metadata, so that requests for them can be answered with a clear error.
+// Tagged versions are never compacted.
+func (r *ConfigurationRepository) CompactVersions(ctx context.Context, name string, versions []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, version := range versions {
+		var tagged bool
+		err = tx.QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM version_tags WHERE name = ? AND version = ?)",
+			name, version,
+		).Scan(&tagged)
+		if err != nil {
+			return err
+		}
+		if tagged {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM version_data WHERE name = ? AND version = ?",
+			name, version,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE versions SET compacted = 1 WHERE name = ? AND version = ?",
+			name, version,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeduplicateVersionData moves inline payloads written before content addressing
+// into the blobs table and removes blobs no longer referenced by any version.
+// It returns the number of migrated rows and removed blobs.
+func (r *ConfigurationRepository) DeduplicateVersionData(ctx context.Context) (int, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT name, version, data FROM version_data WHERE content_hash IS NULL")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	type inlineRow struct {
+		name    string
+		version int
+		data    string
+	}
+	var inline []inlineRow
+	for rows.Next() {
+		var row inlineRow
+		if err := rows.Scan(&row.name, &row.version, &row.data); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		inline = append(inline, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, row := range inline {
+		hash := contentHash([]byte(row.data))
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO blobs (hash, data) VALUES (?, ?)", hash, row.data); err != nil {
+			return 0, 0, err
+		}
+		_, err := tx.ExecContext(ctx,
+			"UPDATE version_data SET data = '', content_hash = ? WHERE name = ? AND version = ?",
+			hash, row.name, row.version,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM blobs WHERE hash NOT IN (SELECT content_hash FROM version_data WHERE content_hash IS NOT NULL)")
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return len(inline), int(removed), nil
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"time"
+)
+
+// CreateRuleSet stores validation rules as the next rule set version of a configuration
+func (r *ConfigurationRepository) CreateRuleSet(ctx context.Context, configName string, ruleList []rules.Rule) (*entity.RuleSet, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ruleSet, err := createRuleSet(ctx, tx, configName, ruleList)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+// createRuleSet stores the next rule set version of a configuration within a transaction
+func createRuleSet(ctx context.Context, tx *sql.Tx, configName string, ruleList []rules.Rule) (*entity.RuleSet, error) {
+	encoded, err := json.Marshal(ruleList)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM rule_sets WHERE name = ?",
+		configName,
+	).Scan(&latest)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet := &entity.RuleSet{
+		Name:      configName,
+		Version:   latest + 1,
+		Rules:     ruleList,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO rule_sets (name, version, rules, created_at) VALUES (?, ?, ?, ?)",
+		configName, ruleSet.Version, string(encoded), ruleSet.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+// GetRuleSet retrieves the most recent rule set version of a configuration
+func (r *ConfigurationRepository) GetRuleSet(ctx context.Context, configName string) (*entity.RuleSet, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT name, version, rules, created_at FROM rule_sets WHERE name = ? ORDER BY version DESC LIMIT 1",
+		configName,
+	)
+
+	ruleSet, err := scanRuleSet(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Rule set", configName)
+		}
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+// GetRuleSetVersion retrieves a specific rule set version of a configuration
+func (r *ConfigurationRepository) GetRuleSetVersion(ctx context.Context, configName string, version int) (*entity.RuleSet, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT name, version, rules, created_at FROM rule_sets WHERE name = ? AND version = ?",
+		configName, version,
+	)
+
+	ruleSet, err := scanRuleSet(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Rule set version", fmt.Sprintf("%s:%d", configName, version))
+		}
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+// ListRuleSetVersions lists the rule set history of a configuration, oldest first
+func (r *ConfigurationRepository) ListRuleSetVersions(ctx context.Context, configName string) ([]*entity.RuleSet, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT name, version, rules, created_at FROM rule_sets WHERE name = ? ORDER BY version",
+		configName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ruleSets := []*entity.RuleSet{}
+	for rows.Next() {
+		ruleSet, err := scanRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, ruleSet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ruleSets) == 0 {
+		return nil, errors.NewNotFoundError("Rule set", configName)
+	}
+
+	return ruleSets, nil
+}
+
+// scanRuleSet scans a rule set version from a row
+func scanRuleSet(row rowScanner) (*entity.RuleSet, error) {
+	var ruleSet entity.RuleSet
+	var encoded string
+	if err := row.Scan(&ruleSet.Name, &ruleSet.Version, &encoded, &ruleSet.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(encoded), &ruleSet.Rules); err != nil {
+		return nil, err
+	}
+	return &ruleSet, nil
+}
@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"time"
+)
+
+const scheduledChangeColumns = "id, name, data, effective_at, status, created_at, updated_at, applied_version, error"
+
+// CreateScheduledChange persists a new scheduled change and assigns its ID
+func (r *ConfigurationRepository) CreateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO scheduled_changes (name, data, effective_at, effective_at_ns, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		change.Name, string(change.Data), change.EffectiveAt, change.EffectiveAt.UnixNano(), string(change.Status), change.CreatedAt, change.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	change.ID = id
+
+	return nil
+}
+
+// GetScheduledChange retrieves a scheduled change by ID
+func (r *ConfigurationRepository) GetScheduledChange(ctx context.Context, id int64) (*entity.ScheduledChange, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+scheduledChangeColumns+" FROM scheduled_changes WHERE id = ?",
+		id,
+	)
+
+	change, err := scanScheduledChange(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Scheduled change", fmt.Sprintf("%d", id))
+		}
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// ListScheduledChanges lists all scheduled changes for a configuration
+func (r *ConfigurationRepository) ListScheduledChanges(ctx context.Context, configName string) ([]*entity.ScheduledChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+scheduledChangeColumns+" FROM scheduled_changes WHERE name = ? ORDER BY effective_at_ns, id",
+		configName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*entity.ScheduledChange{}
+	for rows.Next() {
+		change, err := scanScheduledChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// ListDueScheduledChanges lists pending scheduled changes effective at or before the given time
+func (r *ConfigurationRepository) ListDueScheduledChanges(ctx context.Context, before time.Time) ([]*entity.ScheduledChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+scheduledChangeColumns+" FROM scheduled_changes WHERE status = ? AND effective_at_ns <= ? ORDER BY effective_at_ns, id",
+		string(entity.ScheduledChangeStatusPending), before.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*entity.ScheduledChange{}
+	for rows.Next() {
+		change, err := scanScheduledChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// ListStaleScheduledChanges lists scheduled changes claimed for applying before the given
+// time and never marked applied or failed, as left behind by a scheduler that stopped
+// while applying them
+func (r *ConfigurationRepository) ListStaleScheduledChanges(ctx context.Context, claimedBefore time.Time) ([]*entity.ScheduledChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+scheduledChangeColumns+" FROM scheduled_changes WHERE status = ? ORDER BY effective_at_ns, id",
+		string(entity.ScheduledChangeStatusApplying),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*entity.ScheduledChange{}
+	for rows.Next() {
+		change, err := scanScheduledChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		// A change's updated_at is the time it was claimed while it is applying
+		if change.UpdatedAt.Before(claimedBefore) {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, rows.Err()
+}
+
+// UpdateScheduledChange moves a scheduled change to its new status. The update only applies
+// while the change is still in the status it moves from, so a change cancelled while the
+// scheduler claims it ends up either cancelled or applied, never both.
+func (r *ConfigurationRepository) UpdateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error {
+	from := change.Status.PreviousStatus()
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE scheduled_changes SET status = ?, updated_at = ?, applied_version = ?, error = ? WHERE id = ? AND status = ?",
+		string(change.Status), change.UpdatedAt, change.AppliedVersion, change.Error, change.ID, string(from),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		current, err := r.GetScheduledChange(ctx, change.ID)
+		if err != nil {
+			return err
+		}
+		return errors.NewConflictError(fmt.Sprintf("Scheduled change is no longer %s", from), map[string]string{
+			"status": string(current.Status),
+		})
+	}
+
+	return nil
+}
+
+// rowScanner abstracts over *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanScheduledChange scans a scheduled_changes row into an entity
+func scanScheduledChange(row rowScanner) (*entity.ScheduledChange, error) {
+	var change entity.ScheduledChange
+	var dataStr, status string
+	var appliedVersion sql.NullInt64
+	var errorStr sql.NullString
+
+	err := row.Scan(
+		&change.ID,
+		&change.Name,
+		&dataStr,
+		&change.EffectiveAt,
+		&status,
+		&change.CreatedAt,
+		&change.UpdatedAt,
+		&appliedVersion,
+		&errorStr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Data = json.RawMessage(dataStr)
+	change.Status = entity.ScheduledChangeStatus(status)
+	if appliedVersion.Valid {
+		change.AppliedVersion = int(appliedVersion.Int64)
+	}
+	if errorStr.Valid {
+		change.Error = errorStr.String
+	}
+
+	return &change, nil
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// GetSchemaVersion retrieves a specific version of a configuration's schema
+func (r *ConfigurationRepository) GetSchemaVersion(ctx context.Context, configName string, version int) (*entity.SchemaVersion, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT name, version, schema, created_at FROM schema_versions WHERE name = ? AND version = ?",
+		configName, version,
+	)
+
+	result, err := scanSchemaVersion(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Schema version", fmt.Sprintf("%s:%d", configName, version))
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListSchemaVersions lists the schema history of a configuration, oldest first
+func (r *ConfigurationRepository) ListSchemaVersions(ctx context.Context, configName string) ([]*entity.SchemaVersion, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT name, version, schema, created_at FROM schema_versions WHERE name = ? ORDER BY version",
+		configName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*entity.SchemaVersion{}
+	for rows.Next() {
+		version, err := scanSchemaVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, errors.NewNotFoundError("Schema", configName)
+	}
+
+	return versions, nil
+}
+
+// scanSchemaVersion scans a schema version from a row
+func scanSchemaVersion(row rowScanner) (*entity.SchemaVersion, error) {
+	var version entity.SchemaVersion
+	var schema string
+	err := row.Scan(&version.Name, &version.Version, &schema, &version.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	version.Schema = json.RawMessage(schema)
+	return &version, nil
+}
+
+// ListSchemas lists the current schema of every configuration ordered by name,
+// returning one page and the total number of schemas
+func (r *ConfigurationRepository) ListSchemas(ctx context.Context, limit, offset int) ([]*entity.SchemaVersion, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schemas").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT s.name, s.version, s.schema, v.created_at
+		FROM schemas s JOIN schema_versions v ON v.name = s.name AND v.version = s.version
+		ORDER BY s.name LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	schemas := []*entity.SchemaVersion{}
+	for rows.Next() {
+		schema, err := scanSchemaVersion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, total, rows.Err()
+}
+
+// DeleteSchema detaches the current schema from a configuration. The schema history is
+// kept, so a schema registered later continues the version numbering.
+func (r *ConfigurationRepository) DeleteSchema(ctx context.Context, configName string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM schemas WHERE name = ?", configName)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("Schema", configName)
+	}
+
+	return nil
+}
+
+// ListUnvalidatedConfigurations lists the configurations whose current version is not
+// validated by a schema, ordered by name
+func (r *ConfigurationRepository) ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.name, c.version, s.name IS NULL
+		FROM configurations c
+		JOIN versions v ON v.name = c.name AND v.version = c.version
+		LEFT JOIN schemas s ON s.name = c.name
+		WHERE s.name IS NULL OR v.schema_version IS NULL
+		ORDER BY c.name`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	configs := []*entity.UnvalidatedConfiguration{}
+	for rows.Next() {
+		var config entity.UnvalidatedConfiguration
+		var noSchema bool
+		if err := rows.Scan(&config.Name, &config.Version, &noSchema); err != nil {
+			return nil, err
+		}
+		config.Reason = entity.UnvalidatedReasonNotValidated
+		if noSchema {
+			config.Reason = entity.UnvalidatedReasonNoSchema
+		}
+		configs = append(configs, &config)
+	}
+
+	return configs, rows.Err()
+}
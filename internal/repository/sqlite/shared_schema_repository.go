@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"time"
+)
+
+// SetSharedSchema creates or replaces a shared schema fragment, keeping its creation time
+func (r *ConfigurationRepository) SetSharedSchema(ctx context.Context, schema *entity.SharedSchema) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setSharedSchema(ctx, tx, schema); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setSharedSchema creates or replaces a shared schema fragment within a transaction
+func setSharedSchema(ctx context.Context, tx *sql.Tx, schema *entity.SharedSchema) error {
+	now := time.Now().UTC()
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO shared_schemas (name, schema, created_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET schema = excluded.schema, updated_at = excluded.updated_at`,
+		schema.Name, string(schema.Schema), now, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRowContext(ctx,
+		"SELECT created_at, updated_at FROM shared_schemas WHERE name = ?",
+		schema.Name,
+	).Scan(&schema.CreatedAt, &schema.UpdatedAt)
+}
+
+// GetSharedSchema retrieves a shared schema fragment by name
+func (r *ConfigurationRepository) GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT name, schema, created_at, updated_at FROM shared_schemas WHERE name = ?",
+		name,
+	)
+
+	schema, err := scanSharedSchema(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Shared schema", name)
+		}
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// ListSharedSchemas lists all shared schema fragments ordered by name
+func (r *ConfigurationRepository) ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name, schema, created_at, updated_at FROM shared_schemas ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []*entity.SharedSchema{}
+	for rows.Next() {
+		schema, err := scanSharedSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// DeleteSharedSchema removes a shared schema fragment
+func (r *ConfigurationRepository) DeleteSharedSchema(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM shared_schemas WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("Shared schema", name)
+	}
+
+	return nil
+}
+
+// scanSharedSchema scans a shared schema fragment from a row
+func scanSharedSchema(row rowScanner) (*entity.SharedSchema, error) {
+	var shared entity.SharedSchema
+	var schema string
+	err := row.Scan(&shared.Name, &schema, &shared.CreatedAt, &shared.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	shared.Schema = json.RawMessage(schema)
+	return &shared, nil
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// SetVersionTag creates a version tag or moves a mutable one. The version is checked to
+// still have its data in the same transaction, so the compactor cannot remove it in
+// between, and an immutable tag is never moved or replaced, even by a concurrent writer.
+// Setting an immutable tag to the version it already points at changes nothing.
+func (r *ConfigurationRepository) SetVersionTag(ctx context.Context, tag *entity.VersionTag) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var compacted bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT compacted FROM versions WHERE name = ? AND version = ?",
+		tag.Name, tag.Version,
+	).Scan(&compacted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("Configuration version", tag.Name)
+		}
+		return err
+	}
+	if compacted {
+		return errors.NewVersionCompactedError(tag.Name, tag.Version)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO version_tags (name, tag, version, immutable, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name, tag) DO UPDATE SET
+			version = excluded.version,
+			immutable = excluded.immutable,
+			updated_at = excluded.updated_at
+		WHERE version_tags.immutable = 0`,
+		tag.Name, tag.Tag, tag.Version, tag.Immutable, tag.CreatedAt, tag.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		// The tag is immutable
+		current, err := scanVersionTag(tx.QueryRowContext(ctx,
+			`SELECT name, tag, version, immutable, created_at, updated_at
+			FROM version_tags WHERE name = ? AND tag = ?`,
+			tag.Name, tag.Tag,
+		))
+		if err != nil {
+			return err
+		}
+		if current.Version != tag.Version {
+			return errors.NewConflictError("Tag is immutable and cannot be moved", map[string]interface{}{
+				"tag":     tag.Tag,
+				"version": current.Version,
+			})
+		}
+		*tag = *current
+	}
+
+	return tx.Commit()
+}
+
+// GetVersionTag retrieves a version tag of a configuration
+func (r *ConfigurationRepository) GetVersionTag(ctx context.Context, name, tag string) (*entity.VersionTag, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT name, tag, version, immutable, created_at, updated_at
+		FROM version_tags WHERE name = ? AND tag = ?`,
+		name, tag,
+	)
+
+	result, err := scanVersionTag(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("Tag", tag)
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListVersionTags lists the tags of a configuration
+func (r *ConfigurationRepository) ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, tag, version, immutable, created_at, updated_at
+		FROM version_tags WHERE name = ? ORDER BY tag`,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*entity.VersionTag{}
+	for rows.Next() {
+		tag, err := scanVersionTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// DeleteVersionTag removes a version tag
+func (r *ConfigurationRepository) DeleteVersionTag(ctx context.Context, name, tag string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM version_tags WHERE name = ? AND tag = ?", name, tag)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("Tag", tag)
+	}
+
+	return nil
+}
+
+// scanVersionTag scans a version tag from a row
+func scanVersionTag(row rowScanner) (*entity.VersionTag, error) {
+	var tag entity.VersionTag
+	err := row.Scan(&tag.Name, &tag.Tag, &tag.Version, &tag.Immutable, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
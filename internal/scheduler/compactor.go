@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"log"
+	"time"
+)
+
+// HistoryCompactor enforces retention policies on configuration history
+type HistoryCompactor interface {
+	CompactAll(ctx context.Context, now time.Time) (*entity.CompactionSummary, error)
+}
+
+// Compactor periodically enforces version retention policies and deduplicates
+// stored payloads in-process
+type Compactor struct {
+	*runner
+	compactor HistoryCompactor
+	now       func() time.Time
+}
+
+// NewCompactor creates a new compactor that runs at the given interval, first one
+// interval after it is started
+func NewCompactor(compactor HistoryCompactor, interval time.Duration) *Compactor {
+	c := &Compactor{
+		compactor: compactor,
+		now:       time.Now,
+	}
+	c.runner = newRunner(c.RunOnce, interval, false)
+	return c
+}
+
+// RunOnce performs a single compaction pass
+func (c *Compactor) RunOnce() {
+	summary, err := c.compactor.CompactAll(context.Background(), c.now().UTC())
+	if err != nil {
+		log.Printf("[Compactor] Failed to compact configuration history: %v", err)
+	}
+	if summary == nil {
+		return
+	}
+
+	for _, result := range summary.Configurations {
+		log.Printf("[Compactor] Compacted %d versions of %s", len(result.CompactedVersions), result.Name)
+	}
+	if summary.MigratedPayloads > 0 || summary.RemovedPayloads > 0 {
+		log.Printf("[Compactor] Deduplicated %d payloads, removed %d unreferenced payloads", summary.MigratedPayloads, summary.RemovedPayloads)
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCompactor records the times it was asked to compact history
+type fakeCompactor struct {
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (f *fakeCompactor) CompactAll(ctx context.Context, now time.Time) (*entity.CompactionSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, now)
+	return &entity.CompactionSummary{}, nil
+}
+
+func (f *fakeCompactor) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestCompactor(t *testing.T) {
+	t.Run("RunOnceUsesCurrentTime", func(t *testing.T) {
+		compactor := &fakeCompactor{}
+		c := NewCompactor(compactor, time.Hour)
+		fixed := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		c.now = func() time.Time { return fixed }
+
+		c.RunOnce()
+
+		assert.Equal(t, []time.Time{fixed}, compactor.calls)
+	})
+
+	t.Run("RunsPeriodicallyUntilStopped", func(t *testing.T) {
+		compactor := &fakeCompactor{}
+		c := NewCompactor(compactor, 10*time.Millisecond)
+
+		c.Start()
+		assert.Eventually(t, func() bool { return compactor.callCount() >= 2 }, time.Second, 5*time.Millisecond)
+		c.Stop()
+
+		// No further runs after Stop returns
+		count := compactor.callCount()
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, count, compactor.callCount())
+	})
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// runner calls a job at a fixed interval in a background goroutine until stopped. It is
+// shared by the background jobs, which only differ in what they run.
+type runner struct {
+	job       func()
+	interval  time.Duration
+	immediate bool
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newRunner creates a runner calling job every interval, and once right away on start if
+// immediate is set
+func newRunner(job func(), interval time.Duration, immediate bool) *runner {
+	return &runner{
+		job:       job,
+		interval:  interval,
+		immediate: immediate,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the loop in a background goroutine
+func (r *runner) Start() {
+	go r.run()
+}
+
+// Stop stops the loop and waits for a run in progress to finish
+func (r *runner) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+// run is the loop calling the job
+func (r *runner) run() {
+	defer close(r.done)
+
+	if r.immediate {
+		r.job()
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.job()
+		case <-r.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner(t *testing.T) {
+	t.Run("ImmediateRunsOnStart", func(t *testing.T) {
+		var runs atomic.Int32
+		r := newRunner(func() { runs.Add(1) }, time.Hour, true)
+
+		r.Start()
+		assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 5*time.Millisecond)
+		r.Stop()
+	})
+
+	t.Run("WaitsForFirstInterval", func(t *testing.T) {
+		var runs atomic.Int32
+		r := newRunner(func() { runs.Add(1) }, time.Hour, false)
+
+		r.Start()
+		time.Sleep(20 * time.Millisecond)
+		r.Stop()
+		assert.Zero(t, runs.Load())
+	})
+
+	t.Run("StopWaitsForRunInProgress", func(t *testing.T) {
+		started := make(chan struct{})
+		var finished atomic.Bool
+		r := newRunner(func() {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+		}, time.Hour, true)
+
+		r.Start()
+		<-started
+		r.Stop()
+		assert.True(t, finished.Load())
+	})
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"log"
+	"time"
+)
+
+// ChangeApplier applies scheduled configuration changes that have become due
+type ChangeApplier interface {
+	ApplyDueScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error)
+}
+
+// Scheduler periodically applies due scheduled changes in-process.
+// Pending changes live in the repository, so changes that fell due while
+// the service was down are applied on the first run after a restart.
+type Scheduler struct {
+	*runner
+	applier ChangeApplier
+	now     func() time.Time
+}
+
+// NewScheduler creates a new scheduler that polls for due changes at the given interval.
+// It catches up on anything that fell due while the service was not running as soon as
+// it is started.
+func NewScheduler(applier ChangeApplier, interval time.Duration) *Scheduler {
+	s := &Scheduler{
+		applier: applier,
+		now:     time.Now,
+	}
+	s.runner = newRunner(s.RunOnce, interval, true)
+	return s
+}
+
+// RunOnce applies all changes that are due right now
+func (s *Scheduler) RunOnce() {
+	changes, err := s.applier.ApplyDueScheduledChanges(context.Background(), s.now().UTC())
+	if err != nil {
+		log.Printf("[Scheduler] Failed to apply scheduled changes: %v", err)
+	}
+
+	for _, change := range changes {
+		if change.Status == entity.ScheduledChangeStatusApplied {
+			log.Printf("[Scheduler] Applied scheduled change %d to %s as version %d", change.ID, change.Name, change.AppliedVersion)
+		} else {
+			log.Printf("[Scheduler] Scheduled change %d to %s failed: %s", change.ID, change.Name, change.Error)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeApplier records the times it was asked to apply changes
+type fakeApplier struct {
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (f *fakeApplier) ApplyDueScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, now)
+	return nil, nil
+}
+
+func (f *fakeApplier) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("RunOnceUsesCurrentTime", func(t *testing.T) {
+		applier := &fakeApplier{}
+		s := NewScheduler(applier, time.Hour)
+		fixed := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return fixed }
+
+		s.RunOnce()
+
+		assert.Equal(t, []time.Time{fixed}, applier.calls)
+	})
+
+	t.Run("CatchesUpOnStartAndPolls", func(t *testing.T) {
+		applier := &fakeApplier{}
+		s := NewScheduler(applier, 10*time.Millisecond)
+
+		s.Start()
+		assert.Eventually(t, func() bool { return applier.callCount() >= 3 }, time.Second, 5*time.Millisecond)
+		s.Stop()
+
+		// No further runs after Stop returns
+		count := applier.callCount()
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, count, applier.callCount())
+	})
+}
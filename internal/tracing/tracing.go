@@ -0,0 +1,128 @@
+// Package tracing sets up OpenTelemetry tracing for the service and instruments the
+// usecase and repository layers with spans. Spans are exported over OTLP/gRPC to a
+// collector or written to stdout for local use.
+package tracing
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName names the tracers of the service's own instrumentation
+const instrumentationName = "github.com/Titonu/configuration-management-service"
+
+// Exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config configures how spans are exported
+type Config struct {
+	// Exporter is one of none, stdout or otlp
+	Exporter string
+	// Endpoint is the URL of the OTLP/gRPC collector, e.g. http://localhost:4317. When
+	// empty the exporter reads the standard OTEL_EXPORTER_OTLP_* variables.
+	Endpoint string
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are sampled. Requests that carry a
+	// traceparent follow the sampling decision of the caller.
+	SampleRatio float64
+}
+
+// Provider creates the tracers of the service and exports their spans
+type Provider struct {
+	trace.TracerProvider
+	shutdown func(context.Context) error
+}
+
+// NewProvider creates a tracer provider exporting spans as configured. With the none
+// exporter spans are not recorded at all.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	var exporter sdktrace.SpanExporter
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+		return &Provider{
+			TracerProvider: noop.NewTracerProvider(),
+			shutdown:       func(context.Context) error { return nil },
+		}, nil
+	case ExporterStdout:
+		var err error
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+	case ExporterOTLP:
+		var opts []otlptracegrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+		}
+		var err error
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, use none, stdout or otlp", cfg.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	return &Provider{TracerProvider: provider, shutdown: provider.Shutdown}, nil
+}
+
+// Shutdown exports the spans that are still buffered and stops the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+// Propagator reads and writes the W3C traceparent and tracestate headers
+func Propagator() propagation.TextMapPropagator {
+	return propagation.TraceContext{}
+}
+
+// Tracer returns the tracer of the service's own instrumentation
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	return provider.Tracer(instrumentationName)
+}
+
+// end records the outcome of an operation on its span and ends it. Errors the service
+// reports as API errors, such as a configuration that does not exist, are recorded
+// without marking the span as failed, unless they are internal errors.
+func end(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		var appErr *errors.AppError
+		if !stdErrors.As(*err, &appErr) || appErr.Code == errors.ErrorCodeInternalError {
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+	}
+	span.End()
+}
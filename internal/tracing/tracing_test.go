@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	"github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newRecorder creates a tracer provider that records every span in memory
+func newRecorder() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+// newRepository creates a repository on a temporary database
+func newRepository(t *testing.T) repository.ConfigurationRepository {
+	repo, err := sqlite.NewConfigurationRepository(filepath.Join(t.TempDir(), "config.db"))
+	require.NoError(t, err)
+	return repo
+}
+
+// spanNamed returns the ended span with the given name
+func spanNamed(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %s", name)
+	return nil
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider(context.Background(), Config{})
+	require.NoError(t, err)
+	_, span := Tracer(provider).Start(context.Background(), "operation")
+	assert.False(t, span.IsRecording(), "spans are not recorded without an exporter")
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	provider, err = NewProvider(context.Background(), Config{Exporter: ExporterStdout, ServiceName: "test", SampleRatio: 1})
+	require.NoError(t, err)
+	_, span = Tracer(provider).Start(context.Background(), "operation")
+	assert.True(t, span.IsRecording())
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	_, err = NewProvider(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestInstrumentedUsecase(t *testing.T) {
+	provider, recorder := newRecorder()
+	configService := NewUsecase(
+		usecase.NewConfigurationUseCase(NewRepository(newRepository(t), provider), usecase.WithTracer(Tracer(provider))),
+		provider,
+	)
+
+	_, err := configService.CreateConfiguration(context.Background(), "payments", json.RawMessage(`{"max_limit": 1000}`))
+	require.NoError(t, err)
+	_, err = configService.RegisterSchema(context.Background(), "payments", json.RawMessage(`{"type": "object", "properties": {"max_limit": {"maximum": 5000}}}`), entity.SchemaRegistrationOptions{})
+	require.NoError(t, err)
+
+	recorder.Reset()
+	_, err = configService.UpdateConfiguration(context.Background(), "payments", json.RawMessage(`{"max_limit": 9000}`))
+	require.Error(t, err)
+
+	// Repository and validation spans are children of the usecase span
+	update := spanNamed(t, recorder, "ConfigurationUsecase.UpdateConfiguration")
+	read := spanNamed(t, recorder, "ConfigurationRepository.GetConfiguration")
+	validation := spanNamed(t, recorder, "Validator.ValidateConfiguration")
+	assert.Equal(t, update.SpanContext().SpanID(), read.Parent().SpanID())
+	assert.Equal(t, update.SpanContext().SpanID(), validation.Parent().SpanID())
+	assert.Equal(t, update.SpanContext().TraceID(), validation.SpanContext().TraceID())
+
+	// Rejected data is an expected outcome, not a failed operation
+	assert.Equal(t, codes.Unset, update.Status().Code)
+	assert.Equal(t, codes.Unset, validation.Status().Code)
+	assert.Len(t, update.Events(), 1, "the error is recorded as an event")
+}
+
+func TestRepositoryRecordsStorageErrors(t *testing.T) {
+	provider, recorder := newRecorder()
+	inner := newRepository(t)
+	repo := NewRepository(inner, provider)
+
+	// A configuration that does not exist is an expected outcome
+	_, err := repo.GetConfiguration(context.Background(), "missing")
+	require.Error(t, err)
+	assert.Equal(t, codes.Unset, spanNamed(t, recorder, "ConfigurationRepository.GetConfiguration").Status().Code)
+
+	require.NoError(t, inner.(interface{ Close() error }).Close())
+	_, err = repo.ListConfigurationNames(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, codes.Error, spanNamed(t, recorder, "ConfigurationRepository.ListConfigurationNames").Status().Code)
+}
@@ -0,0 +1,404 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Usecase is a configuration usecase that records a span for every operation. The
+// repository and validation spans of an operation are children of its span.
+type Usecase struct {
+	usecase usecase.ConfigurationUsecase
+	tracer  trace.Tracer
+}
+
+// NewUsecase instruments a configuration usecase
+func NewUsecase(uc usecase.ConfigurationUsecase, provider trace.TracerProvider) usecase.ConfigurationUsecase {
+	return &Usecase{usecase: uc, tracer: Tracer(provider)}
+}
+
+// start starts the span of a usecase operation
+func (u *Usecase) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return u.tracer.Start(ctx, "ConfigurationUsecase."+method)
+}
+
+// CreateConfiguration creates a new configuration
+func (u *Usecase) CreateConfiguration(ctx context.Context, name string, data json.RawMessage) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "CreateConfiguration")
+	defer end(span, &err)
+	return u.usecase.CreateConfiguration(ctx, name, data)
+}
+
+// UpdateConfiguration updates an existing configuration
+func (u *Usecase) UpdateConfiguration(ctx context.Context, name string, data json.RawMessage) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "UpdateConfiguration")
+	defer end(span, &err)
+	return u.usecase.UpdateConfiguration(ctx, name, data)
+}
+
+// GetConfiguration retrieves a configuration by name
+func (u *Usecase) GetConfiguration(ctx context.Context, name string) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "GetConfiguration")
+	defer end(span, &err)
+	return u.usecase.GetConfiguration(ctx, name)
+}
+
+// GetConfigurationVersion retrieves a specific version of a configuration
+func (u *Usecase) GetConfigurationVersion(ctx context.Context, name string, version int) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "GetConfigurationVersion")
+	defer end(span, &err)
+	return u.usecase.GetConfigurationVersion(ctx, name, version)
+}
+
+// ListConfigurationVersions lists all versions of a configuration
+func (u *Usecase) ListConfigurationVersions(ctx context.Context, name string) (result *entity.VersionList, err error) {
+	ctx, span := u.start(ctx, "ListConfigurationVersions")
+	defer end(span, &err)
+	return u.usecase.ListConfigurationVersions(ctx, name)
+}
+
+// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+func (u *Usecase) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "GetConfigurationAsOf")
+	defer end(span, &err)
+	return u.usecase.GetConfigurationAsOf(ctx, name, asOf)
+}
+
+// ListConfigurationsAsOf retrieves the effective version of every configuration at the given time
+func (u *Usecase) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) (result []entity.BulkReadItem, err error) {
+	ctx, span := u.start(ctx, "ListConfigurationsAsOf")
+	defer end(span, &err)
+	return u.usecase.ListConfigurationsAsOf(ctx, asOf)
+}
+
+// GetConfigurations reads the current or a given version of many configurations at once,
+// reporting configurations that cannot be read per item
+func (u *Usecase) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) (result []entity.BulkReadItem, err error) {
+	ctx, span := u.start(ctx, "GetConfigurations")
+	defer end(span, &err)
+	return u.usecase.GetConfigurations(ctx, refs)
+}
+
+// RollbackConfiguration rolls back a configuration to a previous version
+func (u *Usecase) RollbackConfiguration(ctx context.Context, name string, targetVersion int) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "RollbackConfiguration")
+	defer end(span, &err)
+	return u.usecase.RollbackConfiguration(ctx, name, targetVersion)
+}
+
+// RegisterSchema registers a JSON schema as the next schema version of a configuration
+// after checking its compatibility and that existing configuration data conforms to it
+func (u *Usecase) RegisterSchema(ctx context.Context, configName string, schema json.RawMessage, opts entity.SchemaRegistrationOptions) (result *entity.SchemaVersion, err error) {
+	ctx, span := u.start(ctx, "RegisterSchema")
+	defer end(span, &err)
+	return u.usecase.RegisterSchema(ctx, configName, schema, opts)
+}
+
+// AnalyzeSchemaImpact reports how a proposed schema would affect a configuration without registering it
+func (u *Usecase) AnalyzeSchemaImpact(ctx context.Context, configName string, schema json.RawMessage, checkVersions int) (result *entity.SchemaImpact, err error) {
+	ctx, span := u.start(ctx, "AnalyzeSchemaImpact")
+	defer end(span, &err)
+	return u.usecase.AnalyzeSchemaImpact(ctx, configName, schema, checkVersions)
+}
+
+// GetSchema retrieves the current JSON schema for a configuration
+func (u *Usecase) GetSchema(ctx context.Context, configName string) (result json.RawMessage, err error) {
+	ctx, span := u.start(ctx, "GetSchema")
+	defer end(span, &err)
+	return u.usecase.GetSchema(ctx, configName)
+}
+
+// GetSchemaVersion retrieves a specific version of a configuration's schema
+func (u *Usecase) GetSchemaVersion(ctx context.Context, configName string, version int) (result *entity.SchemaVersion, err error) {
+	ctx, span := u.start(ctx, "GetSchemaVersion")
+	defer end(span, &err)
+	return u.usecase.GetSchemaVersion(ctx, configName, version)
+}
+
+// ListSchemaVersions lists the schema history of a configuration
+func (u *Usecase) ListSchemaVersions(ctx context.Context, configName string) (result []*entity.SchemaVersion, err error) {
+	ctx, span := u.start(ctx, "ListSchemaVersions")
+	defer end(span, &err)
+	return u.usecase.ListSchemaVersions(ctx, configName)
+}
+
+// ListSchemas lists one page of the current schemas of all configurations
+func (u *Usecase) ListSchemas(ctx context.Context, limit, offset int) (result *entity.SchemaList, err error) {
+	ctx, span := u.start(ctx, "ListSchemas")
+	defer end(span, &err)
+	return u.usecase.ListSchemas(ctx, limit, offset)
+}
+
+// DeleteSchema detaches the schema from a configuration
+func (u *Usecase) DeleteSchema(ctx context.Context, configName string) (err error) {
+	ctx, span := u.start(ctx, "DeleteSchema")
+	defer end(span, &err)
+	return u.usecase.DeleteSchema(ctx, configName)
+}
+
+// ListUnvalidatedConfigurations lists configurations whose current data is not validated by a schema
+func (u *Usecase) ListUnvalidatedConfigurations(ctx context.Context) (result []*entity.UnvalidatedConfiguration, err error) {
+	ctx, span := u.start(ctx, "ListUnvalidatedConfigurations")
+	defer end(span, &err)
+	return u.usecase.ListUnvalidatedConfigurations(ctx)
+}
+
+// RegisterSharedSchema creates or replaces a shared schema fragment, re-validating dependent configurations unless forced
+func (u *Usecase) RegisterSharedSchema(ctx context.Context, name string, schema json.RawMessage, force bool) (result *entity.SharedSchema, err error) {
+	ctx, span := u.start(ctx, "RegisterSharedSchema")
+	defer end(span, &err)
+	return u.usecase.RegisterSharedSchema(ctx, name, schema, force)
+}
+
+// GetSharedSchema retrieves a shared schema fragment
+func (u *Usecase) GetSharedSchema(ctx context.Context, name string) (result *entity.SharedSchema, err error) {
+	ctx, span := u.start(ctx, "GetSharedSchema")
+	defer end(span, &err)
+	return u.usecase.GetSharedSchema(ctx, name)
+}
+
+// ListSharedSchemas lists all shared schema fragments
+func (u *Usecase) ListSharedSchemas(ctx context.Context) (result []*entity.SharedSchema, err error) {
+	ctx, span := u.start(ctx, "ListSharedSchemas")
+	defer end(span, &err)
+	return u.usecase.ListSharedSchemas(ctx)
+}
+
+// DeleteSharedSchema removes a shared schema fragment that no schema references
+func (u *Usecase) DeleteSharedSchema(ctx context.Context, name string) (err error) {
+	ctx, span := u.start(ctx, "DeleteSharedSchema")
+	defer end(span, &err)
+	return u.usecase.DeleteSharedSchema(ctx, name)
+}
+
+// SetRuleSet stores validation rules as the next rule set version of a configuration
+func (u *Usecase) SetRuleSet(ctx context.Context, configName string, ruleList []rules.Rule, force bool) (result *entity.RuleSet, err error) {
+	ctx, span := u.start(ctx, "SetRuleSet")
+	defer end(span, &err)
+	return u.usecase.SetRuleSet(ctx, configName, ruleList, force)
+}
+
+// GetRuleSet retrieves the current rule set of a configuration
+func (u *Usecase) GetRuleSet(ctx context.Context, configName string) (result *entity.RuleSet, err error) {
+	ctx, span := u.start(ctx, "GetRuleSet")
+	defer end(span, &err)
+	return u.usecase.GetRuleSet(ctx, configName)
+}
+
+// GetRuleSetVersion retrieves a specific rule set version of a configuration
+func (u *Usecase) GetRuleSetVersion(ctx context.Context, configName string, version int) (result *entity.RuleSet, err error) {
+	ctx, span := u.start(ctx, "GetRuleSetVersion")
+	defer end(span, &err)
+	return u.usecase.GetRuleSetVersion(ctx, configName, version)
+}
+
+// ListRuleSetVersions lists the rule set history of a configuration
+func (u *Usecase) ListRuleSetVersions(ctx context.Context, configName string) (result []*entity.RuleSet, err error) {
+	ctx, span := u.start(ctx, "ListRuleSetVersions")
+	defer end(span, &err)
+	return u.usecase.ListRuleSetVersions(ctx, configName)
+}
+
+// TestRules evaluates validation rules against configuration data without storing anything
+func (u *Usecase) TestRules(ctx context.Context, configName string, ruleList []rules.Rule, data json.RawMessage) (result *entity.RuleTestResult, err error) {
+	ctx, span := u.start(ctx, "TestRules")
+	defer end(span, &err)
+	return u.usecase.TestRules(ctx, configName, ruleList, data)
+}
+
+// ValidateConfigurationData validates configuration data against its schema
+func (u *Usecase) ValidateConfigurationData(ctx context.Context, configName string, data json.RawMessage) (err error) {
+	ctx, span := u.start(ctx, "ValidateConfigurationData")
+	defer end(span, &err)
+	return u.usecase.ValidateConfigurationData(ctx, configName, data)
+}
+
+// ValidateDocument reports every schema and rule violation in configuration data without storing anything
+func (u *Usecase) ValidateDocument(ctx context.Context, configName string, data json.RawMessage) (result *entity.ValidationReport, err error) {
+	ctx, span := u.start(ctx, "ValidateDocument")
+	defer end(span, &err)
+	return u.usecase.ValidateDocument(ctx, configName, data)
+}
+
+// ValidateAgainstSchema reports every violation of an ad-hoc schema in a document without storing anything
+func (u *Usecase) ValidateAgainstSchema(ctx context.Context, schema json.RawMessage, data json.RawMessage) (result *entity.ValidationReport, err error) {
+	ctx, span := u.start(ctx, "ValidateAgainstSchema")
+	defer end(span, &err)
+	return u.usecase.ValidateAgainstSchema(ctx, schema, data)
+}
+
+// ScheduleConfigurationChange stages a configuration update to go live at a future time
+func (u *Usecase) ScheduleConfigurationChange(ctx context.Context, name string, data json.RawMessage, effectiveAt time.Time) (result *entity.ScheduledChange, err error) {
+	ctx, span := u.start(ctx, "ScheduleConfigurationChange")
+	defer end(span, &err)
+	return u.usecase.ScheduleConfigurationChange(ctx, name, data, effectiveAt)
+}
+
+// ListScheduledChanges lists the scheduled changes of a configuration
+func (u *Usecase) ListScheduledChanges(ctx context.Context, name string) (result []*entity.ScheduledChange, err error) {
+	ctx, span := u.start(ctx, "ListScheduledChanges")
+	defer end(span, &err)
+	return u.usecase.ListScheduledChanges(ctx, name)
+}
+
+// CancelScheduledChange cancels a pending scheduled change
+func (u *Usecase) CancelScheduledChange(ctx context.Context, name string, id int64) (result *entity.ScheduledChange, err error) {
+	ctx, span := u.start(ctx, "CancelScheduledChange")
+	defer end(span, &err)
+	return u.usecase.CancelScheduledChange(ctx, name, id)
+}
+
+// ApplyDueScheduledChanges applies all pending scheduled changes that are due at the given time
+func (u *Usecase) ApplyDueScheduledChanges(ctx context.Context, now time.Time) (result []*entity.ScheduledChange, err error) {
+	ctx, span := u.start(ctx, "ApplyDueScheduledChanges")
+	defer end(span, &err)
+	return u.usecase.ApplyDueScheduledChanges(ctx, now)
+}
+
+// GetRetentionPolicy retrieves a retention policy by configuration name or the global policy name
+func (u *Usecase) GetRetentionPolicy(ctx context.Context, name string) (result *entity.RetentionPolicy, err error) {
+	ctx, span := u.start(ctx, "GetRetentionPolicy")
+	defer end(span, &err)
+	return u.usecase.GetRetentionPolicy(ctx, name)
+}
+
+// SetRetentionPolicy creates or replaces a retention policy
+func (u *Usecase) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) (result *entity.RetentionPolicy, err error) {
+	ctx, span := u.start(ctx, "SetRetentionPolicy")
+	defer end(span, &err)
+	return u.usecase.SetRetentionPolicy(ctx, policy)
+}
+
+// DeleteRetentionPolicy removes a retention policy
+func (u *Usecase) DeleteRetentionPolicy(ctx context.Context, name string) (err error) {
+	ctx, span := u.start(ctx, "DeleteRetentionPolicy")
+	defer end(span, &err)
+	return u.usecase.DeleteRetentionPolicy(ctx, name)
+}
+
+// CompactConfiguration enforces the effective retention policy on a configuration
+func (u *Usecase) CompactConfiguration(ctx context.Context, name string, now time.Time) (result *entity.CompactionResult, err error) {
+	ctx, span := u.start(ctx, "CompactConfiguration")
+	defer end(span, &err)
+	return u.usecase.CompactConfiguration(ctx, name, now)
+}
+
+// CompactAll enforces retention policies on every configuration and deduplicates stored payloads
+func (u *Usecase) CompactAll(ctx context.Context, now time.Time) (result *entity.CompactionSummary, err error) {
+	ctx, span := u.start(ctx, "CompactAll")
+	defer end(span, &err)
+	return u.usecase.CompactAll(ctx, now)
+}
+
+// TagVersion attaches a tag to a configuration version, moving it if it is movable
+func (u *Usecase) TagVersion(ctx context.Context, name, tag string, version int, immutable bool) (result *entity.VersionTag, err error) {
+	ctx, span := u.start(ctx, "TagVersion")
+	defer end(span, &err)
+	return u.usecase.TagVersion(ctx, name, tag, version, immutable)
+}
+
+// ListVersionTags lists the tags of a configuration
+func (u *Usecase) ListVersionTags(ctx context.Context, name string) (result []*entity.VersionTag, err error) {
+	ctx, span := u.start(ctx, "ListVersionTags")
+	defer end(span, &err)
+	return u.usecase.ListVersionTags(ctx, name)
+}
+
+// DeleteVersionTag removes a movable tag
+func (u *Usecase) DeleteVersionTag(ctx context.Context, name, tag string) (err error) {
+	ctx, span := u.start(ctx, "DeleteVersionTag")
+	defer end(span, &err)
+	return u.usecase.DeleteVersionTag(ctx, name, tag)
+}
+
+// GetConfigurationByTag retrieves the configuration version a tag points at
+func (u *Usecase) GetConfigurationByTag(ctx context.Context, name, tag string) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "GetConfigurationByTag")
+	defer end(span, &err)
+	return u.usecase.GetConfigurationByTag(ctx, name, tag)
+}
+
+// RollbackConfigurationToTag rolls back a configuration to the version a tag points at
+func (u *Usecase) RollbackConfigurationToTag(ctx context.Context, name, tag string) (result *entity.Configuration, err error) {
+	ctx, span := u.start(ctx, "RollbackConfigurationToTag")
+	defer end(span, &err)
+	return u.usecase.RollbackConfigurationToTag(ctx, name, tag)
+}
+
+// CreateChangeRequest proposes a configuration update that goes live once approved
+func (u *Usecase) CreateChangeRequest(ctx context.Context, name string, data json.RawMessage, author string) (result *entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "CreateChangeRequest")
+	defer end(span, &err)
+	return u.usecase.CreateChangeRequest(ctx, name, data, author)
+}
+
+// GetChangeRequest retrieves a change request of a configuration
+func (u *Usecase) GetChangeRequest(ctx context.Context, name string, id int64) (result *entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "GetChangeRequest")
+	defer end(span, &err)
+	return u.usecase.GetChangeRequest(ctx, name, id)
+}
+
+// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+func (u *Usecase) ListChangeRequests(ctx context.Context, name string, status entity.ChangeRequestStatus) (result []*entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "ListChangeRequests")
+	defer end(span, &err)
+	return u.usecase.ListChangeRequests(ctx, name, status)
+}
+
+// ApproveChangeRequest approves a change request, applying it once enough approvals are collected
+func (u *Usecase) ApproveChangeRequest(ctx context.Context, name string, id int64, clientID string) (result *entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "ApproveChangeRequest")
+	defer end(span, &err)
+	return u.usecase.ApproveChangeRequest(ctx, name, id, clientID)
+}
+
+// RejectChangeRequest closes a pending change request without applying it
+func (u *Usecase) RejectChangeRequest(ctx context.Context, name string, id int64, clientID string, reason string) (result *entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "RejectChangeRequest")
+	defer end(span, &err)
+	return u.usecase.RejectChangeRequest(ctx, name, id, clientID, reason)
+}
+
+// WithdrawChangeRequest lets the author close a pending change request
+func (u *Usecase) WithdrawChangeRequest(ctx context.Context, name string, id int64, clientID string) (result *entity.ChangeRequest, err error) {
+	ctx, span := u.start(ctx, "WithdrawChangeRequest")
+	defer end(span, &err)
+	return u.usecase.WithdrawChangeRequest(ctx, name, id, clientID)
+}
+
+// CommentOnChangeRequest adds a review comment to a change request
+func (u *Usecase) CommentOnChangeRequest(ctx context.Context, name string, id int64, clientID string, body string) (result *entity.ChangeRequestComment, err error) {
+	ctx, span := u.start(ctx, "CommentOnChangeRequest")
+	defer end(span, &err)
+	return u.usecase.CommentOnChangeRequest(ctx, name, id, clientID, body)
+}
+
+// ExportArchive emits every configuration with its full history, the shared schemas
+// and the global retention policy as archive records
+func (u *Usecase) ExportArchive(ctx context.Context, emit func(*entity.ArchiveRecord) error) (err error) {
+	ctx, span := u.start(ctx, "ExportArchive")
+	defer end(span, &err)
+	return u.usecase.ExportArchive(ctx, emit)
+}
+
+// ImportArchive restores the records of an archive, or reports what it would change on a dry run
+func (u *Usecase) ImportArchive(ctx context.Context, records []*entity.ArchiveRecord, opts entity.ImportOptions) (result *entity.ImportReport, err error) {
+	ctx, span := u.start(ctx, "ImportArchive")
+	defer end(span, &err)
+	return u.usecase.ImportArchive(ctx, records, opts)
+}
+
+// ApplyBatch validates create, update and rollback operations across configurations and
+// applies them in a single transaction, or none of them if any operation fails
+func (u *Usecase) ApplyBatch(ctx context.Context, changesetID string, operations []entity.BatchOperation) (result *entity.BatchResult, err error) {
+	ctx, span := u.start(ctx, "ApplyBatch")
+	defer end(span, &err)
+	return u.usecase.ApplyBatch(ctx, changesetID, operations)
+}
@@ -0,0 +1,744 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/jsondiff"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"sort"
+	"time"
+)
+
+// archiveContents holds the records of an archive between its header and footer
+type archiveContents struct {
+	sharedSchemas   []*entity.SharedSchema
+	retentionPolicy *entity.RetentionPolicy
+	configurations  []*entity.ConfigurationArchive
+}
+
+// localConfiguration is the current state stored under a configuration name
+type localConfiguration struct {
+	config  *entity.Configuration
+	schema  json.RawMessage
+	ruleSet *entity.RuleSet
+	policy  *entity.RetentionPolicy
+}
+
+// exists reports whether anything is stored under the name
+func (l *localConfiguration) exists() bool {
+	return l.config != nil || l.schema != nil || l.ruleSet != nil || l.policy != nil
+}
+
+// plannedConfiguration is an archived configuration with what an import does with it
+type plannedConfiguration struct {
+	archive *entity.ConfigurationArchive
+	local   *localConfiguration
+	action  entity.ImportAction
+}
+
+// ExportArchive emits a header, the shared schemas, the global retention policy, one
+// record per configuration with its full history and a footer counting the records.
+// Change requests and scheduled changes are not exported.
+func (uc *ConfigurationUseCase) ExportArchive(ctx context.Context, emit func(*entity.ArchiveRecord) error) error {
+	err := emit(&entity.ArchiveRecord{
+		Type:   entity.ArchiveRecordHeader,
+		Header: &entity.ArchiveHeader{FormatVersion: entity.ArchiveFormatVersion, ExportedAt: time.Now().UTC()},
+	})
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	write := func(record *entity.ArchiveRecord) error {
+		count++
+		return emit(record)
+	}
+
+	shared, err := uc.ListSharedSchemas(ctx)
+	if err != nil {
+		return err
+	}
+	for _, schema := range shared {
+		if err := write(&entity.ArchiveRecord{Type: entity.ArchiveRecordSharedSchema, SharedSchema: schema}); err != nil {
+			return err
+		}
+	}
+
+	policy, err := uc.repo.GetRetentionPolicy(ctx, entity.GlobalRetentionPolicyName)
+	if err != nil && !isNotFound(err) {
+		return internalError(ctx, "Failed to get retention policy", err)
+	}
+	if policy != nil {
+		if err := write(&entity.ArchiveRecord{Type: entity.ArchiveRecordRetentionPolicy, RetentionPolicy: policy}); err != nil {
+			return err
+		}
+	}
+
+	names, err := uc.archivedNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		archive, err := uc.archiveConfiguration(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := write(&entity.ArchiveRecord{Type: entity.ArchiveRecordConfiguration, Configuration: archive}); err != nil {
+			return err
+		}
+	}
+
+	return emit(&entity.ArchiveRecord{Type: entity.ArchiveRecordFooter, Footer: &entity.ArchiveFooter{Records: count}})
+}
+
+// archivedNames lists the names of all configurations and configuration schemas, sorted
+func (uc *ConfigurationUseCase) archivedNames(ctx context.Context) ([]string, error) {
+	names, err := uc.repo.ListConfigurationNames(ctx)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list configurations", err)
+	}
+
+	schemas, err := uc.allSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, schema := range schemas {
+		if !seen[schema.Name] {
+			seen[schema.Name] = true
+			names = append(names, schema.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// archiveConfiguration collects everything stored under a configuration name
+func (uc *ConfigurationUseCase) archiveConfiguration(ctx context.Context, name string) (*entity.ConfigurationArchive, error) {
+	archive := &entity.ConfigurationArchive{Name: name}
+
+	config, err := uc.repo.GetConfiguration(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get configuration", err)
+	}
+	if config != nil {
+		archive.CreatedAt = config.CreatedAt
+		archive.RollbackFrom = config.RollbackFrom
+		archive.RollbackTo = config.RollbackTo
+
+		list, err := uc.repo.ListConfigurationVersions(ctx, name)
+		if err != nil {
+			return nil, internalError(ctx, "Failed to list configuration versions", err)
+		}
+		for _, info := range list.Versions {
+			version := entity.ArchivedVersion{
+				Version:       info.Version,
+				CreatedAt:     info.CreatedAt,
+				IsRollback:    info.IsRollback,
+				Compacted:     info.Compacted,
+				SchemaVersion: info.SchemaVersion,
+				ChangesetID:   info.ChangesetID,
+			}
+			if !info.Compacted {
+				if version.Data, err = uc.repo.GetVersionData(ctx, name, info.Version); err != nil {
+					return nil, internalError(ctx, "Failed to get version data", err)
+				}
+			}
+			archive.Versions = append(archive.Versions, version)
+		}
+
+		if archive.Tags, err = uc.repo.ListVersionTags(ctx, name); err != nil {
+			return nil, internalError(ctx, "Failed to list version tags", err)
+		}
+	}
+
+	archive.Schemas, err = uc.repo.ListSchemaVersions(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to list schema versions", err)
+	}
+	if len(archive.Schemas) > 0 {
+		if _, err := uc.repo.GetSchema(ctx, name); err != nil {
+			if !isNotFound(err) {
+				return nil, internalError(ctx, "Failed to get schema", err)
+			}
+			archive.SchemaDetached = true
+		}
+	}
+
+	archive.RuleSets, err = uc.repo.ListRuleSetVersions(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to list rule sets", err)
+	}
+
+	archive.RetentionPolicy, err = uc.repo.GetRetentionPolicy(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get retention policy", err)
+	}
+
+	return archive, nil
+}
+
+// ImportArchive restores the records of an archive. Every item is compared with what is
+// stored under its name and the current data of each written configuration is validated
+// against its resulting schema and rules, resolving shared schemas from the archive first.
+// As when a shared schema is registered, the stored configurations that depend on an
+// imported shared schema must still validate. Nothing is written when any item is
+// invalid or on a dry run, and everything else is written in a single transaction.
+func (uc *ConfigurationUseCase) ImportArchive(ctx context.Context, records []*entity.ArchiveRecord, opts entity.ImportOptions) (*entity.ImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = entity.ImportModeSkipExisting
+	}
+	if !opts.Mode.IsValid() {
+		return nil, errors.NewInvalidRequestError("Invalid import mode", string(opts.Mode))
+	}
+
+	contents, err := readArchive(records)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.ImportReport{Mode: opts.Mode, DryRun: opts.DryRun, Items: []entity.ImportItem{}}
+
+	// Shared schemas written by the import take precedence over stored ones when
+	// validating the archive
+	imported := make(map[string]json.RawMessage)
+	load := func(ctx context.Context, ref string) (json.RawMessage, error) {
+		if schema, ok := imported[ref]; ok {
+			return schema, nil
+		}
+		return uc.loadSharedSchema(ctx, ref)
+	}
+	v := validator.NewJSONSchemaValidatorWithSharedSchemas(load, validator.WithStrictFormats(uc.strictFormats))
+
+	sharedActions := make([]entity.ImportAction, len(contents.sharedSchemas))
+	for i, shared := range contents.sharedSchemas {
+		stored, err := uc.repo.GetSharedSchema(ctx, shared.Name)
+		if err != nil && !isNotFound(err) {
+			return nil, internalError(ctx, "Failed to get shared schema", err)
+		}
+		item := documentImportItem(entity.ImportItemSharedSchema, shared.Name, opts.Mode, stored != nil, stored != nil && sameJSON(stored.Schema, shared.Schema))
+		if writes(item.Action) {
+			imported[shared.Name] = shared.Schema
+		}
+		sharedActions[i] = item.Action
+		report.Items = append(report.Items, item)
+	}
+	// Shared schema definitions are checked once all imported fragments are known
+	for i, shared := range contents.sharedSchemas {
+		if writes(sharedActions[i]) {
+			if report.Items[i].Errors, err = validationIssues(v.ValidateSchemaDefinition(ctx, shared.Schema)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var policyAction entity.ImportAction
+	if policy := contents.retentionPolicy; policy != nil {
+		stored, err := uc.repo.GetRetentionPolicy(ctx, entity.GlobalRetentionPolicyName)
+		if err != nil && !isNotFound(err) {
+			return nil, internalError(ctx, "Failed to get retention policy", err)
+		}
+		item := documentImportItem(entity.ImportItemRetentionPolicy, policy.Name, opts.Mode, stored != nil, sameRetentionPolicy(stored, policy))
+		policyAction = item.Action
+		report.Items = append(report.Items, item)
+	}
+
+	planned := make([]plannedConfiguration, 0, len(contents.configurations))
+	for _, archive := range contents.configurations {
+		local, err := uc.localConfiguration(ctx, archive.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		item := entity.ImportItem{Kind: entity.ImportItemConfiguration, Name: archive.Name, Action: entity.ImportActionCreate}
+		if local.exists() {
+			if sameConfiguration(local, archive) {
+				item.Action = entity.ImportActionUnchanged
+			} else {
+				item.Conflict = true
+				item.Action = map[entity.ImportMode]entity.ImportAction{
+					entity.ImportModeSkipExisting: entity.ImportActionSkip,
+					entity.ImportModeOverwrite:    entity.ImportActionOverwrite,
+					entity.ImportModeMerge:        entity.ImportActionMerge,
+				}[opts.Mode]
+			}
+		}
+		if writes(item.Action) {
+			// Configurations that require approval only change through change requests,
+			// whether written directly or restored from an archive
+			if changesVersions(archive, local, item.Action) {
+				if err := uc.requireDirectWrite(archive.Name); err != nil {
+					return nil, err
+				}
+			}
+			if item.Errors, err = validateImportedConfiguration(ctx, v, archive, local, item.Action); err != nil {
+				return nil, err
+			}
+			if item.Action == entity.ImportActionOverwrite && local.config != nil {
+				issues, err := uc.immutableTagIssues(ctx, archive)
+				if err != nil {
+					return nil, err
+				}
+				item.Errors = append(item.Errors, issues...)
+			}
+		}
+
+		planned = append(planned, plannedConfiguration{archive: archive, local: local, action: item.Action})
+		report.Items = append(report.Items, item)
+	}
+
+	// Stored configurations the import leaves as they are must still validate against
+	// the imported shared schemas they depend on
+	written := make(map[string]bool, len(planned))
+	for _, plan := range planned {
+		written[plan.archive.Name] = writes(plan.action)
+	}
+	for i, shared := range contents.sharedSchemas {
+		if !writes(sharedActions[i]) || len(report.Items[i].Errors) > 0 {
+			continue
+		}
+		if report.Items[i].Errors, err = uc.dependentIssues(ctx, shared.Name, load, v, written); err != nil {
+			return nil, err
+		}
+	}
+
+	// Count the items once their validation errors are known
+	items := report.Items
+	report.Items = make([]entity.ImportItem, 0, len(items))
+	for _, item := range items {
+		report.AddItem(item)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+	if report.Summary.Invalid > 0 {
+		return nil, errors.NewValidationFailedError("Archive does not conform to its schemas", report)
+	}
+
+	archiveImport := &entity.ArchiveImport{}
+	for i, shared := range contents.sharedSchemas {
+		if writes(sharedActions[i]) {
+			archiveImport.SharedSchemas = append(archiveImport.SharedSchemas, &entity.SharedSchema{Name: shared.Name, Schema: shared.Schema})
+		}
+	}
+	if writes(policyAction) {
+		archiveImport.RetentionPolicy = contents.retentionPolicy
+	}
+	for _, plan := range planned {
+		switch plan.action {
+		case entity.ImportActionCreate, entity.ImportActionOverwrite:
+			archiveImport.Replaced = append(archiveImport.Replaced, plan.archive)
+		case entity.ImportActionMerge:
+			archiveImport.Merged = append(archiveImport.Merged, configurationMerge(plan.archive, plan.local))
+		}
+	}
+	if err := uc.repo.ImportArchive(ctx, archiveImport); err != nil {
+		if isConflict(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to import archive", err)
+	}
+	uc.invalidateAllSchemas()
+
+	report.Applied = true
+	return report, nil
+}
+
+// readArchive checks the structure of an archive and returns its contents
+func readArchive(records []*entity.ArchiveRecord) (*archiveContents, error) {
+	invalid := func(index int, format string, args ...interface{}) error {
+		return errors.NewInvalidRequestError("Invalid archive", fmt.Sprintf("record %d: %s", index+1, fmt.Sprintf(format, args...)))
+	}
+
+	if len(records) == 0 || records[0].Type != entity.ArchiveRecordHeader || records[0].Header == nil {
+		return nil, invalid(0, "archive must start with a header record")
+	}
+	if v := records[0].Header.FormatVersion; v < 1 || v > entity.ArchiveFormatVersion {
+		return nil, invalid(0, "unsupported archive format version %d", v)
+	}
+	last := len(records) - 1
+	if last == 0 || records[last].Type != entity.ArchiveRecordFooter || records[last].Footer == nil {
+		return nil, invalid(last, "archive must end with a footer record; it may be truncated")
+	}
+	if records[last].Footer.Records != last-1 {
+		return nil, invalid(last, "footer counts %d records but the archive holds %d", records[last].Footer.Records, last-1)
+	}
+
+	contents := &archiveContents{}
+	sharedNames := make(map[string]bool)
+	configNames := make(map[string]bool)
+	for i := 1; i < last; i++ {
+		record := records[i]
+		switch record.Type {
+		case entity.ArchiveRecordSharedSchema:
+			shared := record.SharedSchema
+			if shared == nil || !validator.IsValidSharedSchemaName(shared.Name) || !json.Valid(shared.Schema) {
+				return nil, invalid(i, "shared schema record needs a valid name and schema")
+			}
+			if sharedNames[shared.Name] {
+				return nil, invalid(i, "shared schema %q appears more than once", shared.Name)
+			}
+			sharedNames[shared.Name] = true
+			contents.sharedSchemas = append(contents.sharedSchemas, shared)
+		case entity.ArchiveRecordRetentionPolicy:
+			policy := record.RetentionPolicy
+			if policy == nil || policy.Name != entity.GlobalRetentionPolicyName || contents.retentionPolicy != nil {
+				return nil, invalid(i, "an archive holds at most one retention policy record, for the global policy %q", entity.GlobalRetentionPolicyName)
+			}
+			if policy.KeepLast < 0 || policy.KeepDays < 0 {
+				return nil, invalid(i, "keep_last and keep_days must not be negative")
+			}
+			contents.retentionPolicy = policy
+		case entity.ArchiveRecordConfiguration:
+			archive := record.Configuration
+			if archive == nil || archive.Name == "" {
+				return nil, invalid(i, "configuration record needs a name")
+			}
+			if configNames[archive.Name] {
+				return nil, invalid(i, "configuration %q appears more than once", archive.Name)
+			}
+			if err := checkConfigurationArchive(archive); err != nil {
+				return nil, invalid(i, "configuration %q: %s", archive.Name, err.Error())
+			}
+			configNames[archive.Name] = true
+			contents.configurations = append(contents.configurations, archive)
+		default:
+			return nil, invalid(i, "unexpected %q record", record.Type)
+		}
+	}
+
+	return contents, nil
+}
+
+// checkConfigurationArchive checks that the history of an archived configuration is
+// consistent: versions in ascending order with data, and tags pointing at them
+func checkConfigurationArchive(archive *entity.ConfigurationArchive) error {
+	versions := make(map[int]bool, len(archive.Versions))
+	previous := 0
+	for _, version := range archive.Versions {
+		if version.Version <= previous {
+			return fmt.Errorf("versions must be in ascending order, got %d after %d", version.Version, previous)
+		}
+		previous = version.Version
+		if !version.Compacted && !json.Valid(version.Data) {
+			return fmt.Errorf("version %d has no valid JSON data", version.Version)
+		}
+		versions[version.Version] = true
+	}
+	if current := archive.Current(); current != nil && current.Compacted {
+		return fmt.Errorf("current version %d cannot be compacted", current.Version)
+	}
+
+	for _, tag := range archive.Tags {
+		if !versions[tag.Version] {
+			return fmt.Errorf("tag %q points at version %d, which is not archived", tag.Tag, tag.Version)
+		}
+		tag.Name = archive.Name
+	}
+
+	previous = 0
+	for _, schema := range archive.Schemas {
+		if schema.Version <= previous || !json.Valid(schema.Schema) {
+			return fmt.Errorf("schema version %d must follow version %d and hold valid JSON", schema.Version, previous)
+		}
+		previous = schema.Version
+		schema.Name = archive.Name
+	}
+
+	previous = 0
+	for _, ruleSet := range archive.RuleSets {
+		if ruleSet.Version <= previous {
+			return fmt.Errorf("rule set version %d must follow version %d", ruleSet.Version, previous)
+		}
+		if issues := rules.Check(ruleSet.Rules); len(issues) > 0 {
+			return fmt.Errorf("rule set version %d: %s: %s", ruleSet.Version, issues[0].Field, issues[0].Reason)
+		}
+		previous = ruleSet.Version
+		ruleSet.Name = archive.Name
+	}
+
+	if policy := archive.RetentionPolicy; policy != nil {
+		if policy.KeepLast < 0 || policy.KeepDays < 0 {
+			return fmt.Errorf("keep_last and keep_days of the retention policy must not be negative")
+		}
+		policy.Name = archive.Name
+	}
+
+	return nil
+}
+
+// localConfiguration loads the current state stored under a configuration name
+func (uc *ConfigurationUseCase) localConfiguration(ctx context.Context, name string) (*localConfiguration, error) {
+	local := &localConfiguration{}
+	var err error
+
+	if local.config, err = uc.repo.GetConfiguration(ctx, name); err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get configuration", err)
+	}
+	if local.schema, err = uc.repo.GetSchema(ctx, name); err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get schema", err)
+	}
+	if local.ruleSet, err = uc.repo.GetRuleSet(ctx, name); err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to load validation rules", err)
+	}
+	if local.policy, err = uc.repo.GetRetentionPolicy(ctx, name); err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get retention policy", err)
+	}
+
+	return local, nil
+}
+
+// sameConfiguration reports whether the current state of an archived configuration
+// matches what is stored locally
+func sameConfiguration(local *localConfiguration, archive *entity.ConfigurationArchive) bool {
+	var data, schema json.RawMessage
+	if current := archive.Current(); current != nil {
+		data = current.Data
+	}
+	if current := archive.CurrentSchema(); current != nil {
+		schema = current.Schema
+	}
+	var localData json.RawMessage
+	if local.config != nil {
+		localData = local.config.Data
+	}
+
+	return sameJSON(localData, data) &&
+		sameJSON(local.schema, schema) &&
+		sameRules(local.ruleSet, archive.CurrentRuleSet()) &&
+		sameRetentionPolicy(local.policy, archive.RetentionPolicy)
+}
+
+// changesVersions reports whether importing an archived configuration changes the
+// versions of a stored configuration, as opposed to creating it or only changing its
+// schema, rules or retention policy
+func changesVersions(archive *entity.ConfigurationArchive, local *localConfiguration, action entity.ImportAction) bool {
+	if local.config == nil {
+		return false
+	}
+	switch action {
+	case entity.ImportActionOverwrite:
+		return true
+	case entity.ImportActionMerge:
+		current := archive.Current()
+		return current != nil && !sameJSON(local.config.Data, current.Data)
+	}
+	return false
+}
+
+// immutableTagIssues reports the immutable tags of a stored configuration that
+// overwriting it with an archive would remove or point at different data. Immutable
+// tags can never be moved or removed, so the archive must carry each of them, still
+// immutable, on the same version with the same data.
+func (uc *ConfigurationUseCase) immutableTagIssues(ctx context.Context, archive *entity.ConfigurationArchive) ([]errors.ValidationError, error) {
+	tags, err := uc.repo.ListVersionTags(ctx, archive.Name)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list version tags", err)
+	}
+
+	archivedTags := make(map[string]*entity.VersionTag, len(archive.Tags))
+	for _, tag := range archive.Tags {
+		archivedTags[tag.Tag] = tag
+	}
+	archivedData := make(map[int]json.RawMessage, len(archive.Versions))
+	for _, version := range archive.Versions {
+		archivedData[version.Version] = version.Data
+	}
+
+	var issues []errors.ValidationError
+	for _, tag := range tags {
+		if !tag.Immutable {
+			continue
+		}
+		if archived := archivedTags[tag.Tag]; archived != nil && archived.Immutable && archived.Version == tag.Version {
+			// Compacted versions have no data on either side
+			data, err := uc.repo.GetVersionData(ctx, archive.Name, tag.Version)
+			if err != nil && !isVersionCompacted(err) {
+				return nil, internalError(ctx, "Failed to get version data", err)
+			}
+			if sameJSON(data, archivedData[tag.Version]) {
+				continue
+			}
+		}
+		issues = append(issues, errors.ValidationError{
+			Field:    "tags." + tag.Tag,
+			Reason:   fmt.Sprintf("Immutable tag %q on version %d would be removed or changed by the overwrite", tag.Tag, tag.Version),
+			Code:     errors.ValidationCodeNotAllowed,
+			Expected: tag.Version,
+		})
+	}
+
+	return issues, nil
+}
+
+// validateImportedConfiguration validates the current data a configuration will have
+// after the import against the schema and rules it will have
+func validateImportedConfiguration(ctx context.Context, v validator.Validator, archive *entity.ConfigurationArchive, local *localConfiguration, action entity.ImportAction) ([]errors.ValidationError, error) {
+	var data, schema json.RawMessage
+	var ruleSet *entity.RuleSet
+	if action == entity.ImportActionMerge {
+		if local.config != nil {
+			data = local.config.Data
+		}
+		schema, ruleSet = local.schema, local.ruleSet
+	}
+	if current := archive.Current(); current != nil {
+		data = current.Data
+	}
+	if current := archive.CurrentSchema(); current != nil {
+		schema = current.Schema
+		issues, err := validationIssues(v.ValidateSchemaDefinition(ctx, schema))
+		if err != nil || len(issues) > 0 {
+			return issues, err
+		}
+	}
+	if current := archive.CurrentRuleSet(); current != nil {
+		ruleSet = current
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	if schema != nil {
+		issues, err := validationIssues(v.ValidateJSON(ctx, schema, data))
+		if err != nil || len(issues) > 0 {
+			return issues, err
+		}
+	}
+	if ruleSet != nil {
+		return validationIssues(evaluateRules(ruleSet.Rules, data))
+	}
+
+	return nil, nil
+}
+
+// validationIssues turns a validation error into the issues reported for an import item.
+// Errors other than invalid data or definitions are returned as is.
+func validationIssues(err error) ([]errors.ValidationError, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) {
+		return nil, err
+	}
+	switch appErr.Code {
+	case errors.ErrorCodeValidationFailed:
+		if issues, ok := appErr.Details.([]errors.ValidationError); ok && len(issues) > 0 {
+			return issues, nil
+		}
+	case errors.ErrorCodeInvalidRequest:
+	default:
+		return nil, err
+	}
+	return []errors.ValidationError{{Field: "(root)", Reason: fmt.Sprintf("%s: %v", appErr.Message, appErr.Details)}}, nil
+}
+
+// dependentIssues validates the current data of the stored configurations that depend
+// on an imported shared schema, as registering the shared schema would, and reports
+// each failure. Configurations the import writes are validated on their own item.
+func (uc *ConfigurationUseCase) dependentIssues(ctx context.Context, name string, load validator.SharedSchemaLoader, v validator.Validator, written map[string]bool) ([]errors.ValidationError, error) {
+	results, err := uc.validateDependents(ctx, name, load, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []errors.ValidationError
+	for _, result := range results {
+		if result.Valid || written[result.Name] {
+			continue
+		}
+		for _, issue := range result.Errors {
+			issue.Reason = fmt.Sprintf("configuration %q would no longer validate: %s", result.Name, issue.Reason)
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// configurationMerge returns what merging an archived configuration appends to an
+// existing one: the archived current schema, rules, retention policy and data where they
+// differ from the stored ones
+func configurationMerge(archive *entity.ConfigurationArchive, local *localConfiguration) *entity.ConfigurationMerge {
+	merge := &entity.ConfigurationMerge{Name: archive.Name}
+
+	if schema := archive.CurrentSchema(); schema != nil && !sameJSON(local.schema, schema.Schema) {
+		merge.Schema = schema.Schema
+	}
+	if ruleSet := archive.CurrentRuleSet(); ruleSet != nil && !sameRules(local.ruleSet, ruleSet) {
+		merge.RuleSet = ruleSet
+	}
+	if policy := archive.RetentionPolicy; policy != nil && !sameRetentionPolicy(local.policy, policy) {
+		merge.RetentionPolicy = policy
+	}
+
+	current := archive.Current()
+	if current == nil || (local.config != nil && sameJSON(local.config.Data, current.Data)) {
+		return merge
+	}
+	if local.config == nil {
+		merge.Configuration = entity.NewConfiguration(archive.Name, current.Data)
+	} else {
+		merge.Configuration = local.config.UpdateVersion(current.Data)
+	}
+	return merge
+}
+
+// documentImportItem reports what an import does with a shared schema or the global
+// retention policy. Both are single documents, so merging replaces them.
+func documentImportItem(kind entity.ImportItemKind, name string, mode entity.ImportMode, exists, same bool) entity.ImportItem {
+	item := entity.ImportItem{Kind: kind, Name: name, Action: entity.ImportActionCreate}
+	switch {
+	case !exists:
+	case same:
+		item.Action = entity.ImportActionUnchanged
+	case mode == entity.ImportModeSkipExisting:
+		item.Action, item.Conflict = entity.ImportActionSkip, true
+	default:
+		item.Action, item.Conflict = entity.ImportActionOverwrite, true
+	}
+	return item
+}
+
+// writes reports whether an import action changes stored state
+func writes(action entity.ImportAction) bool {
+	return action == entity.ImportActionCreate || action == entity.ImportActionOverwrite || action == entity.ImportActionMerge
+}
+
+// sameJSON reports whether two documents are semantically equal; two missing documents are equal
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	changes, err := jsondiff.Diff(a, b)
+	return err == nil && len(changes) == 0
+}
+
+// sameRules reports whether two rule sets hold the same rules
+func sameRules(a, b *entity.RuleSet) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	encodedA, errA := json.Marshal(a.Rules)
+	encodedB, errB := json.Marshal(b.Rules)
+	return errA == nil && errB == nil && sameJSON(encodedA, encodedB)
+}
+
+// sameRetentionPolicy reports whether two retention policies keep the same versions
+func sameRetentionPolicy(a, b *entity.RetentionPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.KeepLast == b.KeepLast && a.KeepDays == b.KeepDays
+}
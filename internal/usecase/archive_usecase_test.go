@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveOf wraps records in a header and footer
+func archiveOf(records ...*entity.ArchiveRecord) []*entity.ArchiveRecord {
+	archive := []*entity.ArchiveRecord{{
+		Type:   entity.ArchiveRecordHeader,
+		Header: &entity.ArchiveHeader{FormatVersion: entity.ArchiveFormatVersion, ExportedAt: time.Now().UTC()},
+	}}
+	archive = append(archive, records...)
+	return append(archive, &entity.ArchiveRecord{Type: entity.ArchiveRecordFooter, Footer: &entity.ArchiveFooter{Records: len(records)}})
+}
+
+// expectNothingStored sets up the mock to report that nothing is stored under a name
+func expectNothingStored(mockRepo *MockConfigurationRepository, name string) {
+	mockRepo.On("GetConfiguration", name).Return(nil, errors.NewNotFoundError("Configuration", name))
+	mockRepo.On("GetSchema", name).Return(nil, errors.NewNotFoundError("Schema", name))
+	mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
+	mockRepo.On("GetRetentionPolicy", name).Return(nil, errors.NewNotFoundError("Retention policy", name))
+}
+
+func TestConfigurationUseCase_ExportArchive(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	config := &entity.Configuration{Name: "payments", Version: 2, Data: json.RawMessage(`{"max_limit":2}`)}
+	mockRepo.On("ListSharedSchemas").Return([]*entity.SharedSchema{{Name: "common/money", Schema: json.RawMessage(`{"type":"number"}`)}}, nil)
+	mockRepo.On("GetRetentionPolicy", entity.GlobalRetentionPolicyName).Return(&entity.RetentionPolicy{Name: "*", KeepLast: 10}, nil)
+	mockRepo.On("ListConfigurationNames").Return([]string{"payments"}, nil)
+	mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{{Name: "routing", Version: 1, Schema: json.RawMessage(`{}`)}}, 1, nil)
+
+	mockRepo.On("GetConfiguration", "payments").Return(config, nil)
+	mockRepo.On("ListConfigurationVersions", "payments").Return(&entity.VersionList{Name: "payments", Versions: []entity.VersionInfo{
+		{Version: 1, Compacted: true},
+		{Version: 2},
+	}}, nil)
+	mockRepo.On("GetVersionData", "payments", 2).Return(config.Data, nil)
+	mockRepo.On("ListVersionTags", "payments").Return([]*entity.VersionTag{}, nil)
+	mockRepo.On("ListSchemaVersions", "payments").Return(nil, errors.NewNotFoundError("Schema", "payments"))
+	mockRepo.On("ListRuleSetVersions", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+	mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+
+	mockRepo.On("GetConfiguration", "routing").Return(nil, errors.NewNotFoundError("Configuration", "routing"))
+	mockRepo.On("ListSchemaVersions", "routing").Return([]*entity.SchemaVersion{{Name: "routing", Version: 1, Schema: json.RawMessage(`{}`)}}, nil)
+	mockRepo.On("GetSchema", "routing").Return(nil, errors.NewNotFoundError("Schema", "routing"))
+	mockRepo.On("ListRuleSetVersions", "routing").Return(nil, errors.NewNotFoundError("Rule set", "routing"))
+	mockRepo.On("GetRetentionPolicy", "routing").Return(nil, errors.NewNotFoundError("Retention policy", "routing"))
+
+	// Call the method
+	var records []*entity.ArchiveRecord
+	err := useCase.ExportArchive(context.Background(), func(record *entity.ArchiveRecord) error {
+		records = append(records, record)
+		return nil
+	})
+
+	// Assertions
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, entity.ArchiveRecordHeader, records[0].Type)
+	assert.Equal(t, entity.ArchiveRecordSharedSchema, records[1].Type)
+	assert.Equal(t, entity.ArchiveRecordRetentionPolicy, records[2].Type)
+
+	payments := records[3].Configuration
+	require.NotNil(t, payments)
+	assert.Equal(t, "payments", payments.Name)
+	require.Len(t, payments.Versions, 2)
+	assert.Nil(t, payments.Versions[0].Data)
+	assert.JSONEq(t, `{"max_limit":2}`, string(payments.Versions[1].Data))
+	mockRepo.AssertNotCalled(t, "GetVersionData", "payments", 1)
+
+	routing := records[4].Configuration
+	require.NotNil(t, routing)
+	assert.True(t, routing.SchemaDetached)
+	assert.Empty(t, routing.Versions)
+
+	assert.Equal(t, 4, records[5].Footer.Records)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfigurationUseCase_ImportArchive(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"max_limit":{"type":"integer"}},"required":["max_limit"]}`)
+	payments := func(data string) *entity.ArchiveRecord {
+		return &entity.ArchiveRecord{Type: entity.ArchiveRecordConfiguration, Configuration: &entity.ConfigurationArchive{
+			Name:     "payments",
+			Versions: []entity.ArchivedVersion{{Version: 1, Data: json.RawMessage(data), SchemaVersion: 1}},
+			Schemas:  []*entity.SchemaVersion{{Version: 1, Schema: schema}},
+		}}
+	}
+
+	t.Run("CreatesMissingConfigurations", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		expectNothingStored(mockRepo, "payments")
+		mockRepo.On("ImportArchive", mock.AnythingOfType("*entity.ArchiveImport")).Return(nil)
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1000}`)), entity.ImportOptions{})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.True(t, report.Applied)
+		assert.Equal(t, entity.ImportModeSkipExisting, report.Mode)
+		assert.Equal(t, 1, report.Summary.Created)
+		assert.Equal(t, entity.ImportActionCreate, report.Items[0].Action)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DryRunReportsConflicts", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 4, Data: json.RawMessage(`{"max_limit":10}`)}, nil)
+		mockRepo.On("GetSchema", "payments").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+		mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+		mockRepo.On("ListVersionTags", "payments").Return([]*entity.VersionTag{}, nil)
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1000}`)), entity.ImportOptions{Mode: entity.ImportModeOverwrite, DryRun: true})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, report.Applied)
+		assert.Equal(t, 1, report.Summary.Overwritten)
+		assert.Equal(t, 1, report.Summary.Conflicts)
+		assert.True(t, report.Items[0].Conflict)
+		mockRepo.AssertNotCalled(t, "ImportArchive", mock.Anything)
+	})
+
+	t.Run("SkipsExistingAndUnchanged", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "common/money").Return(&entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type": "number"}`)}, nil)
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 4, Data: json.RawMessage(`{"max_limit":10}`)}, nil)
+		mockRepo.On("GetSchema", "payments").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+		mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+		mockRepo.On("ImportArchive", &entity.ArchiveImport{}).Return(nil)
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(
+			&entity.ArchiveRecord{Type: entity.ArchiveRecordSharedSchema, SharedSchema: &entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type":"number"}`)}},
+			payments(`{"max_limit":1000}`),
+		), entity.ImportOptions{Mode: entity.ImportModeSkipExisting})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.True(t, report.Applied)
+		assert.Equal(t, entity.ImportActionUnchanged, report.Items[0].Action)
+		assert.Equal(t, entity.ImportActionSkip, report.Items[1].Action)
+		assert.Equal(t, 1, report.Summary.Skipped)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OverwriteKeepsImmutableTags", func(t *testing.T) {
+		expectStored := func(mockRepo *MockConfigurationRepository) {
+			mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 4, Data: json.RawMessage(`{"max_limit":10}`)}, nil)
+			mockRepo.On("GetSchema", "payments").Return(schema, nil)
+			mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+			mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+			mockRepo.On("ListVersionTags", "payments").Return([]*entity.VersionTag{
+				entity.NewVersionTag("payments", "release-1", 1, true),
+				entity.NewVersionTag("payments", "latest", 4, false),
+			}, nil)
+			mockRepo.On("GetVersionData", "payments", 1).Return(json.RawMessage(`{"max_limit":5}`), nil)
+		}
+
+		// The archive replaces version 1, which the immutable tag release-1 points at
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		expectStored(mockRepo)
+
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1000}`)), entity.ImportOptions{Mode: entity.ImportModeOverwrite})
+
+		require.Error(t, err)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		assert.Nil(t, report)
+		failed := appErr.Details.(*entity.ImportReport)
+		require.Len(t, failed.Items[0].Errors, 1)
+		assert.Equal(t, "tags.release-1", failed.Items[0].Errors[0].Field)
+		mockRepo.AssertNotCalled(t, "ImportArchive", mock.Anything)
+
+		// An archive carrying the tag on the same data may overwrite; movable tags follow the archive
+		mockRepo = new(MockConfigurationRepository)
+		useCase = NewConfigurationUseCase(mockRepo)
+		expectStored(mockRepo)
+		mockRepo.On("ImportArchive", mock.AnythingOfType("*entity.ArchiveImport")).Return(nil)
+
+		record := payments(`{"max_limit":5}`)
+		record.Configuration.Versions = append(record.Configuration.Versions, entity.ArchivedVersion{Version: 2, Data: json.RawMessage(`{"max_limit":1000}`), SchemaVersion: 1})
+		record.Configuration.Tags = []*entity.VersionTag{entity.NewVersionTag("payments", "release-1", 1, true)}
+		report, err = useCase.ImportArchive(context.Background(), archiveOf(record), entity.ImportOptions{Mode: entity.ImportModeOverwrite})
+
+		require.NoError(t, err)
+		assert.True(t, report.Applied)
+		assert.Equal(t, 1, report.Summary.Overwritten)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RespectsApprovalPolicy", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithApprovalPolicy(entity.ApprovalPolicy{{Pattern: "payments", RequiredApprovals: 1}}))
+
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 4, Data: json.RawMessage(`{"max_limit":10}`)}, nil)
+		mockRepo.On("GetSchema", "payments").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+		mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1000}`)), entity.ImportOptions{Mode: entity.ImportModeMerge})
+
+		// Assertions
+		require.Error(t, err)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeForbidden, appErr.Code)
+		assert.Nil(t, report)
+		mockRepo.AssertNotCalled(t, "ImportArchive", mock.Anything)
+	})
+
+	t.Run("MergeAppendsNewVersion", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 4, Data: json.RawMessage(`{"max_limit":10}`)}, nil)
+		mockRepo.On("GetSchema", "payments").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+		mockRepo.On("GetRetentionPolicy", "payments").Return(nil, errors.NewNotFoundError("Retention policy", "payments"))
+		mockRepo.On("ImportArchive", mock.MatchedBy(func(archive *entity.ArchiveImport) bool {
+			if len(archive.Merged) != 1 || len(archive.Replaced) != 0 {
+				return false
+			}
+			merge := archive.Merged[0]
+			return merge.Schema == nil && merge.Configuration.Version == 5 && string(merge.Configuration.Data) == `{"max_limit":1000}`
+		})).Return(nil)
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1000}`)), entity.ImportOptions{Mode: entity.ImportModeMerge})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Summary.Merged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsInvalidData", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		expectNothingStored(mockRepo, "payments")
+
+		// Call the method
+		_, err := useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":"high"}`)), entity.ImportOptions{})
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		report, ok := appErr.Details.(*entity.ImportReport)
+		require.True(t, ok)
+		assert.Equal(t, 1, report.Summary.Invalid)
+		assert.Equal(t, "/max_limit", report.Items[0].Errors[0].Pointer)
+		mockRepo.AssertNotCalled(t, "ImportArchive", mock.Anything)
+	})
+
+	t.Run("ValidatesAgainstImportedSharedSchemas", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "common/money").Return(nil, errors.NewNotFoundError("Shared schema", "common/money"))
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{}, 0, nil)
+		expectNothingStored(mockRepo, "payments")
+
+		record := payments(`{"max_limit":-1}`)
+		record.Configuration.Schemas[0].Schema = json.RawMessage(`{"type":"object","properties":{"max_limit":{"$ref":"shared://common/money"}}}`)
+
+		// Call the method
+		report, err := useCase.ImportArchive(context.Background(), archiveOf(
+			&entity.ArchiveRecord{Type: entity.ArchiveRecordSharedSchema, SharedSchema: &entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type":"number","minimum":0}`)}},
+			record,
+		), entity.ImportOptions{DryRun: true})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Summary.Invalid)
+		assert.NotEmpty(t, report.Items[1].Errors)
+	})
+
+	t.Run("RejectsSharedSchemaBreakingStoredDependents", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// routing is not in the archive, but its schema uses the imported shared schema
+		routingSchema := json.RawMessage(`{"type":"object","properties":{"fee":{"$ref":"shared://common/money"}}}`)
+		mockRepo.On("GetSharedSchema", "common/money").Return(&entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type":"number"}`)}, nil)
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{{Name: "routing", Version: 1, Schema: routingSchema}}, 1, nil)
+		mockRepo.On("GetConfiguration", "routing").Return(&entity.Configuration{Name: "routing", Version: 2, Data: json.RawMessage(`{"fee":-1}`)}, nil)
+
+		// Call the method
+		_, err := useCase.ImportArchive(context.Background(), archiveOf(
+			&entity.ArchiveRecord{Type: entity.ArchiveRecordSharedSchema, SharedSchema: &entity.SharedSchema{Name: "common/money", Schema: json.RawMessage(`{"type":"number","minimum":0}`)}},
+		), entity.ImportOptions{Mode: entity.ImportModeOverwrite})
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		report := appErr.Details.(*entity.ImportReport)
+		require.NotEmpty(t, report.Items[0].Errors)
+		assert.Contains(t, report.Items[0].Errors[0].Reason, `configuration "routing"`)
+		mockRepo.AssertNotCalled(t, "ImportArchive", mock.Anything)
+	})
+
+	t.Run("InvalidArchive", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		truncated := archiveOf(payments(`{"max_limit":1}`))
+		truncated = truncated[:len(truncated)-1]
+
+		_, err := useCase.ImportArchive(context.Background(), truncated, entity.ImportOptions{})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+		assert.Contains(t, err.(*errors.AppError).Details, "truncated")
+
+		_, err = useCase.ImportArchive(context.Background(), archiveOf(payments(`{"max_limit":1}`)), entity.ImportOptions{Mode: "replace"})
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+	})
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// ApplyBatch validates every operation of a batch against the state left by the
+// operations before it, then applies all of them in a single repository transaction.
+// If any operation fails, nothing is applied and the error carries the code of the first
+// failure with the result of every operation in its details.
+func (uc *ConfigurationUseCase) ApplyBatch(ctx context.Context, changesetID string, operations []entity.BatchOperation) (*entity.BatchResult, error) {
+	if len(operations) == 0 {
+		return nil, errors.NewInvalidRequestError("A batch needs at least one operation", nil)
+	}
+	if len(operations) > entity.MaxBatchOperations {
+		return nil, errors.NewInvalidRequestError(
+			fmt.Sprintf("A batch holds at most %d operations", entity.MaxBatchOperations),
+			map[string]int{"operations": len(operations)},
+		)
+	}
+	if len(changesetID) > entity.MaxChangesetIDLength {
+		return nil, errors.NewInvalidRequestError(
+			fmt.Sprintf("changeset_id must not be longer than %d characters", entity.MaxChangesetIDLength),
+			nil,
+		)
+	}
+
+	result := &entity.BatchResult{ChangesetID: changesetID, Results: make([]entity.BatchOperationResult, 0, len(operations))}
+
+	// state holds the configurations as the operations so far leave them; a nil entry
+	// records a configuration that does not exist
+	state := make(map[string]*entity.Configuration)
+	configs := make([]*entity.Configuration, 0, len(operations))
+	var failed *errors.AppError
+	failedIndex := 0
+
+	for i, op := range operations {
+		opResult := entity.BatchOperationResult{Index: i, Op: op.Op, Name: op.Name}
+
+		config, err := uc.prepareBatchOperation(ctx, op, state, configs)
+		if err != nil {
+			var appErr *errors.AppError
+			if !stdErrors.As(err, &appErr) {
+				appErr = internalError(ctx, "Failed to prepare batch operation", err)
+			}
+			opResult.Error = appErr.ToErrorResponse()
+			if failed == nil {
+				failed, failedIndex = appErr, i
+			}
+		} else {
+			config.ChangesetID = changesetID
+			state[op.Name] = config
+			configs = append(configs, config)
+			opResult.Configuration = config
+		}
+
+		result.Results = append(result.Results, opResult)
+	}
+
+	if failed != nil {
+		return nil, errors.NewAppError(
+			fmt.Sprintf("Batch operation %d failed: %s; nothing was applied", failedIndex, failed.Message),
+			failed.Code,
+			result,
+		)
+	}
+
+	if err := uc.repo.ApplyBatch(ctx, configs); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeConflict {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to apply batch", err)
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// prepareBatchOperation validates one operation of a batch and returns the configuration
+// version it writes. pending holds the versions prepared by earlier operations, which a
+// rollback may target.
+func (uc *ConfigurationUseCase) prepareBatchOperation(ctx context.Context, op entity.BatchOperation, state map[string]*entity.Configuration, pending []*entity.Configuration) (*entity.Configuration, error) {
+	if op.Name == "" {
+		return nil, errors.NewInvalidRequestError("Configuration name is required", nil)
+	}
+
+	current, ok := state[op.Name]
+	if !ok {
+		var err error
+		current, err = uc.repo.GetConfiguration(ctx, op.Name)
+		if err != nil && !isNotFound(err) {
+			return nil, internalError(ctx, "Failed to get configuration", err)
+		}
+		state[op.Name] = current
+	}
+
+	switch op.Op {
+	case entity.BatchOperationCreate:
+		if current != nil {
+			return nil, errors.NewAlreadyExistsError("Configuration", op.Name)
+		}
+		if len(op.Data) == 0 {
+			return nil, errors.NewInvalidRequestError("Configuration data is required", nil)
+		}
+		data, defaulted, err := uc.prepareData(ctx, op.Name, op.Data)
+		if err != nil {
+			return nil, err
+		}
+		config := entity.NewConfiguration(op.Name, data)
+		config.DefaultedFields = defaulted
+		return config, nil
+
+	case entity.BatchOperationUpdate:
+		if current == nil {
+			return nil, errors.NewNotFoundError("Configuration", op.Name)
+		}
+		if err := uc.requireDirectWrite(op.Name); err != nil {
+			return nil, err
+		}
+		if len(op.Data) == 0 {
+			return nil, errors.NewInvalidRequestError("Configuration data is required", nil)
+		}
+		data, defaulted, err := uc.prepareData(ctx, op.Name, op.Data)
+		if err != nil {
+			return nil, err
+		}
+		config := current.UpdateVersion(data)
+		config.DefaultedFields = defaulted
+		return config, nil
+
+	case entity.BatchOperationRollback:
+		if current == nil {
+			return nil, errors.NewNotFoundError("Configuration", op.Name)
+		}
+		if err := uc.requireDirectWrite(op.Name); err != nil {
+			return nil, err
+		}
+		if op.TargetVersion < 1 || op.TargetVersion >= current.Version {
+			return nil, errors.NewInvalidRequestError("target_version must be a previous version", map[string]int{
+				"target_version":  op.TargetVersion,
+				"current_version": current.Version,
+			})
+		}
+		data, err := uc.batchVersionData(ctx, op.Name, op.TargetVersion, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		// The target was valid when it was written, but the schema or rules may have
+		// changed since; the data is restored as it was, without filling defaults
+		schema, err := uc.repo.GetSchema(ctx, op.Name)
+		if err == nil && schema != nil {
+			if err := uc.validateData(ctx, op.Name, schema, data); err != nil {
+				return nil, err
+			}
+		}
+		if err := uc.checkRules(ctx, op.Name, data); err != nil {
+			return nil, err
+		}
+		return entity.NewVersionFromRollback(current, op.TargetVersion, data), nil
+	}
+
+	return nil, errors.NewInvalidRequestError("Invalid batch operation", fmt.Sprintf("unknown op %q, use create, update or rollback", op.Op))
+}
+
+// batchVersionData returns the data of a configuration version, which may have been
+// prepared by an earlier operation of the same batch
+func (uc *ConfigurationUseCase) batchVersionData(ctx context.Context, name string, version int, pending []*entity.Configuration) (json.RawMessage, error) {
+	for _, config := range pending {
+		if config.Name == name && config.Version == version {
+			return config.Data, nil
+		}
+	}
+
+	data, err := uc.repo.GetVersionData(ctx, name, version)
+	if isVersionCompacted(err) {
+		return nil, err
+	}
+	if err != nil || data == nil {
+		return nil, notFoundError(ctx, err, "Configuration version", fmt.Sprintf("%s:%d", name, version))
+	}
+	return data, nil
+}
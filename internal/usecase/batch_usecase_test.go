@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_ApplyBatch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		existing := &entity.Configuration{Name: "routing", Version: 2, Data: json.RawMessage(`{"region":"eu"}`)}
+		expectNothingStored(mockRepo, "payments")
+		mockRepo.On("GetConfiguration", "routing").Return(existing, nil)
+		mockRepo.On("GetSchema", "routing").Return(nil, errors.NewNotFoundError("Schema", "routing"))
+		mockRepo.On("GetRuleSet", "routing").Return(nil, errors.NewNotFoundError("Rule set", "routing"))
+		mockRepo.On("ApplyBatch", mock.MatchedBy(func(configs []*entity.Configuration) bool {
+			return len(configs) == 4 &&
+				configs[0].Name == "payments" && configs[0].Version == 1 &&
+				configs[1].Name == "routing" && configs[1].Version == 3 &&
+				configs[2].Name == "routing" && configs[2].Version == 4 &&
+				configs[3].Name == "routing" && configs[3].Version == 5 && configs[3].RollbackTo == 3 &&
+				configs[3].ChangesetID == "release-42"
+		})).Return(nil)
+
+		// Call the method
+		result, err := useCase.ApplyBatch(context.Background(), "release-42", []entity.BatchOperation{
+			{Op: entity.BatchOperationCreate, Name: "payments", Data: json.RawMessage(`{"max_limit":1000}`)},
+			{Op: entity.BatchOperationUpdate, Name: "routing", Data: json.RawMessage(`{"region":"us"}`)},
+			{Op: entity.BatchOperationUpdate, Name: "routing", Data: json.RawMessage(`{"region":"apac"}`)},
+			{Op: entity.BatchOperationRollback, Name: "routing", TargetVersion: 3},
+		})
+
+		// Assertions
+		require.NoError(t, err)
+		assert.True(t, result.Applied)
+		assert.Equal(t, "release-42", result.ChangesetID)
+		require.Len(t, result.Results, 4)
+		assert.JSONEq(t, `{"region":"us"}`, string(result.Results[3].Configuration.Data))
+		assert.Equal(t, 3, result.Results[3].Configuration.RollbackTo)
+		mockRepo.AssertNotCalled(t, "GetVersionData", mock.Anything, mock.Anything)
+		mockRepo.AssertCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("InvalidOperationAppliesNothing", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		expectNothingStored(mockRepo, "payments")
+		mockRepo.On("GetConfiguration", "routing").Return(nil, errors.NewNotFoundError("Configuration", "routing"))
+
+		// Call the method
+		result, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationCreate, Name: "payments", Data: json.RawMessage(`{"max_limit":1000}`)},
+			{Op: entity.BatchOperationUpdate, Name: "routing", Data: json.RawMessage(`{"region":"us"}`)},
+		})
+
+		// Assertions
+		assert.Nil(t, result)
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeNotFound, appErr.Code)
+		details := appErr.Details.(*entity.BatchResult)
+		assert.False(t, details.Applied)
+		require.Len(t, details.Results, 2)
+		assert.Nil(t, details.Results[0].Error)
+		require.NotNil(t, details.Results[1].Error)
+		assert.Equal(t, errors.ErrorCodeNotFound, details.Results[1].Error.Code)
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("SchemaViolation", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "payments").Return(nil, errors.NewNotFoundError("Configuration", "payments"))
+		mockRepo.On("GetSchema", "payments").Return(json.RawMessage(`{"type":"object","required":["max_limit"]}`), nil)
+
+		// Call the method
+		_, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationCreate, Name: "payments", Data: json.RawMessage(`{}`)},
+		})
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("RollbackTargetViolatesCurrentSchema", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// max_limit became required after version 1 was written
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 2, Data: json.RawMessage(`{"max_limit":1000}`)}, nil)
+		mockRepo.On("GetVersionData", "payments", 1).Return(json.RawMessage(`{}`), nil)
+		mockRepo.On("GetSchema", "payments").Return(json.RawMessage(`{"type":"object","required":["max_limit"]}`), nil)
+
+		// Call the method
+		_, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationRollback, Name: "payments", TargetVersion: 1},
+		})
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("RollbackTargetFailsRules", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "payments").Return(&entity.Configuration{Name: "payments", Version: 2, Data: json.RawMessage(`{"max_limit":1000}`)}, nil)
+		mockRepo.On("GetVersionData", "payments", 1).Return(json.RawMessage(`{"min_limit":10,"max_limit":5}`), nil)
+		mockRepo.On("GetSchema", "payments").Return(nil, errors.NewNotFoundError("Schema", "payments"))
+		mockRepo.On("GetRuleSet", "payments").Return(&entity.RuleSet{Name: "payments", Rules: limitRules}, nil)
+
+		// Call the method
+		_, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationRollback, Name: "payments", TargetVersion: 1},
+		})
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("ProtectedConfiguration", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithApprovalPolicy(entity.ApprovalPolicy{{Pattern: "prod-*", RequiredApprovals: 1}}))
+
+		mockRepo.On("GetConfiguration", "prod-payments").Return(&entity.Configuration{Name: "prod-payments", Version: 1}, nil)
+
+		// Call the method
+		_, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationUpdate, Name: "prod-payments", Data: json.RawMessage(`{"max_limit":1}`)},
+		})
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeForbidden, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		expectNothingStored(mockRepo, "payments")
+		mockRepo.On("ApplyBatch", mock.Anything).Return(errors.NewConflictError("Configuration changed while the batch was being applied", nil))
+
+		// Call the method
+		_, err := useCase.ApplyBatch(context.Background(), "", []entity.BatchOperation{
+			{Op: entity.BatchOperationCreate, Name: "payments", Data: json.RawMessage(`{"max_limit":1000}`)},
+		})
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeConflict, err.(*errors.AppError).Code)
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		_, err := useCase.ApplyBatch(context.Background(), "", nil)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+
+		_, err = useCase.ApplyBatch(context.Background(), "", make([]entity.BatchOperation, entity.MaxBatchOperations+1))
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+	})
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// GetConfigurations reads the current or a given version of many configurations with a
+// single repository query. Configurations that do not exist or whose version was
+// compacted are reported on their item rather than failing the whole read.
+func (uc *ConfigurationUseCase) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error) {
+	if len(refs) == 0 {
+		return nil, errors.NewInvalidRequestError("At least one configuration name is required", nil)
+	}
+	if len(refs) > entity.MaxBulkReadNames {
+		return nil, errors.NewInvalidRequestError(
+			fmt.Sprintf("At most %d configurations can be read at once", entity.MaxBulkReadNames),
+			map[string]int{"names": len(refs)},
+		)
+	}
+	for _, ref := range refs {
+		if ref.Name == "" {
+			return nil, errors.NewInvalidRequestError("Configuration name is required", nil)
+		}
+		if ref.Version < 0 {
+			return nil, errors.NewInvalidRequestError("Invalid version", map[string]interface{}{
+				"name":    ref.Name,
+				"version": ref.Version,
+			})
+		}
+	}
+
+	items, err := uc.repo.GetConfigurations(ctx, refs)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get configurations", err)
+	}
+
+	// Fill defaults on read exactly as single reads do, loading every schema involved
+	// with one query
+	schemas, err := uc.readSchemas(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Configuration == nil {
+			continue
+		}
+		ref, ok := readSchemaRef(items[i])
+		if !ok {
+			continue
+		}
+		schema, ok := schemas[ref]
+		if !ok {
+			continue
+		}
+		config, err := uc.withSchemaReadDefaults(ctx, items[i].Configuration, schema)
+		if err != nil {
+			return nil, err
+		}
+		items[i].Configuration = config
+	}
+
+	return items, nil
+}
+
+// readSchemaRef returns the schema a bulk read item is read with: the current schema for
+// a current read, as GetConfiguration does, and the recorded schema version for a given
+// version, as GetConfigurationVersion does. Versions stored without a schema are read
+// without defaults.
+func readSchemaRef(item entity.BulkReadItem) (entity.SchemaRef, bool) {
+	if item.Version == 0 {
+		return entity.SchemaRef{Name: item.Name}, true
+	}
+	if item.Configuration.SchemaVersion == 0 {
+		return entity.SchemaRef{}, false
+	}
+	return entity.SchemaRef{Name: item.Name, Version: item.Configuration.SchemaVersion}, true
+}
+
+// readSchemas loads the schemas the read items are read with in a single repository query
+func (uc *ConfigurationUseCase) readSchemas(ctx context.Context, items []entity.BulkReadItem) (map[entity.SchemaRef]json.RawMessage, error) {
+	refs := []entity.SchemaRef{}
+	seen := make(map[entity.SchemaRef]bool, len(items))
+	for _, item := range items {
+		if item.Configuration == nil {
+			continue
+		}
+		ref, ok := readSchemaRef(item)
+		if ok && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	schemas, err := uc.repo.GetSchemas(ctx, refs)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to load schemas", err)
+	}
+	return schemas, nil
+}
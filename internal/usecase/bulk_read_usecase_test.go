@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_GetConfigurations(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		refs := []entity.ConfigurationRef{{Name: "payments"}, {Name: "missing"}}
+		mockRepo.On("GetConfigurations", refs).Return([]entity.BulkReadItem{
+			{ConfigurationRef: refs[0], Configuration: &entity.Configuration{Name: "payments", Version: 1, Data: json.RawMessage(`{"max_limit":1000}`)}},
+			{ConfigurationRef: refs[1], Error: errors.NewNotFoundError("Configuration", "missing").ToErrorResponse()},
+		}, nil)
+		mockRepo.On("GetSchemas", []entity.SchemaRef{{Name: "payments"}}).Return(map[entity.SchemaRef]json.RawMessage{
+			{Name: "payments"}: json.RawMessage(`{"type":"object","x-apply-defaults":"read","properties":{"enabled":{"type":"boolean","default":true}}}`),
+		}, nil)
+
+		// Call the method
+		items, err := useCase.GetConfigurations(context.Background(), refs)
+
+		// Assertions
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.JSONEq(t, `{"max_limit":1000,"enabled":true}`, string(items[0].Configuration.Data))
+		assert.Equal(t, []string{"/enabled"}, items[0].Configuration.DefaultedFields)
+		assert.Equal(t, errors.ErrorCodeNotFound, items[1].Error.Code)
+		mockRepo.AssertNotCalled(t, "GetConfiguration", mock.Anything)
+		mockRepo.AssertNotCalled(t, "GetSchema", mock.Anything)
+	})
+
+	t.Run("LoadsSchemasInOneQuery", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		current := json.RawMessage(`{"type":"object","x-apply-defaults":"read","properties":{"enabled":{"type":"boolean","default":true}}}`)
+		recorded := json.RawMessage(`{"type":"object","x-apply-defaults":"read","properties":{"region":{"type":"string","default":"eu"}}}`)
+		refs := []entity.ConfigurationRef{{Name: "payments"}, {Name: "payments", Version: 1}, {Name: "payments", Version: 2}, {Name: "routing"}}
+		mockRepo.On("GetConfigurations", refs).Return([]entity.BulkReadItem{
+			{ConfigurationRef: refs[0], Configuration: &entity.Configuration{Name: "payments", Version: 3, SchemaVersion: 2, Data: json.RawMessage(`{}`)}},
+			{ConfigurationRef: refs[1], Configuration: &entity.Configuration{Name: "payments", Version: 1, Data: json.RawMessage(`{}`)}},
+			{ConfigurationRef: refs[2], Configuration: &entity.Configuration{Name: "payments", Version: 2, SchemaVersion: 1, Data: json.RawMessage(`{}`)}},
+			{ConfigurationRef: refs[3], Configuration: &entity.Configuration{Name: "routing", Version: 1, Data: json.RawMessage(`{}`)}},
+		}, nil)
+		mockRepo.On("GetSchemas", []entity.SchemaRef{{Name: "payments"}, {Name: "payments", Version: 1}, {Name: "routing"}}).Return(map[entity.SchemaRef]json.RawMessage{
+			{Name: "payments"}:             current,
+			{Name: "payments", Version: 1}: recorded,
+		}, nil).Once()
+
+		// Call the method
+		items, err := useCase.GetConfigurations(context.Background(), refs)
+
+		// Assertions
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		assert.JSONEq(t, `{"enabled":true}`, string(items[0].Configuration.Data))
+		assert.JSONEq(t, `{}`, string(items[1].Configuration.Data), "versions stored without a schema get no defaults")
+		assert.JSONEq(t, `{"region":"eu"}`, string(items[2].Configuration.Data), "given versions use their recorded schema")
+		assert.JSONEq(t, `{}`, string(items[3].Configuration.Data))
+		mockRepo.AssertNotCalled(t, "GetSchema", mock.Anything)
+		mockRepo.AssertNotCalled(t, "GetSchemaVersion", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SchemaLoadFails", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		refs := []entity.ConfigurationRef{{Name: "payments"}}
+		mockRepo.On("GetConfigurations", refs).Return([]entity.BulkReadItem{
+			{ConfigurationRef: refs[0], Configuration: &entity.Configuration{Name: "payments", Version: 1, Data: json.RawMessage(`{}`)}},
+		}, nil)
+		mockRepo.On("GetSchemas", mock.Anything).Return(nil, errors.NewInternalError("Database error", nil))
+
+		// Call the method
+		_, err := useCase.GetConfigurations(context.Background(), refs)
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInternalError, err.(*errors.AppError).Code)
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		for _, refs := range [][]entity.ConfigurationRef{
+			nil,
+			make([]entity.ConfigurationRef, entity.MaxBulkReadNames+1),
+			{{Name: ""}},
+			{{Name: "payments", Version: -1}},
+		} {
+			_, err := useCase.GetConfigurations(context.Background(), refs)
+			require.Error(t, err)
+			assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+		}
+		mockRepo.AssertNotCalled(t, "GetConfigurations", mock.Anything)
+	})
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/jsondiff"
+	"strings"
+	"time"
+)
+
+// CreateChangeRequest proposes a configuration update that goes live once approved
+func (uc *ConfigurationUseCase) CreateChangeRequest(ctx context.Context, name string, data json.RawMessage, author string) (*entity.ChangeRequest, error) {
+	if author == "" {
+		return nil, errors.NewInvalidRequestError("Client identity is required", nil)
+	}
+
+	// Check if configuration exists
+	existingConfig, err := uc.repo.GetConfiguration(ctx, name)
+	if err != nil || existingConfig == nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	// Validate against the current schema so reviewers only see valid proposals;
+	// the data is validated again when the request is applied
+	schema, err := uc.repo.GetSchema(ctx, name)
+	if err == nil && schema != nil {
+		if err := uc.validateData(ctx, name, schema, data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check custom validation rules
+	if err := uc.checkRules(ctx, name, data); err != nil {
+		return nil, err
+	}
+
+	diff, err := jsondiff.Diff(existingConfig.Data, data)
+	if err != nil {
+		return nil, errors.NewInvalidRequestError("Invalid configuration data", err.Error())
+	}
+
+	// Every change request needs at least one approval, even where the policy
+	// does not require them for direct updates
+	required := uc.approvalPolicy.RequiredApprovals(name)
+	if required < 1 {
+		required = 1
+	}
+
+	cr := entity.NewChangeRequest(name, data, existingConfig.Version, diff, author, required)
+	if err := uc.repo.CreateChangeRequest(ctx, cr); err != nil {
+		return nil, internalError(ctx, "Failed to create change request", err)
+	}
+
+	return cr, nil
+}
+
+// GetChangeRequest retrieves a change request of a configuration
+func (uc *ConfigurationUseCase) GetChangeRequest(ctx context.Context, name string, id int64) (*entity.ChangeRequest, error) {
+	return uc.getChangeRequest(ctx, name, id)
+}
+
+// ListChangeRequests lists the change requests of a configuration, optionally filtered by status
+func (uc *ConfigurationUseCase) ListChangeRequests(ctx context.Context, name string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error) {
+	// Check if configuration exists
+	if _, err := uc.repo.GetConfiguration(ctx, name); err != nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	changeRequests, err := uc.repo.ListChangeRequests(ctx, name, status)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list change requests", err)
+	}
+
+	return changeRequests, nil
+}
+
+// ApproveChangeRequest records an approval and applies the change request once
+// it has collected the required number of approvals
+func (uc *ConfigurationUseCase) ApproveChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error) {
+	cr, err := uc.getPendingChangeRequest(ctx, name, id, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cr.Author == clientID {
+		return nil, errors.NewForbiddenError("Change requests cannot be approved by their author", map[string]string{
+			"client_id": clientID,
+		})
+	}
+
+	if !cr.HasApprovalFrom(clientID) {
+		cr.Approvals = append(cr.Approvals, entity.ChangeRequestApproval{ClientID: clientID, CreatedAt: time.Now().UTC()})
+
+		// The approval that completes the request is recorded together with applying it,
+		// so a request that cannot be applied is never left approved and pending
+		if !cr.IsApproved() {
+			if err := uc.repo.AddChangeRequestApproval(ctx, cr.ID, cr.Approvals[len(cr.Approvals)-1]); err != nil {
+				return nil, internalError(ctx, "Failed to record approval", err)
+			}
+			return cr, nil
+		}
+	}
+
+	if !cr.IsApproved() {
+		return cr, nil
+	}
+
+	if err := uc.applyChangeRequest(ctx, cr); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// RejectChangeRequest closes a pending change request without applying it
+func (uc *ConfigurationUseCase) RejectChangeRequest(ctx context.Context, name string, id int64, clientID string, reason string) (*entity.ChangeRequest, error) {
+	cr, err := uc.getPendingChangeRequest(ctx, name, id, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	cr.MarkRejected(reason)
+	if err := uc.repo.UpdateChangeRequest(ctx, cr); err != nil {
+		// The request was applied or closed after it was read
+		if isConflict(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to reject change request", err)
+	}
+
+	return cr, nil
+}
+
+// WithdrawChangeRequest lets the author close a pending change request
+func (uc *ConfigurationUseCase) WithdrawChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error) {
+	cr, err := uc.getPendingChangeRequest(ctx, name, id, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cr.Author != clientID {
+		return nil, errors.NewForbiddenError("Only the author can withdraw a change request", map[string]string{
+			"client_id": clientID,
+		})
+	}
+
+	cr.MarkWithdrawn()
+	if err := uc.repo.UpdateChangeRequest(ctx, cr); err != nil {
+		// The request was applied or closed after it was read
+		if isConflict(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to withdraw change request", err)
+	}
+
+	return cr, nil
+}
+
+// CommentOnChangeRequest adds a review comment to a change request
+func (uc *ConfigurationUseCase) CommentOnChangeRequest(ctx context.Context, name string, id int64, clientID string, body string) (*entity.ChangeRequestComment, error) {
+	if clientID == "" {
+		return nil, errors.NewInvalidRequestError("Client identity is required", nil)
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, errors.NewInvalidRequestError("Comment body is required", nil)
+	}
+
+	cr, err := uc.getChangeRequest(ctx, name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &entity.ChangeRequestComment{
+		ClientID:  clientID,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := uc.repo.AddChangeRequestComment(ctx, cr.ID, comment); err != nil {
+		return nil, internalError(ctx, "Failed to add comment", err)
+	}
+
+	return comment, nil
+}
+
+// applyChangeRequest re-validates an approved change request and applies it atomically
+func (uc *ConfigurationUseCase) applyChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error {
+	currentConfig, err := uc.repo.GetConfiguration(ctx, cr.Name)
+	if err != nil || currentConfig == nil {
+		return notFoundError(ctx, err, "Configuration", cr.Name)
+	}
+
+	// The schema may have changed since the request was created
+	data := cr.Data
+	schema, err := uc.repo.GetSchema(ctx, cr.Name)
+	if err == nil && schema != nil {
+		if data, _, err = uc.applyDefaults(ctx, entity.DefaultsModeWrite, schema, data); err != nil {
+			return err
+		}
+		if err := uc.validateData(ctx, cr.Name, schema, data); err != nil {
+			return err
+		}
+	}
+
+	// Check custom validation rules
+	if err := uc.checkRules(ctx, cr.Name, data); err != nil {
+		return err
+	}
+
+	newConfig := currentConfig.UpdateVersion(data)
+	cr.MarkApplied(newConfig.Version)
+
+	// The repository closes the request as stale if the configuration moved past the
+	// base version; otherwise a failed request is still pending
+	if err := uc.repo.ApplyChangeRequest(ctx, cr, newConfig); err != nil {
+		if cr.Status == entity.ChangeRequestStatusApplied {
+			cr.Status = entity.ChangeRequestStatusPending
+			cr.AppliedVersion = 0
+		}
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			return appErr
+		}
+		return internalError(ctx, "Failed to apply change request", err)
+	}
+
+	return nil
+}
+
+// getChangeRequest retrieves a change request and checks it belongs to the configuration
+func (uc *ConfigurationUseCase) getChangeRequest(ctx context.Context, name string, id int64) (*entity.ChangeRequest, error) {
+	cr, err := uc.repo.GetChangeRequest(ctx, id)
+	if err != nil || cr == nil || cr.Name != name {
+		return nil, notFoundError(ctx, err, "Change request", fmt.Sprintf("%d", id))
+	}
+
+	return cr, nil
+}
+
+// getPendingChangeRequest retrieves a change request that is still open for review
+func (uc *ConfigurationUseCase) getPendingChangeRequest(ctx context.Context, name string, id int64, clientID string) (*entity.ChangeRequest, error) {
+	if clientID == "" {
+		return nil, errors.NewInvalidRequestError("Client identity is required", nil)
+	}
+
+	cr, err := uc.getChangeRequest(ctx, name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cr.IsPending() {
+		return nil, errors.NewInvalidRequestError("Only pending change requests can be reviewed", map[string]string{
+			"status": string(cr.Status),
+		})
+	}
+
+	return cr, nil
+}
+
+// requireDirectWrite rejects direct changes to configurations that require approval
+func (uc *ConfigurationUseCase) requireDirectWrite(name string) error {
+	if required := uc.approvalPolicy.RequiredApprovals(name); required > 0 {
+		return errors.NewForbiddenError("Configuration requires an approved change request", map[string]interface{}{
+			"id":                 name,
+			"required_approvals": required,
+		})
+	}
+	return nil
+}
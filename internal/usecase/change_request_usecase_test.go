@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConfigurationUseCase_CreateChangeRequest(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithApprovalPolicy(entity.ApprovalPolicy{
+			{Pattern: "prod-*", RequiredApprovals: 2},
+		}))
+
+		current := &entity.Configuration{Name: "prod-payments", Version: 3, Data: json.RawMessage(`{"limit":100}`)}
+		mockRepo.On("GetConfiguration", "prod-payments").Return(current, nil)
+		mockRepo.On("GetSchema", "prod-payments").Return(nil, errors.NewNotFoundError("Schema", "prod-payments"))
+		mockRepo.On("GetRuleSet", "prod-payments").Return(nil, errors.NewNotFoundError("Rule set", "prod-payments"))
+		mockRepo.On("CreateChangeRequest", mock.AnythingOfType("*entity.ChangeRequest")).Return(nil)
+
+		// Call the method
+		result, err := useCase.CreateChangeRequest(context.Background(), "prod-payments", json.RawMessage(`{"limit":200}`), "alice")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.BaseVersion)
+		assert.Equal(t, 2, result.RequiredApprovals)
+		assert.Equal(t, "alice", result.Author)
+		assert.Equal(t, entity.ChangeRequestStatusPending, result.Status)
+		assert.Len(t, result.Diff, 1)
+		assert.Equal(t, "/limit", result.Diff[0].Path)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnprotectedConfigurationNeedsOneApproval", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 1, Data: json.RawMessage(`{}`)}, nil)
+		mockRepo.On("GetSchema", "test-config").Return(nil, errors.NewNotFoundError("Schema", "test-config"))
+		mockRepo.On("GetRuleSet", "test-config").Return(nil, errors.NewNotFoundError("Rule set", "test-config"))
+		mockRepo.On("CreateChangeRequest", mock.AnythingOfType("*entity.ChangeRequest")).Return(nil)
+
+		// Call the method
+		result, err := useCase.CreateChangeRequest(context.Background(), "test-config", json.RawMessage(`{"a":1}`), "alice")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.RequiredApprovals)
+	})
+
+	t.Run("MissingClient", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		_, err := useCase.CreateChangeRequest(context.Background(), "test-config", json.RawMessage(`{}`), "")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+	})
+}
+
+func TestConfigurationUseCase_ApproveChangeRequest(t *testing.T) {
+	newPending := func(required int) *entity.ChangeRequest {
+		cr := entity.NewChangeRequest("prod-payments", json.RawMessage(`{"limit":200}`), 3, nil, "alice", required)
+		cr.ID = 7
+		return cr
+	}
+
+	t.Run("RecordsApprovalBelowThreshold", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetChangeRequest", int64(7)).Return(newPending(2), nil)
+		mockRepo.On("AddChangeRequestApproval", int64(7), mock.AnythingOfType("entity.ChangeRequestApproval")).Return(nil)
+
+		// Call the method
+		result, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "bob")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ChangeRequestStatusPending, result.Status)
+		assert.Len(t, result.Approvals, 1)
+		mockRepo.AssertNotCalled(t, "ApplyChangeRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AppliesWhenApproved", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		current := &entity.Configuration{Name: "prod-payments", Version: 3, Data: json.RawMessage(`{"limit":100}`)}
+		mockRepo.On("GetChangeRequest", int64(7)).Return(newPending(1), nil)
+		mockRepo.On("GetConfiguration", "prod-payments").Return(current, nil)
+		mockRepo.On("GetSchema", "prod-payments").Return(nil, errors.NewNotFoundError("Schema", "prod-payments"))
+		mockRepo.On("GetRuleSet", "prod-payments").Return(nil, errors.NewNotFoundError("Rule set", "prod-payments"))
+		mockRepo.On("ApplyChangeRequest", mock.AnythingOfType("*entity.ChangeRequest"), mock.AnythingOfType("*entity.Configuration")).Return(nil)
+
+		// Call the method
+		result, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "bob")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ChangeRequestStatusApplied, result.Status)
+		assert.Equal(t, 4, result.AppliedVersion)
+
+		// The final approval is recorded together with applying the request
+		assert.Len(t, result.Approvals, 1)
+		mockRepo.AssertNotCalled(t, "AddChangeRequestApproval", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("StaleBase", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		current := &entity.Configuration{Name: "prod-payments", Version: 4, Data: json.RawMessage(`{"limit":150}`)}
+		mockRepo.On("GetChangeRequest", int64(7)).Return(newPending(1), nil)
+		mockRepo.On("GetConfiguration", "prod-payments").Return(current, nil)
+		mockRepo.On("GetSchema", "prod-payments").Return(nil, errors.NewNotFoundError("Schema", "prod-payments"))
+		mockRepo.On("GetRuleSet", "prod-payments").Return(nil, errors.NewNotFoundError("Rule set", "prod-payments"))
+		// The repository closes the request as stale along with recording the approval
+		mockRepo.On("ApplyChangeRequest", mock.AnythingOfType("*entity.ChangeRequest"), mock.AnythingOfType("*entity.Configuration")).
+			Run(func(args mock.Arguments) {
+				args.Get(0).(*entity.ChangeRequest).MarkStale("Configuration moved from version 3 to 4 before the change request was approved")
+			}).
+			Return(errors.NewConflictError("Configuration changed since the change request was created", nil))
+
+		// Call the method
+		result, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "bob")
+
+		// Assertions
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		mockRepo.AssertNotCalled(t, "AddChangeRequestApproval", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthorCannotApprove", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetChangeRequest", int64(7)).Return(newPending(1), nil)
+
+		// Call the method
+		_, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "alice")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeForbidden, appErr.Code)
+		mockRepo.AssertNotCalled(t, "AddChangeRequestApproval", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RepeatedApprovalDoesNotCount", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		cr := newPending(2)
+		cr.Approvals = []entity.ChangeRequestApproval{{ClientID: "bob"}}
+		mockRepo.On("GetChangeRequest", int64(7)).Return(cr, nil)
+
+		// Call the method
+		result, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "bob")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Len(t, result.Approvals, 1)
+		assert.Equal(t, entity.ChangeRequestStatusPending, result.Status)
+		mockRepo.AssertNotCalled(t, "AddChangeRequestApproval", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotPending", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		cr := newPending(1)
+		cr.MarkWithdrawn()
+		mockRepo.On("GetChangeRequest", int64(7)).Return(cr, nil)
+
+		// Call the method
+		_, err := useCase.ApproveChangeRequest(context.Background(), "prod-payments", 7, "bob")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+	})
+}
+
+func TestConfigurationUseCase_RejectAndWithdrawChangeRequest(t *testing.T) {
+	t.Run("Reject", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		cr := entity.NewChangeRequest("test-config", json.RawMessage(`{}`), 1, nil, "alice", 1)
+		cr.ID = 1
+		mockRepo.On("GetChangeRequest", int64(1)).Return(cr, nil)
+		mockRepo.On("UpdateChangeRequest", cr).Return(nil)
+
+		// Call the method
+		result, err := useCase.RejectChangeRequest(context.Background(), "test-config", 1, "bob", "limit too high")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ChangeRequestStatusRejected, result.Status)
+		assert.Equal(t, "limit too high", result.Reason)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectAfterApplied", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// The request was applied by a concurrent approval after it was read
+		cr := entity.NewChangeRequest("test-config", json.RawMessage(`{}`), 1, nil, "alice", 1)
+		cr.ID = 1
+		mockRepo.On("GetChangeRequest", int64(1)).Return(cr, nil)
+		mockRepo.On("UpdateChangeRequest", cr).Return(errors.NewConflictError("Change request is no longer pending", map[string]string{
+			"status": string(entity.ChangeRequestStatusApplied),
+		}))
+
+		// Call the method
+		result, err := useCase.RejectChangeRequest(context.Background(), "test-config", 1, "bob", "limit too high")
+
+		// Assertions
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("WithdrawByOtherClient", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		cr := entity.NewChangeRequest("test-config", json.RawMessage(`{}`), 1, nil, "alice", 1)
+		cr.ID = 1
+		mockRepo.On("GetChangeRequest", int64(1)).Return(cr, nil)
+
+		// Call the method
+		_, err := useCase.WithdrawChangeRequest(context.Background(), "test-config", 1, "bob")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeForbidden, appErr.Code)
+		mockRepo.AssertNotCalled(t, "UpdateChangeRequest", mock.Anything)
+	})
+
+	t.Run("WrongConfiguration", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		cr := entity.NewChangeRequest("other-config", json.RawMessage(`{}`), 1, nil, "alice", 1)
+		mockRepo.On("GetChangeRequest", int64(1)).Return(cr, nil)
+
+		// Call the method
+		_, err := useCase.WithdrawChangeRequest(context.Background(), "test-config", 1, "alice")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeNotFound, appErr.Code)
+	})
+}
+
+func TestConfigurationUseCase_ProtectedConfiguration(t *testing.T) {
+	policy := WithApprovalPolicy(entity.ApprovalPolicy{{Pattern: "prod-*", RequiredApprovals: 2}})
+
+	t.Run("UpdateRejected", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, policy)
+
+		mockRepo.On("GetConfiguration", "prod-payments").Return(&entity.Configuration{Name: "prod-payments", Version: 1}, nil)
+
+		// Call the method
+		_, err := useCase.UpdateConfiguration(context.Background(), "prod-payments", json.RawMessage(`{}`))
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeForbidden, appErr.Code)
+		mockRepo.AssertNotCalled(t, "UpdateConfiguration", mock.Anything)
+	})
+
+	t.Run("RollbackRejected", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, policy)
+
+		mockRepo.On("GetConfiguration", "prod-payments").Return(&entity.Configuration{Name: "prod-payments", Version: 2}, nil)
+
+		// Call the method
+		_, err := useCase.RollbackConfiguration(context.Background(), "prod-payments", 1)
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeForbidden, appErr.Code)
+		mockRepo.AssertNotCalled(t, "GetVersionData", mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigurationUseCase_CommentOnChangeRequest(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	cr := entity.NewChangeRequest("test-config", json.RawMessage(`{}`), 1, nil, "alice", 1)
+	cr.ID = 1
+	mockRepo.On("GetChangeRequest", int64(1)).Return(cr, nil)
+	mockRepo.On("AddChangeRequestComment", int64(1), mock.AnythingOfType("*entity.ChangeRequestComment")).Return(nil)
+
+	// Call the method
+	comment, err := useCase.CommentOnChangeRequest(context.Background(), "test-config", 1, "bob", "Looks good")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", comment.ClientID)
+	assert.Equal(t, "Looks good", comment.Body)
+
+	_, err = useCase.CommentOnChangeRequest(context.Background(), "test-config", 1, "bob", "  ")
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,18 +1,31 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/Titonu/configuration-management-service/internal/domain/repository"
 	"github.com/Titonu/configuration-management-service/internal/domain/usecase"
 	"github.com/Titonu/configuration-management-service/pkg/errors"
 	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"time"
 )
 
 // ConfigurationUseCase implements the configuration service interface
 type ConfigurationUseCase struct {
-	repo      repository.ConfigurationRepository
-	validator validator.Validator
+	repo           repository.ConfigurationRepository
+	validator      validator.Validator
+	approvalPolicy entity.ApprovalPolicy
+
+	schemaCompatibility entity.SchemaCompatibility
+	strictFormats       bool
+	wrapValidator       func(validator.Validator) validator.Validator
+	tracer              trace.Tracer
 }
 
 // SetValidator sets the validator for testing purposes
@@ -21,180 +34,332 @@ func (uc *ConfigurationUseCase) SetValidator(v validator.Validator) {
 }
 
 // NewConfigurationUseCase creates a new configuration use case
-func NewConfigurationUseCase(repo repository.ConfigurationRepository) usecase.ConfigurationUsecase {
-	return &ConfigurationUseCase{
-		repo:      repo,
-		validator: validator.NewJSONSchemaValidator(),
+func NewConfigurationUseCase(repo repository.ConfigurationRepository, opts ...Option) usecase.ConfigurationUsecase {
+	uc := &ConfigurationUseCase{repo: repo}
+	for _, opt := range opts {
+		opt(uc)
 	}
+	uc.validator = validator.NewJSONSchemaValidatorWithSharedSchemas(uc.loadSharedSchema, validator.WithStrictFormats(uc.strictFormats))
+	if uc.wrapValidator != nil {
+		uc.validator = uc.wrapValidator(uc.validator)
+	}
+	return uc
 }
 
 // CreateConfiguration creates a new configuration
-func (uc *ConfigurationUseCase) CreateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error) {
+func (uc *ConfigurationUseCase) CreateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error) {
 	// Check if configuration already exists
-	existingConfig, err := uc.repo.GetConfiguration(name)
+	existingConfig, err := uc.repo.GetConfiguration(ctx, name)
 	if err == nil && existingConfig != nil {
 		return nil, errors.NewAlreadyExistsError("Configuration", name)
 	}
 
-	// Check if schema exists and validate against it
-	schema, err := uc.repo.GetSchema(name)
-	if err == nil && schema != nil {
-		if err := uc.validator.ValidateJSON(schema, data); err != nil {
-			return nil, err
-		}
+	// Fill schema defaults, validate against the schema and the custom rules
+	data, defaulted, err := uc.prepareData(ctx, name, data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create new configuration
 	config := entity.NewConfiguration(name, data)
+	config.DefaultedFields = defaulted
 
 	// Store in repository
-	if err := uc.repo.CreateConfiguration(config); err != nil {
-		return nil, errors.NewInternalError("Failed to create configuration", err.Error())
+	if err := uc.repo.CreateConfiguration(ctx, config); err != nil {
+		return nil, internalError(ctx, "Failed to create configuration", err)
 	}
 
 	// Store version data
-	if err := uc.repo.StoreVersionData(name, config.Version, data); err != nil {
-		return nil, errors.NewInternalError("Failed to store version data", err.Error())
+	if err := uc.repo.StoreVersionData(ctx, name, config.Version, data); err != nil {
+		return nil, internalError(ctx, "Failed to store version data", err)
 	}
 
 	return config, nil
 }
 
+// prepareData fills the defaults of the configuration's schema when it stores them, then
+// validates the data against the schema and the custom validation rules
+func (uc *ConfigurationUseCase) prepareData(ctx context.Context, name string, data json.RawMessage) (json.RawMessage, []string, error) {
+	schema, err := uc.repo.GetSchema(ctx, name)
+	var defaulted []string
+	if err == nil && schema != nil {
+		if data, defaulted, err = uc.applyDefaults(ctx, entity.DefaultsModeWrite, schema, data); err != nil {
+			return nil, nil, err
+		}
+		if err := uc.validateData(ctx, name, schema, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := uc.checkRules(ctx, name, data); err != nil {
+		return nil, nil, err
+	}
+	return data, defaulted, nil
+}
+
 // UpdateConfiguration updates an existing configuration
-func (uc *ConfigurationUseCase) UpdateConfiguration(name string, data json.RawMessage) (*entity.Configuration, error) {
+func (uc *ConfigurationUseCase) UpdateConfiguration(ctx context.Context, name string, data json.RawMessage) (*entity.Configuration, error) {
 	// Check if configuration exists
-	existingConfig, err := uc.repo.GetConfiguration(name)
+	existingConfig, err := uc.repo.GetConfiguration(ctx, name)
 	if err != nil || existingConfig == nil {
-		return nil, errors.NewNotFoundError("Configuration", name)
+		return nil, notFoundError(ctx, err, "Configuration", name)
 	}
 
-	// Check if schema exists and validate against it
-	schema, err := uc.repo.GetSchema(name)
-	if err == nil && schema != nil {
-		if err := uc.validator.ValidateJSON(schema, data); err != nil {
-			return nil, err
-		}
+	// Protected configurations only change through approved change requests
+	if err := uc.requireDirectWrite(name); err != nil {
+		return nil, err
+	}
+
+	// Fill schema defaults, validate against the schema and the custom rules
+	data, defaulted, err := uc.prepareData(ctx, name, data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create new version
 	newConfig := existingConfig.UpdateVersion(data)
+	newConfig.DefaultedFields = defaulted
 
 	// Store in repository
-	if err := uc.repo.UpdateConfiguration(newConfig); err != nil {
-		return nil, errors.NewInternalError("Failed to update configuration", err.Error())
+	if err := uc.repo.UpdateConfiguration(ctx, newConfig); err != nil {
+		return nil, internalError(ctx, "Failed to update configuration", err)
 	}
 
 	// Store version data
-	if err := uc.repo.StoreVersionData(name, newConfig.Version, data); err != nil {
-		return nil, errors.NewInternalError("Failed to store version data", err.Error())
+	if err := uc.repo.StoreVersionData(ctx, name, newConfig.Version, data); err != nil {
+		return nil, internalError(ctx, "Failed to store version data", err)
 	}
 
 	return newConfig, nil
 }
 
 // GetConfiguration retrieves a configuration by name
-func (uc *ConfigurationUseCase) GetConfiguration(name string) (*entity.Configuration, error) {
-	config, err := uc.repo.GetConfiguration(name)
+func (uc *ConfigurationUseCase) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
+	config, err := uc.repo.GetConfiguration(ctx, name)
 	if err != nil {
-		return nil, errors.NewNotFoundError("Configuration", name)
+		return nil, notFoundError(ctx, err, "Configuration", name)
 	}
 
-	return config, nil
+	return uc.withReadDefaults(ctx, config)
 }
 
 // GetConfigurationVersion retrieves a specific version of a configuration
-func (uc *ConfigurationUseCase) GetConfigurationVersion(name string, version int) (*entity.Configuration, error) {
-	config, err := uc.repo.GetConfigurationVersion(name, version)
+func (uc *ConfigurationUseCase) GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error) {
+	config, err := uc.repo.GetConfigurationVersion(ctx, name, version)
 	if err != nil {
-		return nil, errors.NewNotFoundError("Configuration version", name)
+		if isVersionCompacted(err) {
+			return nil, err
+		}
+		return nil, notFoundError(ctx, err, "Configuration version", name)
 	}
 
-	return config, nil
+	return uc.withVersionReadDefaults(ctx, config)
 }
 
 // ListConfigurationVersions lists all versions of a configuration
-func (uc *ConfigurationUseCase) ListConfigurationVersions(name string) (*entity.VersionList, error) {
+func (uc *ConfigurationUseCase) ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error) {
 	// Check if configuration exists
-	_, err := uc.repo.GetConfiguration(name)
+	_, err := uc.repo.GetConfiguration(ctx, name)
 	if err != nil {
-		return nil, errors.NewNotFoundError("Configuration", name)
+		return nil, notFoundError(ctx, err, "Configuration", name)
 	}
 
-	versions, err := uc.repo.ListConfigurationVersions(name)
+	versions, err := uc.repo.ListConfigurationVersions(ctx, name)
 	if err != nil {
-		return nil, errors.NewInternalError("Failed to list configuration versions", err.Error())
+		return nil, internalError(ctx, "Failed to list configuration versions", err)
 	}
 
 	return versions, nil
 }
 
+// GetConfigurationAsOf retrieves the version of a configuration that was effective at the given time
+func (uc *ConfigurationUseCase) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error) {
+	config, err := uc.repo.GetConfigurationAsOf(ctx, name, asOf.UTC())
+	if err != nil {
+		if isVersionCompacted(err) {
+			return nil, err
+		}
+		return nil, errors.NewAppError(
+			"Configuration not found at the requested time",
+			errors.ErrorCodeNotFound,
+			map[string]string{"id": name, "as_of": asOf.UTC().Format(time.RFC3339)},
+		)
+	}
+
+	return uc.withVersionReadDefaults(ctx, config)
+}
+
+// ListConfigurationsAsOf retrieves the effective version of every configuration at the
+// given time. Configurations whose effective version was compacted are reported on their
+// item rather than failing the whole list.
+func (uc *ConfigurationUseCase) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error) {
+	items, err := uc.repo.ListConfigurationsAsOf(ctx, asOf.UTC())
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list configurations", err)
+	}
+
+	for i := range items {
+		if items[i].Configuration == nil {
+			continue
+		}
+		if items[i].Configuration, err = uc.withVersionReadDefaults(ctx, items[i].Configuration); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
 // RollbackConfiguration rolls back a configuration to a previous version
-func (uc *ConfigurationUseCase) RollbackConfiguration(name string, targetVersion int) (*entity.Configuration, error) {
+func (uc *ConfigurationUseCase) RollbackConfiguration(ctx context.Context, name string, targetVersion int) (*entity.Configuration, error) {
 	// Check if configuration exists
-	currentConfig, err := uc.repo.GetConfiguration(name)
+	currentConfig, err := uc.repo.GetConfiguration(ctx, name)
 	if err != nil || currentConfig == nil {
-		return nil, errors.NewNotFoundError("Configuration", name)
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	// Protected configurations only change through approved change requests
+	if err := uc.requireDirectWrite(name); err != nil {
+		return nil, err
 	}
 
 	// Check if target version exists
-	targetData, err := uc.repo.GetVersionData(name, targetVersion)
+	targetData, err := uc.repo.GetVersionData(ctx, name, targetVersion)
+	if isVersionCompacted(err) {
+		return nil, err
+	}
 	if err != nil || targetData == nil {
-		return nil, errors.NewNotFoundError("Configuration version", name)
+		return nil, notFoundError(ctx, err, "Configuration version", name)
 	}
 
 	// Create new version from rollback
 	newConfig := entity.NewVersionFromRollback(currentConfig, targetVersion, targetData)
 
 	// Store in repository
-	if err := uc.repo.UpdateConfiguration(newConfig); err != nil {
-		return nil, errors.NewInternalError("Failed to rollback configuration", err.Error())
+	if err := uc.repo.UpdateConfiguration(ctx, newConfig); err != nil {
+		return nil, internalError(ctx, "Failed to rollback configuration", err)
 	}
 
 	// Store version data
-	if err := uc.repo.StoreVersionData(name, newConfig.Version, targetData); err != nil {
-		return nil, errors.NewInternalError("Failed to store version data", err.Error())
+	if err := uc.repo.StoreVersionData(ctx, name, newConfig.Version, targetData); err != nil {
+		return nil, internalError(ctx, "Failed to store version data", err)
 	}
 
 	return newConfig, nil
 }
 
-// RegisterSchema registers a JSON schema for a configuration
-func (uc *ConfigurationUseCase) RegisterSchema(configName string, schema json.RawMessage) error {
+// RegisterSchema registers a JSON schema as the next schema version of a configuration.
+// Unless forced, the schema must be compatible with the current schema under the
+// configured compatibility mode, and the most recent configuration versions must conform to it.
+func (uc *ConfigurationUseCase) RegisterSchema(ctx context.Context, configName string, schema json.RawMessage, opts entity.SchemaRegistrationOptions) (*entity.SchemaVersion, error) {
 	// Validate schema definition
-	if err := uc.validator.ValidateSchemaDefinition(schema); err != nil {
-		return err
+	schema, err := withDefaultDraft(schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.validator.ValidateSchemaDefinition(ctx, schema); err != nil {
+		return nil, validatorError(ctx, err)
+	}
+	if _, err := defaultsMode(schema); err != nil {
+		return nil, err
+	}
+
+	if !opts.Force {
+		// Check compatibility with the current schema
+		if uc.schemaCompatibility == entity.SchemaCompatibilityBackward {
+			if err := uc.checkSchemaCompatibility(ctx, configName, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		// Check that existing configuration data still conforms
+		if err := uc.checkExistingData(ctx, configName, schema, opts.CheckVersions); err != nil {
+			return nil, err
+		}
 	}
 
 	// Store schema
-	if err := uc.repo.RegisterSchema(configName, schema); err != nil {
-		return errors.NewInternalError("Failed to register schema", err.Error())
+	schemaVersion, err := uc.repo.RegisterSchema(ctx, configName, schema)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to register schema", err)
 	}
+	uc.invalidateSchema(configName)
 
-	return nil
+	return schemaVersion, nil
 }
 
 // GetSchema retrieves the JSON schema for a configuration
-func (uc *ConfigurationUseCase) GetSchema(configName string) (json.RawMessage, error) {
-	schema, err := uc.repo.GetSchema(configName)
+func (uc *ConfigurationUseCase) GetSchema(ctx context.Context, configName string) (json.RawMessage, error) {
+	schema, err := uc.repo.GetSchema(ctx, configName)
 	if err != nil {
-		return nil, errors.NewNotFoundError("Schema", configName)
+		return nil, notFoundError(ctx, err, "Schema", configName)
 	}
 
 	return schema, nil
 }
 
 // ValidateConfigurationData validates configuration data against its schema
-func (uc *ConfigurationUseCase) ValidateConfigurationData(configName string, data json.RawMessage) error {
+func (uc *ConfigurationUseCase) ValidateConfigurationData(ctx context.Context, configName string, data json.RawMessage) error {
 	// Get schema
-	schema, err := uc.repo.GetSchema(configName)
+	schema, err := uc.repo.GetSchema(ctx, configName)
 	if err != nil {
-		return errors.NewNotFoundError("Schema", configName)
+		return notFoundError(ctx, err, "Schema", configName)
 	}
 
 	// Validate data against schema
-	if err := uc.validator.ValidateJSON(schema, data); err != nil {
+	if err := uc.validateData(ctx, configName, schema, data); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// validateData validates data against the schema of a configuration, reusing the
+// compiled schema when the validator caches them. The span of the validation includes
+// compiling the schema when it is not cached yet.
+func (uc *ConfigurationUseCase) validateData(ctx context.Context, name string, schema json.RawMessage, data json.RawMessage) (err error) {
+	tracer := uc.tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer("")
+	}
+	_, span := tracer.Start(ctx, "Validator.ValidateConfiguration", trace.WithAttributes(attribute.String("config.name", name)))
+	defer func() {
+		span.SetAttributes(attribute.Bool("validation.valid", err == nil))
+		if err != nil && !isValidationFailed(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if cached, ok := uc.validator.(validator.CachingValidator); ok {
+		return validatorError(ctx, cached.ValidateConfiguration(ctx, name, schema, data))
+	}
+	return validatorError(ctx, uc.validator.ValidateJSON(ctx, schema, data))
+}
+
+// withDefaultDraft declares draft 2020-12 in a new schema that does not declare its
+// draft. Stored schemas without $schema keep validating as draft-07.
+func withDefaultDraft(schema json.RawMessage) (json.RawMessage, error) {
+	stamped, err := validator.WithDefaultDraft(schema)
+	if err != nil {
+		return nil, errors.NewInvalidRequestError(
+			"Invalid JSON Schema",
+			fmt.Sprintf("Schema validation error: %s", err.Error()),
+		)
+	}
+	return stamped, nil
+}
+
+// invalidateSchema drops the compiled schema of a configuration after its schema changed
+func (uc *ConfigurationUseCase) invalidateSchema(name string) {
+	if cached, ok := uc.validator.(validator.CachingValidator); ok {
+		cached.InvalidateSchema(name)
+	}
+}
+
+// invalidateAllSchemas drops every compiled schema after a shared schema changed
+func (uc *ConfigurationUseCase) invalidateAllSchemas() {
+	if cached, ok := uc.validator.(validator.CachingValidator); ok {
+		cached.InvalidateAllSchemas()
+	}
+}
@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/Titonu/configuration-management-service/internal/domain/entity"
 	"github.com/Titonu/configuration-management-service/internal/domain/repository"
 	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
 	"testing"
 	"time"
 
@@ -26,17 +28,17 @@ type MockConfigurationRepository struct {
 	mock.Mock
 }
 
-func (m *MockConfigurationRepository) CreateConfiguration(config *entity.Configuration) error {
+func (m *MockConfigurationRepository) CreateConfiguration(ctx context.Context, config *entity.Configuration) error {
 	args := m.Called(config)
 	return args.Error(0)
 }
 
-func (m *MockConfigurationRepository) UpdateConfiguration(config *entity.Configuration) error {
+func (m *MockConfigurationRepository) UpdateConfiguration(ctx context.Context, config *entity.Configuration) error {
 	args := m.Called(config)
 	return args.Error(0)
 }
 
-func (m *MockConfigurationRepository) GetConfiguration(name string) (*entity.Configuration, error) {
+func (m *MockConfigurationRepository) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,7 +46,7 @@ func (m *MockConfigurationRepository) GetConfiguration(name string) (*entity.Con
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationRepository) GetConfigurationVersion(name string, version int) (*entity.Configuration, error) {
+func (m *MockConfigurationRepository) GetConfigurationVersion(ctx context.Context, name string, version int) (*entity.Configuration, error) {
 	args := m.Called(name, version)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,7 +54,7 @@ func (m *MockConfigurationRepository) GetConfigurationVersion(name string, versi
 	return args.Get(0).(*entity.Configuration), args.Error(1)
 }
 
-func (m *MockConfigurationRepository) ListConfigurationVersions(name string) (*entity.VersionList, error) {
+func (m *MockConfigurationRepository) ListConfigurationVersions(ctx context.Context, name string) (*entity.VersionList, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -60,12 +62,31 @@ func (m *MockConfigurationRepository) ListConfigurationVersions(name string) (*e
 	return args.Get(0).(*entity.VersionList), args.Error(1)
 }
 
-func (m *MockConfigurationRepository) RegisterSchema(name string, schema json.RawMessage) error {
+func (m *MockConfigurationRepository) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*entity.Configuration, error) {
+	args := m.Called(name, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Configuration), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListConfigurationsAsOf(ctx context.Context, asOf time.Time) ([]entity.BulkReadItem, error) {
+	args := m.Called(asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.BulkReadItem), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) RegisterSchema(ctx context.Context, name string, schema json.RawMessage) (*entity.SchemaVersion, error) {
 	args := m.Called(name, schema)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaVersion), args.Error(1)
 }
 
-func (m *MockConfigurationRepository) GetSchema(name string) (json.RawMessage, error) {
+func (m *MockConfigurationRepository) GetSchema(ctx context.Context, name string) (json.RawMessage, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -73,12 +94,20 @@ func (m *MockConfigurationRepository) GetSchema(name string) (json.RawMessage, e
 	return args.Get(0).(json.RawMessage), args.Error(1)
 }
 
-func (m *MockConfigurationRepository) StoreVersionData(configName string, version int, data json.RawMessage) error {
+func (m *MockConfigurationRepository) GetSchemas(ctx context.Context, refs []entity.SchemaRef) (map[entity.SchemaRef]json.RawMessage, error) {
+	args := m.Called(refs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[entity.SchemaRef]json.RawMessage), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) StoreVersionData(ctx context.Context, configName string, version int, data json.RawMessage) error {
 	args := m.Called(configName, version, data)
 	return args.Error(0)
 }
 
-func (m *MockConfigurationRepository) GetVersionData(configName string, version int) (json.RawMessage, error) {
+func (m *MockConfigurationRepository) GetVersionData(ctx context.Context, configName string, version int) (json.RawMessage, error) {
 	args := m.Called(configName, version)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -86,17 +115,275 @@ func (m *MockConfigurationRepository) GetVersionData(configName string, version
 	return args.Get(0).(json.RawMessage), args.Error(1)
 }
 
+func (m *MockConfigurationRepository) CreateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetScheduledChange(ctx context.Context, id int64) (*entity.ScheduledChange, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListScheduledChanges(ctx context.Context, configName string) ([]*entity.ScheduledChange, error) {
+	args := m.Called(configName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListDueScheduledChanges(ctx context.Context, before time.Time) ([]*entity.ScheduledChange, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListStaleScheduledChanges(ctx context.Context, claimedBefore time.Time) ([]*entity.ScheduledChange, error) {
+	args := m.Called(claimedBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ScheduledChange), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) UpdateScheduledChange(ctx context.Context, change *entity.ScheduledChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) DeleteRetentionPolicy(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) ListConfigurationNames(ctx context.Context) ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) CompactVersions(ctx context.Context, name string, versions []int) error {
+	args := m.Called(name, versions)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) DeduplicateVersionData(ctx context.Context) (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockConfigurationRepository) SetVersionTag(ctx context.Context, tag *entity.VersionTag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetVersionTag(ctx context.Context, name, tag string) (*entity.VersionTag, error) {
+	args := m.Called(name, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VersionTag), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.VersionTag), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) DeleteVersionTag(ctx context.Context, name, tag string) error {
+	args := m.Called(name, tag)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) CreateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error {
+	args := m.Called(cr)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetChangeRequest(ctx context.Context, id int64) (*entity.ChangeRequest, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListChangeRequests(ctx context.Context, configName string, status entity.ChangeRequestStatus) ([]*entity.ChangeRequest, error) {
+	args := m.Called(configName, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ChangeRequest), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) UpdateChangeRequest(ctx context.Context, cr *entity.ChangeRequest) error {
+	args := m.Called(cr)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) AddChangeRequestApproval(ctx context.Context, id int64, approval entity.ChangeRequestApproval) error {
+	args := m.Called(id, approval)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) AddChangeRequestComment(ctx context.Context, id int64, comment *entity.ChangeRequestComment) error {
+	args := m.Called(id, comment)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) ApplyChangeRequest(ctx context.Context, cr *entity.ChangeRequest, config *entity.Configuration) error {
+	args := m.Called(cr, config)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) ImportArchive(ctx context.Context, archive *entity.ArchiveImport) error {
+	args := m.Called(archive)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) ApplyBatch(ctx context.Context, configs []*entity.Configuration) error {
+	args := m.Called(configs)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetConfigurations(ctx context.Context, refs []entity.ConfigurationRef) ([]entity.BulkReadItem, error) {
+	args := m.Called(refs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.BulkReadItem), args.Error(1)
+}
+
 // MockJSONSchemaValidator is a mock implementation of validator.JSONSchemaValidator
+func (m *MockConfigurationRepository) GetSchemaVersion(ctx context.Context, name string, version int) (*entity.SchemaVersion, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SchemaVersion), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListSchemaVersions(ctx context.Context, name string) ([]*entity.SchemaVersion, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.SchemaVersion), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListSchemas(ctx context.Context, limit, offset int) ([]*entity.SchemaVersion, int, error) {
+	args := m.Called(limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.SchemaVersion), args.Int(1), args.Error(2)
+}
+
+func (m *MockConfigurationRepository) DeleteSchema(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.UnvalidatedConfiguration), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) SetSharedSchema(ctx context.Context, schema *entity.SharedSchema) error {
+	args := m.Called(schema)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SharedSchema), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.SharedSchema), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) DeleteSharedSchema(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockConfigurationRepository) CreateRuleSet(ctx context.Context, name string, ruleList []rules.Rule) (*entity.RuleSet, error) {
+	args := m.Called(name, ruleList)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) GetRuleSet(ctx context.Context, name string) (*entity.RuleSet, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) GetRuleSetVersion(ctx context.Context, name string, version int) (*entity.RuleSet, error) {
+	args := m.Called(name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RuleSet), args.Error(1)
+}
+
+func (m *MockConfigurationRepository) ListRuleSetVersions(ctx context.Context, name string) ([]*entity.RuleSet, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.RuleSet), args.Error(1)
+}
+
 type MockJSONSchemaValidator struct {
 	mock.Mock
 }
 
-func (m *MockJSONSchemaValidator) ValidateJSON(schema json.RawMessage, data json.RawMessage) error {
+func (m *MockJSONSchemaValidator) ValidateJSON(ctx context.Context, schema json.RawMessage, data json.RawMessage) error {
 	args := m.Called(schema, data)
 	return args.Error(0)
 }
 
-func (m *MockJSONSchemaValidator) ValidateSchemaDefinition(schema json.RawMessage) error {
+func (m *MockJSONSchemaValidator) ValidateSchemaDefinition(ctx context.Context, schema json.RawMessage) error {
 	args := m.Called(schema)
 	return args.Error(0)
 }
@@ -115,6 +402,7 @@ func TestConfigurationUseCase_CreateConfiguration(t *testing.T) {
 
 		// No schema exists yet
 		mockRepo.On("GetSchema", name).Return(nil, errors.NewNotFoundError("Schema", name))
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
 
 		// Configuration creation should succeed
 		mockRepo.On("CreateConfiguration", mock.AnythingOfType("*entity.Configuration")).Return(nil)
@@ -128,7 +416,7 @@ func TestConfigurationUseCase_CreateConfiguration(t *testing.T) {
 		}
 
 		// Call the method
-		result, err := useCase.CreateConfiguration(name, data)
+		result, err := useCase.CreateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -155,6 +443,7 @@ func TestConfigurationUseCase_CreateConfiguration(t *testing.T) {
 
 		// Schema exists and is valid
 		mockRepo.On("GetSchema", name).Return(schema, nil)
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
 		mockValidator.On("ValidateJSON", schema, data).Return(nil)
 
 		// Configuration creation should succeed
@@ -162,7 +451,7 @@ func TestConfigurationUseCase_CreateConfiguration(t *testing.T) {
 		mockRepo.On("StoreVersionData", name, 1, data).Return(nil)
 
 		// Call the method
-		result, err := useCase.CreateConfiguration(name, data)
+		result, err := useCase.CreateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -194,7 +483,7 @@ func TestConfigurationUseCase_CreateConfiguration(t *testing.T) {
 		mockValidator.On("ValidateJSON", schema, data).Return(validationErr)
 
 		// Call the method
-		result, err := useCase.CreateConfiguration(name, data)
+		result, err := useCase.CreateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.Error(t, err)
@@ -224,13 +513,14 @@ func TestConfigurationUseCase_UpdateConfiguration(t *testing.T) {
 
 		// No schema exists
 		mockRepo.On("GetSchema", name).Return(nil, errors.NewNotFoundError("Schema", name))
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
 
 		// Update should succeed
 		mockRepo.On("UpdateConfiguration", mock.AnythingOfType("*entity.Configuration")).Return(nil)
 		mockRepo.On("StoreVersionData", name, 2, data).Return(nil)
 
 		// Call the method
-		result, err := useCase.UpdateConfiguration(name, data)
+		result, err := useCase.UpdateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -253,7 +543,7 @@ func TestConfigurationUseCase_UpdateConfiguration(t *testing.T) {
 		mockRepo.On("GetConfiguration", name).Return(nil, notFoundErr)
 
 		// Call the method
-		result, err := useCase.UpdateConfiguration(name, data)
+		result, err := useCase.UpdateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.Error(t, err)
@@ -285,6 +575,7 @@ func TestConfigurationUseCase_UpdateConfiguration(t *testing.T) {
 
 		// Schema exists and validation passes
 		mockRepo.On("GetSchema", name).Return(schema, nil)
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
 		mockValidator.On("ValidateJSON", schema, data).Return(nil)
 
 		// Update should succeed
@@ -292,7 +583,7 @@ func TestConfigurationUseCase_UpdateConfiguration(t *testing.T) {
 		mockRepo.On("StoreVersionData", name, 2, data).Return(nil)
 
 		// Call the method
-		result, err := uc.UpdateConfiguration(name, data)
+		result, err := uc.UpdateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -328,7 +619,7 @@ func TestConfigurationUseCase_UpdateConfiguration(t *testing.T) {
 		mockValidator.On("ValidateJSON", schema, data).Return(validationErr)
 
 		// Call the method
-		result, err := uc.UpdateConfiguration(name, data)
+		result, err := uc.UpdateConfiguration(context.Background(), name, data)
 
 		// Assertions
 		assert.Error(t, err)
@@ -356,9 +647,10 @@ func TestConfigurationUseCase_GetConfiguration(t *testing.T) {
 
 		// Configuration exists
 		mockRepo.On("GetConfiguration", name).Return(config, nil)
+		mockRepo.On("GetSchema", name).Return(nil, errors.NewNotFoundError("Schema", name))
 
 		// Call the method
-		result, err := useCase.GetConfiguration(name)
+		result, err := useCase.GetConfiguration(context.Background(), name)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -378,7 +670,7 @@ func TestConfigurationUseCase_GetConfiguration(t *testing.T) {
 		mockRepo.On("GetConfiguration", name).Return(nil, notFoundErr)
 
 		// Call the method
-		result, err := useCase.GetConfiguration(name)
+		result, err := useCase.GetConfiguration(context.Background(), name)
 
 		// Assertions
 		assert.Error(t, err)
@@ -386,6 +678,26 @@ func TestConfigurationUseCase_GetConfiguration(t *testing.T) {
 		assert.Equal(t, notFoundErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("ContextEnded", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// The read is aborted because the request's context ended
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mockRepo.On("GetConfiguration", "test-config").Return(nil, context.Canceled)
+
+		// Call the method
+		result, err := useCase.GetConfiguration(ctx, "test-config")
+
+		// The cancellation is reported rather than a missing configuration
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeRequestCancelled, appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestConfigurationUseCase_GetConfigurationVersion(t *testing.T) {
@@ -406,7 +718,7 @@ func TestConfigurationUseCase_GetConfigurationVersion(t *testing.T) {
 		mockRepo.On("GetConfigurationVersion", name, version).Return(config, nil)
 
 		// Call the method
-		result, err := useCase.GetConfigurationVersion(name, version)
+		result, err := useCase.GetConfigurationVersion(context.Background(), name, version)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -441,7 +753,7 @@ func TestConfigurationUseCase_ListConfigurationVersions(t *testing.T) {
 		mockRepo.On("ListConfigurationVersions", name).Return(versions, nil)
 
 		// Call the method
-		result, err := useCase.ListConfigurationVersions(name)
+		result, err := useCase.ListConfigurationVersions(context.Background(), name)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -477,7 +789,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("StoreVersionData", name, currentVersion+1, targetData).Return(nil)
 
 		// Call the method
-		result, err := useCase.RollbackConfiguration(name, targetVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, targetVersion)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -502,7 +814,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("GetConfiguration", name).Return(nil, notFoundErr)
 
 		// Call the method
-		result, err := useCase.RollbackConfiguration(name, targetVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, targetVersion)
 
 		// Assertions
 		assert.Error(t, err)
@@ -536,7 +848,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("GetVersionData", name, targetVersion).Return(nil, notFoundErr)
 
 		// Call the method
-		result, err := useCase.RollbackConfiguration(name, targetVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, targetVersion)
 
 		// Assertions
 		assert.Error(t, err)
@@ -573,7 +885,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("StoreVersionData", name, currentVersion+1, currentConfig.Data).Return(nil)
 
 		// Call the method with same version
-		result, err := useCase.RollbackConfiguration(name, currentVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, currentVersion)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -608,7 +920,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("GetVersionData", name, futureVersion).Return(nil, notFoundErr)
 
 		// Call the method with future version
-		result, err := useCase.RollbackConfiguration(name, futureVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, futureVersion)
 
 		// Assertions
 		assert.Error(t, err)
@@ -645,7 +957,7 @@ func TestConfigurationUseCase_RollbackConfiguration(t *testing.T) {
 		mockRepo.On("UpdateConfiguration", mock.AnythingOfType("*entity.Configuration")).Return(updateErr)
 
 		// Call the method
-		result, err := useCase.RollbackConfiguration(name, targetVersion)
+		result, err := useCase.RollbackConfiguration(context.Background(), name, targetVersion)
 
 		// Assertions
 		assert.Error(t, err)
@@ -668,18 +980,24 @@ func TestConfigurationUseCase_RegisterSchema(t *testing.T) {
 		// Test data
 		name := "test-config"
 		schema := json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"}}}`)
+		// New schemas without $schema are stored as draft 2020-12
+		stamped := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"key":{"type":"string"}}}`)
 
 		// Validate schema
-		mockValidator.On("ValidateSchemaDefinition", schema).Return(nil)
+		mockValidator.On("ValidateSchemaDefinition", stamped).Return(nil)
+
+		// No configuration data exists yet
+		mockRepo.On("GetConfiguration", name).Return(nil, errors.NewNotFoundError("Configuration", name))
 
 		// Register schema
-		mockRepo.On("RegisterSchema", name, schema).Return(nil)
+		mockRepo.On("RegisterSchema", name, stamped).Return(&entity.SchemaVersion{Name: name, Version: 1, Schema: stamped}, nil)
 
 		// Call the method
-		err := useCase.RegisterSchema(name, schema)
+		result, err := useCase.RegisterSchema(context.Background(), name, schema, entity.SchemaRegistrationOptions{})
 
 		// Assertions
 		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Version)
 		mockRepo.AssertExpectations(t)
 		mockValidator.AssertExpectations(t)
 	})
@@ -693,14 +1011,14 @@ func TestConfigurationUseCase_RegisterSchema(t *testing.T) {
 
 		// Test data
 		name := "test-config"
-		invalidSchema := json.RawMessage(`{"type":"invalid"}`)
+		invalidSchema := json.RawMessage(`{"$schema":"http://json-schema.org/draft-07/schema#","type":"invalid"}`)
 		validationErr := errors.NewValidationFailedError("Invalid schema", "unknown type: invalid")
 
 		// Validate schema fails
 		mockValidator.On("ValidateSchemaDefinition", invalidSchema).Return(validationErr)
 
 		// Call the method
-		err := useCase.RegisterSchema(name, invalidSchema)
+		_, err := useCase.RegisterSchema(context.Background(), name, invalidSchema, entity.SchemaRegistrationOptions{})
 
 		// Assertions
 		assert.Error(t, err)
@@ -708,6 +1026,29 @@ func TestConfigurationUseCase_RegisterSchema(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "RegisterSchema")
 		mockValidator.AssertExpectations(t)
 	})
+
+	t.Run("SharedSchemaLoadCancelled", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		mockValidator := new(MockJSONSchemaValidator)
+		useCase := NewTestConfigurationUseCase(mockRepo)
+		useCase.SetValidator(mockValidator)
+
+		// The shared schema referenced by the schema could not be loaded because the
+		// request was cancelled
+		schema := json.RawMessage(`{"$schema":"http://json-schema.org/draft-07/schema#","$ref":"shared://common/endpoint"}`)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mockValidator.On("ValidateSchemaDefinition", schema).Return(errors.NewInvalidRequestError("Invalid JSON Schema", context.Canceled.Error()))
+
+		// Call the method
+		_, err := useCase.RegisterSchema(ctx, "test-config", schema, entity.SchemaRegistrationOptions{})
+
+		// The cancellation is reported rather than an invalid schema
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeRequestCancelled, appErr.Code)
+		mockRepo.AssertNotCalled(t, "RegisterSchema")
+	})
 }
 
 func TestConfigurationUseCase_ValidateConfigurationData(t *testing.T) {
@@ -728,7 +1069,7 @@ func TestConfigurationUseCase_ValidateConfigurationData(t *testing.T) {
 		mockValidator.On("ValidateJSON", schema, data).Return(nil)
 
 		// Call the method
-		err := uc.ValidateConfigurationData(name, data)
+		err := uc.ValidateConfigurationData(context.Background(), name, data)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -750,7 +1091,7 @@ func TestConfigurationUseCase_ValidateConfigurationData(t *testing.T) {
 		mockRepo.On("GetSchema", name).Return(nil, notFoundErr)
 
 		// Call the method
-		err := uc.ValidateConfigurationData(name, data)
+		err := uc.ValidateConfigurationData(context.Background(), name, data)
 
 		// Assertions
 		assert.Error(t, err)
@@ -776,7 +1117,7 @@ func TestConfigurationUseCase_ValidateConfigurationData(t *testing.T) {
 		mockValidator.On("ValidateJSON", schema, data).Return(validationErr)
 
 		// Call the method
-		err := uc.ValidateConfigurationData(name, data)
+		err := uc.ValidateConfigurationData(context.Background(), name, data)
 
 		// Assertions
 		assert.Error(t, err)
@@ -784,6 +1125,32 @@ func TestConfigurationUseCase_ValidateConfigurationData(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 		mockValidator.AssertExpectations(t)
 	})
+
+	t.Run("SharedSchemaLoadTimedOut", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		mockValidator := new(MockJSONSchemaValidator)
+		uc := NewTestConfigurationUseCase(mockRepo)
+		uc.SetValidator(mockValidator)
+
+		name := "test-config"
+		schema := json.RawMessage(`{"$ref":"shared://common/endpoint"}`)
+		data := json.RawMessage(`{"key":"value"}`)
+
+		// Loading the shared schema fails because the request's deadline passed
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		mockRepo.On("GetSchema", name).Return(schema, nil)
+		mockValidator.On("ValidateJSON", schema, data).Return(errors.NewInternalError("Failed to load shared schema", context.DeadlineExceeded.Error()))
+
+		// Call the method
+		err := uc.ValidateConfigurationData(ctx, name, data)
+
+		// The timeout is reported rather than a server error
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeRequestTimeout, appErr.Code)
+		mockValidator.AssertExpectations(t)
+	})
 }
 
 func TestConfigurationUseCase_GetSchema(t *testing.T) {
@@ -798,7 +1165,7 @@ func TestConfigurationUseCase_GetSchema(t *testing.T) {
 
 		mockRepo.On("GetSchema", name).Return(schema, nil)
 
-		result, err := uc.GetSchema(name)
+		result, err := uc.GetSchema(context.Background(), name)
 
 		assert.NoError(t, err)
 		assert.Equal(t, schema, result)
@@ -816,7 +1183,7 @@ func TestConfigurationUseCase_GetSchema(t *testing.T) {
 
 		mockRepo.On("GetSchema", name).Return(nil, notFoundErr)
 
-		result, err := uc.GetSchema(name)
+		result, err := uc.GetSchema(context.Background(), name)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -826,3 +1193,74 @@ func TestConfigurationUseCase_GetSchema(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestConfigurationUseCase_GetConfigurationAsOf(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Test data
+		name := "test-config"
+		asOf := time.Date(2025, 8, 10, 14, 32, 0, 0, time.UTC)
+		expectedConfig := &entity.Configuration{
+			Name:    name,
+			Version: 2,
+			Data:    json.RawMessage(`{"key":"value-at-1432"}`),
+		}
+
+		mockRepo.On("GetConfigurationAsOf", name, asOf).Return(expectedConfig, nil)
+
+		// Call the method
+		result, err := useCase.GetConfigurationAsOf(context.Background(), name, asOf)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, expectedConfig, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotYetCreated", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Test data
+		name := "test-config"
+		asOf := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockRepo.On("GetConfigurationAsOf", name, asOf).Return(nil, errors.NewNotFoundError("Configuration", name))
+
+		// Call the method
+		result, err := useCase.GetConfigurationAsOf(context.Background(), name, asOf)
+
+		// Assertions
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeNotFound, appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestConfigurationUseCase_ListConfigurationsAsOf(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		asOf := time.Date(2025, 8, 10, 14, 32, 0, 0, time.UTC)
+		expected := []entity.BulkReadItem{
+			{ConfigurationRef: entity.ConfigurationRef{Name: "a-config", Version: 1}, Configuration: &entity.Configuration{Name: "a-config", Version: 1}},
+			{ConfigurationRef: entity.ConfigurationRef{Name: "b-config", Version: 3}, Error: errors.NewVersionCompactedError("b-config", 3).ToErrorResponse()},
+		}
+
+		mockRepo.On("ListConfigurationsAsOf", asOf).Return(expected, nil)
+
+		// Call the method
+		result, err := useCase.ListConfigurationsAsOf(context.Background(), asOf)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+)
+
+// defaultsMode reads the defaults mode a schema opts into with its x-apply-defaults
+// annotation
+func defaultsMode(schema json.RawMessage) (entity.DefaultsMode, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(schema, &document); err != nil {
+		// Boolean schemas declare no defaults
+		return entity.DefaultsModeNone, nil
+	}
+
+	raw, ok := document[entity.DefaultsModeKeyword]
+	if !ok {
+		return entity.DefaultsModeNone, nil
+	}
+	var mode entity.DefaultsMode
+	if err := json.Unmarshal(raw, &mode); err != nil || !mode.IsValid() {
+		return entity.DefaultsModeNone, errors.NewInvalidRequestError(
+			"Invalid JSON Schema",
+			fmt.Sprintf("%s must be %q or %q", entity.DefaultsModeKeyword, entity.DefaultsModeWrite, entity.DefaultsModeRead),
+		)
+	}
+	return mode, nil
+}
+
+// applyDefaults fills the defaults a schema declares into data when the schema is in
+// the given mode, returning the data and the JSON Pointers of the filled properties
+func (uc *ConfigurationUseCase) applyDefaults(ctx context.Context, mode entity.DefaultsMode, schema json.RawMessage, data json.RawMessage) (json.RawMessage, []string, error) {
+	current, err := defaultsMode(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current != mode {
+		return data, nil, nil
+	}
+
+	withDefaults, defaulted, err := validator.ApplyDefaults(ctx, schema, data, uc.loadSharedSchema)
+	if err != nil {
+		return nil, nil, internalError(ctx, "Failed to apply schema defaults", err)
+	}
+	return withDefaults, defaulted, nil
+}
+
+// withReadDefaults returns a configuration with the defaults of its current schema
+// applied, when the schema applies defaults on read. Stored data is not changed.
+func (uc *ConfigurationUseCase) withReadDefaults(ctx context.Context, config *entity.Configuration) (*entity.Configuration, error) {
+	schema, err := uc.repo.GetSchema(ctx, config.Name)
+	if err != nil {
+		if isNotFound(err) {
+			return config, nil
+		}
+		return nil, internalError(ctx, "Failed to load schema", err)
+	}
+
+	return uc.withSchemaReadDefaults(ctx, config, schema)
+}
+
+// withVersionReadDefaults returns a version of a configuration with the defaults of the
+// schema version that validated it applied, so that a historical read shows the
+// defaults that version was read with rather than those of the current schema.
+// Versions stored without a schema have none.
+func (uc *ConfigurationUseCase) withVersionReadDefaults(ctx context.Context, config *entity.Configuration) (*entity.Configuration, error) {
+	if config.SchemaVersion == 0 {
+		return config, nil
+	}
+	schemaVersion, err := uc.repo.GetSchemaVersion(ctx, config.Name, config.SchemaVersion)
+	if err != nil {
+		if isNotFound(err) {
+			return config, nil
+		}
+		return nil, internalError(ctx, "Failed to load schema version", err)
+	}
+
+	return uc.withSchemaReadDefaults(ctx, config, schemaVersion.Schema)
+}
+
+// withSchemaReadDefaults returns a configuration with the defaults of schema applied,
+// when the schema applies defaults on read
+func (uc *ConfigurationUseCase) withSchemaReadDefaults(ctx context.Context, config *entity.Configuration, schema json.RawMessage) (*entity.Configuration, error) {
+	data, defaulted, err := uc.applyDefaults(ctx, entity.DefaultsModeRead, schema, config.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(defaulted) == 0 {
+		return config, nil
+	}
+
+	withDefaults := *config
+	withDefaults.Data = data
+	withDefaults.DefaultedFields = defaulted
+	return &withDefaults, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_SchemaDefaults(t *testing.T) {
+	name := "test-config"
+	properties := `"properties":{"limit":{"type":"integer"},"enabled":{"type":"boolean","default":true}},"required":["limit","enabled"]`
+
+	t.Run("WriteModeStoresDefaults", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		stored := json.RawMessage(`{"enabled":true,"limit":5}`)
+
+		mockRepo.On("GetConfiguration", name).Return(nil, errors.NewNotFoundError("Configuration", name))
+		mockRepo.On("GetSchema", name).Return(json.RawMessage(`{"x-apply-defaults":"write",`+properties+`}`), nil)
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
+		mockRepo.On("CreateConfiguration", mock.MatchedBy(func(config *entity.Configuration) bool {
+			return string(config.Data) == string(stored)
+		})).Return(nil)
+		mockRepo.On("StoreVersionData", name, 1, stored).Return(nil)
+
+		// Call the method
+		result, err := useCase.CreateConfiguration(context.Background(), name, json.RawMessage(`{"limit":5}`))
+
+		// Assertions
+		require.NoError(t, err)
+		assert.JSONEq(t, string(stored), string(result.Data))
+		assert.Equal(t, []string{"/enabled"}, result.DefaultedFields)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("WithoutModeDefaultsAreNotApplied", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", name).Return(nil, errors.NewNotFoundError("Configuration", name))
+		mockRepo.On("GetSchema", name).Return(json.RawMessage(`{`+properties+`}`), nil)
+
+		// Call the method
+		_, err := useCase.CreateConfiguration(context.Background(), name, json.RawMessage(`{"limit":5}`))
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateConfiguration", mock.Anything)
+	})
+
+	t.Run("ReadModeAppliesDefaultsOnRead", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		config := &entity.Configuration{Name: name, Version: 2, Data: json.RawMessage(`{"limit":5}`)}
+
+		mockRepo.On("GetConfiguration", name).Return(config, nil)
+		mockRepo.On("GetSchema", name).Return(json.RawMessage(`{"x-apply-defaults":"read","properties":{"enabled":{"default":true}}}`), nil)
+
+		// Call the method
+		result, err := useCase.GetConfiguration(context.Background(), name)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"enabled":true,"limit":5}`, string(result.Data))
+		assert.Equal(t, []string{"/enabled"}, result.DefaultedFields)
+		assert.Equal(t, 2, result.Version)
+		assert.JSONEq(t, `{"limit":5}`, string(config.Data), "stored configuration must not change")
+	})
+
+	t.Run("WriteModeDoesNotApplyOnRead", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		config := &entity.Configuration{Name: name, Version: 1, Data: json.RawMessage(`{"limit":5}`)}
+
+		mockRepo.On("GetConfiguration", name).Return(config, nil)
+		mockRepo.On("GetSchema", name).Return(json.RawMessage(`{"x-apply-defaults":"write","properties":{"enabled":{"default":true}}}`), nil)
+
+		// Call the method
+		result, err := useCase.GetConfiguration(context.Background(), name)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Same(t, config, result)
+		assert.Empty(t, result.DefaultedFields)
+	})
+
+	t.Run("VersionReadUsesRecordedSchema", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		config := &entity.Configuration{Name: name, Version: 1, SchemaVersion: 1, Data: json.RawMessage(`{"limit":5}`)}
+
+		// Version 1 was validated by a schema without the default the current schema declares
+		mockRepo.On("GetConfigurationVersion", name, 1).Return(config, nil)
+		mockRepo.On("GetSchemaVersion", name, 1).Return(&entity.SchemaVersion{
+			Name:    name,
+			Version: 1,
+			Schema:  json.RawMessage(`{"x-apply-defaults":"read","properties":{"limit":{"default":10}}}`),
+		}, nil)
+
+		// Call the method
+		result, err := useCase.GetConfigurationVersion(context.Background(), name, 1)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Same(t, config, result)
+		assert.Empty(t, result.DefaultedFields)
+		mockRepo.AssertNotCalled(t, "GetSchema", name)
+	})
+
+	t.Run("VersionReadAppliesRecordedDefaults", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		config := &entity.Configuration{Name: name, Version: 3, SchemaVersion: 2, Data: json.RawMessage(`{"limit":5}`)}
+
+		mockRepo.On("GetConfigurationVersion", name, 3).Return(config, nil)
+		mockRepo.On("GetSchemaVersion", name, 2).Return(&entity.SchemaVersion{
+			Name:    name,
+			Version: 2,
+			Schema:  json.RawMessage(`{"x-apply-defaults":"read","properties":{"enabled":{"default":false}}}`),
+		}, nil)
+
+		// Call the method
+		result, err := useCase.GetConfigurationVersion(context.Background(), name, 3)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"enabled":false,"limit":5}`, string(result.Data))
+		assert.Equal(t, []string{"/enabled"}, result.DefaultedFields)
+	})
+
+	t.Run("InvalidStoredModeIsReported", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+		config := &entity.Configuration{Name: name, Version: 1, Data: json.RawMessage(`{"limit":5}`)}
+
+		mockRepo.On("GetConfiguration", name).Return(config, nil)
+		mockRepo.On("GetSchema", name).Return(json.RawMessage(`{"x-apply-defaults":"always","properties":{"enabled":{"default":true}}}`), nil)
+
+		// Call the method
+		_, err := useCase.GetConfiguration(context.Background(), name)
+
+		// The invalid annotation is reported rather than ignored
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+	})
+
+	t.Run("RegisterRejectsUnknownMode", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		_, err := useCase.RegisterSchema(context.Background(), name, json.RawMessage(`{"x-apply-defaults":"always"}`), entity.SchemaRegistrationOptions{})
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+		mockRepo.AssertNotCalled(t, "RegisterSchema", mock.Anything, mock.Anything)
+	})
+}
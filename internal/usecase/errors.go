@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	stdErrors "errors"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// isVersionCompacted reports whether err signals that a version's data was compacted
+func isVersionCompacted(err error) bool {
+	var appErr *errors.AppError
+	return stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeVersionCompacted
+}
+
+// isNotFound reports whether err is a not found AppError
+func isNotFound(err error) bool {
+	var appErr *errors.AppError
+	return stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeNotFound
+}
+
+// isConflict reports whether err is a conflict AppError
+func isConflict(err error) bool {
+	var appErr *errors.AppError
+	return stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeConflict
+}
+
+// isValidationFailed reports whether err is a validation failed AppError
+func isValidationFailed(err error) bool {
+	var appErr *errors.AppError
+	return stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeValidationFailed
+}
+
+// internalError reports a failed repository call. When the request's context ended
+// before the call completed, the timeout or cancellation is reported instead.
+func internalError(ctx context.Context, message string, err error) *errors.AppError {
+	if ctx.Err() != nil {
+		return errors.NewContextError(ctx.Err())
+	}
+	return errors.NewInternalError(message, err.Error())
+}
+
+// notFoundError reports a resource a repository call did not return, unless the call
+// failed because the request's context ended
+func notFoundError(ctx context.Context, err error, resourceType, resourceID string) *errors.AppError {
+	if err != nil && ctx.Err() != nil {
+		return errors.NewContextError(ctx.Err())
+	}
+	return errors.NewNotFoundError(resourceType, resourceID)
+}
+
+// validatorError passes on an error of the validator, unless the request's context ended
+// while the validator was loading shared schemas, in which case the timeout or
+// cancellation is reported instead of a server error or an invalid schema. A validation
+// failure is passed on either way, since the data was checked.
+func validatorError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil && !isValidationFailed(err) {
+		return errors.NewContextError(ctx.Err())
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures optional behaviour of a ConfigurationUseCase
+type Option func(*ConfigurationUseCase)
+
+// WithApprovalPolicy requires approved change requests for configurations matched by the policy
+func WithApprovalPolicy(policy entity.ApprovalPolicy) Option {
+	return func(uc *ConfigurationUseCase) {
+		uc.approvalPolicy = policy
+	}
+}
+
+// WithSchemaCompatibility sets the compatibility mode enforced when registering new schema versions
+func WithSchemaCompatibility(mode entity.SchemaCompatibility) Option {
+	return func(uc *ConfigurationUseCase) {
+		uc.schemaCompatibility = mode
+	}
+}
+
+// WithStrictFormats asserts the format keyword in draft 2019-09 and 2020-12 schemas,
+// rejecting data such as an invalid email in a "format": "email" string
+func WithStrictFormats(strict bool) Option {
+	return func(uc *ConfigurationUseCase) {
+		uc.strictFormats = strict
+	}
+}
+
+// WithValidatorWrapper wraps the schema validator of the usecase, e.g. to instrument it.
+// The wrapper should implement validator.CachingValidator when the validator it wraps
+// does, or compiled schemas are no longer cached.
+func WithValidatorWrapper(wrap func(validator.Validator) validator.Validator) Option {
+	return func(uc *ConfigurationUseCase) {
+		uc.wrapValidator = wrap
+	}
+}
+
+// WithTracer records a span for every schema validation, including the compilation of
+// schemas that are not cached yet
+func WithTracer(tracer trace.Tracer) Option {
+	return func(uc *ConfigurationUseCase) {
+		uc.tracer = tracer
+	}
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"time"
+)
+
+// GetRetentionPolicy retrieves the retention policy of a configuration, or the
+// global policy when name is entity.GlobalRetentionPolicyName
+func (uc *ConfigurationUseCase) GetRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error) {
+	policy, err := uc.repo.GetRetentionPolicy(ctx, name)
+	if err != nil {
+		return nil, notFoundError(ctx, err, "Retention policy", name)
+	}
+
+	return policy, nil
+}
+
+// SetRetentionPolicy creates or replaces a retention policy
+func (uc *ConfigurationUseCase) SetRetentionPolicy(ctx context.Context, policy *entity.RetentionPolicy) (*entity.RetentionPolicy, error) {
+	if policy.KeepLast < 0 || policy.KeepDays < 0 {
+		return nil, errors.NewInvalidRequestError("keep_last and keep_days must not be negative", map[string]int{
+			"keep_last": policy.KeepLast,
+			"keep_days": policy.KeepDays,
+		})
+	}
+
+	if policy.Name != entity.GlobalRetentionPolicyName {
+		// Check if configuration exists
+		if _, err := uc.repo.GetConfiguration(ctx, policy.Name); err != nil {
+			return nil, notFoundError(ctx, err, "Configuration", policy.Name)
+		}
+	}
+
+	if err := uc.repo.SetRetentionPolicy(ctx, policy); err != nil {
+		return nil, internalError(ctx, "Failed to store retention policy", err)
+	}
+
+	return policy, nil
+}
+
+// DeleteRetentionPolicy removes a retention policy
+func (uc *ConfigurationUseCase) DeleteRetentionPolicy(ctx context.Context, name string) error {
+	if err := uc.repo.DeleteRetentionPolicy(ctx, name); err != nil {
+		return notFoundError(ctx, err, "Retention policy", name)
+	}
+
+	return nil
+}
+
+// CompactConfiguration removes the data of versions of a configuration that fall
+// outside its effective retention policy
+func (uc *ConfigurationUseCase) CompactConfiguration(ctx context.Context, name string, now time.Time) (*entity.CompactionResult, error) {
+	config, err := uc.repo.GetConfiguration(ctx, name)
+	if err != nil || config == nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	result := &entity.CompactionResult{Name: name, CompactedVersions: []int{}}
+
+	policy, err := uc.effectiveRetentionPolicy(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil || policy.IsDisabled() {
+		return result, nil
+	}
+
+	versions, err := uc.repo.ListConfigurationVersions(ctx, name)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list configuration versions", err)
+	}
+
+	toCompact := policy.VersionsToCompact(versions.Versions, config.Version, now)
+	if len(toCompact) == 0 {
+		return result, nil
+	}
+
+	if err := uc.repo.CompactVersions(ctx, name, toCompact); err != nil {
+		return nil, internalError(ctx, "Failed to compact configuration versions", err)
+	}
+	result.CompactedVersions = toCompact
+
+	return result, nil
+}
+
+// CompactAll enforces retention policies on every configuration and deduplicates
+// stored version payloads
+func (uc *ConfigurationUseCase) CompactAll(ctx context.Context, now time.Time) (*entity.CompactionSummary, error) {
+	names, err := uc.repo.ListConfigurationNames(ctx)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list configurations", err)
+	}
+
+	summary := &entity.CompactionSummary{Configurations: []*entity.CompactionResult{}}
+	for _, name := range names {
+		result, err := uc.CompactConfiguration(ctx, name, now)
+		if err != nil {
+			return summary, err
+		}
+		if len(result.CompactedVersions) > 0 {
+			summary.Configurations = append(summary.Configurations, result)
+		}
+	}
+
+	migrated, removed, err := uc.repo.DeduplicateVersionData(ctx)
+	if err != nil {
+		return summary, internalError(ctx, "Failed to deduplicate version data", err)
+	}
+	summary.MigratedPayloads = migrated
+	summary.RemovedPayloads = removed
+
+	return summary, nil
+}
+
+// effectiveRetentionPolicy returns the policy of a configuration, falling back to
+// the global policy; nil means no retention applies
+func (uc *ConfigurationUseCase) effectiveRetentionPolicy(ctx context.Context, name string) (*entity.RetentionPolicy, error) {
+	for _, candidate := range []string{name, entity.GlobalRetentionPolicyName} {
+		policy, err := uc.repo.GetRetentionPolicy(ctx, candidate)
+		if err == nil {
+			return policy, nil
+		}
+		if !isNotFound(err) {
+			return nil, internalError(ctx, "Failed to load retention policy", err)
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurationUseCase_SetRetentionPolicy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		policy := &entity.RetentionPolicy{Name: "test-config", KeepLast: 5}
+		mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config"}, nil)
+		mockRepo.On("SetRetentionPolicy", policy).Return(nil)
+
+		// Call the method
+		result, err := useCase.SetRetentionPolicy(context.Background(), policy)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, policy, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("GlobalPolicySkipsConfigurationLookup", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		policy := &entity.RetentionPolicy{Name: entity.GlobalRetentionPolicyName, KeepDays: 90}
+		mockRepo.On("SetRetentionPolicy", policy).Return(nil)
+
+		// Call the method
+		_, err := useCase.SetRetentionPolicy(context.Background(), policy)
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetConfiguration", entity.GlobalRetentionPolicyName)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NegativeValues", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		result, err := useCase.SetRetentionPolicy(context.Background(), &entity.RetentionPolicy{Name: "test-config", KeepLast: -1})
+
+		// Assertions
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+		mockRepo.AssertNotCalled(t, "SetRetentionPolicy")
+	})
+}
+
+func TestConfigurationUseCase_CompactConfiguration(t *testing.T) {
+	now := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	versions := &entity.VersionList{
+		Name: "test-config",
+		Versions: []entity.VersionInfo{
+			{Version: 1, CreatedAt: now.AddDate(0, 0, -30)},
+			{Version: 2, CreatedAt: now.AddDate(0, 0, -20)},
+			{Version: 3, CreatedAt: now.AddDate(0, 0, -10)},
+			{Version: 4, CreatedAt: now.AddDate(0, 0, -1)},
+		},
+	}
+
+	t.Run("UsesConfigurationPolicy", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 4}, nil)
+		mockRepo.On("GetRetentionPolicy", "test-config").Return(&entity.RetentionPolicy{Name: "test-config", KeepLast: 2}, nil)
+		mockRepo.On("ListConfigurationVersions", "test-config").Return(versions, nil)
+		mockRepo.On("CompactVersions", "test-config", []int{1, 2}).Return(nil)
+
+		// Call the method
+		result, err := useCase.CompactConfiguration(context.Background(), "test-config", now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, result.CompactedVersions)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("FallsBackToGlobalPolicy", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 4}, nil)
+		mockRepo.On("GetRetentionPolicy", "test-config").Return(nil, errors.NewNotFoundError("Retention policy", "test-config"))
+		mockRepo.On("GetRetentionPolicy", entity.GlobalRetentionPolicyName).
+			Return(&entity.RetentionPolicy{Name: entity.GlobalRetentionPolicyName, KeepDays: 15}, nil)
+		mockRepo.On("ListConfigurationVersions", "test-config").Return(versions, nil)
+		mockRepo.On("CompactVersions", "test-config", []int{1, 2}).Return(nil)
+
+		// Call the method
+		result, err := useCase.CompactConfiguration(context.Background(), "test-config", now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, result.CompactedVersions)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NoPolicy", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 4}, nil)
+		mockRepo.On("GetRetentionPolicy", "test-config").Return(nil, errors.NewNotFoundError("Retention policy", "test-config"))
+		mockRepo.On("GetRetentionPolicy", entity.GlobalRetentionPolicyName).
+			Return(nil, errors.NewNotFoundError("Retention policy", entity.GlobalRetentionPolicyName))
+
+		// Call the method
+		result, err := useCase.CompactConfiguration(context.Background(), "test-config", now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Empty(t, result.CompactedVersions)
+		mockRepo.AssertNotCalled(t, "CompactVersions")
+	})
+}
+
+func TestConfigurationUseCase_CompactAll(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+	now := time.Now().UTC()
+
+	mockRepo.On("ListConfigurationNames").Return([]string{"test-config"}, nil)
+	mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 1}, nil)
+	mockRepo.On("GetRetentionPolicy", "test-config").Return(&entity.RetentionPolicy{Name: "test-config"}, nil)
+	mockRepo.On("DeduplicateVersionData").Return(3, 1, nil)
+
+	// Call the method
+	summary, err := useCase.CompactAll(context.Background(), now)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Empty(t, summary.Configurations)
+	assert.Equal(t, 3, summary.MigratedPayloads)
+	assert.Equal(t, 1, summary.RemovedPayloads)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfigurationUseCase_RollbackCompactedVersion(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	mockRepo.On("GetConfiguration", "test-config").Return(&entity.Configuration{Name: "test-config", Version: 5}, nil)
+	mockRepo.On("GetVersionData", "test-config", 1).Return(nil, errors.NewVersionCompactedError("test-config", 1))
+
+	// Call the method
+	result, err := useCase.RollbackConfiguration(context.Background(), "test-config", 1)
+
+	// Assertions
+	assert.Nil(t, result)
+	appErr, ok := err.(*errors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, errors.ErrorCodeVersionCompacted, appErr.Code)
+	mockRepo.AssertExpectations(t)
+}
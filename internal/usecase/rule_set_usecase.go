@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+)
+
+// SetRuleSet stores validation rules as the next rule set version of a configuration.
+// Unless forced, the current configuration data must satisfy the new rules.
+func (uc *ConfigurationUseCase) SetRuleSet(ctx context.Context, configName string, ruleList []rules.Rule, force bool) (*entity.RuleSet, error) {
+	if problems := rules.Check(ruleList); len(problems) > 0 {
+		return nil, errors.NewInvalidRequestError("Invalid validation rules", problems)
+	}
+
+	if !force {
+		current, err := uc.repo.GetConfiguration(ctx, configName)
+		if err != nil && !isNotFound(err) {
+			return nil, internalError(ctx, "Failed to load configuration", err)
+		}
+		if err == nil {
+			if err := evaluateRules(ruleList, current.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ruleSet, err := uc.repo.CreateRuleSet(ctx, configName, ruleList)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to store rule set", err)
+	}
+
+	return ruleSet, nil
+}
+
+// GetRuleSet retrieves the current rule set of a configuration
+func (uc *ConfigurationUseCase) GetRuleSet(ctx context.Context, configName string) (*entity.RuleSet, error) {
+	ruleSet, err := uc.repo.GetRuleSet(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to get rule set", err)
+	}
+
+	return ruleSet, nil
+}
+
+// GetRuleSetVersion retrieves a specific rule set version of a configuration
+func (uc *ConfigurationUseCase) GetRuleSetVersion(ctx context.Context, configName string, version int) (*entity.RuleSet, error) {
+	ruleSet, err := uc.repo.GetRuleSetVersion(ctx, configName, version)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to get rule set version", err)
+	}
+
+	return ruleSet, nil
+}
+
+// ListRuleSetVersions lists the rule set history of a configuration
+func (uc *ConfigurationUseCase) ListRuleSetVersions(ctx context.Context, configName string) ([]*entity.RuleSet, error) {
+	ruleSets, err := uc.repo.ListRuleSetVersions(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to list rule set versions", err)
+	}
+
+	return ruleSets, nil
+}
+
+// TestRules evaluates validation rules against configuration data without storing
+// anything. Nil rules default to the current rule set and nil data to the current
+// configuration data.
+func (uc *ConfigurationUseCase) TestRules(ctx context.Context, configName string, ruleList []rules.Rule, data json.RawMessage) (*entity.RuleTestResult, error) {
+	if ruleList == nil {
+		ruleSet, err := uc.GetRuleSet(ctx, configName)
+		if err != nil {
+			return nil, err
+		}
+		ruleList = ruleSet.Rules
+	} else if problems := rules.Check(ruleList); len(problems) > 0 {
+		return nil, errors.NewInvalidRequestError("Invalid validation rules", problems)
+	}
+
+	if data == nil {
+		current, err := uc.repo.GetConfiguration(ctx, configName)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, errors.NewNotFoundError("Configuration", configName)
+			}
+			return nil, internalError(ctx, "Failed to load configuration", err)
+		}
+		data = current.Data
+	}
+
+	failures, err := rules.Evaluate(ruleList, data)
+	if err != nil {
+		return nil, errors.NewInvalidRequestError("Invalid configuration data", err.Error())
+	}
+
+	return &entity.RuleTestResult{
+		Valid:  len(failures) == 0,
+		Errors: failures,
+	}, nil
+}
+
+// checkRules validates data against the current rule set of a configuration, if any.
+// It runs after JSON Schema validation, so the data is known to be well-formed.
+func (uc *ConfigurationUseCase) checkRules(ctx context.Context, configName string, data json.RawMessage) error {
+	ruleSet, err := uc.repo.GetRuleSet(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return internalError(ctx, "Failed to load validation rules", err)
+	}
+
+	return evaluateRules(ruleSet.Rules, data)
+}
+
+// evaluateRules reports rule failures as a validation error
+func evaluateRules(ruleList []rules.Rule, data json.RawMessage) error {
+	failures, err := rules.Evaluate(ruleList, data)
+	if err != nil {
+		return errors.NewInvalidRequestError("Invalid configuration data", err.Error())
+	}
+	if len(failures) > 0 {
+		return errors.NewValidationFailedError("Validation rules failed", failures)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var limitRules = []rules.Rule{
+	{Name: "limits", Expression: "max_limit > min_limit"},
+	{Name: "https", Expression: "!enabled || startsWith(endpoint, 'https://')", Field: "endpoint"},
+}
+
+func TestConfigurationUseCase_SetRuleSet(t *testing.T) {
+	current := &entity.Configuration{Name: "payments", Version: 2, Data: json.RawMessage(`{"min_limit":10,"max_limit":5}`)}
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		_, err := useCase.SetRuleSet(context.Background(), "payments", []rules.Rule{{Name: "broken", Expression: "max_limit >"}}, false)
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "CreateRuleSet", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RejectsWhenCurrentDataFails", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "payments").Return(current, nil)
+
+		// Call the method
+		_, err := useCase.SetRuleSet(context.Background(), "payments", limitRules, false)
+
+		// Assertions
+		require.Error(t, err)
+		appErr := err.(*errors.AppError)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		failures := appErr.Details.([]errors.ValidationError)
+		require.Len(t, failures, 1)
+		assert.Equal(t, "max_limit", failures[0].Field)
+		mockRepo.AssertNotCalled(t, "CreateRuleSet", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ForceSkipsCurrentData", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("CreateRuleSet", "payments", limitRules).Return(&entity.RuleSet{Name: "payments", Version: 3, Rules: limitRules}, nil)
+
+		// Call the method
+		result, err := useCase.SetRuleSet(context.Background(), "payments", limitRules, true)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Version)
+		mockRepo.AssertNotCalled(t, "GetConfiguration", "payments")
+	})
+}
+
+func TestConfigurationUseCase_RulesOnWrite(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	data := json.RawMessage(`{"min_limit":10,"max_limit":100,"enabled":true,"endpoint":"http://payments"}`)
+	mockRepo.On("GetConfiguration", "payments").Return(nil, errors.NewNotFoundError("Configuration", "payments"))
+	mockRepo.On("GetSchema", "payments").Return(json.RawMessage(`{"type":"object"}`), nil)
+	mockRepo.On("GetRuleSet", "payments").Return(&entity.RuleSet{Name: "payments", Version: 1, Rules: limitRules}, nil)
+
+	// Call the method
+	_, err := useCase.CreateConfiguration(context.Background(), "payments", data)
+
+	// Assertions
+	require.Error(t, err)
+	appErr := err.(*errors.AppError)
+	assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+	assert.Equal(t, []errors.ValidationError{{
+		Field:    "endpoint",
+		Reason:   `rule "https" failed: !enabled || startsWith(endpoint, 'https://')`,
+		Pointer:  "/endpoint",
+		Code:     errors.ValidationCodeRuleFailed,
+		Expected: limitRules[1].Expression,
+	}}, appErr.Details)
+	mockRepo.AssertNotCalled(t, "CreateConfiguration", mock.Anything)
+}
+
+func TestConfigurationUseCase_TestRules(t *testing.T) {
+	t.Run("DefaultsToCurrentRulesAndData", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetRuleSet", "payments").Return(&entity.RuleSet{Name: "payments", Version: 1, Rules: limitRules}, nil)
+		mockRepo.On("GetConfiguration", "payments").
+			Return(&entity.Configuration{Name: "payments", Version: 1, Data: json.RawMessage(`{"min_limit":1,"max_limit":2}`)}, nil)
+
+		// Call the method
+		result, err := useCase.TestRules(context.Background(), "payments", nil, nil)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("ProposedRulesAndData", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		result, err := useCase.TestRules(context.Background(), "payments", limitRules, json.RawMessage(`{"min_limit":5,"max_limit":2}`))
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Len(t, result.Errors, 1)
+		mockRepo.AssertNotCalled(t, "GetRuleSet", "payments")
+	})
+
+	t.Run("NoRuleSet", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+
+		// Call the method
+		_, err := useCase.TestRules(context.Background(), "payments", nil, json.RawMessage(`{}`))
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.AppError).Code)
+	})
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"time"
+)
+
+// ScheduleConfigurationChange stages a configuration update to go live at a future time
+func (uc *ConfigurationUseCase) ScheduleConfigurationChange(ctx context.Context, name string, data json.RawMessage, effectiveAt time.Time) (*entity.ScheduledChange, error) {
+	if !effectiveAt.After(time.Now()) {
+		return nil, errors.NewInvalidRequestError("effective_at must be in the future", map[string]string{
+			"effective_at": effectiveAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	// Check if configuration exists
+	existingConfig, err := uc.repo.GetConfiguration(ctx, name)
+	if err != nil || existingConfig == nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	// Protected configurations only change through approved change requests
+	if err := uc.requireDirectWrite(name); err != nil {
+		return nil, err
+	}
+
+	// Validate against the current schema so obviously broken changes are rejected early;
+	// the change is validated again when it is applied
+	schema, err := uc.repo.GetSchema(ctx, name)
+	if err == nil && schema != nil {
+		if err := uc.validateData(ctx, name, schema, data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check custom validation rules
+	if err := uc.checkRules(ctx, name, data); err != nil {
+		return nil, err
+	}
+
+	change := entity.NewScheduledChange(name, data, effectiveAt)
+	if err := uc.repo.CreateScheduledChange(ctx, change); err != nil {
+		return nil, internalError(ctx, "Failed to schedule configuration change", err)
+	}
+
+	return change, nil
+}
+
+// ListScheduledChanges lists the scheduled changes of a configuration
+func (uc *ConfigurationUseCase) ListScheduledChanges(ctx context.Context, name string) ([]*entity.ScheduledChange, error) {
+	// Check if configuration exists
+	_, err := uc.repo.GetConfiguration(ctx, name)
+	if err != nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	changes, err := uc.repo.ListScheduledChanges(ctx, name)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list scheduled changes", err)
+	}
+
+	return changes, nil
+}
+
+// CancelScheduledChange cancels a pending scheduled change
+func (uc *ConfigurationUseCase) CancelScheduledChange(ctx context.Context, name string, id int64) (*entity.ScheduledChange, error) {
+	change, err := uc.repo.GetScheduledChange(ctx, id)
+	if err != nil || change == nil || change.Name != name {
+		return nil, notFoundError(ctx, err, "Scheduled change", fmt.Sprintf("%d", id))
+	}
+
+	if change.Status != entity.ScheduledChangeStatusPending {
+		return nil, errors.NewInvalidRequestError("Only pending scheduled changes can be cancelled", map[string]string{
+			"status": string(change.Status),
+		})
+	}
+
+	change.MarkCancelled()
+	if err := uc.repo.UpdateScheduledChange(ctx, change); err != nil {
+		// The scheduler claimed the change after it was read
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeConflict {
+			return nil, errors.NewInvalidRequestError("Only pending scheduled changes can be cancelled", appErr.Details)
+		}
+		return nil, internalError(ctx, "Failed to cancel scheduled change", err)
+	}
+
+	return change, nil
+}
+
+// scheduledChangeClaimTimeout is how long a scheduled change may stay claimed before the
+// scheduler that claimed it is presumed to have stopped while applying it
+const scheduledChangeClaimTimeout = 5 * time.Minute
+
+// ApplyDueScheduledChanges applies all pending scheduled changes that are due at the given
+// time, after settling changes left claimed by a scheduler that stopped while applying them
+func (uc *ConfigurationUseCase) ApplyDueScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error) {
+	processed, err := uc.recoverStaleScheduledChanges(ctx, now)
+	if err != nil {
+		return processed, err
+	}
+
+	changes, err := uc.repo.ListDueScheduledChanges(ctx, now)
+	if err != nil {
+		return processed, internalError(ctx, "Failed to list due scheduled changes", err)
+	}
+
+	for _, change := range changes {
+		// Claim the change first so that it can no longer be cancelled; a change cancelled
+		// since it was listed is skipped
+		change.MarkApplying()
+		if err := uc.repo.UpdateScheduledChange(ctx, change); err != nil {
+			var appErr *errors.AppError
+			if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeConflict {
+				continue
+			}
+			return processed, internalError(ctx, "Failed to claim scheduled change", err)
+		}
+
+		// UpdateConfiguration re-validates the data against the schema current at apply time
+		config, err := uc.UpdateConfiguration(ctx, change.Name, change.Data)
+		if err != nil {
+			change.MarkFailed(describeError(err))
+		} else {
+			change.MarkApplied(config.Version)
+		}
+
+		if err := uc.repo.UpdateScheduledChange(ctx, change); err != nil {
+			return processed, internalError(ctx, "Failed to update scheduled change", err)
+		}
+		processed = append(processed, change)
+	}
+
+	return processed, nil
+}
+
+// recoverStaleScheduledChanges settles changes claimed more than
+// scheduledChangeClaimTimeout ago and never marked applied or failed. A change that went
+// live before the scheduler stopped is marked applied as the version holding its data;
+// any other is marked failed rather than retried, since its effective time has passed.
+func (uc *ConfigurationUseCase) recoverStaleScheduledChanges(ctx context.Context, now time.Time) ([]*entity.ScheduledChange, error) {
+	changes, err := uc.repo.ListStaleScheduledChanges(ctx, now.Add(-scheduledChangeClaimTimeout))
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list stale scheduled changes", err)
+	}
+
+	recovered := make([]*entity.ScheduledChange, 0, len(changes))
+	for _, change := range changes {
+		version, err := uc.appliedScheduledVersion(ctx, change)
+		if err != nil {
+			return recovered, err
+		}
+		if version > 0 {
+			change.MarkApplied(version)
+		} else {
+			change.MarkFailed("The scheduler stopped while applying the change before it went live")
+		}
+
+		if err := uc.repo.UpdateScheduledChange(ctx, change); err != nil {
+			var appErr *errors.AppError
+			if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeConflict {
+				continue
+			}
+			return recovered, internalError(ctx, "Failed to update scheduled change", err)
+		}
+		recovered = append(recovered, change)
+	}
+
+	return recovered, nil
+}
+
+// appliedScheduledVersion returns the version a claimed change went live as: the first
+// version created since the change was claimed that holds its data, or 0 if there is none
+func (uc *ConfigurationUseCase) appliedScheduledVersion(ctx context.Context, change *entity.ScheduledChange) (int, error) {
+	list, err := uc.repo.ListConfigurationVersions(ctx, change.Name)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, nil
+		}
+		return 0, internalError(ctx, "Failed to list configuration versions", err)
+	}
+
+	for _, info := range list.Versions {
+		if info.Compacted || info.CreatedAt.Before(change.UpdatedAt) {
+			continue
+		}
+		data, err := uc.repo.GetVersionData(ctx, change.Name, info.Version)
+		if err != nil {
+			return 0, internalError(ctx, "Failed to get version data", err)
+		}
+		if sameJSON(data, change.Data) {
+			return info.Version, nil
+		}
+	}
+	return 0, nil
+}
+
+// describeError renders an error together with its AppError details, if any
+func describeError(err error) string {
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) && appErr.Details != nil {
+		if details, mErr := json.Marshal(appErr.Details); mErr == nil {
+			return fmt.Sprintf("%s: %s", appErr.Message, details)
+		}
+	}
+	return err.Error()
+}
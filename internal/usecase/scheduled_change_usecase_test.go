@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_ScheduleConfigurationChange(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Test data
+		name := "test-config"
+		data := json.RawMessage(`{"key":"scheduled"}`)
+		effectiveAt := time.Now().Add(time.Hour)
+
+		// Configuration exists, no schema
+		mockRepo.On("GetConfiguration", name).Return(&entity.Configuration{Name: name, Version: 1}, nil)
+		mockRepo.On("GetSchema", name).Return(nil, errors.NewNotFoundError("Schema", name))
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
+		mockRepo.On("CreateScheduledChange", mock.AnythingOfType("*entity.ScheduledChange")).Return(nil)
+
+		// Call the method
+		result, err := useCase.ScheduleConfigurationChange(context.Background(), name, data, effectiveAt)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, name, result.Name)
+		assert.Equal(t, entity.ScheduledChangeStatusPending, result.Status)
+		assert.True(t, result.EffectiveAt.Equal(effectiveAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("EffectiveAtInPast", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		result, err := useCase.ScheduleConfigurationChange(context.Background(), "test-config", json.RawMessage(`{}`), time.Now().Add(-time.Minute))
+
+		// Assertions
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateScheduledChange")
+	})
+
+	t.Run("ValidationFailed", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		mockValidator := new(MockJSONSchemaValidator)
+		useCase := NewTestConfigurationUseCase(mockRepo)
+		useCase.SetValidator(mockValidator)
+
+		// Test data
+		name := "test-config"
+		data := json.RawMessage(`{"key":123}`)
+		schema := json.RawMessage(`{"type":"object","properties":{"key":{"type":"string"}}}`)
+		validationErr := errors.NewValidationFailedError("Invalid data", "key must be a string")
+
+		mockRepo.On("GetConfiguration", name).Return(&entity.Configuration{Name: name, Version: 1}, nil)
+		mockRepo.On("GetSchema", name).Return(schema, nil)
+		mockRepo.On("GetRuleSet", name).Return(nil, errors.NewNotFoundError("Rule set", name))
+		mockValidator.On("ValidateJSON", schema, data).Return(validationErr)
+
+		// Call the method
+		result, err := useCase.ScheduleConfigurationChange(context.Background(), name, data, time.Now().Add(time.Hour))
+
+		// Assertions
+		assert.Nil(t, result)
+		assert.Equal(t, validationErr, err)
+		mockRepo.AssertNotCalled(t, "CreateScheduledChange")
+		mockValidator.AssertExpectations(t)
+	})
+}
+
+func TestConfigurationUseCase_CancelScheduledChange(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		change := entity.NewScheduledChange("test-config", json.RawMessage(`{}`), time.Now().Add(time.Hour))
+		change.ID = 7
+
+		mockRepo.On("GetScheduledChange", int64(7)).Return(change, nil)
+		mockRepo.On("UpdateScheduledChange", change).Return(nil)
+
+		// Call the method
+		result, err := useCase.CancelScheduledChange(context.Background(), "test-config", 7)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ScheduledChangeStatusCancelled, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("WrongConfiguration", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		change := entity.NewScheduledChange("other-config", json.RawMessage(`{}`), time.Now().Add(time.Hour))
+		change.ID = 7
+
+		mockRepo.On("GetScheduledChange", int64(7)).Return(change, nil)
+
+		// Call the method
+		result, err := useCase.CancelScheduledChange(context.Background(), "test-config", 7)
+
+		// Assertions
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "not found")
+		mockRepo.AssertNotCalled(t, "UpdateScheduledChange")
+	})
+
+	t.Run("AlreadyApplied", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		change := entity.NewScheduledChange("test-config", json.RawMessage(`{}`), time.Now().Add(-time.Hour))
+		change.ID = 7
+		change.MarkApplied(3)
+
+		mockRepo.On("GetScheduledChange", int64(7)).Return(change, nil)
+
+		// Call the method
+		result, err := useCase.CancelScheduledChange(context.Background(), "test-config", 7)
+
+		// Assertions
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "UpdateScheduledChange")
+	})
+
+	t.Run("ClaimedByScheduler", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		change := entity.NewScheduledChange("test-config", json.RawMessage(`{}`), time.Now().Add(-time.Minute))
+		change.ID = 7
+
+		// The scheduler claims the change between the read and the cancel
+		mockRepo.On("GetScheduledChange", int64(7)).Return(change, nil)
+		mockRepo.On("UpdateScheduledChange", change).Return(errors.NewConflictError("Scheduled change is no longer pending", map[string]string{
+			"status": string(entity.ScheduledChangeStatusApplying),
+		}))
+
+		// Call the method
+		result, err := useCase.CancelScheduledChange(context.Background(), "test-config", 7)
+
+		// Assertions
+		assert.Nil(t, result)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestConfigurationUseCase_ApplyDueScheduledChanges(t *testing.T) {
+	t.Run("AppliesAndRecordsFailures", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		mockValidator := new(MockJSONSchemaValidator)
+		useCase := NewTestConfigurationUseCase(mockRepo)
+		useCase.SetValidator(mockValidator)
+
+		now := time.Now().UTC()
+		schema := json.RawMessage(`{"type":"object"}`)
+		validData := json.RawMessage(`{"key":"valid"}`)
+		invalidData := json.RawMessage(`{"key":"invalid"}`)
+
+		good := entity.NewScheduledChange("good-config", validData, now.Add(-time.Minute))
+		good.ID = 1
+		bad := entity.NewScheduledChange("bad-config", invalidData, now.Add(-time.Minute))
+		bad.ID = 2
+
+		mockRepo.On("ListStaleScheduledChanges", now.Add(-scheduledChangeClaimTimeout)).Return([]*entity.ScheduledChange{}, nil)
+		mockRepo.On("ListDueScheduledChanges", now).Return([]*entity.ScheduledChange{good, bad}, nil)
+
+		// The good change updates version 1 to version 2
+		mockRepo.On("GetConfiguration", "good-config").Return(&entity.Configuration{Name: "good-config", Version: 1}, nil)
+		mockRepo.On("GetSchema", "good-config").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "good-config").Return(nil, errors.NewNotFoundError("Rule set", "good-config"))
+		mockValidator.On("ValidateJSON", schema, validData).Return(nil)
+		mockRepo.On("UpdateConfiguration", mock.AnythingOfType("*entity.Configuration")).Return(nil)
+		mockRepo.On("StoreVersionData", "good-config", 2, validData).Return(nil)
+
+		// The bad change no longer conforms to the schema at apply time
+		mockRepo.On("GetConfiguration", "bad-config").Return(&entity.Configuration{Name: "bad-config", Version: 4}, nil)
+		mockRepo.On("GetSchema", "bad-config").Return(schema, nil)
+		mockValidator.On("ValidateJSON", schema, invalidData).Return(errors.NewValidationFailedError("JSON validation failed", nil))
+
+		mockRepo.On("UpdateScheduledChange", mock.AnythingOfType("*entity.ScheduledChange")).Return(nil)
+
+		// Call the method
+		result, err := useCase.ApplyDueScheduledChanges(context.Background(), now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, entity.ScheduledChangeStatusApplied, good.Status)
+		assert.Equal(t, 2, good.AppliedVersion)
+		assert.Equal(t, entity.ScheduledChangeStatusFailed, bad.Status)
+		assert.Contains(t, bad.Error, "validation failed")
+		mockRepo.AssertExpectations(t)
+		mockValidator.AssertExpectations(t)
+	})
+
+	t.Run("SkipsCancelledChanges", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		now := time.Now().UTC()
+		change := entity.NewScheduledChange("test-config", json.RawMessage(`{}`), now.Add(-time.Minute))
+		change.ID = 3
+
+		// The change was cancelled after it was listed, so claiming it fails
+		mockRepo.On("ListStaleScheduledChanges", now.Add(-scheduledChangeClaimTimeout)).Return([]*entity.ScheduledChange{}, nil)
+		mockRepo.On("ListDueScheduledChanges", now).Return([]*entity.ScheduledChange{change}, nil)
+		mockRepo.On("UpdateScheduledChange", change).Return(errors.NewConflictError("Scheduled change is no longer pending", map[string]string{
+			"status": string(entity.ScheduledChangeStatusCancelled),
+		}))
+
+		// Call the method
+		result, err := useCase.ApplyDueScheduledChanges(context.Background(), now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		mockRepo.AssertNotCalled(t, "UpdateConfiguration", mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SettlesStaleClaims", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		now := time.Now().UTC()
+		claimedAt := now.Add(-time.Hour)
+
+		// Both changes were claimed by a scheduler that stopped before marking them; only
+		// the first went live, as version 3
+		applied := entity.NewScheduledChange("applied-config", json.RawMessage(`{"key":"new"}`), claimedAt)
+		applied.ID = 4
+		applied.MarkApplying()
+		applied.UpdatedAt = claimedAt
+		lost := entity.NewScheduledChange("lost-config", json.RawMessage(`{"key":"new"}`), claimedAt)
+		lost.ID = 5
+		lost.MarkApplying()
+		lost.UpdatedAt = claimedAt
+
+		mockRepo.On("ListStaleScheduledChanges", now.Add(-scheduledChangeClaimTimeout)).Return([]*entity.ScheduledChange{applied, lost}, nil)
+		mockRepo.On("ListConfigurationVersions", "applied-config").Return(&entity.VersionList{Name: "applied-config", Versions: []entity.VersionInfo{
+			{Version: 2, CreatedAt: claimedAt.Add(-time.Hour)},
+			{Version: 3, CreatedAt: claimedAt.Add(time.Millisecond)},
+		}}, nil)
+		mockRepo.On("GetVersionData", "applied-config", 3).Return(json.RawMessage(`{"key": "new"}`), nil)
+		mockRepo.On("ListConfigurationVersions", "lost-config").Return(&entity.VersionList{Name: "lost-config", Versions: []entity.VersionInfo{
+			{Version: 1, CreatedAt: claimedAt.Add(-time.Hour)},
+		}}, nil)
+		mockRepo.On("UpdateScheduledChange", mock.AnythingOfType("*entity.ScheduledChange")).Return(nil)
+		mockRepo.On("ListDueScheduledChanges", now).Return([]*entity.ScheduledChange{}, nil)
+
+		// Call the method
+		result, err := useCase.ApplyDueScheduledChanges(context.Background(), now)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, entity.ScheduledChangeStatusApplied, applied.Status)
+		assert.Equal(t, 3, applied.AppliedVersion)
+		assert.Equal(t, entity.ScheduledChangeStatusFailed, lost.Status)
+		assert.Contains(t, lost.Error, "stopped while applying")
+		mockRepo.AssertExpectations(t)
+	})
+}
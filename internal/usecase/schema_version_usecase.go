@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+)
+
+// GetSchemaVersion retrieves a specific version of a configuration's schema
+func (uc *ConfigurationUseCase) GetSchemaVersion(ctx context.Context, configName string, version int) (*entity.SchemaVersion, error) {
+	schemaVersion, err := uc.repo.GetSchemaVersion(ctx, configName, version)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to get schema version", err)
+	}
+
+	return schemaVersion, nil
+}
+
+// ListSchemaVersions lists the schema history of a configuration
+func (uc *ConfigurationUseCase) ListSchemaVersions(ctx context.Context, configName string) ([]*entity.SchemaVersion, error) {
+	versions, err := uc.repo.ListSchemaVersions(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to list schema versions", err)
+	}
+
+	return versions, nil
+}
+
+// ListSchemas lists one page of the current schemas of all configurations
+func (uc *ConfigurationUseCase) ListSchemas(ctx context.Context, limit, offset int) (*entity.SchemaList, error) {
+	schemas, total, err := uc.repo.ListSchemas(ctx, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list schemas", err)
+	}
+
+	return &entity.SchemaList{
+		Schemas: schemas,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// DeleteSchema detaches the schema from a configuration. The configuration's data is
+// no longer validated until a new schema is registered.
+func (uc *ConfigurationUseCase) DeleteSchema(ctx context.Context, configName string) error {
+	if err := uc.repo.DeleteSchema(ctx, configName); err != nil {
+		if isNotFound(err) {
+			return err
+		}
+		return internalError(ctx, "Failed to delete schema", err)
+	}
+	uc.invalidateSchema(configName)
+
+	return nil
+}
+
+// ListUnvalidatedConfigurations lists configurations whose current data is not validated by a schema
+func (uc *ConfigurationUseCase) ListUnvalidatedConfigurations(ctx context.Context) ([]*entity.UnvalidatedConfiguration, error) {
+	configs, err := uc.repo.ListUnvalidatedConfigurations(ctx)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list unvalidated configurations", err)
+	}
+
+	return configs, nil
+}
+
+// AnalyzeSchemaImpact reports how a proposed schema would affect a configuration without
+// registering it: its compatibility with the current schema and whether the most recent
+// configuration versions conform to it
+func (uc *ConfigurationUseCase) AnalyzeSchemaImpact(ctx context.Context, configName string, schema json.RawMessage, checkVersions int) (*entity.SchemaImpact, error) {
+	// Validate schema definition
+	schema, err := withDefaultDraft(schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.validator.ValidateSchemaDefinition(ctx, schema); err != nil {
+		return nil, validatorError(ctx, err)
+	}
+
+	issues, err := uc.compatibilityIssues(ctx, configName, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := uc.validateStoredVersions(ctx, configName, schema, checkVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.SchemaImpact{
+		Name:                configName,
+		Compatible:          len(issues) == 0,
+		CompatibilityIssues: issues,
+		Versions:            versions,
+	}, nil
+}
+
+// checkSchemaCompatibility rejects a schema that is not backward compatible with the
+// current schema of a configuration. The first schema of a configuration always passes.
+func (uc *ConfigurationUseCase) checkSchemaCompatibility(ctx context.Context, configName string, schema json.RawMessage) error {
+	issues, err := uc.compatibilityIssues(ctx, configName, schema)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return errors.NewConflictError("Schema is not backward compatible with the current schema", issues)
+	}
+
+	return nil
+}
+
+// compatibilityIssues lists the backward-incompatible changes from the current schema of a configuration
+func (uc *ConfigurationUseCase) compatibilityIssues(ctx context.Context, configName string, schema json.RawMessage) ([]errors.ValidationError, error) {
+	current, err := uc.repo.GetSchema(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, internalError(ctx, "Failed to load current schema", err)
+	}
+
+	issues, err := validator.CheckBackwardCompatibility(current, schema)
+	if err != nil {
+		return nil, errors.NewInvalidRequestError("Invalid JSON Schema", err.Error())
+	}
+
+	return issues, nil
+}
+
+// checkExistingData rejects a schema that the most recent configuration versions do not conform to
+func (uc *ConfigurationUseCase) checkExistingData(ctx context.Context, configName string, schema json.RawMessage, checkVersions int) error {
+	versions, err := uc.validateStoredVersions(ctx, configName, schema, checkVersions)
+	if err != nil {
+		return err
+	}
+
+	impact := entity.SchemaImpact{Name: configName, Versions: versions}
+	if invalid := impact.InvalidVersions(); len(invalid) > 0 {
+		return errors.NewValidationFailedError("Existing configuration data does not conform to the schema", invalid)
+	}
+
+	return nil
+}
+
+// validateStoredVersions validates the most recent versions of a configuration against a
+// schema, newest first. Compacted versions are skipped; a configuration that does not
+// exist yet has nothing to validate.
+func (uc *ConfigurationUseCase) validateStoredVersions(ctx context.Context, configName string, schema json.RawMessage, checkVersions int) ([]entity.VersionValidation, error) {
+	results := []entity.VersionValidation{}
+
+	current, err := uc.repo.GetConfiguration(ctx, configName)
+	if err != nil {
+		if isNotFound(err) {
+			return results, nil
+		}
+		return nil, internalError(ctx, "Failed to load configuration", err)
+	}
+
+	if checkVersions < 1 {
+		checkVersions = 1
+	}
+
+	for version := current.Version; version >= 1 && len(results) < checkVersions; version-- {
+		data := current.Data
+		if version != current.Version {
+			config, err := uc.repo.GetConfigurationVersion(ctx, configName, version)
+			if err != nil {
+				if isVersionCompacted(err) || isNotFound(err) {
+					continue
+				}
+				return nil, internalError(ctx, "Failed to load configuration version", err)
+			}
+			data = config.Data
+		}
+
+		result, err := uc.validateVersion(ctx, schema, version, data)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// validateVersion validates the data of one configuration version against a schema
+func (uc *ConfigurationUseCase) validateVersion(ctx context.Context, schema json.RawMessage, version int, data json.RawMessage) (entity.VersionValidation, error) {
+	result := entity.VersionValidation{Version: version, Valid: true}
+
+	err := validatorError(ctx, uc.validator.ValidateJSON(ctx, schema, data))
+	if err == nil {
+		return result, nil
+	}
+
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) && appErr.Code == errors.ErrorCodeValidationFailed {
+		result.Valid = false
+		result.Errors, _ = appErr.Details.([]errors.ValidationError)
+		return result, nil
+	}
+
+	return result, err
+}
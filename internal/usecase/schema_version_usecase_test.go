@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_RegisterSchemaCompatibility(t *testing.T) {
+	current := json.RawMessage(`{"type":"object","properties":{"limit":{"type":"integer"}}}`)
+	breaking := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"limit":{"type":"string"}},"required":["limit"]}`)
+	compatible := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"limit":{"type":"number"},"name":{"description":"Display name"}}}`)
+
+	t.Run("RejectsBreakingChange", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithSchemaCompatibility(entity.SchemaCompatibilityBackward))
+
+		mockRepo.On("GetSchema", "test-config").Return(current, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSchema(context.Background(), "test-config", breaking, entity.SchemaRegistrationOptions{})
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		issues, ok := appErr.Details.([]errors.ValidationError)
+		require.True(t, ok)
+		assert.Len(t, issues, 2)
+		mockRepo.AssertNotCalled(t, "RegisterSchema")
+	})
+
+	t.Run("AcceptsCompatibleChange", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithSchemaCompatibility(entity.SchemaCompatibilityBackward))
+
+		mockRepo.On("GetSchema", "test-config").Return(current, nil)
+		mockRepo.On("GetConfiguration", "test-config").Return(nil, errors.NewNotFoundError("Configuration", "test-config"))
+		mockRepo.On("RegisterSchema", "test-config", compatible).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 2, Schema: compatible}, nil)
+
+		// Call the method
+		result, err := useCase.RegisterSchema(context.Background(), "test-config", compatible, entity.SchemaRegistrationOptions{})
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ForceSkipsCheck", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithSchemaCompatibility(entity.SchemaCompatibilityBackward))
+
+		mockRepo.On("RegisterSchema", "test-config", breaking).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 2, Schema: breaking}, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSchema(context.Background(), "test-config", breaking, entity.SchemaRegistrationOptions{Force: true})
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetSchema", "test-config")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("FirstSchemaAlwaysAccepted", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo, WithSchemaCompatibility(entity.SchemaCompatibilityBackward))
+
+		mockRepo.On("GetSchema", "test-config").Return(nil, errors.NewNotFoundError("Schema", "test-config"))
+		mockRepo.On("GetConfiguration", "test-config").Return(nil, errors.NewNotFoundError("Configuration", "test-config"))
+		mockRepo.On("RegisterSchema", "test-config", breaking).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 1, Schema: breaking}, nil)
+
+		// Call the method
+		result, err := useCase.RegisterSchema(context.Background(), "test-config", breaking, entity.SchemaRegistrationOptions{})
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Version)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestConfigurationUseCase_RegisterSchemaExistingData(t *testing.T) {
+	schema := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"limit":{"type":"integer","maximum":100}}}`)
+	current := &entity.Configuration{Name: "test-config", Version: 3, Data: json.RawMessage(`{"limit":50}`)}
+
+	t.Run("RejectsNonConformingVersions", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(current, nil)
+		mockRepo.On("GetConfigurationVersion", "test-config", 2).Return(nil, errors.NewVersionCompactedError("test-config", 2))
+		mockRepo.On("GetConfigurationVersion", "test-config", 1).
+			Return(&entity.Configuration{Name: "test-config", Version: 1, Data: json.RawMessage(`{"limit":500}`)}, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSchema(context.Background(), "test-config", schema, entity.SchemaRegistrationOptions{CheckVersions: 2})
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		invalid, ok := appErr.Details.([]entity.VersionValidation)
+		require.True(t, ok)
+		require.Len(t, invalid, 1)
+		assert.Equal(t, 1, invalid[0].Version)
+		assert.NotEmpty(t, invalid[0].Errors)
+		mockRepo.AssertNotCalled(t, "RegisterSchema")
+	})
+
+	t.Run("ChecksCurrentVersionByDefault", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfiguration", "test-config").Return(current, nil)
+		mockRepo.On("RegisterSchema", "test-config", schema).
+			Return(&entity.SchemaVersion{Name: "test-config", Version: 1, Schema: schema}, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSchema(context.Background(), "test-config", schema, entity.SchemaRegistrationOptions{})
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetConfigurationVersion", "test-config", 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AnalyzeSchemaImpact", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSchema", "test-config").Return(json.RawMessage(`{"type":"object","properties":{"limit":{"type":"number"}}}`), nil)
+		mockRepo.On("GetConfiguration", "test-config").
+			Return(&entity.Configuration{Name: "test-config", Version: 2, Data: json.RawMessage(`{"limit":500}`)}, nil)
+		mockRepo.On("GetConfigurationVersion", "test-config", 1).
+			Return(&entity.Configuration{Name: "test-config", Version: 1, Data: json.RawMessage(`{"limit":5}`)}, nil)
+
+		// Call the method
+		impact, err := useCase.AnalyzeSchemaImpact(context.Background(), "test-config", schema, 5)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, impact.Compatible)
+		assert.NotEmpty(t, impact.CompatibilityIssues)
+		require.Len(t, impact.Versions, 2)
+		assert.False(t, impact.Versions[0].Valid)
+		assert.True(t, impact.Versions[1].Valid)
+		mockRepo.AssertNotCalled(t, "RegisterSchema")
+	})
+}
+
+func TestConfigurationUseCase_SchemaVersions(t *testing.T) {
+	t.Run("GetSchemaVersion", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		version := &entity.SchemaVersion{Name: "test-config", Version: 1, Schema: json.RawMessage(`{"type":"object"}`)}
+		mockRepo.On("GetSchemaVersion", "test-config", 1).Return(version, nil)
+
+		// Call the method
+		result, err := useCase.GetSchemaVersion(context.Background(), "test-config", 1)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, version, result)
+	})
+
+	t.Run("GetSchemaVersionNotFound", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSchemaVersion", "test-config", 9).Return(nil, errors.NewNotFoundError("Schema version", "test-config:9"))
+
+		// Call the method
+		_, err := useCase.GetSchemaVersion(context.Background(), "test-config", 9)
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.AppError).Code)
+	})
+
+	t.Run("ListSchemaVersions", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		versions := []*entity.SchemaVersion{{Name: "test-config", Version: 1}, {Name: "test-config", Version: 2}}
+		mockRepo.On("ListSchemaVersions", "test-config").Return(versions, nil)
+
+		// Call the method
+		result, err := useCase.ListSchemaVersions(context.Background(), "test-config")
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+}
+
+func TestConfigurationUseCase_SchemaCatalog(t *testing.T) {
+	t.Run("ListSchemas", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		schemas := []*entity.SchemaVersion{{Name: "a", Version: 1}, {Name: "b", Version: 3}}
+		mockRepo.On("ListSchemas", 2, 0).Return(schemas, 5, nil)
+
+		// Call the method
+		result, err := useCase.ListSchemas(context.Background(), 2, 0)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.Total)
+		assert.Equal(t, 2, result.Limit)
+		assert.Len(t, result.Schemas, 2)
+	})
+
+	t.Run("DeleteSchemaNotFound", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("DeleteSchema", "test-config").Return(errors.NewNotFoundError("Schema", "test-config"))
+
+		// Call the method
+		err := useCase.DeleteSchema(context.Background(), "test-config")
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.AppError).Code)
+	})
+
+	t.Run("ListUnvalidatedConfigurations", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		configs := []*entity.UnvalidatedConfiguration{{Name: "a", Version: 1, Reason: entity.UnvalidatedReasonNoSchema}}
+		mockRepo.On("ListUnvalidatedConfigurations").Return(configs, nil)
+
+		// Call the method
+		result, err := useCase.ListUnvalidatedConfigurations(context.Background())
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, configs, result)
+	})
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+	"sort"
+)
+
+// schemaScanPageSize is how many configuration schemas are loaded at a time when
+// looking for schemas that depend on a shared schema
+const schemaScanPageSize = 100
+
+// RegisterSharedSchema creates or replaces a shared schema fragment. Unless force is set,
+// the change is rejected when the current data of a configuration whose schema depends
+// on the fragment would no longer validate.
+func (uc *ConfigurationUseCase) RegisterSharedSchema(ctx context.Context, name string, schema json.RawMessage, force bool) (*entity.SharedSchema, error) {
+	if !validator.IsValidSharedSchemaName(name) {
+		return nil, errors.NewInvalidRequestError("Invalid shared schema name", name)
+	}
+	schema, err := withDefaultDraft(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve references as if the new definition were already stored, so that cycles
+	// through the fragment itself are detected
+	load := func(ctx context.Context, ref string) (json.RawMessage, error) {
+		if ref == name {
+			return schema, nil
+		}
+		return uc.loadSharedSchema(ctx, ref)
+	}
+	proposed := validator.NewJSONSchemaValidatorWithSharedSchemas(load, validator.WithStrictFormats(uc.strictFormats))
+
+	if err := proposed.ValidateSchemaDefinition(ctx, schema); err != nil {
+		return nil, validatorError(ctx, err)
+	}
+
+	if !force {
+		results, err := uc.validateDependents(ctx, name, load, proposed)
+		if err != nil {
+			return nil, err
+		}
+
+		invalid := []entity.DependentValidation{}
+		for _, result := range results {
+			if !result.Valid {
+				invalid = append(invalid, result)
+			}
+		}
+		if len(invalid) > 0 {
+			return nil, errors.NewValidationFailedError("Dependent configurations do not conform to the shared schema", invalid)
+		}
+	}
+
+	shared := &entity.SharedSchema{Name: name, Schema: schema}
+	if err := uc.repo.SetSharedSchema(ctx, shared); err != nil {
+		return nil, internalError(ctx, "Failed to store shared schema", err)
+	}
+	uc.invalidateAllSchemas()
+
+	return shared, nil
+}
+
+// GetSharedSchema retrieves a shared schema fragment
+func (uc *ConfigurationUseCase) GetSharedSchema(ctx context.Context, name string) (*entity.SharedSchema, error) {
+	shared, err := uc.repo.GetSharedSchema(ctx, name)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to get shared schema", err)
+	}
+
+	return shared, nil
+}
+
+// ListSharedSchemas lists all shared schema fragments
+func (uc *ConfigurationUseCase) ListSharedSchemas(ctx context.Context) ([]*entity.SharedSchema, error) {
+	schemas, err := uc.repo.ListSharedSchemas(ctx)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list shared schemas", err)
+	}
+
+	return schemas, nil
+}
+
+// DeleteSharedSchema removes a shared schema fragment that no schema references
+func (uc *ConfigurationUseCase) DeleteSharedSchema(ctx context.Context, name string) error {
+	if _, err := uc.GetSharedSchema(ctx, name); err != nil {
+		return err
+	}
+
+	referrers, err := uc.sharedSchemaReferrers(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(referrers) > 0 {
+		return errors.NewConflictError("Shared schema is still referenced", map[string][]string{
+			"referenced_by": referrers,
+		})
+	}
+
+	if err := uc.repo.DeleteSharedSchema(ctx, name); err != nil {
+		if isNotFound(err) {
+			return err
+		}
+		return internalError(ctx, "Failed to delete shared schema", err)
+	}
+
+	return nil
+}
+
+// loadSharedSchema loads a shared schema definition for the validator, within the
+// request that needs it
+func (uc *ConfigurationUseCase) loadSharedSchema(ctx context.Context, name string) (json.RawMessage, error) {
+	shared, err := uc.repo.GetSharedSchema(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return shared.Schema, nil
+}
+
+// validateDependents validates the current data of every configuration whose schema
+// references the shared schema name, directly or through other shared schemas
+func (uc *ConfigurationUseCase) validateDependents(ctx context.Context, name string, load validator.SharedSchemaLoader, v validator.Validator) ([]entity.DependentValidation, error) {
+	schemas, err := uc.allSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []entity.DependentValidation{}
+	for _, schema := range schemas {
+		// The proposed fragment resolves on its own, so a schema that fails to resolve
+		// was already broken and is not affected by the change
+		fragments, err := validator.ResolveSharedSchemas(ctx, schema.Schema, load)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, errors.NewContextError(ctx.Err())
+			}
+			continue
+		}
+		if _, ok := fragments[name]; !ok {
+			continue
+		}
+
+		config, err := uc.repo.GetConfiguration(ctx, schema.Name)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, internalError(ctx, "Failed to load configuration", err)
+		}
+
+		result := entity.DependentValidation{
+			Name:              schema.Name,
+			VersionValidation: entity.VersionValidation{Version: config.Version, Valid: true},
+		}
+		if err := validatorError(ctx, v.ValidateJSON(ctx, schema.Schema, config.Data)); err != nil {
+			var appErr *errors.AppError
+			if !stdErrors.As(err, &appErr) || appErr.Code != errors.ErrorCodeValidationFailed {
+				return nil, err
+			}
+			result.Valid = false
+			result.Errors, _ = appErr.Details.([]errors.ValidationError)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// sharedSchemaReferrers lists the configuration schemas and shared schemas that reference
+// the shared schema name directly, sorted
+func (uc *ConfigurationUseCase) sharedSchemaReferrers(ctx context.Context, name string) ([]string, error) {
+	referrers := []string{}
+	references := func(schema json.RawMessage) bool {
+		refs, err := validator.SharedSchemaRefs(schema)
+		if err != nil {
+			return false
+		}
+		i := sort.SearchStrings(refs, name)
+		return i < len(refs) && refs[i] == name
+	}
+
+	schemas, err := uc.allSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, schema := range schemas {
+		if references(schema.Schema) {
+			referrers = append(referrers, schema.Name)
+		}
+	}
+
+	shared, err := uc.ListSharedSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, fragment := range shared {
+		if fragment.Name != name && references(fragment.Schema) {
+			referrers = append(referrers, validator.SharedSchemaPrefix+fragment.Name)
+		}
+	}
+
+	return referrers, nil
+}
+
+// allSchemas loads the current schema of every configuration
+func (uc *ConfigurationUseCase) allSchemas(ctx context.Context) ([]*entity.SchemaVersion, error) {
+	all := []*entity.SchemaVersion{}
+	for offset := 0; ; offset += schemaScanPageSize {
+		schemas, total, err := uc.repo.ListSchemas(ctx, schemaScanPageSize, offset)
+		if err != nil {
+			return nil, internalError(ctx, "Failed to list schemas", err)
+		}
+		all = append(all, schemas...)
+		if len(schemas) == 0 || offset+len(schemas) >= total {
+			return all, nil
+		}
+	}
+}
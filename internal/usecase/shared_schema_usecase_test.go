@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_RegisterSharedSchema(t *testing.T) {
+	endpoint := json.RawMessage(`{"type":"object","properties":{"url":{"type":"string"}},"required":["url"]}`)
+	stricter := json.RawMessage(`{"type":"object","properties":{"url":{"type":"string"},"timeout":{"type":"integer"}},"required":["url","timeout"]}`)
+	dependent := &entity.SchemaVersion{
+		Name:    "api-config",
+		Version: 1,
+		Schema:  json.RawMessage(`{"type":"object","properties":{"api":{"$ref":"shared://common/endpoint"}}}`),
+	}
+	unrelated := &entity.SchemaVersion{Name: "other", Version: 1, Schema: json.RawMessage(`{"type":"object"}`)}
+	config := &entity.Configuration{Name: "api-config", Version: 4, Data: json.RawMessage(`{"api":{"url":"https://example.com"}}`)}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{dependent, unrelated}, 2, nil)
+		mockRepo.On("GetConfiguration", "api-config").Return(config, nil)
+		mockRepo.On("SetSharedSchema", mock.Anything).Return(nil)
+
+		// Call the method
+		result, err := useCase.RegisterSharedSchema(context.Background(), "common/endpoint", endpoint, false)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, "common/endpoint", result.Name)
+		mockRepo.AssertNotCalled(t, "GetConfiguration", "other")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsBreakingDependents", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{dependent}, 1, nil)
+		mockRepo.On("GetConfiguration", "api-config").Return(config, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSharedSchema(context.Background(), "common/endpoint", stricter, false)
+
+		// Assertions
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		invalid, ok := appErr.Details.([]entity.DependentValidation)
+		require.True(t, ok)
+		require.Len(t, invalid, 1)
+		assert.Equal(t, "api-config", invalid[0].Name)
+		assert.Equal(t, 4, invalid[0].Version)
+		mockRepo.AssertNotCalled(t, "SetSharedSchema", mock.Anything)
+	})
+
+	t.Run("ForceSkipsDependents", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("SetSharedSchema", mock.Anything).Return(nil)
+
+		// Call the method
+		_, err := useCase.RegisterSharedSchema(context.Background(), "common/endpoint", stricter, true)
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "ListSchemas", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RejectsCycle", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "common/retry-policy").Return(&entity.SharedSchema{
+			Name:   "common/retry-policy",
+			Schema: json.RawMessage(`{"properties":{"endpoint":{"$ref":"shared://common/endpoint"}}}`),
+		}, nil)
+
+		// Call the method
+		_, err := useCase.RegisterSharedSchema(context.Background(), "common/endpoint", json.RawMessage(`{"properties":{"retry":{"$ref":"shared://common/retry-policy"}}}`), false)
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+		mockRepo.AssertNotCalled(t, "SetSharedSchema", mock.Anything)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		_, err := useCase.RegisterSharedSchema(context.Background(), "../endpoint", endpoint, false)
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+	})
+}
+
+func TestConfigurationUseCase_DeleteSharedSchema(t *testing.T) {
+	fragment := &entity.SharedSchema{Name: "common/endpoint", Schema: json.RawMessage(`{"type":"object"}`)}
+
+	t.Run("RejectsReferenced", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "common/endpoint").Return(fragment, nil)
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{
+			{Name: "api-config", Schema: json.RawMessage(`{"$ref":"shared://common/endpoint"}`)},
+		}, 1, nil)
+		mockRepo.On("ListSharedSchemas").Return([]*entity.SharedSchema{
+			fragment,
+			{Name: "common/gateway", Schema: json.RawMessage(`{"items":{"$ref":"shared://common/endpoint"}}`)},
+		}, nil)
+
+		// Call the method
+		err := useCase.DeleteSharedSchema(context.Background(), "common/endpoint")
+
+		// Assertions
+		require.Error(t, err)
+		appErr := err.(*errors.AppError)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		assert.Equal(t, map[string][]string{"referenced_by": {"api-config", "shared://common/gateway"}}, appErr.Details)
+		mockRepo.AssertNotCalled(t, "DeleteSharedSchema", "common/endpoint")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "common/endpoint").Return(fragment, nil)
+		mockRepo.On("ListSchemas", schemaScanPageSize, 0).Return([]*entity.SchemaVersion{}, 0, nil)
+		mockRepo.On("ListSharedSchemas").Return([]*entity.SharedSchema{fragment}, nil)
+		mockRepo.On("DeleteSharedSchema", "common/endpoint").Return(nil)
+
+		// Call the method
+		err := useCase.DeleteSharedSchema(context.Background(), "common/endpoint")
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSharedSchema", "missing").Return(nil, errors.NewNotFoundError("Shared schema", "missing"))
+
+		// Call the method
+		err := useCase.DeleteSharedSchema(context.Background(), "missing")
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeNotFound, err.(*errors.AppError).Code)
+	})
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+	"github.com/Titonu/configuration-management-service/pkg/validator"
+)
+
+// ValidateDocument validates configuration data against the schema and rule set of a
+// configuration, reporting every violation with its position in the document. Checks
+// that are not configured are skipped; nothing is stored.
+func (uc *ConfigurationUseCase) ValidateDocument(ctx context.Context, configName string, data json.RawMessage) (*entity.ValidationReport, error) {
+	report := &entity.ValidationReport{Valid: true, Errors: []entity.ValidationIssue{}}
+
+	doc, ok := parseValidatedDocument(report, data)
+	if !ok {
+		return report, nil
+	}
+
+	schema, err := uc.repo.GetSchema(ctx, configName)
+	switch {
+	case err == nil:
+		// Validate the document as it would be stored, with the schema's defaults filled in
+		if data, _, err = uc.applyDefaults(ctx, entity.DefaultsModeWrite, schema, data); err != nil {
+			return nil, err
+		}
+		report.Summary.SchemaChecked = true
+		if err := addValidationIssues(report, doc, entity.ValidationSourceSchema, uc.validateData(ctx, configName, schema, data)); err != nil {
+			return nil, err
+		}
+	case !isNotFound(err):
+		return nil, internalError(ctx, "Failed to load schema", err)
+	}
+
+	ruleSet, err := uc.repo.GetRuleSet(ctx, configName)
+	switch {
+	case err == nil:
+		report.Summary.RulesChecked = len(ruleSet.Rules)
+		failures, err := rules.Evaluate(ruleSet.Rules, data)
+		if err != nil {
+			return nil, internalError(ctx, "Failed to evaluate validation rules", err)
+		}
+		for _, failure := range failures {
+			report.AddIssue(locateIssue(doc, entity.ValidationSourceRule, failure))
+		}
+	case !isNotFound(err):
+		return nil, internalError(ctx, "Failed to load validation rules", err)
+	}
+
+	return report, nil
+}
+
+// ValidateAgainstSchema validates a document against an ad-hoc schema, reporting every
+// violation with its position in the document. Nothing is stored.
+func (uc *ConfigurationUseCase) ValidateAgainstSchema(ctx context.Context, schema json.RawMessage, data json.RawMessage) (*entity.ValidationReport, error) {
+	// Validate schema definition
+	schema, err := withDefaultDraft(schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.validator.ValidateSchemaDefinition(ctx, schema); err != nil {
+		return nil, validatorError(ctx, err)
+	}
+
+	report := &entity.ValidationReport{Valid: true, Errors: []entity.ValidationIssue{}}
+
+	doc, ok := parseValidatedDocument(report, data)
+	if !ok {
+		return report, nil
+	}
+
+	report.Summary.SchemaChecked = true
+	if err := addValidationIssues(report, doc, entity.ValidationSourceSchema, validatorError(ctx, uc.validator.ValidateJSON(ctx, schema, data))); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// parseValidatedDocument parses the document being validated, recording a syntax
+// issue in the report if it is not well-formed JSON
+func parseValidatedDocument(report *entity.ValidationReport, data json.RawMessage) (*validator.Document, bool) {
+	doc, err := validator.ParseDocument(data)
+	if err != nil {
+		var syntaxErr *validator.SyntaxError
+		if !stdErrors.As(err, &syntaxErr) {
+			syntaxErr = &validator.SyntaxError{Position: validator.Position{Line: 1, Column: 1}, Message: err.Error()}
+		}
+		report.AddIssue(entity.ValidationIssue{
+			Source: entity.ValidationSourceSyntax,
+			ValidationError: errors.ValidationError{
+				Field:  validator.RootField,
+				Reason: syntaxErr.Message,
+				Code:   errors.ValidationCodeInvalid,
+			},
+			Line:   syntaxErr.Line,
+			Column: syntaxErr.Column,
+		})
+		return nil, false
+	}
+
+	return doc, true
+}
+
+// addValidationIssues records the details of a validation failure in the report. Any
+// other error is returned.
+func addValidationIssues(report *entity.ValidationReport, doc *validator.Document, source entity.ValidationSource, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) || appErr.Code != errors.ErrorCodeValidationFailed {
+		return err
+	}
+
+	details, _ := appErr.Details.([]errors.ValidationError)
+	for _, detail := range details {
+		report.AddIssue(locateIssue(doc, source, detail))
+	}
+
+	return nil
+}
+
+// locateIssue converts a validation error into an issue located in the document
+func locateIssue(doc *validator.Document, source entity.ValidationSource, detail errors.ValidationError) entity.ValidationIssue {
+	var position validator.Position
+	if detail.Pointer != "" {
+		position = doc.LocatePointer(detail.Pointer)
+	} else {
+		detail.Pointer, position = doc.Locate(detail.Field)
+	}
+
+	return entity.ValidationIssue{
+		Source:          source,
+		ValidationError: detail,
+		Line:            position.Line,
+		Column:          position.Column,
+	}
+}
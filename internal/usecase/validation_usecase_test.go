@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationUseCase_ValidateDocument(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"min_limit":{"type":"integer"},"max_limit":{"type":"integer","maximum":100}}}`)
+	ruleSet := &entity.RuleSet{Name: "payments", Version: 1, Rules: limitRules[:1]}
+
+	t.Run("ReportsSchemaAndRuleErrors", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSchema", "payments").Return(schema, nil)
+		mockRepo.On("GetRuleSet", "payments").Return(ruleSet, nil)
+
+		// Call the method
+		report, err := useCase.ValidateDocument(context.Background(), "payments", json.RawMessage("{\n  \"min_limit\": 500,\n  \"max_limit\": 200\n}"))
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, entity.ValidationSummary{SchemaChecked: true, RulesChecked: 1, ErrorCount: 2, SchemaErrors: 1, RuleErrors: 1}, report.Summary)
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, entity.ValidationSourceSchema, report.Errors[0].Source)
+		assert.Equal(t, "max_limit", report.Errors[0].Field)
+		assert.Equal(t, "/max_limit", report.Errors[0].Pointer)
+		assert.Equal(t, errors.ValidationCodeTooLarge, report.Errors[0].Code)
+		assert.Equal(t, 3, report.Errors[0].Line)
+		assert.Equal(t, 16, report.Errors[0].Column)
+		assert.Equal(t, entity.ValidationSourceRule, report.Errors[1].Source)
+		assert.Equal(t, "/max_limit", report.Errors[1].Pointer)
+		mockRepo.AssertNotCalled(t, "UpdateConfiguration")
+	})
+
+	t.Run("SkipsMissingChecks", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetSchema", "payments").Return(nil, errors.NewNotFoundError("Schema", "payments"))
+		mockRepo.On("GetRuleSet", "payments").Return(nil, errors.NewNotFoundError("Rule set", "payments"))
+
+		// Call the method
+		report, err := useCase.ValidateDocument(context.Background(), "payments", json.RawMessage(`{"max_limit":500}`))
+
+		// Assertions
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.False(t, report.Summary.SchemaChecked)
+		assert.Empty(t, report.Errors)
+	})
+
+	t.Run("ReportsSyntaxError", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		report, err := useCase.ValidateDocument(context.Background(), "payments", json.RawMessage("{\n  \"max_limit\": 5,\n}"))
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, 1, report.Summary.SyntaxErrors)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, 3, report.Errors[0].Line)
+		assert.Equal(t, 1, report.Errors[0].Column)
+		mockRepo.AssertNotCalled(t, "GetSchema", "payments")
+	})
+}
+
+func TestConfigurationUseCase_ValidateAgainstSchema(t *testing.T) {
+	t.Run("ReportsErrors", func(t *testing.T) {
+		useCase := NewConfigurationUseCase(new(MockConfigurationRepository))
+
+		// Call the method
+		report, err := useCase.ValidateAgainstSchema(context.Background(),
+			json.RawMessage(`{"type":"object","required":["name"],"properties":{"port":{"type":"integer"}}}`),
+			json.RawMessage(`{"port":"80"}`),
+		)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, 2, report.Summary.SchemaErrors)
+		pointers := []string{report.Errors[0].Pointer, report.Errors[1].Pointer}
+		assert.ElementsMatch(t, []string{"/name", "/port"}, pointers)
+		for _, issue := range report.Errors {
+			assert.Equal(t, 1, issue.Line)
+		}
+	})
+
+	t.Run("InvalidSchema", func(t *testing.T) {
+		useCase := NewConfigurationUseCase(new(MockConfigurationRepository))
+
+		// Call the method
+		_, err := useCase.ValidateAgainstSchema(context.Background(), json.RawMessage(`{"type":"nonsense"}`), json.RawMessage(`{}`))
+
+		// Assertions
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, err.(*errors.AppError).Code)
+	})
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// TagVersion attaches a tag to a configuration version, moving it if it is movable
+func (uc *ConfigurationUseCase) TagVersion(ctx context.Context, name, tag string, version int, immutable bool) (*entity.VersionTag, error) {
+	if !entity.IsValidTagName(tag) {
+		return nil, errors.NewInvalidRequestError("Invalid tag name", map[string]string{
+			"tag": tag,
+		})
+	}
+
+	// Check if the version exists and still has its data
+	if _, err := uc.repo.GetConfigurationVersion(ctx, name, version); err != nil {
+		if isVersionCompacted(err) {
+			return nil, err
+		}
+		return nil, notFoundError(ctx, err, "Configuration version", name)
+	}
+
+	existing, err := uc.repo.GetVersionTag(ctx, name, tag)
+	if err != nil && !isNotFound(err) {
+		return nil, internalError(ctx, "Failed to get tag", err)
+	}
+
+	var result *entity.VersionTag
+	if existing == nil {
+		result = entity.NewVersionTag(name, tag, version, immutable)
+	} else {
+		if existing.Immutable {
+			if existing.Version == version {
+				return existing, nil
+			}
+			return nil, errors.NewConflictError("Tag is immutable and cannot be moved", map[string]interface{}{
+				"tag":     tag,
+				"version": existing.Version,
+			})
+		}
+		existing.MoveTo(version, immutable)
+		result = existing
+	}
+
+	// The repository re-checks both in the write, in case a concurrent writer made the tag
+	// immutable or the compactor removed the version since they were read
+	if err := uc.repo.SetVersionTag(ctx, result); err != nil {
+		var appErr *errors.AppError
+		if stdErrors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, internalError(ctx, "Failed to store tag", err)
+	}
+
+	return result, nil
+}
+
+// ListVersionTags lists the tags of a configuration
+func (uc *ConfigurationUseCase) ListVersionTags(ctx context.Context, name string) ([]*entity.VersionTag, error) {
+	// Check if configuration exists
+	if _, err := uc.repo.GetConfiguration(ctx, name); err != nil {
+		return nil, notFoundError(ctx, err, "Configuration", name)
+	}
+
+	tags, err := uc.repo.ListVersionTags(ctx, name)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list tags", err)
+	}
+
+	return tags, nil
+}
+
+// DeleteVersionTag removes a movable tag
+func (uc *ConfigurationUseCase) DeleteVersionTag(ctx context.Context, name, tag string) error {
+	existing, err := uc.repo.GetVersionTag(ctx, name, tag)
+	if err != nil {
+		return notFoundError(ctx, err, "Tag", tag)
+	}
+
+	if existing.Immutable {
+		return errors.NewConflictError("Tag is immutable and cannot be removed", map[string]interface{}{
+			"tag":     tag,
+			"version": existing.Version,
+		})
+	}
+
+	if err := uc.repo.DeleteVersionTag(ctx, name, tag); err != nil {
+		return internalError(ctx, "Failed to remove tag", err)
+	}
+
+	return nil
+}
+
+// GetConfigurationByTag retrieves the configuration version a tag points at
+func (uc *ConfigurationUseCase) GetConfigurationByTag(ctx context.Context, name, tag string) (*entity.Configuration, error) {
+	existing, err := uc.repo.GetVersionTag(ctx, name, tag)
+	if err != nil {
+		return nil, notFoundError(ctx, err, "Tag", tag)
+	}
+
+	return uc.GetConfigurationVersion(ctx, name, existing.Version)
+}
+
+// RollbackConfigurationToTag rolls back a configuration to the version a tag points at
+func (uc *ConfigurationUseCase) RollbackConfigurationToTag(ctx context.Context, name, tag string) (*entity.Configuration, error) {
+	existing, err := uc.repo.GetVersionTag(ctx, name, tag)
+	if err != nil {
+		return nil, notFoundError(ctx, err, "Tag", tag)
+	}
+
+	return uc.RollbackConfiguration(ctx, name, existing.Version)
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConfigurationUseCase_TagVersion(t *testing.T) {
+	t.Run("NewTag", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfigurationVersion", "test-config", 2).Return(&entity.Configuration{Name: "test-config", Version: 2}, nil)
+		mockRepo.On("GetVersionTag", "test-config", "v2.3-release").Return(nil, errors.NewNotFoundError("Tag", "v2.3-release"))
+		mockRepo.On("SetVersionTag", mock.AnythingOfType("*entity.VersionTag")).Return(nil)
+
+		// Call the method
+		result, err := useCase.TagVersion(context.Background(), "test-config", "v2.3-release", 2, true)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Version)
+		assert.True(t, result.Immutable)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MoveMovableTag", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		existing := entity.NewVersionTag("test-config", "last-known-good", 1, false)
+		mockRepo.On("GetConfigurationVersion", "test-config", 3).Return(&entity.Configuration{Name: "test-config", Version: 3}, nil)
+		mockRepo.On("GetVersionTag", "test-config", "last-known-good").Return(existing, nil)
+		mockRepo.On("SetVersionTag", existing).Return(nil)
+
+		// Call the method
+		result, err := useCase.TagVersion(context.Background(), "test-config", "last-known-good", 3, false)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ImmutableTagCannotMove", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		existing := entity.NewVersionTag("test-config", "v2.3-release", 2, true)
+		mockRepo.On("GetConfigurationVersion", "test-config", 3).Return(&entity.Configuration{Name: "test-config", Version: 3}, nil)
+		mockRepo.On("GetVersionTag", "test-config", "v2.3-release").Return(existing, nil)
+
+		// Call the method
+		result, err := useCase.TagVersion(context.Background(), "test-config", "v2.3-release", 3, false)
+
+		// Assertions
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		mockRepo.AssertNotCalled(t, "SetVersionTag", mock.Anything)
+	})
+
+	t.Run("TagMadeImmutableConcurrently", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// The tag was movable when read, but another writer made it immutable before the write
+		existing := entity.NewVersionTag("test-config", "last-known-good", 1, false)
+		mockRepo.On("GetConfigurationVersion", "test-config", 3).Return(&entity.Configuration{Name: "test-config", Version: 3}, nil)
+		mockRepo.On("GetVersionTag", "test-config", "last-known-good").Return(existing, nil)
+		mockRepo.On("SetVersionTag", existing).Return(errors.NewConflictError("Tag is immutable and cannot be moved", nil))
+
+		// Call the method
+		result, err := useCase.TagVersion(context.Background(), "test-config", "last-known-good", 3, false)
+
+		// Assertions
+		assert.Nil(t, result)
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidTagName", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		// Call the method
+		_, err := useCase.TagVersion(context.Background(), "test-config", "bad/tag", 1, false)
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeInvalidRequest, appErr.Code)
+	})
+
+	t.Run("VersionNotFound", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetConfigurationVersion", "test-config", 9).Return(nil, errors.NewNotFoundError("Configuration version", "test-config"))
+
+		// Call the method
+		_, err := useCase.TagVersion(context.Background(), "test-config", "release", 9, false)
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeNotFound, appErr.Code)
+	})
+}
+
+func TestConfigurationUseCase_DeleteVersionTag(t *testing.T) {
+	t.Run("Movable", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetVersionTag", "test-config", "last-known-good").
+			Return(entity.NewVersionTag("test-config", "last-known-good", 1, false), nil)
+		mockRepo.On("DeleteVersionTag", "test-config", "last-known-good").Return(nil)
+
+		// Call the method
+		err := useCase.DeleteVersionTag(context.Background(), "test-config", "last-known-good")
+
+		// Assertions
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		mockRepo := new(MockConfigurationRepository)
+		useCase := NewConfigurationUseCase(mockRepo)
+
+		mockRepo.On("GetVersionTag", "test-config", "v2.3-release").
+			Return(entity.NewVersionTag("test-config", "v2.3-release", 2, true), nil)
+
+		// Call the method
+		err := useCase.DeleteVersionTag(context.Background(), "test-config", "v2.3-release")
+
+		// Assertions
+		appErr, ok := err.(*errors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeConflict, appErr.Code)
+		mockRepo.AssertNotCalled(t, "DeleteVersionTag", "test-config", "v2.3-release")
+	})
+}
+
+func TestConfigurationUseCase_GetConfigurationByTag(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	expected := &entity.Configuration{Name: "test-config", Version: 2, Data: json.RawMessage(`{"key":"v2"}`)}
+	mockRepo.On("GetVersionTag", "test-config", "release").Return(entity.NewVersionTag("test-config", "release", 2, true), nil)
+	mockRepo.On("GetConfigurationVersion", "test-config", 2).Return(expected, nil)
+	mockRepo.On("GetSchema", "test-config").Return(nil, errors.NewNotFoundError("Schema", "test-config"))
+	mockRepo.On("GetVersionTag", "test-config", "missing").Return(nil, errors.NewNotFoundError("Tag", "missing"))
+
+	// Call the method
+	result, err := useCase.GetConfigurationByTag(context.Background(), "test-config", "release")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = useCase.GetConfigurationByTag(context.Background(), "test-config", "missing")
+	appErr, ok := err.(*errors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, errors.ErrorCodeNotFound, appErr.Code)
+}
+
+func TestConfigurationUseCase_RollbackConfigurationToTag(t *testing.T) {
+	mockRepo := new(MockConfigurationRepository)
+	useCase := NewConfigurationUseCase(mockRepo)
+
+	current := &entity.Configuration{Name: "test-config", Version: 4, Data: json.RawMessage(`{"key":"v4"}`)}
+	mockRepo.On("GetVersionTag", "test-config", "last-known-good").
+		Return(entity.NewVersionTag("test-config", "last-known-good", 2, false), nil)
+	mockRepo.On("GetConfiguration", "test-config").Return(current, nil)
+	mockRepo.On("GetVersionData", "test-config", 2).Return(json.RawMessage(`{"key":"v2"}`), nil)
+	mockRepo.On("UpdateConfiguration", mock.AnythingOfType("*entity.Configuration")).Return(nil)
+	mockRepo.On("StoreVersionData", "test-config", 5, json.RawMessage(`{"key":"v2"}`)).Return(nil)
+
+	// Call the method
+	result, err := useCase.RollbackConfigurationToTag(context.Background(), "test-config", "last-known-good")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.Version)
+	assert.Equal(t, 2, result.RollbackTo)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Export streams an archive of every configuration with its full history to w,
+// gzip-compressed when compress is set
+func (c *Client) Export(ctx context.Context, w io.Writer, compress bool) error {
+	var query url.Values
+	if compress {
+		query = url.Values{"compression": {"gzip"}}
+	}
+	req := &request{method: http.MethodGet, path: "/api/v1/export", query: query}
+	req.header = http.Header{"Accept": {"application/x-ndjson, application/gzip"}}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Import restores an archive read from r, compressed or not (requires the admin role).
+// When the archive does not validate nothing is imported, and the *Error details hold
+// the ImportReport.
+func (c *Client) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	archive, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if opts.Mode != "" {
+		query.Set("mode", string(opts.Mode))
+	}
+	if opts.DryRun {
+		query.Set("dry_run", strconv.FormatBool(opts.DryRun))
+	}
+	req := &request{
+		method:      http.MethodPost,
+		path:        "/api/v1/import",
+		query:       query,
+		body:        archive,
+		contentType: "application/x-ndjson",
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var report ImportReport
+	if err := decodeResponse(resp, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+)
+
+const defaultPollInterval = 30 * time.Second
+
+// ErrNotCached is returned for a configuration the cache does not hold, either because
+// it is not among the cached names or because it does not exist
+var ErrNotCached = stdErrors.New("configuration not cached")
+
+// Cache keeps the current version of a set of configurations in memory. It refreshes
+// them in the background by polling the bulk read endpoint with the ETag of the last
+// response, so an unchanged set costs a 304 response. When a snapshot file is set, every
+// refresh that changes the set is written to it, and the snapshot is loaded when the
+// service cannot be reached at start.
+type Cache struct {
+	client       *Client
+	names        []string
+	pollInterval time.Duration
+	snapshotPath string
+	onChange     func(old, updated *Configuration)
+	onError      func(error)
+
+	mu        sync.RWMutex
+	configs   map[string]*Configuration
+	etags     []string
+	refreshed time.Time
+	stale     bool
+}
+
+// CacheOption configures a Cache
+type CacheOption func(*Cache)
+
+// WithPollInterval sets how often the cache is refreshed in the background
+func WithPollInterval(interval time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.pollInterval = interval
+	}
+}
+
+// WithSnapshotFile keeps a last-known-good copy of the cached configurations at path
+func WithSnapshotFile(path string) CacheOption {
+	return func(c *Cache) {
+		c.snapshotPath = path
+	}
+}
+
+// WithChangeHandler calls fn whenever a refresh finds a new version of a configuration;
+// old is nil for a configuration seen for the first time
+func WithChangeHandler(fn func(old, updated *Configuration)) CacheOption {
+	return func(c *Cache) {
+		c.onChange = fn
+	}
+}
+
+// WithErrorHandler calls fn with the error of every failed background refresh
+func WithErrorHandler(fn func(error)) CacheOption {
+	return func(c *Cache) {
+		c.onError = fn
+	}
+}
+
+// NewCache creates a cache of the current version of the named configurations
+func NewCache(client *Client, names []string, opts ...CacheOption) *Cache {
+	c := &Cache{
+		client:       client,
+		names:        append([]string(nil), names...),
+		pollInterval: defaultPollInterval,
+		configs:      make(map[string]*Configuration),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.etags = make([]string, len(c.chunks()))
+	return c
+}
+
+// Start fills the cache and refreshes it in the background until ctx is done. If the
+// service cannot be reached, the snapshot file is loaded instead and Start succeeds; the
+// cache then reports Stale until a refresh succeeds.
+func (c *Cache) Start(ctx context.Context) error {
+	if err := c.Refresh(ctx); err != nil {
+		if c.snapshotPath == "" || !isUnavailable(err) {
+			return err
+		}
+		if loadErr := c.loadSnapshot(); loadErr != nil {
+			return fmt.Errorf("%w; loading snapshot: %v", err, loadErr)
+		}
+	}
+
+	go c.poll(ctx)
+	return nil
+}
+
+// poll refreshes the cache every poll interval until ctx is done
+func (c *Cache) poll(ctx context.Context) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil && ctx.Err() == nil && c.onError != nil {
+				c.onError(err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the cached configurations that changed since the last refresh
+func (c *Cache) Refresh(ctx context.Context) error {
+	changed := false
+	for i, chunk := range c.chunks() {
+		c.mu.RLock()
+		etag := c.etags[i]
+		c.mu.RUnlock()
+
+		refs := make([]ConfigurationRef, len(chunk))
+		for j, name := range chunk {
+			refs[j] = ConfigurationRef{Name: name}
+		}
+		items, newETag, err := c.client.GetConfigurations(ctx, refs, etag)
+		if stdErrors.Is(err, ErrNotModified) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if c.apply(items, newETag, i) {
+			changed = true
+		}
+	}
+
+	c.mu.Lock()
+	c.refreshed = time.Now()
+	c.stale = false
+	c.mu.Unlock()
+
+	if changed && c.snapshotPath != "" {
+		return c.saveSnapshot()
+	}
+	return nil
+}
+
+// apply stores the items of one bulk read and reports whether any configuration changed.
+// Configurations that cannot be read keep their cached version.
+func (c *Cache) apply(items []BulkReadItem, etag string, chunk int) bool {
+	type change struct{ old, updated *Configuration }
+	var changes []change
+
+	c.mu.Lock()
+	for _, item := range items {
+		if item.Configuration == nil {
+			continue
+		}
+		old := c.configs[item.Name]
+		if old != nil && old.Version == item.Configuration.Version && string(old.Data) == string(item.Configuration.Data) {
+			continue
+		}
+		c.configs[item.Name] = item.Configuration
+		changes = append(changes, change{old, item.Configuration})
+	}
+	c.etags[chunk] = etag
+	c.mu.Unlock()
+
+	if c.onChange != nil {
+		for _, ch := range changes {
+			c.onChange(ch.old, ch.updated)
+		}
+	}
+	return len(changes) > 0
+}
+
+// chunks splits the cached names into groups that fit one bulk read
+func (c *Cache) chunks() [][]string {
+	var chunks [][]string
+	for start := 0; start < len(c.names); start += entity.MaxBulkReadNames {
+		end := start + entity.MaxBulkReadNames
+		if end > len(c.names) {
+			end = len(c.names)
+		}
+		chunks = append(chunks, c.names[start:end])
+	}
+	return chunks
+}
+
+// Get returns the cached version of a configuration
+func (c *Cache) Get(name string) (*Configuration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	config, ok := c.configs[name]
+	return config, ok
+}
+
+// Unmarshal decodes the data of the cached version of a configuration into v, which is
+// typically a pointer to a struct with json tags
+func (c *Cache) Unmarshal(name string, v interface{}) error {
+	config, ok := c.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotCached, name)
+	}
+	return json.Unmarshal(config.Data, v)
+}
+
+// Stale reports whether the cache holds a snapshot loaded because the service could not
+// be reached, rather than data fetched from the service
+func (c *Cache) Stale() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stale
+}
+
+// LastRefresh returns when the cache was last refreshed from the service
+func (c *Cache) LastRefresh() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.refreshed
+}
+
+// snapshot is the on-disk format of the last-known-good configurations
+type snapshot struct {
+	SavedAt        time.Time                 `json:"saved_at"`
+	Configurations map[string]*Configuration `json:"configurations"`
+}
+
+// saveSnapshot writes the cached configurations to the snapshot file, replacing it
+// atomically so that a crash never leaves a truncated snapshot behind
+func (c *Cache) saveSnapshot() error {
+	c.mu.RLock()
+	encoded, err := json.MarshalIndent(snapshot{SavedAt: time.Now().UTC(), Configurations: c.configs}, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.snapshotPath), filepath.Base(c.snapshotPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.snapshotPath)
+}
+
+// loadSnapshot fills the cache from the snapshot file and marks it stale
+func (c *Cache) loadSnapshot() error {
+	encoded, err := os.ReadFile(c.snapshotPath)
+	if err != nil {
+		return err
+	}
+	var saved snapshot
+	if err := json.Unmarshal(encoded, &saved); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.names {
+		if config, ok := saved.Configurations[name]; ok {
+			c.configs[name] = config
+		}
+	}
+	c.stale = true
+	return nil
+}
+
+// isUnavailable reports whether err means the service could not be reached or answered
+// that it is unavailable, as opposed to rejecting the request
+func isUnavailable(err error) bool {
+	var apiErr *Error
+	if stdErrors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode) || apiErr.StatusCode >= 500
+	}
+	return !stdErrors.Is(err, context.Canceled)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configServer serves bulk reads of a payments configuration whose version can be bumped
+type configServer struct {
+	version  int32
+	requests int32
+	down     int32
+}
+
+func (s *configServer) handle(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.requests, 1)
+	if atomic.LoadInt32(&s.down) == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	version := atomic.LoadInt32(&s.version)
+	etag := `"v` + string(rune('0'+version)) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"configurations": []map[string]interface{}{
+		{"name": "payments", "configuration": map[string]interface{}{
+			"name": "payments", "version": version, "data": map[string]interface{}{"max_limit": 1000 * version},
+		}},
+		{"name": "missing", "error": map[string]interface{}{"error": "Configuration not found", "code": "NOT_FOUND"}},
+	}})
+}
+
+func TestCache(t *testing.T) {
+	type settings struct {
+		MaxLimit int `json:"max_limit"`
+	}
+
+	t.Run("RefreshesInBackground", func(t *testing.T) {
+		server := &configServer{version: 1}
+		c := newTestClient(t, server.handle)
+
+		var mu sync.Mutex
+		var changes []int
+		cache := NewCache(c, []string{"payments", "missing"},
+			WithPollInterval(5*time.Millisecond),
+			WithChangeHandler(func(old, updated *Configuration) {
+				mu.Lock()
+				changes = append(changes, updated.Version)
+				mu.Unlock()
+			}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		require.NoError(t, cache.Start(ctx))
+
+		var s settings
+		require.NoError(t, cache.Unmarshal("payments", &s))
+		assert.Equal(t, 1000, s.MaxLimit)
+		assert.ErrorIs(t, cache.Unmarshal("missing", &s), ErrNotCached)
+		assert.False(t, cache.Stale())
+
+		atomic.StoreInt32(&server.version, 2)
+		assert.Eventually(t, func() bool {
+			config, ok := cache.Get("payments")
+			return ok && config.Version == 2
+		}, time.Second, 5*time.Millisecond)
+
+		mu.Lock()
+		assert.Equal(t, []int{1, 2}, changes)
+		mu.Unlock()
+	})
+
+	t.Run("UnchangedRefreshIsNotModified", func(t *testing.T) {
+		server := &configServer{version: 1}
+		c := newTestClient(t, server.handle)
+		cache := NewCache(c, []string{"payments"})
+
+		require.NoError(t, cache.Refresh(context.Background()))
+		config, _ := cache.Get("payments")
+		require.NoError(t, cache.Refresh(context.Background()))
+		unchanged, _ := cache.Get("payments")
+		assert.Same(t, config, unchanged)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&server.requests))
+	})
+
+	t.Run("FallsBackToSnapshot", func(t *testing.T) {
+		snapshotPath := filepath.Join(t.TempDir(), "snapshot.json")
+		server := &configServer{version: 3}
+		c := newTestClient(t, server.handle)
+
+		// A successful refresh writes the snapshot
+		require.NoError(t, NewCache(c, []string{"payments"}, WithSnapshotFile(snapshotPath)).Refresh(context.Background()))
+
+		// A new cache started while the service is down uses it
+		atomic.StoreInt32(&server.down, 1)
+		cache := NewCache(c, []string{"payments"}, WithSnapshotFile(snapshotPath), WithPollInterval(5*time.Millisecond))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		require.NoError(t, cache.Start(ctx))
+
+		assert.True(t, cache.Stale())
+		var s settings
+		require.NoError(t, cache.Unmarshal("payments", &s))
+		assert.Equal(t, 3000, s.MaxLimit)
+
+		// The cache recovers once the service is back
+		atomic.StoreInt32(&server.down, 0)
+		assert.Eventually(t, func() bool { return !cache.Stale() }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("NoSnapshot", func(t *testing.T) {
+		server := &configServer{version: 1, down: 1}
+		c := newTestClient(t, server.handle)
+
+		err := NewCache(c, []string{"payments"}).Start(context.Background())
+		assert.Error(t, err)
+	})
+}
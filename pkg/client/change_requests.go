@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateChangeRequest proposes data as the next version of a configuration that
+// requires approval
+func (c *Client) CreateChangeRequest(ctx context.Context, name string, data interface{}) (*ChangeRequest, error) {
+	body := map[string]interface{}{"data": data}
+	var cr ChangeRequest
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations/"+escape(name)+"/change-requests", nil, body, &cr); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// ListChangeRequests lists the change requests of a configuration; an empty status
+// lists them all
+func (c *Client) ListChangeRequests(ctx context.Context, name string, status ChangeRequestStatus) ([]*ChangeRequest, error) {
+	var query url.Values
+	if status != "" {
+		query = url.Values{"status": {string(status)}}
+	}
+	var response struct {
+		ChangeRequests []*ChangeRequest `json:"change_requests"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/change-requests", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.ChangeRequests, nil
+}
+
+// GetChangeRequest returns a change request with its approvals and comments
+func (c *Client) GetChangeRequest(ctx context.Context, name string, id int64) (*ChangeRequest, error) {
+	var cr ChangeRequest
+	if err := c.do(ctx, http.MethodGet, changeRequestPath(name, id), nil, nil, &cr); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// ApproveChangeRequest approves a change request; the approval that reaches the required
+// number applies it
+func (c *Client) ApproveChangeRequest(ctx context.Context, name string, id int64) (*ChangeRequest, error) {
+	return c.changeRequestAction(ctx, name, id, "approve", nil)
+}
+
+// RejectChangeRequest rejects a change request with an optional reason
+func (c *Client) RejectChangeRequest(ctx context.Context, name string, id int64, reason string) (*ChangeRequest, error) {
+	return c.changeRequestAction(ctx, name, id, "reject", map[string]interface{}{"reason": reason})
+}
+
+// WithdrawChangeRequest withdraws a change request created with the same API key
+func (c *Client) WithdrawChangeRequest(ctx context.Context, name string, id int64) (*ChangeRequest, error) {
+	return c.changeRequestAction(ctx, name, id, "withdraw", nil)
+}
+
+// CommentOnChangeRequest adds a comment to a change request
+func (c *Client) CommentOnChangeRequest(ctx context.Context, name string, id int64, body string) (*ChangeRequestComment, error) {
+	var comment ChangeRequestComment
+	path := changeRequestPath(name, id) + "/comments"
+	if err := c.do(ctx, http.MethodPost, path, nil, map[string]interface{}{"body": body}, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (c *Client) changeRequestAction(ctx context.Context, name string, id int64, action string, body interface{}) (*ChangeRequest, error) {
+	var cr ChangeRequest
+	if err := c.do(ctx, http.MethodPost, changeRequestPath(name, id)+"/"+action, nil, body, &cr); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+func changeRequestPath(name string, id int64) string {
+	return "/api/v1/configurations/" + escape(name) + "/change-requests/" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,266 @@
+// Package client is a Go client for the configuration management service API.
+//
+// A Client covers every endpoint of the API, retries idempotent requests with
+// exponential backoff and decodes API error responses into *Error values that can be
+// inspected with IsNotFound, IsValidationFailed and the other helpers. A Cache keeps
+// fetched configurations in memory, refreshes them in the background and falls back to
+// a last-known-good snapshot on disk when the service cannot be reached.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// Client calls the configuration management service API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates every request with the given API key
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed idempotent request is retried and the
+// bounds of the exponential backoff between attempts; zero retries disables retrying
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New creates a client for the service at baseURL, such as http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Health reports whether the service is up
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
+
+// request describes one API call; body is sent as is with contentType, or encoded as
+// JSON when it is not a []byte. Requests with a safe method or marked idempotent are
+// retried.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        interface{}
+	contentType string
+	idempotent  bool
+}
+
+// do sends a JSON request and decodes a JSON response into out, if given
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	return c.doRequest(ctx, &request{method: method, path: path, query: query, body: body}, out)
+}
+
+// replace sends a PUT that replaces a resource as a whole, such as a tag, so that
+// repeating it has no further effect and it can be retried. A PUT that adds a version,
+// such as an update of a configuration, is sent with do and never retried.
+func (c *Client) replace(ctx context.Context, path string, query url.Values, body, out interface{}) error {
+	return c.doRequest(ctx, &request{method: http.MethodPut, path: path, query: query, body: body, idempotent: true}, out)
+}
+
+// doRequest sends a request and decodes a JSON response into out, if given
+func (c *Client) doRequest(ctx context.Context, req *request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+// send performs a request, retrying it on transport errors and on responses that say the
+// service is temporarily unavailable. Only idempotent requests are retried, so that a
+// create or a new version is never applied twice. A successful response is returned with its body open;
+// an error response is decoded into an *Error.
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	var payload []byte
+	contentType := req.contentType
+	switch body := req.body.(type) {
+	case nil:
+	case []byte:
+		payload = body
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request body: %w", err)
+		}
+		payload = encoded
+		contentType = "application/json"
+	}
+
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	attempts := 1
+	if req.idempotent || isIdempotent(req.method) {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		var bodyReader io.Reader
+		if req.body != nil {
+			bodyReader = bytes.NewReader(payload)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range req.header {
+			httpReq.Header[key] = values
+		}
+		if contentType != "" {
+			httpReq.Header.Set("Content-Type", contentType)
+		}
+		if httpReq.Header.Get("Accept") == "" {
+			httpReq.Header.Set("Accept", "application/json")
+		}
+		if c.apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode < 400 {
+			return resp, nil
+		}
+		lastErr = decodeError(resp)
+		resp.Body.Close()
+		if !isRetryableStatus(resp.StatusCode) {
+			return nil, lastErr
+		}
+	}
+
+	return nil, lastErr
+}
+
+// backoff returns the delay before a retry: exponential in the attempt, capped, with
+// full jitter so that clients restarted together do not retry in lockstep
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.minBackoff << (attempt - 1)
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// sleep waits for d or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isIdempotent reports whether every request with method can be repeated. PUT is not
+// included, since updating a configuration or its rules with PUT adds a version.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// decodeResponse decodes a successful JSON response into out, if given
+func decodeResponse(resp *http.Response, out interface{}) error {
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// escape escapes a configuration name or tag for use as one path segment
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a client for a test server that retries without waiting
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithAPIKey("test-key"), WithRetries(2, time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+
+	c, err := New("http://localhost:8080/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", c.baseURL)
+}
+
+func TestClient_GetConfiguration(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/configurations/payment%20settings", r.URL.EscapedPath())
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name": "payment settings", "version": 2, "data": map[string]interface{}{"max_limit": 1000},
+		})
+	})
+
+	config, err := c.GetConfiguration(context.Background(), "payment settings")
+	require.NoError(t, err)
+	assert.Equal(t, 2, config.Version)
+
+	var settings struct {
+		MaxLimit int `json:"max_limit"`
+	}
+	require.NoError(t, c.GetConfigurationInto(context.Background(), "payment settings", &settings))
+	assert.Equal(t, 1000, settings.MaxLimit)
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("ValidationFailed", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusBadRequest, errors.NewErrorResponse("Validation failed", errors.ErrorCodeValidationFailed,
+				[]errors.ValidationError{{Field: "max_limit", Reason: "max_limit is required"}}))
+		})
+
+		_, err := c.UpdateConfiguration(context.Background(), "payments", map[string]interface{}{})
+		require.Error(t, err)
+		assert.True(t, IsValidationFailed(err))
+		assert.False(t, IsNotFound(err))
+
+		apiErr := err.(*Error)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		require.Len(t, apiErr.ValidationErrors(), 1)
+		assert.Equal(t, "max_limit", apiErr.ValidationErrors()[0].Field)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusNotFound, errors.NewErrorResponse("Configuration not found", errors.ErrorCodeNotFound, nil))
+		})
+
+		_, err := c.GetConfiguration(context.Background(), "missing")
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("NonAPIErrorBody", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "gone fishing", http.StatusForbidden)
+		})
+
+		_, err := c.GetConfiguration(context.Background(), "payments")
+		assert.True(t, IsForbidden(err))
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Run("IdempotentRequestIsRetried", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"name": "payments", "version": 1})
+		})
+
+		config, err := c.GetConfiguration(context.Background(), "payments")
+		require.NoError(t, err)
+		assert.Equal(t, 1, config.Version)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("GivesUpAfterMaxRetries", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		})
+
+		_, err := c.GetConfiguration(context.Background(), "payments")
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadGateway, err.(*Error).StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CreateIsNotRetried", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := c.CreateConfiguration(context.Background(), "payments", map[string]interface{}{"max_limit": 1})
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("UpdateIsNotRetried", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		})
+
+		_, err := c.UpdateConfiguration(context.Background(), "payments", map[string]interface{}{"max_limit": 1})
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "a timed out update may have added a version")
+	})
+
+	t.Run("TagIsRetried", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"name": "payments", "tag": "stable", "version": 2})
+		})
+
+		tag, err := c.TagVersion(context.Background(), "payments", "stable", 2, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, tag.Version)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("ClientErrorIsNotRetried", func(t *testing.T) {
+		var calls int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			writeJSON(w, http.StatusNotFound, errors.NewErrorResponse("Configuration not found", errors.ErrorCodeNotFound, nil))
+		})
+
+		_, err := c.GetConfiguration(context.Background(), "payments")
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestClient_GetConfigurations(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "payments,routing@3", r.URL.Query().Get("names"))
+		w.Header().Set("ETag", `"abc"`)
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"configurations": []map[string]interface{}{
+			{"name": "payments", "configuration": map[string]interface{}{"name": "payments", "version": 1}},
+			{"name": "routing", "version": 3, "error": errors.NewErrorResponse("Configuration version not found", errors.ErrorCodeNotFound, nil)},
+		}})
+	})
+
+	refs := []ConfigurationRef{{Name: "payments"}, {Name: "routing", Version: 3}}
+	items, etag, err := c.GetConfigurations(context.Background(), refs, "")
+	require.NoError(t, err)
+	assert.Equal(t, `"abc"`, etag)
+	require.Len(t, items, 2)
+	assert.Equal(t, 1, items[0].Configuration.Version)
+	assert.Equal(t, errors.ErrorCodeNotFound, items[1].Error.Code)
+
+	_, _, err = c.GetConfigurations(context.Background(), refs, etag)
+	assert.ErrorIs(t, err, ErrNotModified)
+}
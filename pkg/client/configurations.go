@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by GetConfigurations when the configurations still match the
+// ETag passed as ifNoneMatch
+var ErrNotModified = stdErrors.New("configurations not modified")
+
+// CreateConfiguration creates a configuration; data is encoded as JSON
+func (c *Client) CreateConfiguration(ctx context.Context, name string, data interface{}) (*Configuration, error) {
+	body := map[string]interface{}{"name": name, "data": data}
+	var config Configuration
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations", nil, body, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// UpdateConfiguration stores data as the next version of a configuration
+func (c *Client) UpdateConfiguration(ctx context.Context, name string, data interface{}) (*Configuration, error) {
+	body := map[string]interface{}{"data": data}
+	var config Configuration
+	if err := c.do(ctx, http.MethodPut, "/api/v1/configurations/"+escape(name), nil, body, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetConfiguration returns the current version of a configuration
+func (c *Client) GetConfiguration(ctx context.Context, name string) (*Configuration, error) {
+	var config Configuration
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name), nil, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetConfigurationAsOf returns the version of a configuration that was effective at asOf
+func (c *Client) GetConfigurationAsOf(ctx context.Context, name string, asOf time.Time) (*Configuration, error) {
+	query := url.Values{"as_of": {asOf.UTC().Format(time.RFC3339)}}
+	var config Configuration
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name), query, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetConfigurationInto decodes the data of the current version of a configuration into v
+func (c *Client) GetConfigurationInto(ctx context.Context, name string, v interface{}) error {
+	config, err := c.GetConfiguration(ctx, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(config.Data, v)
+}
+
+// ListConfigurations returns the version of every configuration effective at asOf; a
+// zero asOf means now
+func (c *Client) ListConfigurations(ctx context.Context, asOf time.Time) ([]*Configuration, error) {
+	var query url.Values
+	if !asOf.IsZero() {
+		query = url.Values{"as_of": {asOf.UTC().Format(time.RFC3339)}}
+	}
+	var response struct {
+		Configurations []*Configuration `json:"configurations"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Configurations, nil
+}
+
+// GetConfigurations reads many configurations in one request, returning one item per ref
+// and the ETag of the response. When ifNoneMatch is the ETag of an earlier response and
+// nothing changed since, it returns ErrNotModified.
+func (c *Client) GetConfigurations(ctx context.Context, refs []ConfigurationRef, ifNoneMatch string) ([]BulkReadItem, string, error) {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.Name
+		if ref.Version > 0 {
+			names[i] += "@" + strconv.Itoa(ref.Version)
+		}
+	}
+
+	req := &request{
+		method: http.MethodGet,
+		path:   "/api/v1/configurations",
+		query:  url.Values{"names": {strings.Join(names, ",")}},
+	}
+	if ifNoneMatch != "" {
+		req.header = http.Header{"If-None-Match": {ifNoneMatch}}
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrNotModified
+	}
+	var response struct {
+		Configurations []BulkReadItem `json:"configurations"`
+	}
+	if err := decodeResponse(resp, &response); err != nil {
+		return nil, "", err
+	}
+	return response.Configurations, etag, nil
+}
+
+// ListConfigurationVersions lists the versions of a configuration
+func (c *Client) ListConfigurationVersions(ctx context.Context, name string) (*VersionList, error) {
+	var versions VersionList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/versions", nil, nil, &versions); err != nil {
+		return nil, err
+	}
+	return &versions, nil
+}
+
+// GetConfigurationVersion returns a specific version of a configuration
+func (c *Client) GetConfigurationVersion(ctx context.Context, name string, version int) (*Configuration, error) {
+	var config Configuration
+	path := "/api/v1/configurations/" + escape(name) + "/versions/" + strconv.Itoa(version)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// RollbackConfiguration restores the data of a previous version as the next version
+func (c *Client) RollbackConfiguration(ctx context.Context, name string, targetVersion int) (*Configuration, error) {
+	return c.rollback(ctx, name, map[string]interface{}{"target_version": targetVersion})
+}
+
+// RollbackConfigurationToTag restores the data of the version a tag points at as the next version
+func (c *Client) RollbackConfigurationToTag(ctx context.Context, name, tag string) (*Configuration, error) {
+	return c.rollback(ctx, name, map[string]interface{}{"target_tag": tag})
+}
+
+func (c *Client) rollback(ctx context.Context, name string, body map[string]interface{}) (*Configuration, error) {
+	var config Configuration
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations/"+escape(name)+"/rollback", nil, body, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// TagVersion attaches a tag to a version of a configuration, or moves a movable tag
+func (c *Client) TagVersion(ctx context.Context, name, tag string, version int, immutable bool) (*VersionTag, error) {
+	body := map[string]interface{}{"version": version, "immutable": immutable}
+	var versionTag VersionTag
+	path := "/api/v1/configurations/" + escape(name) + "/tags/" + escape(tag)
+	if err := c.replace(ctx, path, nil, body, &versionTag); err != nil {
+		return nil, err
+	}
+	return &versionTag, nil
+}
+
+// ListVersionTags lists the tags of a configuration
+func (c *Client) ListVersionTags(ctx context.Context, name string) ([]*VersionTag, error) {
+	var response struct {
+		Tags []*VersionTag `json:"tags"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/tags", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Tags, nil
+}
+
+// GetConfigurationByTag returns the version of a configuration a tag points at
+func (c *Client) GetConfigurationByTag(ctx context.Context, name, tag string) (*Configuration, error) {
+	var config Configuration
+	path := "/api/v1/configurations/" + escape(name) + "/tags/" + escape(tag)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// DeleteVersionTag removes a movable tag
+func (c *Client) DeleteVersionTag(ctx context.Context, name, tag string) error {
+	path := "/api/v1/configurations/" + escape(name) + "/tags/" + escape(tag)
+	return c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// ScheduleConfigurationChange schedules data to become the next version at effectiveAt
+func (c *Client) ScheduleConfigurationChange(ctx context.Context, name string, data interface{}, effectiveAt time.Time) (*ScheduledChange, error) {
+	body := map[string]interface{}{"data": data, "effective_at": effectiveAt.UTC()}
+	var change ScheduledChange
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations/"+escape(name)+"/schedules", nil, body, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListScheduledChanges lists the scheduled changes of a configuration
+func (c *Client) ListScheduledChanges(ctx context.Context, name string) ([]*ScheduledChange, error) {
+	var response struct {
+		Schedules []*ScheduledChange `json:"schedules"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/schedules", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Schedules, nil
+}
+
+// CancelScheduledChange cancels a pending scheduled change
+func (c *Client) CancelScheduledChange(ctx context.Context, name string, id int64) (*ScheduledChange, error) {
+	var change ScheduledChange
+	path := "/api/v1/configurations/" + escape(name) + "/schedules/" + strconv.FormatInt(id, 10)
+	if err := c.do(ctx, http.MethodDelete, path, nil, nil, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetRetentionPolicy returns the retention policy of a configuration
+func (c *Client) GetRetentionPolicy(ctx context.Context, name string) (*RetentionPolicy, error) {
+	return c.getRetentionPolicy(ctx, "/api/v1/configurations/"+escape(name)+"/retention")
+}
+
+// SetRetentionPolicy creates or replaces the retention policy of a configuration
+func (c *Client) SetRetentionPolicy(ctx context.Context, name string, keepLast, keepDays int) (*RetentionPolicy, error) {
+	return c.setRetentionPolicy(ctx, "/api/v1/configurations/"+escape(name)+"/retention", keepLast, keepDays)
+}
+
+// DeleteRetentionPolicy removes the retention policy of a configuration, so that the
+// global policy applies to it
+func (c *Client) DeleteRetentionPolicy(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/configurations/"+escape(name)+"/retention", nil, nil, nil)
+}
+
+// GetGlobalRetentionPolicy returns the retention policy of configurations without their own
+func (c *Client) GetGlobalRetentionPolicy(ctx context.Context) (*RetentionPolicy, error) {
+	return c.getRetentionPolicy(ctx, "/api/v1/retention")
+}
+
+// SetGlobalRetentionPolicy creates or replaces the global retention policy
+func (c *Client) SetGlobalRetentionPolicy(ctx context.Context, keepLast, keepDays int) (*RetentionPolicy, error) {
+	return c.setRetentionPolicy(ctx, "/api/v1/retention", keepLast, keepDays)
+}
+
+func (c *Client) getRetentionPolicy(ctx context.Context, path string) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (c *Client) setRetentionPolicy(ctx context.Context, path string, keepLast, keepDays int) (*RetentionPolicy, error) {
+	body := map[string]interface{}{"keep_last": keepLast, "keep_days": keepDays}
+	var policy RetentionPolicy
+	if err := c.replace(ctx, path, nil, body, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// CompactConfiguration removes the data of the versions of a configuration that its
+// retention policy no longer keeps
+func (c *Client) CompactConfiguration(ctx context.Context, name string) (*CompactionResult, error) {
+	var result CompactionResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations/"+escape(name)+"/compact", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApplyBatch applies create, update and rollback operations across configurations
+// atomically. When an operation fails nothing is applied, and the *Error details hold the
+// BatchResult with the error of each operation.
+func (c *Client) ApplyBatch(ctx context.Context, changesetID string, operations []BatchOperation) (*BatchResult, error) {
+	body := map[string]interface{}{"changeset_id": changesetID, "operations": operations}
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/batch", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// Error is an error response of the API
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the API error code, such as NOT_FOUND
+	Code errors.ErrorCode
+	// Message describes the error
+	Message string
+	// Details holds the error details as sent, if any
+	Details json.RawMessage
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// DecodeDetails decodes the error details into v
+func (e *Error) DecodeDetails(v interface{}) error {
+	if len(e.Details) == 0 {
+		return stdErrors.New("error has no details")
+	}
+	return json.Unmarshal(e.Details, v)
+}
+
+// ValidationErrors returns the field errors of a VALIDATION_FAILED error, or nil when
+// its details do not hold a list of them
+func (e *Error) ValidationErrors() []errors.ValidationError {
+	var validationErrors []errors.ValidationError
+	if e.Code != errors.ErrorCodeValidationFailed || e.DecodeDetails(&validationErrors) != nil {
+		return nil
+	}
+	return validationErrors
+}
+
+// decodeError turns an error response into an *Error. Responses without an API error
+// body, such as those of a proxy, get a code derived from their status.
+func decodeError(resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var response struct {
+		Error   string           `json:"error"`
+		Details json.RawMessage  `json:"details"`
+		Code    errors.ErrorCode `json:"code"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil && response.Code != "" {
+		return &Error{StatusCode: resp.StatusCode, Code: response.Code, Message: response.Error, Details: response.Details}
+	}
+
+	return &Error{StatusCode: resp.StatusCode, Code: codeForStatus(resp.StatusCode), Message: http.StatusText(resp.StatusCode)}
+}
+
+// codeForStatus maps an HTTP status to the API error code the service uses for it
+func codeForStatus(status int) errors.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return errors.ErrorCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return errors.ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return errors.ErrorCodeForbidden
+	case http.StatusNotFound:
+		return errors.ErrorCodeNotFound
+	case http.StatusConflict:
+		return errors.ErrorCodeConflict
+	case http.StatusGone:
+		return errors.ErrorCodeVersionCompacted
+	case http.StatusNotAcceptable:
+		return errors.ErrorCodeNotAcceptable
+	}
+	return errors.ErrorCodeInternalError
+}
+
+// hasCode reports whether err is an API error with the given code
+func hasCode(err error, code errors.ErrorCode) bool {
+	var apiErr *Error
+	return stdErrors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsNotFound reports whether err says a configuration, version or other resource does not exist
+func IsNotFound(err error) bool {
+	return hasCode(err, errors.ErrorCodeNotFound)
+}
+
+// IsValidationFailed reports whether err says data does not conform to its schema or rules
+func IsValidationFailed(err error) bool {
+	return hasCode(err, errors.ErrorCodeValidationFailed)
+}
+
+// IsAlreadyExists reports whether err says a configuration already exists
+func IsAlreadyExists(err error) bool {
+	return hasCode(err, errors.ErrorCodeAlreadyExists)
+}
+
+// IsInvalidRequest reports whether err says the request was malformed
+func IsInvalidRequest(err error) bool {
+	return hasCode(err, errors.ErrorCodeInvalidRequest)
+}
+
+// IsConflict reports whether err says the request conflicts with the stored state
+func IsConflict(err error) bool {
+	return hasCode(err, errors.ErrorCodeConflict)
+}
+
+// IsForbidden reports whether err says the client may not make the request
+func IsForbidden(err error) bool {
+	return hasCode(err, errors.ErrorCodeForbidden)
+}
+
+// IsUnauthorized reports whether err says the API key is missing or invalid
+func IsUnauthorized(err error) bool {
+	return hasCode(err, errors.ErrorCodeUnauthorized)
+}
+
+// IsVersionCompacted reports whether err says a version's data was removed by retention
+func IsVersionCompacted(err error) bool {
+	return hasCode(err, errors.ErrorCodeVersionCompacted)
+}
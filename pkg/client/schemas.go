@@ -0,0 +1,262 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/rules"
+)
+
+// SchemaOptions controls how a schema is registered
+type SchemaOptions struct {
+	// Force skips the compatibility and existing data checks
+	Force bool
+	// CheckVersions is how many of the most recent configuration versions must conform
+	// to the new schema; zero checks the current version only
+	CheckVersions int
+}
+
+func (o SchemaOptions) query() url.Values {
+	query := url.Values{}
+	if o.Force {
+		query.Set("force", "true")
+	}
+	if o.CheckVersions > 0 {
+		query.Set("check_versions", strconv.Itoa(o.CheckVersions))
+	}
+	return query
+}
+
+// RegisterSchema stores a JSON schema as the next schema version of a configuration and
+// returns that version
+func (c *Client) RegisterSchema(ctx context.Context, name string, schema json.RawMessage, opts SchemaOptions) (int, error) {
+	var response struct {
+		Version int `json:"version"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/schemas/"+escape(name), opts.query(), schema, &response); err != nil {
+		return 0, err
+	}
+	return response.Version, nil
+}
+
+// AnalyzeSchemaImpact reports which configuration versions a schema would reject,
+// without registering it
+func (c *Client) AnalyzeSchemaImpact(ctx context.Context, name string, schema json.RawMessage, checkVersions int) (*SchemaImpact, error) {
+	query := SchemaOptions{CheckVersions: checkVersions}.query()
+	query.Set("dry_run", "true")
+	var impact SchemaImpact
+	if err := c.do(ctx, http.MethodPost, "/api/v1/schemas/"+escape(name), query, schema, &impact); err != nil {
+		return nil, err
+	}
+	return &impact, nil
+}
+
+// GetSchema returns the current schema of a configuration
+func (c *Client) GetSchema(ctx context.Context, name string) (json.RawMessage, error) {
+	var schema json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/api/v1/schemas/"+escape(name), nil, nil, &schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// ListSchemas returns one page of the current schemas of all configurations; a zero
+// limit uses the server's default page size
+func (c *Client) ListSchemas(ctx context.Context, limit, offset int) (*SchemaList, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	var schemas SchemaList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/schemas", query, nil, &schemas); err != nil {
+		return nil, err
+	}
+	return &schemas, nil
+}
+
+// ListSchemaVersions lists the schema versions of a configuration
+func (c *Client) ListSchemaVersions(ctx context.Context, name string) ([]*SchemaVersion, error) {
+	var response struct {
+		Versions []*SchemaVersion `json:"versions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/schemas/"+escape(name)+"/versions", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Versions, nil
+}
+
+// GetSchemaVersion returns a specific schema version of a configuration
+func (c *Client) GetSchemaVersion(ctx context.Context, name string, version int) (*SchemaVersion, error) {
+	var schemaVersion SchemaVersion
+	path := "/api/v1/schemas/" + escape(name) + "/versions/" + strconv.Itoa(version)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &schemaVersion); err != nil {
+		return nil, err
+	}
+	return &schemaVersion, nil
+}
+
+// DeleteSchema detaches the schema from a configuration (requires the admin role)
+func (c *Client) DeleteSchema(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/schemas/"+escape(name), nil, nil, nil)
+}
+
+// ValidateAgainstSchema validates data against an ad-hoc schema without storing either
+func (c *Client) ValidateAgainstSchema(ctx context.Context, schema json.RawMessage, data interface{}) (*ValidationReport, error) {
+	body := map[string]interface{}{"schema": schema, "data": data}
+	var report ValidationReport
+	if err := c.do(ctx, http.MethodPost, "/api/v1/schemas/validate", nil, body, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListUnvalidatedConfigurations lists configurations whose current data is not
+// validated by a schema
+func (c *Client) ListUnvalidatedConfigurations(ctx context.Context) ([]*UnvalidatedConfiguration, error) {
+	var response struct {
+		Configurations []*UnvalidatedConfiguration `json:"configurations"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/reports/unvalidated", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Configurations, nil
+}
+
+// RegisterSharedSchema creates or replaces a shared schema fragment, such as common/money
+func (c *Client) RegisterSharedSchema(ctx context.Context, name string, schema json.RawMessage, force bool) (*SharedSchema, error) {
+	var query url.Values
+	if force {
+		query = url.Values{"force": {"true"}}
+	}
+	var shared SharedSchema
+	if err := c.replace(ctx, sharedSchemaPath(name), query, schema, &shared); err != nil {
+		return nil, err
+	}
+	return &shared, nil
+}
+
+// GetSharedSchema returns a shared schema fragment
+func (c *Client) GetSharedSchema(ctx context.Context, name string) (*SharedSchema, error) {
+	var shared SharedSchema
+	if err := c.do(ctx, http.MethodGet, sharedSchemaPath(name), nil, nil, &shared); err != nil {
+		return nil, err
+	}
+	return &shared, nil
+}
+
+// ListSharedSchemas lists the shared schema fragments
+func (c *Client) ListSharedSchemas(ctx context.Context) ([]*SharedSchema, error) {
+	var response struct {
+		SharedSchemas []*SharedSchema `json:"shared_schemas"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/shared-schemas", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.SharedSchemas, nil
+}
+
+// DeleteSharedSchema removes a shared schema fragment that no schema references
+// (requires the admin role)
+func (c *Client) DeleteSharedSchema(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, sharedSchemaPath(name), nil, nil, nil)
+}
+
+// sharedSchemaPath escapes each segment of a shared schema name, keeping its slashes
+func sharedSchemaPath(name string) string {
+	segments := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return "/api/v1/shared-schemas/" + strings.Join(segments, "/")
+}
+
+// SetRuleSet stores rules as the next rule set version of a configuration; force skips
+// checking them against the current data
+func (c *Client) SetRuleSet(ctx context.Context, name string, ruleList []rules.Rule, force bool) (*RuleSet, error) {
+	var query url.Values
+	if force {
+		query = url.Values{"force": {"true"}}
+	}
+	body := map[string]interface{}{"rules": ruleList}
+	var ruleSet RuleSet
+	if err := c.do(ctx, http.MethodPut, "/api/v1/configurations/"+escape(name)+"/rules", query, body, &ruleSet); err != nil {
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// GetRuleSet returns the current rule set of a configuration
+func (c *Client) GetRuleSet(ctx context.Context, name string) (*RuleSet, error) {
+	var ruleSet RuleSet
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/rules", nil, nil, &ruleSet); err != nil {
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// ListRuleSetVersions lists the rule set versions of a configuration
+func (c *Client) ListRuleSetVersions(ctx context.Context, name string) ([]*RuleSet, error) {
+	var response struct {
+		Versions []*RuleSet `json:"versions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/configurations/"+escape(name)+"/rules/versions", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Versions, nil
+}
+
+// GetRuleSetVersion returns a specific rule set version of a configuration
+func (c *Client) GetRuleSetVersion(ctx context.Context, name string, version int) (*RuleSet, error) {
+	var ruleSet RuleSet
+	path := "/api/v1/configurations/" + escape(name) + "/rules/versions/" + strconv.Itoa(version)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &ruleSet); err != nil {
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// TestRules evaluates rules against data without storing anything; nil rules use the
+// stored rule set and nil data the current configuration data
+func (c *Client) TestRules(ctx context.Context, name string, ruleList []rules.Rule, data interface{}) (*RuleTestResult, error) {
+	body := map[string]interface{}{}
+	if ruleList != nil {
+		body["rules"] = ruleList
+	}
+	if data != nil {
+		body["data"] = data
+	}
+	var result RuleTestResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/configurations/"+escape(name)+"/rules/test", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ValidateDocument validates a configuration document against the schema and rules of a
+// configuration without storing it
+func (c *Client) ValidateDocument(ctx context.Context, name string, document []byte) (*ValidationReport, error) {
+	req := &request{
+		method:      http.MethodPost,
+		path:        "/api/v1/configurations/" + escape(name) + "/validate",
+		body:        document,
+		contentType: "application/json",
+	}
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var report ValidationReport
+	if err := decodeResponse(resp, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import "github.com/Titonu/configuration-management-service/internal/domain/entity"
+
+// The API resources are the service's own entity types, aliased so that programs outside
+// this module can name them.
+type (
+	Configuration            = entity.Configuration
+	ConfigurationRef         = entity.ConfigurationRef
+	BulkReadItem             = entity.BulkReadItem
+	VersionInfo              = entity.VersionInfo
+	VersionList              = entity.VersionList
+	VersionTag               = entity.VersionTag
+	ScheduledChange          = entity.ScheduledChange
+	RetentionPolicy          = entity.RetentionPolicy
+	CompactionResult         = entity.CompactionResult
+	SchemaVersion            = entity.SchemaVersion
+	SchemaList               = entity.SchemaList
+	SchemaImpact             = entity.SchemaImpact
+	SharedSchema             = entity.SharedSchema
+	UnvalidatedConfiguration = entity.UnvalidatedConfiguration
+	RuleSet                  = entity.RuleSet
+	RuleTestResult           = entity.RuleTestResult
+	ValidationReport         = entity.ValidationReport
+	ChangeRequest            = entity.ChangeRequest
+	ChangeRequestStatus      = entity.ChangeRequestStatus
+	ChangeRequestComment     = entity.ChangeRequestComment
+	ImportMode               = entity.ImportMode
+	ImportOptions            = entity.ImportOptions
+	ImportReport             = entity.ImportReport
+	BatchOperation           = entity.BatchOperation
+	BatchOperationType       = entity.BatchOperationType
+	BatchResult              = entity.BatchResult
+)
+
+// Change request statuses
+const (
+	ChangeRequestStatusPending   = entity.ChangeRequestStatusPending
+	ChangeRequestStatusApplied   = entity.ChangeRequestStatusApplied
+	ChangeRequestStatusRejected  = entity.ChangeRequestStatusRejected
+	ChangeRequestStatusWithdrawn = entity.ChangeRequestStatusWithdrawn
+)
+
+// Import modes
+const (
+	ImportModeSkipExisting = entity.ImportModeSkipExisting
+	ImportModeOverwrite    = entity.ImportModeOverwrite
+	ImportModeMerge        = entity.ImportModeMerge
+)
+
+// Batch operation types
+const (
+	BatchOperationCreate   = entity.BatchOperationCreate
+	BatchOperationUpdate   = entity.BatchOperationUpdate
+	BatchOperationRollback = entity.BatchOperationRollback
+)
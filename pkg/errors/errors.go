@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
+	stdErrors "errors"
 	"fmt"
 )
 
@@ -16,6 +18,12 @@ const (
 	ErrorCodeInvalidRequest   ErrorCode = "INVALID_REQUEST"
 	ErrorCodeInternalError    ErrorCode = "INTERNAL_ERROR"
 	ErrorCodeUnauthorized     ErrorCode = "UNAUTHORIZED"
+	ErrorCodeVersionCompacted ErrorCode = "VERSION_COMPACTED"
+	ErrorCodeConflict         ErrorCode = "CONFLICT"
+	ErrorCodeForbidden        ErrorCode = "FORBIDDEN"
+	ErrorCodeNotAcceptable    ErrorCode = "NOT_ACCEPTABLE"
+	ErrorCodeRequestCancelled ErrorCode = "REQUEST_CANCELLED"
+	ErrorCodeRequestTimeout   ErrorCode = "REQUEST_TIMEOUT"
 )
 
 // ErrorResponse represents a standardized API error response
@@ -25,10 +33,48 @@ type ErrorResponse struct {
 	Code    ErrorCode   `json:"code"`
 }
 
-// ValidationError represents a validation error detail
+// ValidationCode is a stable, machine-readable code for why a value failed validation
+type ValidationCode string
+
+// Validation codes
+const (
+	ValidationCodeRequired             ValidationCode = "REQUIRED"
+	ValidationCodeInvalidType          ValidationCode = "INVALID_TYPE"
+	ValidationCodeTooSmall             ValidationCode = "TOO_SMALL"
+	ValidationCodeTooLarge             ValidationCode = "TOO_LARGE"
+	ValidationCodeNotMultipleOf        ValidationCode = "NOT_MULTIPLE_OF"
+	ValidationCodeTooShort             ValidationCode = "TOO_SHORT"
+	ValidationCodeTooLong              ValidationCode = "TOO_LONG"
+	ValidationCodePatternMismatch      ValidationCode = "PATTERN_MISMATCH"
+	ValidationCodeInvalidFormat        ValidationCode = "INVALID_FORMAT"
+	ValidationCodeNotAllowed           ValidationCode = "NOT_ALLOWED"
+	ValidationCodeTooFewItems          ValidationCode = "TOO_FEW_ITEMS"
+	ValidationCodeTooManyItems         ValidationCode = "TOO_MANY_ITEMS"
+	ValidationCodeDuplicateItems       ValidationCode = "DUPLICATE_ITEMS"
+	ValidationCodeAdditionalItems      ValidationCode = "ADDITIONAL_ITEMS"
+	ValidationCodeMissingContainedItem ValidationCode = "MISSING_CONTAINED_ITEM"
+	ValidationCodeTooFewProperties     ValidationCode = "TOO_FEW_PROPERTIES"
+	ValidationCodeTooManyProperties    ValidationCode = "TOO_MANY_PROPERTIES"
+	ValidationCodeAdditionalProperty   ValidationCode = "ADDITIONAL_PROPERTY"
+	ValidationCodeInvalidPropertyName  ValidationCode = "INVALID_PROPERTY_NAME"
+	ValidationCodeMissingDependency    ValidationCode = "MISSING_DEPENDENCY"
+	ValidationCodeSchemaMismatch       ValidationCode = "SCHEMA_MISMATCH"
+	ValidationCodeRuleFailed           ValidationCode = "RULE_FAILED"
+	ValidationCodeRuleError            ValidationCode = "RULE_ERROR"
+	ValidationCodeInvalid              ValidationCode = "INVALID"
+)
+
+// ValidationError represents a validation error detail. Pointer is the RFC 6901 JSON
+// Pointer of the failing value, Keyword the schema keyword that failed, Expected its
+// constraint and Actual the offending value; they are set when known.
 type ValidationError struct {
-	Field  string `json:"field"`
-	Reason string `json:"reason"`
+	Field    string         `json:"field"`
+	Reason   string         `json:"reason"`
+	Pointer  string         `json:"pointer,omitempty"`
+	Keyword  string         `json:"keyword,omitempty"`
+	Code     ValidationCode `json:"code,omitempty"`
+	Expected interface{}    `json:"expected,omitempty"`
+	Actual   interface{}    `json:"actual,omitempty"`
 }
 
 // AppError is a custom error type that includes error code and details
@@ -123,6 +169,50 @@ func NewInternalError(message string, details interface{}) *AppError {
 	)
 }
 
+// NewVersionCompactedError creates an error for a version whose data was removed by retention compaction
+func NewVersionCompactedError(configName string, version int) *AppError {
+	return NewAppError(
+		"Configuration version has been compacted by the retention policy",
+		ErrorCodeVersionCompacted,
+		map[string]interface{}{"id": configName, "version": version},
+	)
+}
+
+// NewConflictError creates an error for a request that conflicts with the current state of a resource
+func NewConflictError(message string, details interface{}) *AppError {
+	return NewAppError(
+		message,
+		ErrorCodeConflict,
+		details,
+	)
+}
+
+// NewForbiddenError creates an error for an operation the caller is not allowed to perform
+func NewForbiddenError(message string, details interface{}) *AppError {
+	return NewAppError(
+		message,
+		ErrorCodeForbidden,
+		details,
+	)
+}
+
+// NewContextError creates an error for a request whose context ended before it
+// completed: a timeout when the deadline passed, a cancellation otherwise
+func NewContextError(err error) *AppError {
+	if stdErrors.Is(err, context.DeadlineExceeded) {
+		return NewAppError(
+			"Request timed out",
+			ErrorCodeRequestTimeout,
+			err.Error(),
+		)
+	}
+	return NewAppError(
+		"Request cancelled",
+		ErrorCodeRequestCancelled,
+		err.Error(),
+	)
+}
+
 // ToJSON converts the error response to JSON
 func (e *ErrorResponse) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -50,6 +52,17 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, details, err.Details)
 	})
 
+	t.Run("NewVersionCompactedError", func(t *testing.T) {
+		err := NewVersionCompactedError("payments", 3)
+
+		assert.Equal(t, ErrorCodeVersionCompacted, err.Code)
+
+		details, ok := err.Details.(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "payments", details["id"])
+		assert.Equal(t, 3, details["version"])
+	})
+
 	t.Run("NewInternalError", func(t *testing.T) {
 		err := NewInternalError("internal error", nil)
 
@@ -57,6 +70,32 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, ErrorCodeInternalError, err.Code)
 		assert.Nil(t, err.Details)
 	})
+
+	t.Run("NewConflictError", func(t *testing.T) {
+		err := NewConflictError("conflict", map[string]string{"tag": "release"})
+
+		assert.Equal(t, "conflict", err.Error())
+		assert.Equal(t, ErrorCodeConflict, err.Code)
+		assert.Equal(t, map[string]string{"tag": "release"}, err.Details)
+	})
+
+	t.Run("NewForbiddenError", func(t *testing.T) {
+		err := NewForbiddenError("forbidden", nil)
+
+		assert.Equal(t, "forbidden", err.Error())
+		assert.Equal(t, ErrorCodeForbidden, err.Code)
+		assert.Nil(t, err.Details)
+	})
+
+	t.Run("NewContextError", func(t *testing.T) {
+		err := NewContextError(fmt.Errorf("query failed: %w", context.DeadlineExceeded))
+		assert.Equal(t, "Request timed out", err.Error())
+		assert.Equal(t, ErrorCodeRequestTimeout, err.Code)
+
+		err = NewContextError(context.Canceled)
+		assert.Equal(t, "Request cancelled", err.Error())
+		assert.Equal(t, ErrorCodeRequestCancelled, err.Code)
+	})
 }
 
 func TestAppError_Error(t *testing.T) {
@@ -0,0 +1,181 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// flatEntry is a scalar of a flattened document with the path that leads to it
+type flatEntry struct {
+	path  []string
+	value string
+}
+
+// flatten lists the scalars of a JSON object with their paths, in key order. Nulls
+// render as empty values; empty objects and arrays have no scalars and are omitted.
+func flatten(data json.RawMessage, f Format) ([]flatEntry, error) {
+	document, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := document.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%s: only objects can be flattened, got %s", f, jsonTypeOf(document))
+	}
+
+	entries := []flatEntry{}
+	var walk func(value interface{}, path []string)
+	walk = func(value interface{}, path []string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key], append(append([]string{}, path...), key))
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(item, append(append([]string{}, path...), strconv.Itoa(i)))
+			}
+		case nil:
+			entries = append(entries, flatEntry{path: path})
+		case string:
+			entries = append(entries, flatEntry{path: path, value: v})
+		case json.Number:
+			entries = append(entries, flatEntry{path: path, value: v.String()})
+		case bool:
+			entries = append(entries, flatEntry{path: path, value: strconv.FormatBool(v)})
+		}
+	}
+	walk(document, []string{})
+
+	return entries, nil
+}
+
+// jsonToEnv renders a JSON object as a .env file: one KEY=value line per scalar,
+// named by joining the upper-cased path with underscores. Characters other than
+// letters, digits and underscores become underscores, and two paths that end up
+// with the same name are rejected.
+func jsonToEnv(data json.RawMessage) ([]byte, error) {
+	entries, err := flatten(data, Env)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	names := map[string][]string{}
+	for _, entry := range entries {
+		name := envName(entry.path)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("env: %s and %s both render as %s", pointer(other), pointer(entry.path), name)
+		}
+		names[name] = entry.path
+
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(envValue(entry.value))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// envName returns the variable name of a path
+func envName(path []string) string {
+	var b strings.Builder
+	for i, segment := range path {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+		for _, r := range strings.ToUpper(segment) {
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// envValue quotes a value unless it only contains characters that need no quoting
+func envValue(value string) string {
+	plain := true
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+%", r)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// jsonToProperties renders a JSON object as a Java properties file: one key=value
+// line per scalar, keyed by the path joined with dots
+func jsonToProperties(data json.RawMessage) ([]byte, error) {
+	entries, err := flatten(data, Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(propertiesEscape(strings.Join(entry.path, "."), true))
+		buf.WriteByte('=')
+		buf.WriteString(propertiesEscape(entry.value, false))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// propertiesEscape escapes a key or value of a properties file. Characters outside
+// printable ASCII are written as \u escapes, which every properties reader accepts.
+func propertiesEscape(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			b.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+				// Characters outside the basic multilingual plane are written as surrogate pairs
+				fmt.Fprintf(&b, `\u%04x\u%04x`, r1, r2)
+			} else {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,188 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a serialization format configuration data can be exchanged in
+type Format string
+
+// Supported formats
+const (
+	JSON       Format = "json"
+	YAML       Format = "yaml"
+	TOML       Format = "toml"
+	Env        Format = "env"
+	Properties Format = "properties"
+)
+
+// mediaTypes maps every recognised media type to the format it denotes
+var mediaTypes = map[string]Format{
+	"application/json":         JSON,
+	"application/yaml":         YAML,
+	"application/x-yaml":       YAML,
+	"text/yaml":                YAML,
+	"text/x-yaml":              YAML,
+	"application/toml":         TOML,
+	"text/x-toml":              TOML,
+	"text/x-env":               Env,
+	"text/x-java-properties":   Properties,
+	"application/x-env":        Env,
+	"text/x-properties":        Properties,
+	"application/x-properties": Properties,
+}
+
+// formatMediaTypes holds the media type documents in each format are labelled with
+var formatMediaTypes = map[Format]string{
+	JSON:       "application/json",
+	YAML:       "application/yaml",
+	TOML:       "application/toml",
+	Env:        "text/x-env",
+	Properties: "text/x-java-properties",
+}
+
+// Parse returns the format with the given name
+func Parse(name string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := formatMediaTypes[f]; !ok {
+		return "", fmt.Errorf("unsupported format %q, expected one of json, yaml, toml, env or properties", name)
+	}
+	return f, nil
+}
+
+// FromMediaType returns the format of a media type, ignoring its parameters
+func FromMediaType(mediaType string) (Format, bool) {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", false
+	}
+	f, ok := mediaTypes[parsed]
+	return f, ok
+}
+
+// Negotiate picks the format to respond with from an Accept header: the supported
+// media type with the highest quality, in the order listed when qualities are equal.
+// JSON is chosen when the header is empty, accepts any type or names no supported type.
+func Negotiate(accept string) Format {
+	type candidate struct {
+		format  Format
+		quality float64
+	}
+
+	candidates := []candidate{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		f, ok := mediaTypes[mediaType]
+		if !ok && (mediaType == "*/*" || mediaType == "application/*") {
+			f, ok = JSON, true
+		}
+		if ok {
+			candidates = append(candidates, candidate{format: f, quality: quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) == 0 {
+		return JSON
+	}
+	return candidates[0].format
+}
+
+// MediaType returns the media type documents of the format are labelled with
+func (f Format) MediaType() string {
+	return formatMediaTypes[f]
+}
+
+// CanDecode reports whether documents of the format can be converted to JSON. The
+// env and properties formats flatten data and are only rendered.
+func (f Format) CanDecode() bool {
+	return f == JSON || f == YAML || f == TOML
+}
+
+// ToJSON converts a document in the given format to JSON
+func ToJSON(f Format, data []byte) (json.RawMessage, error) {
+	switch f {
+	case JSON:
+		return data, nil
+	case YAML:
+		return yamlToJSON(data)
+	case TOML:
+		return tomlToJSON(data)
+	}
+	return nil, fmt.Errorf("%s documents cannot be converted to JSON", f)
+}
+
+// FromJSON renders a JSON document in the given format
+func FromJSON(f Format, data json.RawMessage) ([]byte, error) {
+	switch f {
+	case JSON:
+		return data, nil
+	case YAML:
+		return jsonToYAML(data)
+	case TOML:
+		return jsonToTOML(data)
+	case Env:
+		return jsonToEnv(data)
+	case Properties:
+		return jsonToProperties(data)
+	}
+	return nil, fmt.Errorf("unsupported format %q", f)
+}
+
+// decodeJSON unmarshals a JSON document keeping numbers exact
+func decodeJSON(data json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encodeJSON marshals a value without escaping HTML characters
+func encodeJSON(value interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// pointer returns the JSON Pointer of a path of object keys and array indexes, or
+// "(root)" for the document itself
+func pointer(path []string) string {
+	if len(path) == 0 {
+		return "(root)"
+	}
+	var b strings.Builder
+	for _, segment := range path {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment))
+	}
+	return b.String()
+}
@@ -0,0 +1,321 @@
+package format
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	f, err := Parse("YAML")
+	require.NoError(t, err)
+	assert.Equal(t, YAML, f)
+
+	_, err = Parse("xml")
+	assert.Error(t, err)
+}
+
+func TestFromMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		expected  Format
+		ok        bool
+	}{
+		{"application/json; charset=utf-8", JSON, true},
+		{"application/x-yaml", YAML, true},
+		{"text/yaml", YAML, true},
+		{"application/toml", TOML, true},
+		{"text/x-env", Env, true},
+		{"text/plain", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			f, ok := FromMediaType(tt.mediaType)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected Format
+	}{
+		{"Empty", "", JSON},
+		{"Any", "*/*", JSON},
+		{"Single", "application/yaml", YAML},
+		{"FirstSupported", "text/html, application/toml, application/yaml", TOML},
+		{"HighestQuality", "application/yaml;q=0.5, text/x-env;q=0.9", Env},
+		{"ExcludedByZeroQuality", "application/yaml;q=0, */*;q=0.1", JSON},
+		{"Unsupported", "text/html", JSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Negotiate(tt.accept))
+		})
+	}
+}
+
+func TestToJSON_YAML(t *testing.T) {
+	t.Run("Conversion", func(t *testing.T) {
+		data, err := ToJSON(YAML, []byte(`
+name: payment
+limits:
+  max: 1000
+  ratio: 0.25
+  big: 9007199254740993
+enabled: yes-not-a-bool
+active: true
+missing: ~
+hosts:
+  - a.example.com
+  - "b.example.com"
+released: 2024-01-02
+quoted: "123"
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"payment","limits":{"max":1000,"ratio":0.25,"big":9007199254740993},"enabled":"yes-not-a-bool","active":true,"missing":null,"hosts":["a.example.com","b.example.com"],"released":"2024-01-02","quoted":"123"}`, string(data))
+	})
+
+	t.Run("AnchorsAndMergeKeys", func(t *testing.T) {
+		data, err := ToJSON(YAML, []byte(`
+base: &base
+  timeout: 30
+  retries: 3
+service:
+  <<: *base
+  retries: 5
+`))
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"base":{"timeout":30,"retries":3},"service":{"retries":5,"timeout":30}}`, string(data))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			document string
+			message  string
+		}{
+			{"Syntax", "a: [1, 2", "yaml:"},
+			{"Empty", "", "empty document"},
+			{"MultipleDocuments", "a: 1\n---\nb: 2\n", "single document"},
+			{"NonStringKey", "limits:\n  200: ok\n", `mapping key "200" at /limits must be a string`},
+			{"DuplicateKey", "a: 1\na: 2\n", `mapping key "a" is already defined`},
+			{"NotANumber", "ratio: .nan\n", "cannot be represented in JSON"},
+			{"CustomTag", "value: !secret abc\n", "unsupported tag !secret at /value"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ToJSON(YAML, []byte(tt.document))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.message)
+			})
+		}
+	})
+}
+
+func TestToJSON_TOML(t *testing.T) {
+	t.Run("Conversion", func(t *testing.T) {
+		data, err := ToJSON(TOML, []byte(`
+name = "payment"
+released = 2024-01-02
+updated_at = 2024-01-02T03:04:05Z
+
+[limits]
+max = 1000
+ratio = 0.25
+
+[[hosts]]
+name = "a"
+`))
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"name": "payment",
+			"released": "2024-01-02",
+			"updated_at": "2024-01-02T03:04:05Z",
+			"limits": {"max": 1000, "ratio": 0.25},
+			"hosts": [{"name": "a"}]
+		}`, string(data))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := ToJSON(TOML, []byte(`name = `))
+		assert.Error(t, err)
+
+		_, err = ToJSON(TOML, []byte("ratio = nan\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/ratio cannot be represented in JSON")
+	})
+
+	t.Run("RenderOnlyFormats", func(t *testing.T) {
+		_, err := ToJSON(Env, []byte("A=1\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	data := json.RawMessage(`{"name":"payment","limits":{"max":1000,"ratio":0.25},"flags":["a","b"],"enabled":true,"note":"true","empty":null}`)
+
+	t.Run("YAML", func(t *testing.T) {
+		rendered, err := FromJSON(YAML, data)
+
+		require.NoError(t, err)
+		assert.Equal(t, `name: payment
+limits:
+  max: 1000
+  ratio: 0.25
+flags:
+  - a
+  - b
+enabled: true
+note: "true"
+empty: null
+`, string(rendered))
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		rendered, err := FromJSON(TOML, json.RawMessage(`{"name":"payment","limits":{"max":1000,"ratio":0.25},"flags":["a","b"]}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, `flags = ['a', 'b']
+name = 'payment'
+
+[limits]
+  max = 1000
+  ratio = 0.25
+`, string(rendered))
+	})
+
+	t.Run("TOMLErrors", func(t *testing.T) {
+		_, err := FromJSON(TOML, data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "null at /empty")
+
+		_, err = FromJSON(TOML, json.RawMessage(`[1]`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only objects")
+
+		_, err = FromJSON(TOML, json.RawMessage(`{"id":18446744073709551616}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "out of the 64-bit range")
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		rendered, err := FromJSON(Env, json.RawMessage(`{"db":{"host":"db.local","max-conns":10},"greeting":"hello world","hosts":["a","b"],"empty":null,"price":"$5"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, `DB_HOST=db.local
+DB_MAX_CONNS=10
+EMPTY=
+GREETING="hello world"
+HOSTS_0=a
+HOSTS_1=b
+PRICE="\$5"
+`, string(rendered))
+	})
+
+	t.Run("EnvNameCollision", func(t *testing.T) {
+		_, err := FromJSON(Env, json.RawMessage(`{"max-conns":1,"max_conns":2}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/max-conns and /max_conns both render as MAX_CONNS")
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		rendered, err := FromJSON(Properties, json.RawMessage(`{"db":{"host":"db.local"},"greeting":" hi=there","hosts":["a"],"name":"café"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, `db.host=db.local
+greeting=\ hi=there
+hosts.0=a
+name=caf\u00e9
+`, string(rendered))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		document := json.RawMessage(`{"name":"payment","limits":{"max":1000,"ratio":0.25},"flags":["a","b"],"enabled":true,"note":"yes"}`)
+
+		for _, f := range []Format{YAML, TOML} {
+			rendered, err := FromJSON(f, document)
+			require.NoError(t, err)
+
+			converted, err := ToJSON(f, rendered)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(document), string(converted), "format %s", f)
+		}
+	})
+}
+
+func TestToJSONWithSourceMap(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		data, positions, err := ToJSONWithSourceMap(YAML, []byte(`# payments
+defaults: &defaults
+  timeout: 30
+limits:
+  max: 1000
+  hosts:
+    - a.example.com
+    - b.example.com
+service:
+  <<: *defaults
+  name: payments
+`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"defaults":{"timeout":30},"limits":{"max":1000,"hosts":["a.example.com","b.example.com"]},"service":{"name":"payments","timeout":30}}`, string(data))
+
+		assert.Equal(t, Position{Line: 2, Column: 1}, positions.Locate(""))
+		assert.Equal(t, Position{Line: 5, Column: 8}, positions.Locate("/limits/max"))
+		assert.Equal(t, Position{Line: 8, Column: 7}, positions.Locate("/limits/hosts/1"))
+		assert.Equal(t, Position{Line: 11, Column: 9}, positions.Locate("/service/name"))
+		assert.Equal(t, Position{Line: 3, Column: 12}, positions.Locate("/service/timeout"), "merged values are located where they were written")
+		assert.Equal(t, Position{Line: 10, Column: 3}, positions.Locate("/service/missing"), "missing values are located at their parent")
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		data, positions, err := ToJSONWithSourceMap(TOML, []byte(`name = "payments"
+limits.max = 1000
+
+[server]
+host = "a.example.com"
+ports = [8080, 8081]
+tls = { enabled = true }
+
+[[replicas]]
+host = "b.example.com"
+
+[[replicas]]
+host = "c.example.com"
+`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"payments","limits":{"max":1000},"server":{"host":"a.example.com","ports":[8080,8081],"tls":{"enabled":true}},"replicas":[{"host":"b.example.com"},{"host":"c.example.com"}]}`, string(data))
+
+		assert.Equal(t, Position{Line: 1, Column: 1}, positions.Locate("/name"))
+		assert.Equal(t, Position{Line: 2, Column: 8}, positions.Locate("/limits/max"))
+		assert.Equal(t, Position{Line: 4, Column: 2}, positions.Locate("/server"))
+		assert.Equal(t, Position{Line: 6, Column: 1}, positions.Locate("/server/ports/1"))
+		assert.Equal(t, Position{Line: 7, Column: 9}, positions.Locate("/server/tls/enabled"))
+		assert.Equal(t, Position{Line: 12, Column: 3}, positions.Locate("/replicas/1"))
+		assert.Equal(t, Position{Line: 13, Column: 1}, positions.Locate("/replicas/1/host"))
+	})
+
+	t.Run("SyntaxError", func(t *testing.T) {
+		_, _, err := ToJSONWithSourceMap(YAML, []byte("name: payments\n  limit: : 1\n"))
+		var syntaxErr *SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 2, syntaxErr.Line)
+
+		_, _, err = ToJSONWithSourceMap(TOML, []byte("name = \"payments\"\nlimit = \n"))
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, 2, syntaxErr.Line)
+	})
+}
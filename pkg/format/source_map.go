@@ -0,0 +1,203 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a 1-based line and column in a source document. Columns count
+// characters, not bytes.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// SyntaxError reports where a document could not be converted to JSON. YAML errors
+// only carry a line and are reported at its first column.
+type SyntaxError struct {
+	Position
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// SourceMap records where the values of a document converted to JSON were written in
+// the source, keyed by RFC 6901 JSON Pointer
+type SourceMap map[string]Position
+
+// Locate returns where the value a JSON Pointer refers to was written. When the value
+// has no position of its own, as for a missing property or a default filled in after
+// conversion, the closest recorded parent is located.
+func (m SourceMap) Locate(pointer string) Position {
+	for {
+		if position, ok := m[pointer]; ok {
+			return position
+		}
+		if pointer == "" {
+			return Position{Line: 1, Column: 1}
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// yamlErrorLine matches the line YAML parse and conversion errors are reported at
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// ToJSONWithSourceMap converts a YAML or TOML document to JSON like ToJSON, also
+// recording where each value was written. YAML values are located at the value and
+// TOML values at their key, arrays of tables at their header. Conversion errors are
+// returned as a *SyntaxError.
+func ToJSONWithSourceMap(f Format, data []byte) (json.RawMessage, SourceMap, error) {
+	switch f {
+	case YAML:
+		c := &yamlConverter{positions: SourceMap{}}
+		converted, err := c.toJSON(data)
+		if err != nil {
+			position := Position{Line: 1, Column: 1}
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				position.Line, _ = strconv.Atoi(match[1])
+			}
+			return nil, nil, &SyntaxError{Position: position, Message: err.Error()}
+		}
+		return converted, c.positions, nil
+	case TOML:
+		converted, err := tomlToJSON(data)
+		if err != nil {
+			position := Position{Line: 1, Column: 1}
+			var decodeErr *toml.DecodeError
+			if stdErrors.As(err, &decodeErr) {
+				row, column := decodeErr.Position()
+				position = sourcePosition(data, tomlErrorOffset(data, row, column))
+			}
+			return nil, nil, &SyntaxError{Position: position, Message: err.Error()}
+		}
+		return converted, tomlPositions(data), nil
+	}
+	return nil, nil, fmt.Errorf("%s documents have no source positions", f)
+}
+
+// tomlErrorOffset converts the 1-based row and byte column of a TOML error to an offset
+func tomlErrorOffset(data []byte, row, column int) int {
+	offset := 0
+	for line := 1; line < row; line++ {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			return len(data)
+		}
+		offset += next + 1
+	}
+	if offset += column - 1; offset > len(data) {
+		return len(data)
+	}
+	return offset
+}
+
+// tomlPositions locates the keys of a well-formed TOML document. Tables declared with
+// a header are located at the header; values at their key.
+func tomlPositions(data []byte) SourceMap {
+	positions := SourceMap{"": {Line: 1, Column: 1}}
+	arrayTables := map[string]int{}
+
+	p := &unstable.Parser{}
+	p.Reset(data)
+	table := []string{}
+	for p.NextExpression() {
+		expression := p.Expression()
+		switch expression.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			var header Position
+			table, header = recordTOMLKey(p, positions, []string{}, expression.Key())
+			if expression.Kind == unstable.ArrayTable {
+				// Every [[header]] appends an element to the array it names
+				array := sourcePointer(table)
+				table = append(table, strconv.Itoa(arrayTables[array]))
+				arrayTables[array]++
+				positions[sourcePointer(table)] = header
+			}
+		case unstable.KeyValue:
+			recordTOMLKeyValue(p, positions, table, expression)
+		}
+	}
+	return positions
+}
+
+// recordTOMLKeyValue locates a key and the values nested in inline tables under it
+func recordTOMLKeyValue(p *unstable.Parser, positions SourceMap, table []string, keyValue *unstable.Node) {
+	path, position := recordTOMLKey(p, positions, table, keyValue.Key())
+	recordTOMLValue(p, positions, path, keyValue.Value(), position)
+}
+
+// recordTOMLValue locates the keys of inline tables within a value. Array items have
+// no key and are located at the key of the array.
+func recordTOMLValue(p *unstable.Parser, positions SourceMap, path []string, value *unstable.Node, position Position) {
+	switch value.Kind {
+	case unstable.InlineTable:
+		children := value.Children()
+		for children.Next() {
+			recordTOMLKeyValue(p, positions, path, children.Node())
+		}
+	case unstable.Array:
+		items := value.Children()
+		for i := 0; items.Next(); i++ {
+			item := append(append([]string{}, path...), strconv.Itoa(i))
+			positions[sourcePointer(item)] = position
+			recordTOMLValue(p, positions, item, items.Node(), position)
+		}
+	}
+}
+
+// recordTOMLKey locates every part of a possibly dotted key under a table, returning
+// the path of the key and the position of its last part. Parts already located, such
+// as tables a dotted key extends, keep their first position.
+func recordTOMLKey(p *unstable.Parser, positions SourceMap, table []string, key unstable.Iterator) ([]string, Position) {
+	path := append([]string{}, table...)
+	var position Position
+	for key.Next() {
+		part := key.Node()
+		path = append(path, string(part.Data))
+		position = sourcePosition(p.Data(), int(part.Raw.Offset))
+		if _, ok := positions[sourcePointer(path)]; !ok {
+			positions[sourcePointer(path)] = position
+		}
+	}
+	return path, position
+}
+
+// sourcePosition returns the position of a byte offset in a document
+func sourcePosition(data []byte, offset int) Position {
+	prefix := data[:offset]
+	lineStart := bytes.LastIndexByte(prefix, '\n') + 1
+	return Position{Line: bytes.Count(prefix, []byte("\n")) + 1, Column: utf8.RuneCount(prefix[lineStart:]) + 1}
+}
+
+// recordYAML locates a YAML node at the path it is converted to. Aliases are located
+// where they are used rather than where their anchor was defined.
+func (c *yamlConverter) recordYAML(node *yaml.Node, path []string) {
+	if c.positions == nil || node.Kind == yaml.DocumentNode {
+		return
+	}
+	if _, ok := c.positions[sourcePointer(path)]; !ok {
+		c.positions[sourcePointer(path)] = Position{Line: node.Line, Column: node.Column}
+	}
+}
+
+// sourcePointer returns the JSON Pointer of a path, which is empty for the document
+// itself
+func sourcePointer(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return pointer(path)
+}
@@ -0,0 +1,147 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlToJSON converts a TOML document to JSON. Date and time values are kept as
+// their RFC 3339 strings.
+func tomlToJSON(data []byte) (json.RawMessage, error) {
+	var document map[string]interface{}
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	value, err := jsonValueOfTOML(document, []string{})
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSON(value)
+}
+
+// jsonValueOfTOML converts a decoded TOML value to its JSON equivalent
+func jsonValueOfTOML(value interface{}, path []string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted, err := jsonValueOfTOML(item, append(path, key))
+			if err != nil {
+				return nil, err
+			}
+			object[key] = converted
+		}
+		return object, nil
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, item := range v {
+			converted, err := jsonValueOfTOML(item, append(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			array[i] = converted
+		}
+		return array, nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("toml: %v at %s cannot be represented in JSON", v, pointer(path))
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64)), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case toml.LocalDate:
+		return v.String(), nil
+	case toml.LocalTime:
+		return v.String(), nil
+	case toml.LocalDateTime:
+		return v.String(), nil
+	case string, bool:
+		return v, nil
+	}
+	return nil, fmt.Errorf("toml: unsupported value of type %T at %s", value, pointer(path))
+}
+
+// jsonToTOML renders a JSON object as a TOML document. TOML has no null, so null
+// values are rejected, as are integers outside the 64-bit range TOML supports.
+func jsonToTOML(data json.RawMessage) ([]byte, error) {
+	document, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := document.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("toml: only objects can be rendered as TOML documents, got %s", jsonTypeOf(document))
+	}
+	if err := checkTOMLValue(document, []string{}); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.SetIndentTables(true)
+	encoder.SetMarshalJsonNumbers(true)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkTOMLValue reports the first value of a decoded JSON document TOML cannot represent
+func checkTOMLValue(value interface{}, path []string) error {
+	switch v := value.(type) {
+	case nil:
+		return fmt.Errorf("toml: null at %s cannot be represented in TOML", pointer(path))
+	case map[string]interface{}:
+		for key, item := range v {
+			if err := checkTOMLValue(item, append(path, key)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := checkTOMLValue(item, append(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case json.Number:
+		if _, err := v.Int64(); err != nil && isInteger(v) {
+			return fmt.Errorf("toml: integer %s at %s is out of the 64-bit range of TOML", v, pointer(path))
+		}
+	}
+	return nil
+}
+
+// isInteger reports whether a JSON number is written without a fraction or exponent
+func isInteger(n json.Number) bool {
+	for _, r := range n.String() {
+		if r == '.' || r == 'e' || r == 'E' {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonTypeOf returns the JSON type name of a decoded value
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "number"
+}
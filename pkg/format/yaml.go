@@ -0,0 +1,314 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLValues bounds how many values a YAML document may expand to through
+// aliases, so that a small document cannot expand into an unbounded one
+const maxYAMLValues = 1 << 20
+
+// yamlConverter converts a parsed YAML document to JSON, keeping the order of
+// mapping keys
+type yamlConverter struct {
+	buf    bytes.Buffer
+	values int
+	// positions records where converted values were written, if not nil
+	positions SourceMap
+}
+
+// yamlToJSON converts a single YAML document to JSON. Mapping keys must be strings
+// and scalars must have a JSON equivalent; timestamps and binary values are kept as
+// the strings they were written as.
+func yamlToJSON(data []byte) (json.RawMessage, error) {
+	return (&yamlConverter{}).toJSON(data)
+}
+
+// toJSON converts a single YAML document to JSON
+func (c *yamlConverter) toJSON(data []byte) (json.RawMessage, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var document yaml.Node
+	if err := decoder.Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("yaml: empty document")
+		}
+		return nil, err
+	}
+	var next yaml.Node
+	if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("yaml: expected a single document, found another at line %d", next.Line)
+	}
+
+	if err := c.convert(&document, []string{}); err != nil {
+		return nil, err
+	}
+	return c.buf.Bytes(), nil
+}
+
+// convert writes the JSON equivalent of a YAML node
+func (c *yamlConverter) convert(node *yaml.Node, path []string) error {
+	if c.values++; c.values > maxYAMLValues {
+		return fmt.Errorf("yaml: document expands to more than %d values", maxYAMLValues)
+	}
+	c.recordYAML(node, path)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return fmt.Errorf("yaml: empty document")
+		}
+		return c.convert(node.Content[0], path)
+	case yaml.AliasNode:
+		return c.convert(node.Alias, path)
+	case yaml.MappingNode:
+		return c.convertMapping(node, path)
+	case yaml.SequenceNode:
+		c.buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			if err := c.convert(item, append(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+		return nil
+	case yaml.ScalarNode:
+		return c.convertScalar(node, path)
+	}
+	return fmt.Errorf("yaml: line %d: unsupported node at %s", node.Line, pointer(path))
+}
+
+// convertMapping writes a YAML mapping as a JSON object. Keys merged in with << are
+// overridden by keys the mapping sets itself.
+func (c *yamlConverter) convertMapping(node *yaml.Node, path []string) error {
+	type entry struct {
+		key   string
+		value *yaml.Node
+	}
+
+	entries := []entry{}
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.ShortTag() == "!!merge" {
+			continue
+		}
+		if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!str" {
+			return fmt.Errorf("yaml: line %d: mapping key %q at %s must be a string; quote it to use it as one", key.Line, key.Value, pointer(path))
+		}
+		if explicit[key.Value] {
+			return fmt.Errorf("yaml: line %d: mapping key %q is already defined at %s", key.Line, key.Value, pointer(path))
+		}
+		explicit[key.Value] = true
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			entries = append(entries, entry{key: key.Value, value: value})
+			seen[key.Value] = true
+			continue
+		}
+
+		merged, err := mergedMappings(value)
+		if err != nil {
+			return fmt.Errorf("yaml: line %d: %v at %s", key.Line, err, pointer(path))
+		}
+		for _, mapping := range merged {
+			for j := 0; j+1 < len(mapping.Content); j += 2 {
+				mergedKey := mapping.Content[j]
+				if mergedKey.ShortTag() != "!!str" || explicit[mergedKey.Value] || seen[mergedKey.Value] {
+					continue
+				}
+				entries = append(entries, entry{key: mergedKey.Value, value: mapping.Content[j+1]})
+				seen[mergedKey.Value] = true
+			}
+		}
+	}
+
+	c.buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			c.buf.WriteByte(',')
+		}
+		key, err := encodeJSON(e.key)
+		if err != nil {
+			return err
+		}
+		c.buf.Write(key)
+		c.buf.WriteByte(':')
+		if err := c.convert(e.value, append(path, e.key)); err != nil {
+			return err
+		}
+	}
+	c.buf.WriteByte('}')
+	return nil
+}
+
+// mergedMappings returns the mappings the value of a << key merges in
+func mergedMappings(value *yaml.Node) ([]*yaml.Node, error) {
+	resolve := func(node *yaml.Node) (*yaml.Node, error) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("merge key value must be a mapping or a sequence of mappings")
+		}
+		return node, nil
+	}
+
+	if value.Kind == yaml.SequenceNode {
+		mappings := make([]*yaml.Node, 0, len(value.Content))
+		for _, item := range value.Content {
+			mapping, err := resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			mappings = append(mappings, mapping)
+		}
+		return mappings, nil
+	}
+
+	mapping, err := resolve(value)
+	if err != nil {
+		return nil, err
+	}
+	return []*yaml.Node{mapping}, nil
+}
+
+// convertScalar writes a YAML scalar as a JSON value
+func (c *yamlConverter) convertScalar(node *yaml.Node, path []string) error {
+	switch node.ShortTag() {
+	case "!!null":
+		c.buf.WriteString("null")
+		return nil
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		c.buf.WriteString(strconv.FormatBool(value))
+		return nil
+	case "!!int":
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		switch v := value.(type) {
+		case int:
+			c.buf.WriteString(strconv.Itoa(v))
+		case int64:
+			c.buf.WriteString(strconv.FormatInt(v, 10))
+		case uint64:
+			c.buf.WriteString(strconv.FormatUint(v, 10))
+		default:
+			return fmt.Errorf("yaml: line %d: integer %s at %s is out of range", node.Line, node.Value, pointer(path))
+		}
+		return nil
+	case "!!float":
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("yaml: line %d: %s at %s cannot be represented in JSON", node.Line, node.Value, pointer(path))
+		}
+		c.buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		return nil
+	case "!!str", "!!timestamp", "!!binary":
+		value, err := encodeJSON(node.Value)
+		if err != nil {
+			return err
+		}
+		c.buf.Write(value)
+		return nil
+	}
+	return fmt.Errorf("yaml: line %d: unsupported tag %s at %s", node.Line, node.Tag, pointer(path))
+}
+
+// jsonToYAML renders a JSON document as YAML, keeping the order of object keys
+func jsonToYAML(data json.RawMessage) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	node, err := yamlNodeOf(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNodeOf reads the next JSON value from the decoder as a YAML node
+func yamlNodeOf(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := yamlNodeOf(decoder)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)}, value)
+			}
+			_, err := decoder.Token()
+			return node, err
+		}
+
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for decoder.More() {
+			item, err := yamlNodeOf(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		_, err := decoder.Token()
+		return node, err
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!float"
+		if isInteger(t) {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+}
@@ -0,0 +1,118 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation is the kind of change between two JSON documents
+type Operation string
+
+// Change operations
+const (
+	OperationAdd     Operation = "add"
+	OperationRemove  Operation = "remove"
+	OperationReplace Operation = "replace"
+)
+
+// Change describes a single difference between two JSON documents.
+// Path is an RFC 6901 JSON Pointer to the changed value.
+type Change struct {
+	Op       Operation   `json:"op"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// Diff computes the changes needed to turn from into to.
+// Objects are compared key by key and arrays of equal length element by element;
+// any other difference is reported as a replacement of the whole value.
+func Diff(from, to json.RawMessage) ([]Change, error) {
+	fromValue, err := decode(from)
+	if err != nil {
+		return nil, err
+	}
+	toValue, err := decode(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	diffValues("", fromValue, toValue, &changes)
+	return changes, nil
+}
+
+// decode unmarshals a JSON document keeping numbers exact; empty input decodes to nil
+func decode(data json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// diffValues appends the changes between two decoded values at path
+func diffValues(path string, from, to interface{}, changes *[]Change) {
+	switch fromTyped := from.(type) {
+	case map[string]interface{}:
+		if toTyped, ok := to.(map[string]interface{}); ok {
+			diffObjects(path, fromTyped, toTyped, changes)
+			return
+		}
+	case []interface{}:
+		if toTyped, ok := to.([]interface{}); ok && len(fromTyped) == len(toTyped) {
+			for i := range fromTyped {
+				diffValues(path+"/"+strconv.Itoa(i), fromTyped[i], toTyped[i], changes)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Op: OperationReplace, Path: path, OldValue: from, NewValue: to})
+	}
+}
+
+// diffObjects appends the changes between two objects in key order
+func diffObjects(path string, from, to map[string]interface{}, changes *[]Change) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "/" + EscapePointerToken(key)
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inFrom:
+			*changes = append(*changes, Change{Op: OperationAdd, Path: childPath, NewValue: toValue})
+		case !inTo:
+			*changes = append(*changes, Change{Op: OperationRemove, Path: childPath, OldValue: fromValue})
+		default:
+			diffValues(childPath, fromValue, toValue, changes)
+		}
+	}
+}
+
+// EscapePointerToken escapes a reference token for use in a JSON Pointer
+func EscapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,63 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("NoChanges", func(t *testing.T) {
+		changes, err := Diff(json.RawMessage(`{"a":1,"b":[1,2]}`), json.RawMessage(`{"b":[1,2],"a":1}`))
+
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("ObjectChanges", func(t *testing.T) {
+		changes, err := Diff(
+			json.RawMessage(`{"limit":100,"old":true,"nested":{"url":"http://a"}}`),
+			json.RawMessage(`{"limit":200,"new":"x","nested":{"url":"https://a"}}`),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Change{
+			{Op: OperationReplace, Path: "/limit", OldValue: json.Number("100"), NewValue: json.Number("200")},
+			{Op: OperationReplace, Path: "/nested/url", OldValue: "http://a", NewValue: "https://a"},
+			{Op: OperationAdd, Path: "/new", NewValue: "x"},
+			{Op: OperationRemove, Path: "/old", OldValue: true},
+		}, changes)
+	})
+
+	t.Run("Arrays", func(t *testing.T) {
+		changes, err := Diff(json.RawMessage(`{"a":[1,2],"b":[1]}`), json.RawMessage(`{"a":[1,3],"b":[1,2]}`))
+
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, "/a/1", changes[0].Path)
+		assert.Equal(t, "/b", changes[1].Path)
+		assert.Equal(t, OperationReplace, changes[1].Op)
+	})
+
+	t.Run("EscapesPointerTokens", func(t *testing.T) {
+		changes, err := Diff(json.RawMessage(`{}`), json.RawMessage(`{"a/b~c":1}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, "/a~1b~0c", changes[0].Path)
+	})
+
+	t.Run("EmptyBase", func(t *testing.T) {
+		changes, err := Diff(nil, json.RawMessage(`{"a":1}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []Change{{Op: OperationReplace, Path: "", NewValue: map[string]interface{}{"a": json.Number("1")}}}, changes)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := Diff(json.RawMessage(`{`), json.RawMessage(`{}`))
+
+		assert.Error(t, err)
+	})
+}
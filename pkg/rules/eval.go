@@ -0,0 +1,378 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// node is an evaluable node of a rule expression's syntax tree
+type node interface {
+	eval(data map[string]interface{}) (interface{}, error)
+}
+
+// literalNode is a constant value
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// listNode is a list literal
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(data map[string]interface{}) (interface{}, error) {
+	values := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// fieldNode reads a top-level field of the configuration; missing fields are null
+type fieldNode struct {
+	name string
+}
+
+func (n *fieldNode) eval(data map[string]interface{}) (interface{}, error) {
+	return data[n.name], nil
+}
+
+// memberNode reads a field of an object; missing fields and fields of null are null
+type memberNode struct {
+	target node
+	name   string
+}
+
+func (n *memberNode) eval(data map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(data)
+	if err != nil || target == nil {
+		return nil, err
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot read field %q of %s", n.name, typeName(target))
+	}
+	return object[n.name], nil
+}
+
+// indexNode reads an element of a list or a field of an object; out of range elements are null
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(data map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(data)
+	if err != nil || target == nil {
+		return nil, err
+	}
+	index, err := n.index.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, fmt.Errorf("list index must be an integer, got %s", typeName(index))
+		}
+		if i < 0 || int(i) >= len(t) {
+			return nil, nil
+		}
+		return t[int(i)], nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("object key must be a string, got %s", typeName(index))
+		}
+		return t[key], nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(target))
+}
+
+// unaryNode applies a prefix operator
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(data map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		b, err := truth(value)
+		if err != nil {
+			return nil, err
+		}
+		return !b, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", typeName(value))
+	}
+	return -number, nil
+}
+
+// binaryNode applies an infix operator
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(data map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, err := truth(left)
+		if err != nil {
+			return nil, err
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(data)
+		if err != nil {
+			return nil, err
+		}
+		return truth(right)
+	}
+
+	right, err := n.right.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "in":
+		return contains(right, left)
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s is not defined for %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+// callNode calls a built-in function
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(data map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(data)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	result, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return result, nil
+}
+
+// function is a built-in function callable from rule expressions
+type function struct {
+	arity int
+	call  func(args []interface{}) (interface{}, error)
+}
+
+// functions are the built-in functions of the rule language
+var functions = map[string]function{
+	"has": {1, func(args []interface{}) (interface{}, error) {
+		return args[0] != nil, nil
+	}},
+	"len": {1, func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("length of %s is not defined", typeName(args[0]))
+	}},
+	"startsWith": {2, stringFunction(strings.HasPrefix)},
+	"endsWith":   {2, stringFunction(strings.HasSuffix)},
+	"contains":   {2, stringFunction(strings.Contains)},
+	"matches": {2, func(args []interface{}) (interface{}, error) {
+		pattern, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string pattern, got %s", typeName(args[1]))
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := args[0].(string)
+		return ok && re.MatchString(s), nil
+	}},
+}
+
+// stringFunction adapts a string predicate to a built-in function
+func stringFunction(predicate func(s, arg string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return false, nil
+		}
+		arg, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string argument, got %s", typeName(args[1]))
+		}
+		return predicate(s, arg), nil
+	}
+}
+
+// truth converts a value to a boolean; null is false
+func truth(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("expected a boolean, got %s", typeName(value))
+}
+
+// compare applies an ordering operator to two numbers or two strings
+func compare(op string, left, right interface{}) (bool, error) {
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return false, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+// contains implements the in operator: list membership, object keys and substrings
+func contains(container, value interface{}) (bool, error) {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if reflect.DeepEqual(item, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := value.(string)
+		if !ok {
+			return false, fmt.Errorf("object key must be a string, got %s", typeName(value))
+		}
+		_, found := c[key]
+		return found, nil
+	case string:
+		s, ok := value.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot search %s in a string", typeName(value))
+		}
+		return strings.Contains(c, s), nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("in is not defined for %s", typeName(container))
+}
+
+// typeName names the JSON type of a value for error messages
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
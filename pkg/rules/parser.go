@@ -0,0 +1,253 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// binaryPrecedence is the binding power of each binary operator; higher binds tighter
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4, "in": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// parser builds the syntax tree of a rule expression
+type parser struct {
+	tokens []token
+	pos    int
+	// fields records the static field paths the expression reads, in order of appearance
+	fields []string
+}
+
+// parse parses a rule expression into a syntax tree
+func parse(expression string) (node, []string, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return root, p.fields, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// expect consumes an operator token with the given text
+func (p *parser) expect(text string) error {
+	tok := p.next()
+	if tok.kind != tokenOperator || tok.text != text {
+		return fmt.Errorf("expected %q at position %d", text, tok.pos)
+	}
+	return nil
+}
+
+// binaryOperator returns the binary operator at the current position, if any
+func (p *parser) binaryOperator() (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator && !(tok.kind == tokenIdent && tok.text == "in") {
+		return "", false
+	}
+	_, ok := binaryPrecedence[tok.text]
+	return tok.text, ok
+}
+
+// parseExpression parses binary operators binding tighter than minPrecedence
+func (p *parser) parseExpression(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || binaryPrecedence[op] <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseExpression(binaryPrecedence[op])
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseUnary parses prefix operators
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: tok.text, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary expression followed by member accesses and indexes
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	// Track the static path of field chains such as servers[0].host
+	path, static := "", false
+	if field, ok := target.(*fieldNode); ok {
+		path, static = field.name, true
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOperator || (tok.text != "." && tok.text != "[") {
+			break
+		}
+		p.next()
+
+		if tok.text == "." {
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, fmt.Errorf("expected field name at position %d", name.pos)
+			}
+			target = &memberNode{target: target, name: name.text}
+			path += "." + name.text
+			continue
+		}
+
+		index, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		target = &indexNode{target: target, index: index}
+		if lit, ok := index.(*literalNode); ok {
+			switch v := lit.value.(type) {
+			case float64:
+				path += "." + strconv.FormatFloat(v, 'f', -1, 64)
+			case string:
+				path += "." + v
+			default:
+				static = false
+			}
+		} else {
+			static = false
+		}
+	}
+
+	if static {
+		p.fields = append(p.fields, path)
+	}
+	return target, nil
+}
+
+// parsePrimary parses literals, fields, function calls, lists and parenthesised expressions
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		case "in":
+			return nil, fmt.Errorf("unexpected \"in\" at position %d", tok.pos)
+		}
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			return p.parseCall(tok)
+		}
+		return &fieldNode{name: tok.text}, nil
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// parseCall parses the arguments of a call to a built-in function
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	p.next()
+
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", name.text, fn.arity, len(args))
+	}
+
+	return &callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+// parseList parses comma-separated expressions up to the closing token
+func (p *parser) parseList(closing string) ([]node, error) {
+	items := []node{}
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == closing {
+		p.next()
+		return items, nil
+	}
+
+	for {
+		item, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		tok := p.next()
+		if tok.kind == tokenOperator && tok.text == closing {
+			return items, nil
+		}
+		if tok.kind != tokenOperator || tok.text != "," {
+			return nil, fmt.Errorf("expected \",\" or %q at position %d", closing, tok.pos)
+		}
+	}
+}
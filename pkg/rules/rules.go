@@ -0,0 +1,157 @@
+// Package rules implements a small expression language for validation rules that
+// JSON Schema cannot express, such as constraints between fields.
+//
+// An expression reads fields of the configuration by name (max_limit, endpoint.url,
+// servers[0].host) and must evaluate to a boolean. Missing fields are null. It supports
+// literals (numbers, 'strings', true, false, null, [lists]), the operators
+// || && ! == != < <= > >= in + - * / % and the functions has, len, startsWith,
+// endsWith, contains and matches. In logical operators null counts as false, so
+// "!enabled || startsWith(endpoint, 'https://')" holds when enabled is absent.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// Rule is a named expression that configuration data must satisfy
+type Rule struct {
+	// Name identifies the rule within its rule set
+	Name string `json:"name"`
+	// Expression must evaluate to true for valid data
+	Expression string `json:"expression"`
+	// Field is the field path reported when the rule fails; it defaults to the
+	// first field the expression reads
+	Field string `json:"field,omitempty"`
+	// Message describes the failure; it defaults to the rule name and expression
+	Message string `json:"message,omitempty"`
+}
+
+// Program is a compiled rule expression
+type Program struct {
+	root   node
+	fields []string
+}
+
+// Compile parses a rule expression
+func Compile(expression string) (*Program, error) {
+	root, fields, err := parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{root: root, fields: fields}, nil
+}
+
+// Fields returns the static field paths the expression reads, in order of appearance
+func (p *Program) Fields() []string {
+	return p.fields
+}
+
+// Eval evaluates the expression against decoded JSON data
+func (p *Program) Eval(data interface{}) (bool, error) {
+	object, _ := data.(map[string]interface{})
+	value, err := p.root.eval(object)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a boolean, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+// Check compiles every rule of a rule set and reports the invalid ones, including
+// rules without a name or expression and duplicate names
+func Check(rules []Rule) []errors.ValidationError {
+	problems := []errors.ValidationError{}
+	seen := map[string]bool{}
+	for i, rule := range rules {
+		field := fmt.Sprintf("rules.%d", i)
+		switch {
+		case rule.Name == "":
+			problems = append(problems, errors.ValidationError{Field: field, Reason: "rule name is required"})
+		case seen[rule.Name]:
+			problems = append(problems, errors.ValidationError{Field: field, Reason: fmt.Sprintf("duplicate rule name %q", rule.Name)})
+		}
+		seen[rule.Name] = true
+
+		if rule.Expression == "" {
+			problems = append(problems, errors.ValidationError{Field: field, Reason: "rule expression is required"})
+			continue
+		}
+		if _, err := Compile(rule.Expression); err != nil {
+			problems = append(problems, errors.ValidationError{Field: field, Reason: fmt.Sprintf("invalid expression: %s", err)})
+		}
+	}
+	return problems
+}
+
+// Evaluate runs every rule against configuration data and reports the rules that fail,
+// or cannot be evaluated, as validation errors with field paths. The rules must have
+// passed Check.
+func Evaluate(rules []Rule, data json.RawMessage) ([]errors.ValidationError, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid configuration data: %w", err)
+	}
+
+	failures := []errors.ValidationError{}
+	for _, rule := range rules {
+		program, err := Compile(rule.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+
+		field := rule.Field
+		if field == "" {
+			field = "(root)"
+			if fields := program.Fields(); len(fields) > 0 {
+				field = fields[0]
+			}
+		}
+
+		ok, err := program.Eval(document)
+		switch {
+		case err != nil:
+			failures = append(failures, errors.ValidationError{
+				Field:   field,
+				Reason:  fmt.Sprintf("rule %q could not be evaluated: %s", rule.Name, err),
+				Pointer: fieldPointer(field),
+				Code:    errors.ValidationCodeRuleError,
+			})
+		case !ok:
+			reason := rule.Message
+			if reason == "" {
+				reason = fmt.Sprintf("rule %q failed: %s", rule.Name, rule.Expression)
+			}
+			failures = append(failures, errors.ValidationError{
+				Field:    field,
+				Reason:   reason,
+				Pointer:  fieldPointer(field),
+				Code:     errors.ValidationCodeRuleFailed,
+				Expected: rule.Expression,
+			})
+		}
+	}
+	return failures, nil
+}
+
+// fieldPointer converts a dotted field path such as "servers.0.host" into an RFC 6901
+// JSON Pointer; "(root)" is the empty pointer
+func fieldPointer(field string) string {
+	field = strings.TrimPrefix(strings.TrimPrefix(field, "(root)"), ".")
+	if field == "" {
+		return ""
+	}
+
+	var pointer strings.Builder
+	for _, segment := range strings.Split(field, ".") {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment))
+	}
+	return pointer.String()
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgram_Eval(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"min_limit": 10,
+		"max_limit": 100,
+		"enabled": true,
+		"endpoint": "https://payments.example.com",
+		"currency": "IDR",
+		"servers": [{"host": "a.internal", "port": 8080}],
+		"tags": {"team": "payments"}
+	}`), &data))
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"max_limit > min_limit", true},
+		{"max_limit - min_limit >= 90 && max_limit % 2 == 0", true},
+		{"!enabled || startsWith(endpoint, 'https://')", true},
+		{"!disabled", true},
+		{"currency in ['IDR', 'SGD']", true},
+		{"'team' in tags && tags.team == \"payments\"", true},
+		{"servers[0].port == 8080 && endsWith(servers[0].host, '.internal')", true},
+		{"servers[1] == null && !has(servers[1].host)", true},
+		{"len(servers) == 1 && len(currency) == 3", true},
+		{"matches(currency, '^[A-Z]{3}$') && contains(endpoint, 'payments')", true},
+		{"-min_limit < 0 && (min_limit + 5) * 2 == 30", true},
+		{"max_limit < min_limit", false},
+		{"has(missing)", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			program, err := Compile(tt.expression)
+			require.NoError(t, err)
+
+			result, err := program.Eval(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("TypeErrors", func(t *testing.T) {
+		for _, expression := range []string{"currency > 5", "enabled + 1", "max_limit", "min_limit / 0 == 1", "currency.code == 'x'"} {
+			program, err := Compile(expression)
+			require.NoError(t, err)
+
+			_, err = program.Eval(data)
+			assert.Error(t, err, expression)
+		}
+	})
+}
+
+func TestCompile(t *testing.T) {
+	program, err := Compile("servers[0].host != '' && max_limit > min_limit")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"servers.0.host", "max_limit", "min_limit"}, program.Fields())
+
+	for _, expression := range []string{"max_limit >", "(a == b", "unknown(a)", "len(a, b)", "a == 'open", "a # b", ""} {
+		_, err := Compile(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	problems := Check([]Rule{
+		{Name: "limits", Expression: "max_limit > min_limit"},
+		{Name: "limits", Expression: "max_limit >"},
+		{Expression: "true"},
+	})
+
+	require.Len(t, problems, 3)
+	assert.Equal(t, "rules.1", problems[0].Field)
+	assert.Contains(t, problems[0].Reason, "duplicate")
+	assert.Contains(t, problems[1].Reason, "invalid expression")
+	assert.Equal(t, "rules.2", problems[2].Field)
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := []Rule{
+		{Name: "limits", Expression: "max_limit > min_limit"},
+		{Name: "https", Expression: "!enabled || startsWith(endpoint, 'https://')", Field: "endpoint", Message: "endpoint must use https when enabled"},
+		{Name: "typed", Expression: "max_limit > 'x'"},
+	}
+
+	failures, err := Evaluate(rules, json.RawMessage(`{"min_limit": 50, "max_limit": 10, "enabled": true, "endpoint": "http://x"}`))
+	require.NoError(t, err)
+	require.Len(t, failures, 3)
+	assert.Equal(t, "max_limit", failures[0].Field)
+	assert.Equal(t, `rule "limits" failed: max_limit > min_limit`, failures[0].Reason)
+	assert.Equal(t, "endpoint", failures[1].Field)
+	assert.Equal(t, "endpoint must use https when enabled", failures[1].Reason)
+	assert.Contains(t, failures[2].Reason, "could not be evaluated")
+
+	failures, err = Evaluate(rules[:2], json.RawMessage(`{"min_limit": 1, "max_limit": 10, "enabled": false}`))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
@@ -0,0 +1,565 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+)
+
+// lowerBounds and upperBounds are the keywords whose tightening narrows the accepted values
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties", "minContains"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties", "maxContains"}
+)
+
+// combinators are the keywords whose effect on the accepted values is not compared; a
+// change to them is reported, since its compatibility cannot be verified. Subschemas of
+// allOf are compared one by one, since each of them must hold.
+var combinators = []string{"allOf", "anyOf", "oneOf", "not", "if", "then", "else"}
+
+// CheckBackwardCompatibility compares a new schema with the previous one and reports
+// every change that could make data accepted by the previous schema invalid, such as
+// newly required fields, narrowed types, removed enum values or tightened bounds.
+// References within the document, such as "#/$defs/port", are followed. Changes to
+// references to other documents or to combining keywords such as anyOf cannot be
+// verified and are reported as well. An empty result means the new schema is backward
+// compatible.
+func CheckBackwardCompatibility(previous, next json.RawMessage) ([]errors.ValidationError, error) {
+	var prev, curr interface{}
+	if err := json.Unmarshal(previous, &prev); err != nil {
+		return nil, fmt.Errorf("invalid previous schema: %w", err)
+	}
+	if err := json.Unmarshal(next, &curr); err != nil {
+		return nil, fmt.Errorf("invalid new schema: %w", err)
+	}
+
+	c := &schemaComparer{
+		prevRoot: prev,
+		nextRoot: curr,
+		compared: map[[2]string]bool{},
+		equating: map[string]bool{},
+		issues:   []errors.ValidationError{},
+	}
+	c.compareSchemas(schemaPath{field: RootField}, prev, curr)
+	return c.issues, nil
+}
+
+// schemaComparer compares two versions of a schema, resolving local references against
+// the version they appear in
+type schemaComparer struct {
+	prevRoot interface{}
+	nextRoot interface{}
+	// compared holds the pairs of local references already compared, so that a
+	// definition used in several places, or by itself, is compared once
+	compared map[[2]string]bool
+	// equating holds the local references being checked for equivalence
+	equating map[string]bool
+	issues   []errors.ValidationError
+}
+
+// schemaPath locates a (sub)schema by the values it describes: their dotted field path
+// and, unless it describes any of several values such as array items, their JSON Pointer
+type schemaPath struct {
+	field    string
+	pointer  string
+	wildcard bool
+}
+
+// child returns the path of a property
+func (p schemaPath) child(name string) schemaPath {
+	child := schemaPath{field: childField(p.field, name), wildcard: p.wildcard}
+	if !p.wildcard {
+		child.pointer = p.pointer + "/" + escapePointerToken(name)
+	}
+	return child
+}
+
+// position returns the path of the item at an index of a tuple
+func (p schemaPath) position(index int) schemaPath {
+	position := schemaPath{field: fmt.Sprintf("%s[%d]", p.field, index), wildcard: p.wildcard}
+	if !p.wildcard {
+		position.pointer = fmt.Sprintf("%s/%d", p.pointer, index)
+	}
+	return position
+}
+
+// items returns the path of the items of an array
+func (p schemaPath) items() schemaPath {
+	return schemaPath{field: p.field + "[]", wildcard: true}
+}
+
+// matching returns the path of the properties whose names match a pattern, or of any
+// property not otherwise declared when the pattern is "*"
+func (p schemaPath) matching(pattern string) schemaPath {
+	return schemaPath{field: childField(p.field, pattern), wildcard: true}
+}
+
+// report records a narrowing change
+func (c *schemaComparer) report(path schemaPath, keyword, format string, args ...interface{}) {
+	c.issues = append(c.issues, errors.ValidationError{
+		Field:   path.field,
+		Reason:  fmt.Sprintf(format, args...),
+		Pointer: path.pointer,
+		Keyword: keyword,
+	})
+}
+
+// compareSchemas records the narrowing changes between two (sub)schemas
+func (c *schemaComparer) compareSchemas(path schemaPath, previous, next interface{}) {
+	// Boolean schemas: only a switch to false rejects previously valid data, and a
+	// schema that accepted anything is compared like an empty one
+	if allowed, ok := next.(bool); ok {
+		if prevAllowed, ok := previous.(bool); !allowed && (!ok || prevAllowed) {
+			c.report(path, "", "schema no longer accepts any value")
+		}
+		return
+	}
+	if allowed, ok := previous.(bool); ok && allowed {
+		previous = map[string]interface{}{}
+	}
+	prev, _ := previous.(map[string]interface{})
+	curr, _ := next.(map[string]interface{})
+	if prev == nil || curr == nil {
+		return
+	}
+
+	// References. Local references are compared by their targets, so a definition
+	// narrowed behind an unchanged reference is found; removing a reference only
+	// widens the schema.
+	prevRef, _ := prev["$ref"].(string)
+	nextRef, _ := curr["$ref"].(string)
+	prevTarget, prevLocal := resolveLocalRef(c.prevRoot, prevRef)
+	nextTarget, nextLocal := resolveLocalRef(c.nextRoot, nextRef)
+	switch {
+	case prevLocal && nextLocal:
+		if key := [2]string{prevRef, nextRef}; !c.compared[key] {
+			c.compared[key] = true
+			c.compareSchemas(path, prevTarget, nextTarget)
+		}
+	case nextLocal && prev["$ref"] == nil:
+		c.compareSchemas(path, true, nextTarget)
+	case curr["$ref"] == nil:
+	case !reflect.DeepEqual(prev["$ref"], curr["$ref"]):
+		c.report(path, "$ref", "cannot verify that the change to $ref is compatible")
+	}
+
+	// Combinators
+	for _, keyword := range combinators {
+		if c.equivalent(prev[keyword], curr[keyword]) {
+			continue
+		}
+		if keyword == "allOf" {
+			prevAll, _ := prev[keyword].([]interface{})
+			nextAll, _ := curr[keyword].([]interface{})
+			for i, nextSchema := range nextAll {
+				var prevSchema interface{} = true
+				if i < len(prevAll) {
+					prevSchema = prevAll[i]
+				}
+				c.compareSchemas(path, prevSchema, nextSchema)
+			}
+			continue
+		}
+		c.report(path, keyword, "cannot verify that the change to %s is compatible", keyword)
+	}
+
+	// Types
+	if nextTypes := typeSet(curr["type"]); nextTypes != nil {
+		prevTypes := typeSet(prev["type"])
+		if prevTypes == nil {
+			c.report(path, "type", "type restricted to %s", sortedKeys(nextTypes))
+		}
+		for _, t := range sortedKeys(prevTypes) {
+			if !nextTypes[t] && !(t == "integer" && nextTypes["number"]) {
+				c.report(path, "type", "type %q is no longer allowed", t)
+			}
+		}
+	}
+
+	// Required properties
+	prevRequired := stringSet(prev["required"])
+	for _, name := range sortedKeys(stringSet(curr["required"])) {
+		if !prevRequired[name] {
+			c.report(path.child(name), "required", "property became required")
+		}
+	}
+
+	// Enumerations and constants
+	if nextEnum, ok := curr["enum"].([]interface{}); ok {
+		prevEnum, hadEnum := prev["enum"].([]interface{})
+		if !hadEnum {
+			c.report(path, "enum", "values restricted to an enum")
+		}
+		for _, value := range prevEnum {
+			if !containsValue(nextEnum, value) {
+				c.report(path, "enum", "enum value %v was removed", value)
+			}
+		}
+	}
+	if nextConst, ok := curr["const"]; ok {
+		if prevConst, hadConst := prev["const"]; !hadConst || !reflect.DeepEqual(prevConst, nextConst) {
+			c.report(path, "const", "value restricted to a constant")
+		}
+	}
+
+	// Bounds
+	for _, keyword := range lowerBounds {
+		if nextBound, ok := curr[keyword].(float64); ok {
+			if prevBound, hadBound := prev[keyword].(float64); !hadBound || nextBound > prevBound {
+				c.report(path, keyword, "%s raised to %v", keyword, nextBound)
+			}
+		}
+	}
+	for _, keyword := range upperBounds {
+		if nextBound, ok := curr[keyword].(float64); ok {
+			if prevBound, hadBound := prev[keyword].(float64); !hadBound || nextBound < prevBound {
+				c.report(path, keyword, "%s lowered to %v", keyword, nextBound)
+			}
+		}
+	}
+	if nextFactor, ok := curr["multipleOf"].(float64); ok {
+		if prevFactor, hadFactor := prev["multipleOf"].(float64); !hadFactor || !isMultiple(prevFactor, nextFactor) {
+			c.report(path, "multipleOf", "multipleOf changed to %v", nextFactor)
+		}
+	}
+	if unique, _ := curr["uniqueItems"].(bool); unique {
+		if prevUnique, _ := prev["uniqueItems"].(bool); !prevUnique {
+			c.report(path, "uniqueItems", "array items must be unique")
+		}
+	}
+
+	// Patterns and formats
+	for _, keyword := range []string{"pattern", "format"} {
+		if nextValue, ok := curr[keyword]; ok && !reflect.DeepEqual(prev[keyword], nextValue) {
+			c.report(path, keyword, "%s changed to %v", keyword, nextValue)
+		}
+	}
+
+	// Additional and unevaluated properties
+	if allowed, ok := curr["additionalProperties"].(bool); ok && !allowed {
+		if prevAllowed, ok := prev["additionalProperties"].(bool); !ok || prevAllowed {
+			c.report(path, "additionalProperties", "additional properties are no longer allowed")
+		}
+	}
+	if nextAdditional, ok := curr["additionalProperties"].(map[string]interface{}); ok {
+		c.compareSchemas(path.matching("*"), optionalSchema(prev, "additionalProperties"), nextAdditional)
+	}
+	if allowed, ok := curr["unevaluatedProperties"].(bool); ok && !allowed {
+		if prevAllowed, ok := prev["unevaluatedProperties"].(bool); !ok || prevAllowed {
+			c.report(path, "unevaluatedProperties", "unevaluated properties are no longer allowed")
+		}
+	}
+
+	// Dependent required properties and schemas
+	prevDependents, _ := prev["dependentRequired"].(map[string]interface{})
+	nextDependents, _ := curr["dependentRequired"].(map[string]interface{})
+	for _, name := range sortedKeys(keySet(nextDependents)) {
+		prevRequired := stringSet(prevDependents[name])
+		for _, dependent := range sortedKeys(stringSet(nextDependents[name])) {
+			if !prevRequired[dependent] {
+				c.report(path.child(dependent), "dependentRequired", "property became required when %s is present", name)
+			}
+		}
+	}
+	prevDependentSchemas, _ := prev["dependentSchemas"].(map[string]interface{})
+	nextDependentSchemas, _ := curr["dependentSchemas"].(map[string]interface{})
+	for _, name := range sortedKeys(keySet(nextDependentSchemas)) {
+		c.compareSchemas(path, optionalSchema(prevDependentSchemas, name), nextDependentSchemas[name])
+	}
+
+	// Properties. A property that is only declared by one of the versions was, or is now,
+	// validated against additionalProperties instead: a removed property is rejected by a
+	// closed object, and a property added to an open object constrains values that were
+	// accepted as additional properties before.
+	prevProperties, _ := prev["properties"].(map[string]interface{})
+	nextProperties, _ := curr["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(keySet(prevProperties)) {
+		if nextProperty, ok := nextProperties[name]; ok {
+			c.compareSchemas(path.child(name), prevProperties[name], nextProperty)
+			continue
+		}
+		if closedObject(curr) {
+			c.report(path.child(name), "properties", "property was removed and is no longer allowed")
+		} else if nextAdditional, ok := curr["additionalProperties"]; ok {
+			c.compareSchemas(path.child(name), prevProperties[name], nextAdditional)
+		}
+	}
+	for _, name := range sortedKeys(keySet(nextProperties)) {
+		if _, ok := prevProperties[name]; ok || closedObject(prev) {
+			continue
+		}
+		c.compareSchemas(path.child(name), optionalSchema(prev, "additionalProperties"), nextProperties[name])
+	}
+
+	// Pattern properties apply to declared properties as well, so a new pattern is
+	// compared with a schema that accepted anything
+	prevPatterns, _ := prev["patternProperties"].(map[string]interface{})
+	nextPatterns, _ := curr["patternProperties"].(map[string]interface{})
+	for _, pattern := range sortedKeys(keySet(prevPatterns)) {
+		if nextPattern, ok := nextPatterns[pattern]; ok {
+			c.compareSchemas(path.matching(pattern), prevPatterns[pattern], nextPattern)
+			continue
+		}
+		if closedObject(curr) {
+			c.report(path.matching(pattern), "patternProperties", "properties matching the pattern are no longer allowed")
+		} else if nextAdditional, ok := curr["additionalProperties"]; ok {
+			c.compareSchemas(path.matching(pattern), prevPatterns[pattern], nextAdditional)
+		}
+	}
+	for _, pattern := range sortedKeys(keySet(nextPatterns)) {
+		if _, ok := prevPatterns[pattern]; !ok {
+			c.compareSchemas(path.matching(pattern), true, nextPatterns[pattern])
+		}
+	}
+
+	// Property names constrain the names rather than the values of properties
+	if nextNames, ok := curr["propertyNames"]; ok {
+		c.compareNested(path, "propertyNames", "property names", optionalSchema(prev, "propertyNames"), nextNames)
+	}
+
+	// Array items: tuple positions first, then the items after them. Items missing from
+	// the previous version accepted any value.
+	prevTuple, prevRest := arrayItems(prev)
+	nextTuple, nextRest := arrayItems(curr)
+	for i, nextItem := range nextTuple {
+		var prevItem interface{} = true
+		if i < len(prevTuple) {
+			prevItem = prevTuple[i]
+		} else if prevRest != nil {
+			prevItem = prevRest
+		}
+		c.compareSchemas(path.position(i), prevItem, nextItem)
+	}
+	if nextRest != nil {
+		for i := len(nextTuple); i < len(prevTuple); i++ {
+			c.compareSchemas(path.position(i), prevTuple[i], nextRest)
+		}
+		if prevRest == nil {
+			prevRest = true
+		}
+		c.compareSchemas(path.items(), prevRest, nextRest)
+	}
+
+	// Contained items: arrays without an item matching the new schema are rejected
+	if nextContains, ok := curr["contains"]; ok {
+		if prevContains, hadContains := prev["contains"]; hadContains {
+			c.compareNested(path, "contains", "contained items", prevContains, nextContains)
+		} else {
+			c.report(path, "contains", "array must contain a matching item")
+		}
+	}
+}
+
+// compareNested compares subschemas that constrain something other than the values at
+// path, such as their property names, and reports each narrowing change under keyword
+func (c *schemaComparer) compareNested(path schemaPath, keyword, subject string, previous, next interface{}) {
+	issues := c.issues
+	c.issues = []errors.ValidationError{}
+	c.compareSchemas(path.matching(subject), previous, next)
+	nested := c.issues
+	c.issues = issues
+
+	for _, issue := range nested {
+		c.report(path, keyword, "%s: %s", subject, issue.Reason)
+	}
+}
+
+// equivalent reports whether two values of a keyword are the same once local references
+// are followed
+func (c *schemaComparer) equivalent(previous, next interface{}) bool {
+	switch prev := previous.(type) {
+	case map[string]interface{}:
+		curr, ok := next.(map[string]interface{})
+		if !ok || len(prev) != len(curr) {
+			return false
+		}
+		for key, value := range prev {
+			nextValue, ok := curr[key]
+			if !ok {
+				return false
+			}
+			if ref, isRef := value.(string); isRef && key == "$ref" {
+				if !c.sameReference(ref, nextValue) {
+					return false
+				}
+			} else if !c.equivalent(value, nextValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		curr, ok := next.([]interface{})
+		if !ok || len(prev) != len(curr) {
+			return false
+		}
+		for i := range prev {
+			if !c.equivalent(prev[i], curr[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(previous, next)
+	}
+}
+
+// sameReference reports whether a reference of the previous version and a value of the
+// new one refer to equivalent schemas. Recursive references are assumed equivalent
+// while their targets are being compared.
+func (c *schemaComparer) sameReference(ref string, next interface{}) bool {
+	if nextRef, ok := next.(string); !ok || nextRef != ref {
+		return false
+	}
+	prevTarget, prevLocal := resolveLocalRef(c.prevRoot, ref)
+	nextTarget, nextLocal := resolveLocalRef(c.nextRoot, ref)
+	if !prevLocal || !nextLocal {
+		return prevLocal == nextLocal
+	}
+	if c.equating[ref] {
+		return true
+	}
+	c.equating[ref] = true
+	defer delete(c.equating, ref)
+	return c.equivalent(prevTarget, nextTarget)
+}
+
+// resolveLocalRef resolves a reference to a location in the same document, such as
+// "#/$defs/port". References to other documents and to anchors are not resolved.
+func resolveLocalRef(root interface{}, ref string) (interface{}, bool) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, false
+	}
+
+	target := root
+	if fragment == "" {
+		return target, true
+	}
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := target.(type) {
+		case map[string]interface{}:
+			child, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			target = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			target = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return target, true
+}
+
+// arrayItems returns the schemas of the positions of a tuple, from prefixItems or an
+// items array, and the schema of the items after them, or nil when it is absent
+func arrayItems(schema map[string]interface{}) ([]interface{}, interface{}) {
+	if tuple, ok := schema["prefixItems"].([]interface{}); ok {
+		return tuple, schema["items"]
+	}
+	if tuple, ok := schema["items"].([]interface{}); ok {
+		return tuple, schema["additionalItems"]
+	}
+	return nil, schema["items"]
+}
+
+// optionalSchema returns the subschema under a keyword, or true, which accepts any value,
+// when it is absent
+func optionalSchema(schema map[string]interface{}, keyword string) interface{} {
+	if subschema, ok := schema[keyword]; ok {
+		return subschema
+	}
+	return true
+}
+
+// isMultiple reports whether every multiple of previous is a multiple of next
+func isMultiple(previous, next float64) bool {
+	quotient := previous / next
+	return next != 0 && math.Abs(quotient-math.Round(quotient)) < 1e-9
+}
+
+// closedObject reports whether a schema rejects properties it does not declare
+func closedObject(schema map[string]interface{}) bool {
+	for _, keyword := range []string{"additionalProperties", "unevaluatedProperties"} {
+		if allowed, ok := schema[keyword].(bool); ok && !allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// typeSet returns the JSON types allowed by a "type" keyword, or nil when it is absent
+func typeSet(value interface{}) map[string]bool {
+	switch t := value.(type) {
+	case string:
+		return map[string]bool{t: true}
+	case []interface{}:
+		return stringSet(t)
+	}
+	return nil
+}
+
+// stringSet converts a JSON array of strings into a set
+func stringSet(value interface{}) map[string]bool {
+	items, _ := value.([]interface{})
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// sortedKeys returns the members of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keySet returns the keys of an object
+func keySet(object map[string]interface{}) map[string]bool {
+	set := make(map[string]bool, len(object))
+	for key := range object {
+		set[key] = true
+	}
+	return set
+}
+
+// containsValue reports whether a JSON array contains a value
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// childField names a property nested under field, matching the validator's field paths
+func childField(field, name string) string {
+	if field == RootField {
+		return name
+	}
+	return field + "." + name
+}
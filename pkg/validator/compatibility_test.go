@@ -0,0 +1,234 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBackwardCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		next     string
+		fields   []string
+	}{
+		{
+			name:     "Identical",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+		},
+		{
+			name:     "PropertyAddedToClosedObject",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"}},"additionalProperties":false}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"},"name":{"type":"string"}},"additionalProperties":false}`,
+		},
+		{
+			name:     "UnconstrainedPropertyAddedToOpenObject",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"},"name":{"description":"Display name"}}}`,
+		},
+		{
+			name:     "TypedPropertyAddedToOpenObject",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"},"name":{"type":"string"}}}`,
+			fields:   []string{"name"},
+		},
+		{
+			name:     "PropertyAddedMatchingAdditionalProperties",
+			previous: `{"type":"object","additionalProperties":{"type":"string"}}`,
+			next:     `{"type":"object","properties":{"name":{"type":"string","maxLength":10}},"additionalProperties":{"type":"string"}}`,
+			fields:   []string{"name"},
+		},
+		{
+			name:     "PropertyRemovedFromClosedObject",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"},"name":{"type":"string"}},"additionalProperties":false}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"}},"additionalProperties":false}`,
+			fields:   []string{"name"},
+		},
+		{
+			name:     "PropertyRemovedFromOpenObject",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"},"name":{"type":"string"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+		},
+		{
+			name:     "TypeWidened",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer","maximum":10}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":["number","null"],"maximum":20}}}`,
+		},
+		{
+			name:     "NewlyRequired",
+			previous: `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"}},"required":["limit"]}`,
+			fields:   []string{"limit"},
+		},
+		{
+			name:     "TypeNarrowed",
+			previous: `{"type":"object","properties":{"limit":{"type":"number"}}}`,
+			next:     `{"type":"object","properties":{"limit":{"type":"integer"}}}`,
+			fields:   []string{"limit"},
+		},
+		{
+			name:     "NestedBoundsTightened",
+			previous: `{"type":"object","properties":{"db":{"type":"object","properties":{"pool":{"type":"integer","minimum":1}}}}}`,
+			next:     `{"type":"object","properties":{"db":{"type":"object","properties":{"pool":{"type":"integer","minimum":5,"maximum":50}}}}}`,
+			fields:   []string{"db.pool", "db.pool"},
+		},
+		{
+			name:     "EnumValueRemoved",
+			previous: `{"type":"object","properties":{"mode":{"enum":["a","b"]}}}`,
+			next:     `{"type":"object","properties":{"mode":{"enum":["a"]}}}`,
+			fields:   []string{"mode"},
+		},
+		{
+			name:     "AdditionalPropertiesClosed",
+			previous: `{"type":"object"}`,
+			next:     `{"type":"object","additionalProperties":false}`,
+			fields:   []string{"(root)"},
+		},
+		{
+			name:     "UnevaluatedPropertiesClosed",
+			previous: `{"type":"object","unevaluatedProperties":true}`,
+			next:     `{"type":"object","unevaluatedProperties":false}`,
+			fields:   []string{"(root)"},
+		},
+		{
+			name:     "DependentRequiredAdded",
+			previous: `{"type":"object","dependentRequired":{"tls":["cert"]}}`,
+			next:     `{"type":"object","dependentRequired":{"tls":["cert","key"],"proxy":["host"]}}`,
+			fields:   []string{"host", "key"},
+		},
+		{
+			name:     "CombinatorChanged",
+			previous: `{"type":"object","properties":{"port":{"anyOf":[{"type":"integer"},{"type":"string"}]}}}`,
+			next:     `{"type":"object","properties":{"port":{"anyOf":[{"type":"integer"}]}}}`,
+			fields:   []string{"port"},
+		},
+		{
+			name:     "ReferenceChanged",
+			previous: `{"type":"object","properties":{"db":{"$ref":"database"}}}`,
+			next:     `{"type":"object","properties":{"db":{"$ref":"database-v2"}}}`,
+			fields:   []string{"db"},
+		},
+		{
+			name:     "ArrayItemsNarrowed",
+			previous: `{"type":"array","items":{"type":["string","integer"]}}`,
+			next:     `{"type":"array","items":{"type":"string"}}`,
+			fields:   []string{"(root)[]"},
+		},
+		{
+			name:     "DefinitionNarrowedBehindReference",
+			previous: `{"type":"object","properties":{"port":{"$ref":"#/$defs/port"}},"$defs":{"port":{"type":"integer","maximum":65535}}}`,
+			next:     `{"type":"object","properties":{"port":{"$ref":"#/$defs/port"}},"$defs":{"port":{"type":"integer","maximum":100}}}`,
+			fields:   []string{"port"},
+		},
+		{
+			name:     "DefinitionWidenedBehindReference",
+			previous: `{"type":"object","properties":{"port":{"$ref":"#/definitions/port"}},"definitions":{"port":{"type":"integer","maximum":100}}}`,
+			next:     `{"type":"object","properties":{"port":{"$ref":"#/definitions/port"}},"definitions":{"port":{"type":"integer","maximum":65535}}}`,
+		},
+		{
+			name:     "RecursiveDefinition",
+			previous: `{"$ref":"#/$defs/node","$defs":{"node":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/node"}}}}}}`,
+			next:     `{"$ref":"#/$defs/node","$defs":{"node":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/node"}},"name":{"type":"string"}}}}}`,
+			fields:   []string{"name"},
+		},
+		{
+			name:     "DefinitionNarrowedBehindAllOf",
+			previous: `{"allOf":[{"$ref":"#/$defs/base"}],"$defs":{"base":{"type":"object","properties":{"name":{"type":"string"}}}}}`,
+			next:     `{"allOf":[{"$ref":"#/$defs/base"}],"$defs":{"base":{"type":"object","properties":{"name":{"type":"string","minLength":3}}}}}`,
+			fields:   []string{"name"},
+		},
+		{
+			name:     "DefinitionUnchangedBehindAnyOf",
+			previous: `{"anyOf":[{"$ref":"#/$defs/host"},{"type":"null"}],"$defs":{"host":{"type":"string"}},"description":"Host"}`,
+			next:     `{"anyOf":[{"$ref":"#/$defs/host"},{"type":"null"}],"$defs":{"host":{"type":"string"}},"description":"Host name"}`,
+		},
+		{
+			name:     "PatternPropertiesNarrowed",
+			previous: `{"type":"object","patternProperties":{"^x-":{"type":"string"}}}`,
+			next:     `{"type":"object","patternProperties":{"^x-":{"type":"string","maxLength":8},"^y-":{"type":"integer"}}}`,
+			fields:   []string{"^x-", "^y-"},
+		},
+		{
+			name:     "MultipleOfChanged",
+			previous: `{"type":"object","properties":{"step":{"type":"number","multipleOf":2}}}`,
+			next:     `{"type":"object","properties":{"step":{"type":"number","multipleOf":3}}}`,
+			fields:   []string{"step"},
+		},
+		{
+			name:     "MultipleOfRelaxed",
+			previous: `{"type":"object","properties":{"step":{"type":"number","multipleOf":0.5}}}`,
+			next:     `{"type":"object","properties":{"step":{"type":"number","multipleOf":0.1}}}`,
+		},
+		{
+			name:     "PrefixItemsNarrowed",
+			previous: `{"type":"array","prefixItems":[{"type":"string"},{"type":"integer"}]}`,
+			next:     `{"type":"array","prefixItems":[{"type":"string"},{"type":"integer","minimum":0}],"items":false}`,
+			fields:   []string{"(root)[1]", "(root)[]"},
+		},
+		{
+			name:     "DependentSchemasAdded",
+			previous: `{"type":"object","properties":{"tls":{"type":"boolean"}}}`,
+			next:     `{"type":"object","properties":{"tls":{"type":"boolean"}},"dependentSchemas":{"tls":{"required":["cert"]}}}`,
+			fields:   []string{"cert"},
+		},
+		{
+			name:     "PropertyNamesNarrowed",
+			previous: `{"type":"object","propertyNames":{"maxLength":32}}`,
+			next:     `{"type":"object","propertyNames":{"maxLength":16}}`,
+			fields:   []string{"(root)"},
+		},
+		{
+			name:     "ContainsAdded",
+			previous: `{"type":"array","items":{"type":"string"}}`,
+			next:     `{"type":"array","items":{"type":"string"},"contains":{"const":"default"}}`,
+			fields:   []string{"(root)"},
+		},
+		{
+			name:     "UniqueItemsRequired",
+			previous: `{"type":"array","items":{"type":"string"}}`,
+			next:     `{"type":"array","items":{"type":"string"},"uniqueItems":true}`,
+			fields:   []string{"(root)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := CheckBackwardCompatibility(json.RawMessage(tt.previous), json.RawMessage(tt.next))
+			require.NoError(t, err)
+
+			fields := []string{}
+			for _, issue := range issues {
+				fields = append(fields, issue.Field)
+			}
+			if tt.fields == nil {
+				tt.fields = []string{}
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+
+	t.Run("Pointers", func(t *testing.T) {
+		issues, err := CheckBackwardCompatibility(
+			json.RawMessage(`{"type":"object","properties":{"tls/ssl":{"type":"object","properties":{"port":{"type":"number"}}},"hosts":{"type":"array","items":{"type":"string"}}}}`),
+			json.RawMessage(`{"type":"object","properties":{"tls/ssl":{"type":"object","properties":{"port":{"type":"integer"}}},"hosts":{"type":"array","items":{"type":"string","maxLength":64}}}}`),
+		)
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+
+		assert.Equal(t, "hosts[]", issues[0].Field)
+		assert.Empty(t, issues[0].Pointer, "array items have no single pointer")
+		assert.Equal(t, "maxLength", issues[0].Keyword)
+		assert.Equal(t, "tls/ssl.port", issues[1].Field)
+		assert.Equal(t, "/tls~1ssl/port", issues[1].Pointer)
+		assert.Equal(t, "type", issues[1].Keyword)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := CheckBackwardCompatibility(json.RawMessage(`{}`), json.RawMessage(`{`))
+		assert.Error(t, err)
+	})
+}
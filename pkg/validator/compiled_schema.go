@@ -0,0 +1,221 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// rootSchemaURL is the resource URL a schema is compiled under; shared schema
+// fragments are compiled under their shared:// URLs
+const rootSchemaURL = "config:///schema.json"
+
+// CompiledSchema is a schema compiled together with the shared schema fragments it
+// references
+type CompiledSchema struct {
+	schema *jsonschema.Schema
+	// documents holds the parsed schema documents by resource URL, so that failing
+	// keywords can be read when reporting validation errors
+	documents map[string]interface{}
+}
+
+// compileSchema compiles a schema together with the shared schema fragments it
+// references. The draft is selected by each document's $schema; documents without
+// one are compiled as draft-07. Formats are asserted for drafts 2019-09 and 2020-12
+// only when strictFormats is set; earlier drafts always assert them.
+func compileSchema(ctx context.Context, schema json.RawMessage, load SharedSchemaLoader, strictFormats bool) (*CompiledSchema, error) {
+	fragments, err := ResolveSharedSchemas(ctx, schema, load)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = legacyDraft
+	compiler.AssertFormat = strictFormats
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("loading %s is not allowed", url)
+	}
+
+	compiled := &CompiledSchema{documents: map[string]interface{}{}}
+	addResource := func(url string, document json.RawMessage) error {
+		parsed, err := decodeJSON(document)
+		if err != nil {
+			return err
+		}
+		if object, ok := parsed.(map[string]interface{}); ok {
+			if declared, ok := object["$schema"]; ok {
+				url, _ := declared.(string)
+				if _, ok := lookupDraft(url); !ok {
+					return fmt.Errorf("unsupported $schema %q", url)
+				}
+			}
+		}
+		compiled.documents[url] = parsed
+		return compiler.AddResource(url, bytes.NewReader(document))
+	}
+
+	names := make([]string, 0, len(fragments))
+	for name := range fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := addResource(SharedSchemaPrefix+name, fragments[name]); err != nil {
+			return nil, fmt.Errorf("shared schema %q: %w", name, err)
+		}
+	}
+	if err := addResource(rootSchemaURL, schema); err != nil {
+		return nil, err
+	}
+
+	compiled.schema, err = compiler.Compile(rootSchemaURL)
+	if err != nil {
+		return nil, compileError(err)
+	}
+
+	return compiled, nil
+}
+
+// compileError strips the library's framing from a compilation error
+func compileError(err error) error {
+	var schemaErr *jsonschema.SchemaError
+	if !stdErrors.As(err, &schemaErr) || schemaErr.Err == nil {
+		return err
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if stdErrors.As(schemaErr.Err, &validationErr) {
+		leaf := validationErr
+		for len(leaf.Causes) > 0 {
+			leaf = leaf.Causes[0]
+		}
+		location := leaf.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return fmt.Errorf("%s at %s", leaf.Message, location)
+	}
+
+	return fmt.Errorf("%s", strings.TrimPrefix(schemaErr.Err.Error(), "jsonschema: "))
+}
+
+// validate validates JSON data against the compiled schema
+func (s *CompiledSchema) validate(data json.RawMessage) error {
+	// Parse data
+	document, err := decodeJSON(data)
+	if err != nil {
+		return errors.NewInternalError("Failed to validate JSON", err.Error())
+	}
+
+	// Validate
+	err = s.schema.Validate(document)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !stdErrors.As(err, &validationErr) {
+		return errors.NewInternalError("Failed to validate JSON", err.Error())
+	}
+
+	// Collect validation errors
+	validationErrors := make([]errors.ValidationError, 0)
+	for _, failure := range failures(validationErr) {
+		validationErrors = append(validationErrors, s.describeFailure(failure, document)...)
+	}
+
+	return errors.NewValidationFailedError(
+		"JSON validation failed",
+		validationErrors,
+	)
+}
+
+// failures flattens a validation error tree into the failures to report. Errors that
+// only group other errors are replaced by their causes; combinators such as anyOf are
+// reported themselves, since the failures of their alternatives are not errors.
+func failures(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	switch keywordAt(err.KeywordLocation) {
+	case "anyOf", "oneOf", "contains", "minContains":
+		return []*jsonschema.ValidationError{err}
+	}
+
+	result := []*jsonschema.ValidationError{}
+	for _, cause := range err.Causes {
+		result = append(result, failures(cause)...)
+	}
+	return result
+}
+
+// decodeJSON parses a JSON value the way the schema library expects, keeping numbers
+// as json.Number
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return value, nil
+}
+
+// lookup reads the value an absolute schema location ("url#/pointer") refers to
+func (s *CompiledSchema) lookup(location string) (interface{}, bool) {
+	url, pointer, _ := strings.Cut(location, "#")
+	document, ok := s.documents[url]
+	if !ok {
+		return nil, false
+	}
+	return resolvePointer(document, pointer)
+}
+
+// resolvePointer reads the value an RFC 6901 JSON Pointer refers to within a document
+func resolvePointer(document interface{}, pointer string) (interface{}, bool) {
+	value := document
+	for _, token := range pointerTokens(pointer) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// pointerTokens splits an RFC 6901 JSON Pointer into unescaped reference tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return []string{}
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens
+}
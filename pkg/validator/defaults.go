@@ -0,0 +1,180 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxRefHops bounds how many references are followed without descending into the
+// data, so that reference cycles cannot loop forever
+const maxRefHops = 32
+
+// defaultsResolver follows the references of a schema while applying defaults
+type defaultsResolver struct {
+	// fragments holds the parsed shared schema fragments by name
+	fragments map[string]interface{}
+	defaulted []string
+}
+
+// ApplyDefaults fills properties that are missing from data with the default values
+// their schemas declare, and returns the data together with the JSON Pointers of the
+// filled properties. Defaults are found through properties, items, prefixItems and
+// allOf, following local and shared:// references; subschemas that only apply
+// conditionally (anyOf, oneOf, if/then/else) are not consulted. Data that receives no
+// defaults is returned unchanged.
+func ApplyDefaults(ctx context.Context, schema json.RawMessage, data json.RawMessage, load SharedSchemaLoader) (json.RawMessage, []string, error) {
+	root, err := decodeJSON(schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	document, err := decodeJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid data: %w", err)
+	}
+
+	fragments, err := ResolveSharedSchemas(ctx, schema, load)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := &defaultsResolver{fragments: map[string]interface{}{}, defaulted: []string{}}
+	for name, fragment := range fragments {
+		if r.fragments[name], err = decodeJSON(fragment); err != nil {
+			return nil, nil, fmt.Errorf("shared schema %q: %w", name, err)
+		}
+	}
+
+	r.apply(root, root, document, []string{}, 0)
+	if len(r.defaulted) == 0 {
+		return data, r.defaulted, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), r.defaulted, nil
+}
+
+// apply fills the defaults a schema declares for a value. base is the document the
+// schema belongs to, against which its local references are resolved.
+func (r *defaultsResolver) apply(base, schema, value interface{}, path []string, hops int) {
+	node, _ := schema.(map[string]interface{})
+	if node == nil {
+		return
+	}
+
+	if ref, ok := node["$ref"].(string); ok && hops < maxRefHops {
+		if refBase, target, ok := r.resolveRef(base, ref); ok {
+			r.apply(refBase, target, value, path, hops+1)
+		}
+	}
+	if allOf, ok := node["allOf"].([]interface{}); ok && hops < maxRefHops {
+		for _, sub := range allOf {
+			r.apply(base, sub, value, path, hops+1)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := node["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			propertyPath := append(append([]string{}, path...), name)
+			if _, ok := v[name]; !ok {
+				if def, ok := r.defaultOf(base, properties[name]); ok {
+					v[name] = def
+					r.defaulted = append(r.defaulted, pointerFromSegments(propertyPath))
+				}
+			}
+			if child, ok := v[name]; ok {
+				r.apply(base, properties[name], child, propertyPath, 0)
+			}
+		}
+
+	case []interface{}:
+		// prefixItems (2020-12) and array-form items (earlier drafts) describe items by position
+		positional, _ := node["prefixItems"].([]interface{})
+		if items, ok := node["items"].([]interface{}); ok {
+			positional = items
+		}
+		for i, item := range v {
+			itemPath := append(append([]string{}, path...), fmt.Sprint(i))
+			if i < len(positional) {
+				r.apply(base, positional[i], item, itemPath, 0)
+			} else if items, ok := node["items"].(map[string]interface{}); ok {
+				r.apply(base, items, item, itemPath, 0)
+			}
+		}
+	}
+}
+
+// defaultOf returns a copy of the default value a schema declares, following references
+func (r *defaultsResolver) defaultOf(base, schema interface{}) (interface{}, bool) {
+	for hops := 0; hops < maxRefHops; hops++ {
+		node, _ := schema.(map[string]interface{})
+		if node == nil {
+			return nil, false
+		}
+		if def, ok := node["default"]; ok {
+			return copyValue(def), true
+		}
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return nil, false
+		}
+		if base, schema, ok = r.resolveRef(base, ref); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// resolveRef finds the schema a reference points to: a JSON Pointer within the
+// current document or a shared schema fragment, optionally followed by a pointer
+func (r *defaultsResolver) resolveRef(base interface{}, ref string) (interface{}, interface{}, bool) {
+	location, pointer, _ := strings.Cut(ref, "#")
+	if location != "" {
+		if !strings.HasPrefix(location, SharedSchemaPrefix) {
+			return nil, nil, false
+		}
+		fragment, ok := r.fragments[strings.TrimPrefix(location, SharedSchemaPrefix)]
+		if !ok {
+			return nil, nil, false
+		}
+		base = fragment
+	}
+
+	target, ok := resolvePointer(base, pointer)
+	return base, target, ok
+}
+
+// copyValue deep-copies a decoded JSON value so that defaults are never shared
+// between the schema and documents
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		data      string
+		expected  string
+		defaulted []string
+	}{
+		{
+			name:      "TopLevel",
+			schema:    `{"properties":{"enabled":{"type":"boolean","default":true},"limit":{"default":10}}}`,
+			data:      `{"limit":5}`,
+			expected:  `{"enabled":true,"limit":5}`,
+			defaulted: []string{"/enabled"},
+		},
+		{
+			name:      "NestedObjectDefault",
+			schema:    `{"properties":{"db":{"default":{},"properties":{"pool":{"default":4},"host":{"type":"string"}}}}}`,
+			data:      `{}`,
+			expected:  `{"db":{"pool":4}}`,
+			defaulted: []string{"/db", "/db/pool"},
+		},
+		{
+			name:      "ArrayItems",
+			schema:    `{"properties":{"servers":{"items":{"properties":{"port":{"default":80}}}}}}`,
+			data:      `{"servers":[{"port":8080},{}]}`,
+			expected:  `{"servers":[{"port":8080},{"port":80}]}`,
+			defaulted: []string{"/servers/1/port"},
+		},
+		{
+			name:      "PrefixItems",
+			schema:    `{"prefixItems":[{"properties":{"a":{"default":1}}}],"items":{"properties":{"b":{"default":2}}}}`,
+			data:      `[{},{}]`,
+			expected:  `[{"a":1},{"b":2}]`,
+			defaulted: []string{"/0/a", "/1/b"},
+		},
+		{
+			name:      "LocalReference",
+			schema:    `{"$defs":{"port":{"type":"integer","default":443}},"properties":{"port":{"$ref":"#/$defs/port"}}}`,
+			data:      `{}`,
+			expected:  `{"port":443}`,
+			defaulted: []string{"/port"},
+		},
+		{
+			name:      "AllOf",
+			schema:    `{"allOf":[{"properties":{"region":{"default":"eu-west-1"}}}]}`,
+			data:      `{}`,
+			expected:  `{"region":"eu-west-1"}`,
+			defaulted: []string{"/region"},
+		},
+		{
+			name:      "ConditionalSchemasIgnored",
+			schema:    `{"anyOf":[{"properties":{"a":{"default":1}}}],"oneOf":[{"properties":{"b":{"default":2}}}]}`,
+			data:      `{}`,
+			expected:  `{}`,
+			defaulted: []string{},
+		},
+		{
+			name:      "PresentNullKept",
+			schema:    `{"properties":{"a":{"default":1}}}`,
+			data:      `{"a":null}`,
+			expected:  `{"a":null}`,
+			defaulted: []string{},
+		},
+		{
+			name:      "EscapedPointer",
+			schema:    `{"properties":{"a/b":{"default":"<x>"}}}`,
+			data:      `{}`,
+			expected:  `{"a/b":"<x>"}`,
+			defaulted: []string{"/a~1b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, defaulted, err := ApplyDefaults(context.Background(), json.RawMessage(tt.schema), json.RawMessage(tt.data), nil)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+			assert.Equal(t, tt.defaulted, defaulted)
+		})
+	}
+
+	t.Run("UnchangedDataKeepsFormatting", func(t *testing.T) {
+		original := json.RawMessage("{\n  \"limit\": 5\n}")
+		data, defaulted, err := ApplyDefaults(context.Background(), json.RawMessage(`{"properties":{"limit":{"default":1}}}`), original, nil)
+		require.NoError(t, err)
+		assert.Empty(t, defaulted)
+		assert.Equal(t, string(original), string(data))
+	})
+
+	t.Run("KeepsNumberPrecision", func(t *testing.T) {
+		data, _, err := ApplyDefaults(context.Background(), json.RawMessage(`{"properties":{"b":{"default":true}}}`), json.RawMessage(`{"a":12345678901234567890}`), nil)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":12345678901234567890,"b":true}`, string(data))
+	})
+
+	t.Run("SharedSchema", func(t *testing.T) {
+		load := func(ctx context.Context, name string) (json.RawMessage, error) {
+			if name == "common/timeouts" {
+				return json.RawMessage(`{"properties":{"connect":{"default":"5s"}}}`), nil
+			}
+			return nil, fmt.Errorf("unknown shared schema %q", name)
+		}
+		data, defaulted, err := ApplyDefaults(context.Background(),
+			json.RawMessage(`{"properties":{"timeouts":{"$ref":"shared://common/timeouts"}}}`),
+			json.RawMessage(`{"timeouts":{}}`), load)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeouts":{"connect":"5s"}}`, string(data))
+		assert.Equal(t, []string{"/timeouts/connect"}, defaulted)
+	})
+
+	t.Run("ReferenceCycle", func(t *testing.T) {
+		_, _, err := ApplyDefaults(context.Background(), json.RawMessage(`{"$defs":{"a":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`), json.RawMessage(`{}`), nil)
+		assert.NoError(t, err)
+	})
+}
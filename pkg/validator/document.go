@@ -0,0 +1,234 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RootField is the field path validation errors report for the document itself
+const RootField = "(root)"
+
+// Position is a 1-based line and column in a JSON document. Columns count characters,
+// not bytes.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Offset translates a position within a value that starts at start in an enclosing
+// document into a position in that document
+func (p Position) Offset(start Position) Position {
+	if p.Line == 1 {
+		return Position{Line: start.Line, Column: start.Column + p.Column - 1}
+	}
+	return Position{Line: start.Line + p.Line - 1, Column: p.Column}
+}
+
+// SyntaxError reports where a JSON document stops being well-formed
+type SyntaxError struct {
+	Position
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Document is a JSON document that maps the field paths reported by validation back
+// to JSON Pointers and positions in its source text
+type Document struct {
+	data []byte
+	root *documentNode
+}
+
+// documentNode is a JSON value and where it starts in the source text
+type documentNode struct {
+	offset int
+	keys   map[string]*documentNode
+	items  []*documentNode
+}
+
+// ParseDocument parses a JSON document, returning a *SyntaxError with the position of
+// the first problem if it is not well-formed
+func ParseDocument(data []byte) (*Document, error) {
+	if err := json.Unmarshal(data, new(interface{})); err != nil {
+		// The decoder reports the offset after the offending byte; an unexpected end
+		// of input is reported at the end of the document
+		offset := len(data)
+		var syntaxErr *json.SyntaxError
+		if stdErrors.As(err, &syntaxErr) && !strings.Contains(syntaxErr.Error(), "end of JSON input") {
+			offset = int(syntaxErr.Offset) - 1
+		}
+		message := strings.TrimPrefix(err.Error(), "json: ")
+		return nil, &SyntaxError{Position: positionAt(data, offset), Message: message}
+	}
+
+	s := &documentScanner{data: data}
+	root := s.value()
+	return &Document{data: data, root: root}, nil
+}
+
+// Locate resolves a dotted field path such as "servers.0.host" or "(root)" to an
+// RFC 6901 JSON Pointer and the position of the value in the document. Object keys
+// that contain dots are matched as a whole. When part of the path does not exist, as
+// for a missing required property, the deepest existing value is located.
+func (d *Document) Locate(field string) (string, Position) {
+	node := d.root
+	pointer := ""
+
+	field = strings.TrimPrefix(strings.TrimPrefix(field, RootField), ".")
+	segments := []string{}
+	if field != "" {
+		segments = strings.Split(field, ".")
+	}
+
+	for len(segments) > 0 {
+		next, consumed, token := node.child(segments)
+		if next == nil {
+			break
+		}
+		node = next
+		pointer += "/" + escapePointerToken(token)
+		segments = segments[consumed:]
+	}
+
+	return pointer, positionAt(d.data, node.offset)
+}
+
+// LocatePointer finds the position of the value an RFC 6901 JSON Pointer refers to.
+// When part of the pointer does not exist, as for a missing required property, the
+// deepest existing value is located.
+func (d *Document) LocatePointer(pointer string) Position {
+	node := d.root
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			next, _, _ := node.child([]string{token})
+			if next == nil {
+				break
+			}
+			node = next
+		}
+	}
+
+	return positionAt(d.data, node.offset)
+}
+
+// child finds the value the leading path segments refer to, preferring the longest
+// object key so that keys containing dots are matched
+func (n *documentNode) child(segments []string) (*documentNode, int, string) {
+	if n.keys != nil {
+		for i := len(segments); i > 0; i-- {
+			key := strings.Join(segments[:i], ".")
+			if child, ok := n.keys[key]; ok {
+				return child, i, key
+			}
+		}
+		return nil, 0, ""
+	}
+	if n.items != nil {
+		index, err := strconv.Atoi(segments[0])
+		if err == nil && index >= 0 && index < len(n.items) {
+			return n.items[index], 1, segments[0]
+		}
+	}
+	return nil, 0, ""
+}
+
+// escapePointerToken escapes a reference token as RFC 6901 requires
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// positionAt converts a byte offset into a line and column
+func positionAt(data []byte, offset int) Position {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	prefix := data[:offset]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(prefix, '\n') + 1
+	return Position{Line: line, Column: utf8.RuneCount(prefix[lineStart:]) + 1}
+}
+
+// documentScanner records where the values of a well-formed JSON document start
+type documentScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *documentScanner) value() *documentNode {
+	s.skipSpace()
+	node := &documentNode{offset: s.pos}
+
+	switch s.peek() {
+	case '{':
+		node.keys = map[string]*documentNode{}
+		s.pos++
+		for s.skipSpace(); s.peek() != '}' && s.peek() != 0; s.skipSpace() {
+			key := s.key()
+			s.skipSpace()
+			s.pos++ // ':'
+			node.keys[key] = s.value()
+			s.skipSpace()
+			if s.peek() == ',' {
+				s.pos++
+			}
+		}
+		s.pos++
+	case '[':
+		node.items = []*documentNode{}
+		s.pos++
+		for s.skipSpace(); s.peek() != ']' && s.peek() != 0; s.skipSpace() {
+			node.items = append(node.items, s.value())
+			s.skipSpace()
+			if s.peek() == ',' {
+				s.pos++
+			}
+		}
+		s.pos++
+	case '"':
+		s.key()
+	default:
+		for s.pos < len(s.data) && !strings.ContainsRune(",]} \t\r\n", rune(s.data[s.pos])) {
+			s.pos++
+		}
+	}
+
+	return node
+}
+
+// key reads a string token and returns its decoded value
+func (s *documentScanner) key() string {
+	start := s.pos
+	s.pos++
+	for s.pos < len(s.data) && s.data[s.pos] != '"' {
+		if s.data[s.pos] == '\\' {
+			s.pos++
+		}
+		s.pos++
+	}
+	s.pos++
+
+	var key string
+	_ = json.Unmarshal(s.data[start:s.pos], &key)
+	return key
+}
+
+func (s *documentScanner) skipSpace() {
+	for s.pos < len(s.data) && strings.ContainsRune(" \t\r\n", rune(s.data[s.pos])) {
+		s.pos++
+	}
+}
+
+func (s *documentScanner) peek() byte {
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
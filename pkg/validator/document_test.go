@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDocument(t *testing.T) {
+	data := []byte(`{
+  "name": "payments",
+  "servers": [
+    {"host": "a.example.com"},
+    {"host": "b.example.com", "port": "8080"}
+  ],
+  "feature.flags": {"beta": true},
+  "a/b": 1
+}`)
+
+	doc, err := ParseDocument(data)
+	require.NoError(t, err)
+
+	tests := []struct {
+		field   string
+		pointer string
+		line    int
+		column  int
+	}{
+		{"(root)", "", 1, 1},
+		{"name", "/name", 2, 11},
+		{"servers.1.port", "/servers/1/port", 5, 39},
+		{"feature.flags.beta", "/feature.flags/beta", 7, 29},
+		{"a/b", "/a~1b", 8, 10},
+		{"servers.0.missing", "/servers/0", 4, 5},
+		{"servers.7", "/servers", 3, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			pointer, position := doc.Locate(tt.field)
+			assert.Equal(t, tt.pointer, pointer)
+			assert.Equal(t, Position{Line: tt.line, Column: tt.column}, position)
+		})
+	}
+}
+
+func TestParseDocument_SyntaxError(t *testing.T) {
+	_, err := ParseDocument([]byte("{\n  \"name\": \"payments\",\n  \"limit\": ,\n}"))
+
+	require.Error(t, err)
+	syntaxErr, ok := err.(*SyntaxError)
+	require.True(t, ok)
+	assert.Equal(t, 3, syntaxErr.Line)
+	assert.Equal(t, 12, syntaxErr.Column)
+	assert.Contains(t, syntaxErr.Error(), "line 3, column 12")
+
+	_, err = ParseDocument([]byte("{\n  \"name\": \"payments\""))
+	require.Error(t, err)
+	syntaxErr, ok = err.(*SyntaxError)
+	require.True(t, ok)
+	assert.Equal(t, Position{Line: 2, Column: 21}, syntaxErr.Position)
+}
+
+func TestPosition_Offset(t *testing.T) {
+	start := Position{Line: 3, Column: 11}
+
+	// Values on the first line of the embedded document follow its start on the same
+	// line; later lines keep their own columns
+	assert.Equal(t, Position{Line: 3, Column: 11}, Position{Line: 1, Column: 1}.Offset(start))
+	assert.Equal(t, Position{Line: 3, Column: 20}, Position{Line: 1, Column: 10}.Offset(start))
+	assert.Equal(t, Position{Line: 5, Column: 4}, Position{Line: 3, Column: 4}.Offset(start))
+}
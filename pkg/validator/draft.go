@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Draft2020URL is the $schema of JSON Schema draft 2020-12, the draft new schemas
+// default to
+const Draft2020URL = "https://json-schema.org/draft/2020-12/schema"
+
+// drafts maps the $schema URLs of the supported JSON Schema drafts to their
+// implementations; URLs are compared without a trailing "#" or scheme
+var drafts = map[string]*jsonschema.Draft{
+	"json-schema.org/draft-04/schema":      jsonschema.Draft4,
+	"json-schema.org/draft-06/schema":      jsonschema.Draft6,
+	"json-schema.org/draft-07/schema":      jsonschema.Draft7,
+	"json-schema.org/draft/2019-09/schema": jsonschema.Draft2019,
+	"json-schema.org/draft/2020-12/schema": jsonschema.Draft2020,
+}
+
+// legacyDraft is the draft of stored schemas that do not declare $schema. They were
+// registered when draft-07 was the only supported draft and keep validating as such.
+var legacyDraft = jsonschema.Draft7
+
+// SupportedDrafts returns the $schema URLs of the supported JSON Schema drafts
+func SupportedDrafts() []string {
+	return []string{
+		"http://json-schema.org/draft-04/schema#",
+		"http://json-schema.org/draft-06/schema#",
+		"http://json-schema.org/draft-07/schema#",
+		"https://json-schema.org/draft/2019-09/schema",
+		Draft2020URL,
+	}
+}
+
+// WithDefaultDraft declares draft 2020-12 in a schema that does not declare its draft
+// with $schema. The rest of the document is left byte for byte as it was. Schemas
+// declaring an unsupported draft are rejected.
+func WithDefaultDraft(schema json.RawMessage) (json.RawMessage, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(schema, &document); err != nil {
+		// Boolean schemas have no draft; anything else is not a schema
+		var allowed bool
+		if json.Unmarshal(schema, &allowed) == nil {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("schema must be a JSON object or boolean: %w", err)
+	}
+
+	if declared, ok := document["$schema"]; ok {
+		url, _ := declared.(string)
+		if _, ok := lookupDraft(url); !ok {
+			return nil, fmt.Errorf("unsupported $schema %q: supported drafts are %s", url, strings.Join(SupportedDrafts(), ", "))
+		}
+		return schema, nil
+	}
+
+	trimmed := bytes.TrimSpace(schema)
+	declaration := `"$schema":"` + Draft2020URL + `"`
+	if len(document) > 0 {
+		declaration += ","
+	}
+
+	stamped := make([]byte, 0, len(trimmed)+len(declaration))
+	stamped = append(stamped, '{')
+	stamped = append(stamped, declaration...)
+	stamped = append(stamped, trimmed[1:]...)
+	return stamped, nil
+}
+
+// lookupDraft finds the draft a $schema URL refers to
+func lookupDraft(url string) (*jsonschema.Draft, bool) {
+	url = strings.TrimSuffix(url, "#")
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	draft, ok := drafts[url]
+	return draft, ok
+}
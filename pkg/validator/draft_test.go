@@ -0,0 +1,225 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaCasesDir holds this service's own test cases, in the format of the official JSON
+// Schema test suite
+const schemaCasesDir = "testdata/schema-cases/tests"
+
+// suiteDir holds the official JSON Schema test suite, vendored with `make
+// json-schema-test-suite` at the commit recorded in its COMMIT file
+const suiteDir = "testdata/JSON-Schema-Test-Suite"
+
+// suiteDrafts maps the draft directories of the test suite to their $schema URLs
+var suiteDrafts = map[string]string{
+	"draft7":       "http://json-schema.org/draft-07/schema#",
+	"draft2019-09": "https://json-schema.org/draft/2019-09/schema",
+	"draft2020-12": Draft2020URL,
+}
+
+// knownSuiteFailures lists the suite files, relative to their draft directory, that the
+// validator deliberately does not pass, with the reason
+var knownSuiteFailures = map[string]string{
+	"refRemote.json":                 "schemas never load remote references",
+	"vocabulary.json":                "its metaschemas are remote references",
+	"optional/cross-draft.json":      "it references remote schemas of other drafts",
+	"optional/format-assertion.json": "its metaschemas are remote references",
+	"optional/ecmascript-regex.json": "patterns are Go regular expressions, not ECMA-262 ones",
+}
+
+// suiteGroup is a schema and the documents it is tested against
+type suiteGroup struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []struct {
+		Description string          `json:"description"`
+		Data        json.RawMessage `json:"data"`
+		Valid       bool            `json:"valid"`
+	} `json:"tests"`
+}
+
+func TestSchemaCases(t *testing.T) {
+	for dir, draft := range suiteDrafts {
+		runSuiteDir(t, schemaCasesDir, dir, draft)
+	}
+}
+
+func TestJSONSchemaTestSuite(t *testing.T) {
+	commit, err := os.ReadFile(filepath.Join(suiteDir, "COMMIT"))
+	if os.IsNotExist(err) {
+		t.Skip("the JSON Schema test suite is not vendored; run make json-schema-test-suite")
+	}
+	require.NoError(t, err)
+	t.Logf("JSON Schema test suite at %s", strings.TrimSpace(string(commit)))
+
+	for _, dir := range []string{"draft2019-09", "draft2020-12"} {
+		runSuiteDir(t, filepath.Join(suiteDir, "tests"), dir, suiteDrafts[dir])
+	}
+}
+
+// runSuiteDir runs every file of a draft directory of the test suite layout
+func runSuiteDir(t *testing.T, root, dir, draft string) {
+	err := filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		name, _ := filepath.Rel(filepath.Join(root, dir), path)
+		if reason, ok := knownSuiteFailures[filepath.ToSlash(name)]; ok {
+			t.Logf("skipping %s/%s: %s", dir, name, reason)
+			return nil
+		}
+		// Format assertion is optional in the suite; it is what strict formats enable
+		strict := strings.Contains(filepath.ToSlash(path), "/optional/format/")
+		runSuiteFile(t, root, path, draft, strict)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func runSuiteFile(t *testing.T, root, path, draft string, strict bool) {
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var groups []suiteGroup
+	require.NoError(t, json.Unmarshal(raw, &groups), path)
+
+	validator := NewJSONSchemaValidator(WithStrictFormats(strict))
+	name, _ := filepath.Rel(root, path)
+
+	for _, group := range groups {
+		schema := declareDraft(t, group.Schema, draft)
+		for _, test := range group.Tests {
+			t.Run(name+"/"+group.Description+"/"+test.Description, func(t *testing.T) {
+				err := validator.ValidateJSON(context.Background(), schema, test.Data)
+				if test.Valid {
+					assert.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok)
+				assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+			})
+		}
+	}
+}
+
+// declareDraft sets the $schema of a suite schema to the draft of its directory
+func declareDraft(t *testing.T, schema json.RawMessage, draft string) json.RawMessage {
+	var document map[string]interface{}
+	if json.Unmarshal(schema, &document) != nil {
+		return schema
+	}
+	document["$schema"] = draft
+	declared, err := json.Marshal(document)
+	require.NoError(t, err)
+	return declared
+}
+
+func TestWithDefaultDraft(t *testing.T) {
+	t.Run("DeclaresDraft2020", func(t *testing.T) {
+		schema, err := WithDefaultDraft(json.RawMessage(` {"type": "object"}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type": "object"}`, string(schema))
+	})
+
+	t.Run("EmptySchema", func(t *testing.T) {
+		schema, err := WithDefaultDraft(json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema"}`, string(schema))
+	})
+
+	t.Run("KeepsDeclaredDraft", func(t *testing.T) {
+		declared := json.RawMessage(`{"$schema":"http://json-schema.org/draft-07/schema#"}`)
+		schema, err := WithDefaultDraft(declared)
+		require.NoError(t, err)
+		assert.Equal(t, string(declared), string(schema))
+	})
+
+	t.Run("RejectsUnsupportedDraft", func(t *testing.T) {
+		_, err := WithDefaultDraft(json.RawMessage(`{"$schema":"https://example.com/schema"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("BooleanSchema", func(t *testing.T) {
+		schema, err := WithDefaultDraft(json.RawMessage(`true`))
+		require.NoError(t, err)
+		assert.Equal(t, `true`, string(schema))
+	})
+}
+
+func TestJSONSchemaValidator_Drafts(t *testing.T) {
+	t.Run("UndeclaredSchemaIsDraft7", func(t *testing.T) {
+		// Stored schemas without $schema predate draft selection: keywords introduced
+		// later are ignored and formats are asserted
+		validator := NewJSONSchemaValidator()
+		schema := json.RawMessage(`{"dependentRequired":{"tls":["cert"]},"properties":{"email":{"format":"email"}}}`)
+
+		assert.NoError(t, validator.ValidateJSON(context.Background(), schema, json.RawMessage(`{"tls":true}`)))
+		assert.Error(t, validator.ValidateJSON(context.Background(), schema, json.RawMessage(`{"email":"nobody"}`)))
+	})
+
+	t.Run("StrictFormats", func(t *testing.T) {
+		schema := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"timeout":{"format":"duration"}}}`)
+		data := json.RawMessage(`{"timeout":"5m"}`)
+
+		assert.NoError(t, NewJSONSchemaValidator().ValidateJSON(context.Background(), schema, data))
+
+		details := validationErrorsOf(t, NewJSONSchemaValidator(WithStrictFormats(true)).ValidateJSON(context.Background(), schema, data))
+		require.Len(t, details, 1)
+		assert.Equal(t, "/timeout", details[0].Pointer)
+		assert.Equal(t, errors.ValidationCodeInvalidFormat, details[0].Code)
+		assert.Equal(t, "duration", details[0].Expected)
+	})
+
+	t.Run("UnevaluatedProperties", func(t *testing.T) {
+		schema := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"host":{}},"unevaluatedProperties":false}`)
+		details := validationErrorsOf(t, NewJSONSchemaValidator().ValidateJSON(context.Background(), schema, json.RawMessage(`{"host":"a","port":1}`)))
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "/port", details[0].Pointer)
+		assert.Equal(t, "unevaluatedProperties", details[0].Keyword)
+		assert.Equal(t, errors.ValidationCodeAdditionalProperty, details[0].Code)
+	})
+
+	t.Run("DependentRequired", func(t *testing.T) {
+		schema := json.RawMessage(`{"$schema":"https://json-schema.org/draft/2020-12/schema","dependentRequired":{"tls":["cert","key"]}}`)
+		details := validationErrorsOf(t, NewJSONSchemaValidator().ValidateJSON(context.Background(), schema, json.RawMessage(`{"tls":true,"cert":"c"}`)))
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "/key", details[0].Pointer)
+		assert.Equal(t, errors.ValidationCodeMissingDependency, details[0].Code)
+		assert.Equal(t, "tls", details[0].Expected)
+	})
+
+	t.Run("UnsupportedDraft", func(t *testing.T) {
+		err := NewJSONSchemaValidator().ValidateSchemaDefinition(context.Background(), json.RawMessage(`{"$schema":"https://example.com/schema"}`))
+		assert.Error(t, err)
+	})
+}
+
+// validationErrorsOf returns the validation errors a failed validation reports
+func validationErrorsOf(t *testing.T, err error) []errors.ValidationError {
+	t.Helper()
+
+	require.Error(t, err)
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	require.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+	details, ok := appErr.Details.([]errors.ValidationError)
+	require.True(t, ok)
+	return details
+}
@@ -1,62 +1,86 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/Titonu/configuration-management-service/pkg/errors"
-	"github.com/xeipuuv/gojsonschema"
 )
 
 // JSONSchemaValidator provides JSON schema validation functionality
-type JSONSchemaValidator struct{}
+type JSONSchemaValidator struct {
+	loadShared    SharedSchemaLoader
+	strictFormats bool
+	cache         *SchemaCache
+}
+
+// JSONSchemaValidatorOption configures a JSON schema validator
+type JSONSchemaValidatorOption func(*JSONSchemaValidator)
 
-// NewJSONSchemaValidator creates a new JSON schema validator
-func NewJSONSchemaValidator() *JSONSchemaValidator {
-	return &JSONSchemaValidator{}
+// WithStrictFormats makes the format keyword an assertion in draft 2019-09 and 2020-12
+// schemas, where it is otherwise only an annotation. Earlier drafts always assert it.
+func WithStrictFormats(strict bool) JSONSchemaValidatorOption {
+	return func(v *JSONSchemaValidator) {
+		v.strictFormats = strict
+	}
 }
 
-// ValidateJSON validates JSON data against a schema
-func (v *JSONSchemaValidator) ValidateJSON(schema json.RawMessage, data json.RawMessage) error {
-	// Parse schema
-	schemaLoader := gojsonschema.NewStringLoader(string(schema))
+// NewJSONSchemaValidator creates a new JSON schema validator. Schemas it validates
+// may only reference definitions within themselves.
+func NewJSONSchemaValidator(opts ...JSONSchemaValidatorOption) *JSONSchemaValidator {
+	return NewJSONSchemaValidatorWithSharedSchemas(nil, opts...)
+}
 
-	// Parse data
-	dataLoader := gojsonschema.NewStringLoader(string(data))
+// NewJSONSchemaValidatorWithSharedSchemas creates a JSON schema validator that resolves
+// shared:// references through load
+func NewJSONSchemaValidatorWithSharedSchemas(load SharedSchemaLoader, opts ...JSONSchemaValidatorOption) *JSONSchemaValidator {
+	v := &JSONSchemaValidator{loadShared: load, cache: NewSchemaCache()}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
 
-	// Validate
-	result, err := gojsonschema.Validate(schemaLoader, dataLoader)
+// ValidateJSON validates JSON data against a schema
+func (v *JSONSchemaValidator) ValidateJSON(ctx context.Context, schema json.RawMessage, data json.RawMessage) error {
+	// Compile schema with the shared schemas it references
+	compiled, err := compileSchema(ctx, schema, v.loadShared, v.strictFormats)
 	if err != nil {
 		return errors.NewInternalError("Failed to validate JSON", err.Error())
 	}
 
-	// Check validation result
-	if !result.Valid() {
-		// Collect validation errors
-		validationErrors := make([]errors.ValidationError, 0)
-		for _, desc := range result.Errors() {
-			validationErrors = append(validationErrors, errors.ValidationError{
-				Field:  desc.Field(),
-				Reason: desc.Description(),
-			})
-		}
+	return compiled.validate(data)
+}
 
-		return errors.NewValidationFailedError(
-			"JSON validation failed",
-			validationErrors,
-		)
+// ValidateConfiguration validates the data of a configuration against its schema,
+// reusing the compiled schema cached for the configuration while the schema is unchanged
+func (v *JSONSchemaValidator) ValidateConfiguration(ctx context.Context, name string, schema json.RawMessage, data json.RawMessage) error {
+	compiled, err := v.cache.Get(name, schema, func(schema json.RawMessage) (*CompiledSchema, error) {
+		return compileSchema(ctx, schema, v.loadShared, v.strictFormats)
+	})
+	if err != nil {
+		return errors.NewInternalError("Failed to validate JSON", err.Error())
 	}
 
-	return nil
+	return compiled.validate(data)
 }
 
-// ValidateSchemaDefinition validates that a schema definition is valid JSON Schema
-func (v *JSONSchemaValidator) ValidateSchemaDefinition(schema json.RawMessage) error {
-	// Parse schema
-	schemaLoader := gojsonschema.NewStringLoader(string(schema))
+// InvalidateSchema drops the cached compiled schema of a configuration
+func (v *JSONSchemaValidator) InvalidateSchema(name string) {
+	v.cache.Invalidate(name)
+}
+
+// InvalidateAllSchemas drops every cached compiled schema, e.g. after a shared schema
+// they may reference has changed
+func (v *JSONSchemaValidator) InvalidateAllSchemas() {
+	v.cache.InvalidateAll()
+}
 
-	// Compile schema to check if it's valid
-	_, err := gojsonschema.NewSchema(schemaLoader)
+// ValidateSchemaDefinition validates that a schema definition is valid JSON Schema
+func (v *JSONSchemaValidator) ValidateSchemaDefinition(ctx context.Context, schema json.RawMessage) error {
+	// Compile schema to check if it's valid and its references resolve
+	_, err := compileSchema(ctx, schema, v.loadShared, v.strictFormats)
 	if err != nil {
 		return errors.NewInvalidRequestError(
 			"Invalid JSON Schema",
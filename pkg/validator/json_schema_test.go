@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -21,7 +22,7 @@ func TestJSONSchemaValidator_ValidateSchemaDefinition(t *testing.T) {
 			"required": ["name"]
 		}`)
 
-		err := validator.ValidateSchemaDefinition(schema)
+		err := validator.ValidateSchemaDefinition(context.Background(), schema)
 		assert.NoError(t, err)
 	})
 
@@ -34,7 +35,7 @@ func TestJSONSchemaValidator_ValidateSchemaDefinition(t *testing.T) {
 			}
 		}`)
 
-		err := validator.ValidateSchemaDefinition(schema)
+		err := validator.ValidateSchemaDefinition(context.Background(), schema)
 		assert.Error(t, err)
 	})
 
@@ -47,7 +48,7 @@ func TestJSONSchemaValidator_ValidateSchemaDefinition(t *testing.T) {
 			}
 		}`)
 
-		err := validator.ValidateSchemaDefinition(schema)
+		err := validator.ValidateSchemaDefinition(context.Background(), schema)
 		assert.Error(t, err)
 	})
 
@@ -55,7 +56,7 @@ func TestJSONSchemaValidator_ValidateSchemaDefinition(t *testing.T) {
 		// Empty schema
 		schema := json.RawMessage(`{}`)
 
-		err := validator.ValidateSchemaDefinition(schema)
+		err := validator.ValidateSchemaDefinition(context.Background(), schema)
 		assert.NoError(t, err) // Empty schema is valid
 	})
 }
@@ -80,7 +81,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"age": 30
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.NoError(t, err)
 	})
 
@@ -100,7 +101,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"age": 30
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.Error(t, err)
 	})
 
@@ -120,7 +121,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"age": "thirty"
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.Error(t, err)
 	})
 
@@ -140,7 +141,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"age": -5
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.Error(t, err)
 	})
 
@@ -155,7 +156,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"name": "John Doe"
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.Error(t, err)
 	})
 
@@ -173,7 +174,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"name": "John Doe",
 		}`)
 
-		err := validator.ValidateJSON(schema, data)
+		err := validator.ValidateJSON(context.Background(), schema, data)
 		assert.Error(t, err)
 	})
 
@@ -205,7 +206,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			}
 		}`)
 
-		err := validator.ValidateJSON(schema, validData)
+		err := validator.ValidateJSON(context.Background(), schema, validData)
 		assert.NoError(t, err)
 
 		// Invalid nested data (invalid zip code)
@@ -218,7 +219,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			}
 		}`)
 
-		err = validator.ValidateJSON(schema, invalidData)
+		err = validator.ValidateJSON(context.Background(), schema, invalidData)
 		assert.Error(t, err)
 	})
 
@@ -242,7 +243,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"tags": ["developer", "golang"]
 		}`)
 
-		err := validator.ValidateJSON(schema, validData)
+		err := validator.ValidateJSON(context.Background(), schema, validData)
 		assert.NoError(t, err)
 
 		// Invalid array data (empty array)
@@ -251,7 +252,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"tags": []
 		}`)
 
-		err = validator.ValidateJSON(schema, invalidData)
+		err = validator.ValidateJSON(context.Background(), schema, invalidData)
 		assert.Error(t, err)
 
 		// Invalid array data (wrong type in array)
@@ -260,7 +261,7 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			"tags": ["developer", 123]
 		}`)
 
-		err = validator.ValidateJSON(schema, invalidTypeData)
+		err = validator.ValidateJSON(context.Background(), schema, invalidTypeData)
 		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+)
+
+// SchemaCache is a concurrency-safe cache of compiled schemas keyed by configuration
+// name. Each entry remembers the hash of the schema it was compiled from, so a schema
+// that changed without the cache being invalidated is recompiled rather than served stale.
+// Invalidation starts a new generation, and a schema whose compilation started in an
+// earlier generation is not stored, since it may embed shared schemas that changed since.
+type SchemaCache struct {
+	mu         sync.RWMutex
+	entries    map[string]cachedSchema
+	generation uint64
+}
+
+// cachedSchema is a compiled schema and the hash of its source
+type cachedSchema struct {
+	hash     [sha256.Size]byte
+	compiled *CompiledSchema
+}
+
+// NewSchemaCache creates an empty schema cache
+func NewSchemaCache() *SchemaCache {
+	return &SchemaCache{entries: make(map[string]cachedSchema)}
+}
+
+// Get returns the compiled schema of a configuration, compiling and caching it when
+// the cache holds no entry for the configuration or the entry was compiled from a
+// different schema
+func (c *SchemaCache) Get(name string, schema json.RawMessage, compile func(json.RawMessage) (*CompiledSchema, error)) (*CompiledSchema, error) {
+	hash := sha256.Sum256(schema)
+
+	c.mu.RLock()
+	entry, ok := c.entries[name]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok && entry.hash == hash {
+		return entry.compiled, nil
+	}
+
+	compiled, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[name] = cachedSchema{hash: hash, compiled: compiled}
+	}
+	c.mu.Unlock()
+
+	return compiled, nil
+}
+
+// Invalidate removes the compiled schema of a configuration
+func (c *SchemaCache) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// InvalidateAll removes every compiled schema
+func (c *SchemaCache) InvalidateAll() {
+	c.mu.Lock()
+	c.entries = make(map[string]cachedSchema)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Len returns the number of cached schemas
+func (c *SchemaCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCache(t *testing.T) {
+	compiles := 0
+	compile := func(schema json.RawMessage) (*CompiledSchema, error) {
+		compiles++
+		return compileSchema(context.Background(), schema, nil, false)
+	}
+	cache := NewSchemaCache()
+	schema := json.RawMessage(`{"type":"object"}`)
+
+	t.Run("ReusesCompiledSchema", func(t *testing.T) {
+		first, err := cache.Get("payments", schema, compile)
+		require.NoError(t, err)
+		second, err := cache.Get("payments", schema, compile)
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, compiles)
+	})
+
+	t.Run("RecompilesChangedSchema", func(t *testing.T) {
+		_, err := cache.Get("payments", json.RawMessage(`{"type":"object","required":["limit"]}`), compile)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, compiles)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		_, err := cache.Get("limits", schema, compile)
+		require.NoError(t, err)
+		require.Equal(t, 2, cache.Len())
+
+		cache.Invalidate("payments")
+		assert.Equal(t, 1, cache.Len())
+
+		cache.InvalidateAll()
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("CompileErrorNotCached", func(t *testing.T) {
+		_, err := cache.Get("broken", json.RawMessage(`{"type":"invalid-type"}`), compile)
+		assert.Error(t, err)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("CompileRacingInvalidationNotCached", func(t *testing.T) {
+		// A shared schema changes while the schema is being compiled from its old definition
+		stale, err := cache.Get("payments", schema, func(schema json.RawMessage) (*CompiledSchema, error) {
+			cache.InvalidateAll()
+			return compile(schema)
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, stale, "the caller still gets the compiled schema")
+		assert.Equal(t, 0, cache.Len())
+
+		fresh, err := cache.Get("payments", schema, compile)
+		require.NoError(t, err)
+		assert.NotSame(t, stale, fresh)
+		assert.Equal(t, 1, cache.Len())
+	})
+}
+
+func TestJSONSchemaValidator_ValidateConfiguration(t *testing.T) {
+	validator := NewJSONSchemaValidator()
+	schema := json.RawMessage(`{"type":"object","properties":{"limit":{"type":"integer","maximum":100}}}`)
+
+	t.Run("ValidData", func(t *testing.T) {
+		assert.NoError(t, validator.ValidateConfiguration(context.Background(), "payments", schema, json.RawMessage(`{"limit":10}`)))
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		err := validator.ValidateConfiguration(context.Background(), "payments", schema, json.RawMessage(`{"limit":1000}`))
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, err.(*errors.AppError).Code)
+	})
+
+	t.Run("ConcurrentValidation", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				name := fmt.Sprintf("config-%d", i%4)
+				assert.NoError(t, validator.ValidateConfiguration(context.Background(), name, schema, json.RawMessage(`{"limit":10}`)))
+				if i%5 == 0 {
+					validator.InvalidateSchema(name)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+// largeSchema builds an object schema with the given number of constrained properties
+func largeSchema(properties int) (json.RawMessage, json.RawMessage) {
+	props := make([]string, 0, properties)
+	values := make([]string, 0, properties)
+	for i := 0; i < properties; i++ {
+		props = append(props, fmt.Sprintf(
+			`"field_%d":{"type":"object","properties":{"name":{"type":"string","pattern":"^[a-z]+$","maxLength":64},"limit":{"type":"integer","minimum":0,"maximum":1000},"mode":{"enum":["a","b","c"]}},"required":["name","limit"]}`,
+			i,
+		))
+		values = append(values, fmt.Sprintf(`"field_%d":{"name":"value","limit":%d,"mode":"b"}`, i, i))
+	}
+	schema := fmt.Sprintf(`{"type":"object","properties":{%s}}`, strings.Join(props, ","))
+	data := fmt.Sprintf(`{%s}`, strings.Join(values, ","))
+	return json.RawMessage(schema), json.RawMessage(data)
+}
+
+func BenchmarkValidateJSON_LargeSchema(b *testing.B) {
+	validator := NewJSONSchemaValidator()
+	schema, data := largeSchema(200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := validator.ValidateJSON(context.Background(), schema, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateConfiguration_LargeSchema(b *testing.B) {
+	validator := NewJSONSchemaValidator()
+	schema, data := largeSchema(200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := validator.ValidateConfiguration(context.Background(), "payments", schema, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateJSON_ParallelWrites(b *testing.B) {
+	validator := NewJSONSchemaValidator()
+	schema, data := largeSchema(20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := validator.ValidateJSON(context.Background(), schema, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkValidateConfiguration_ParallelWrites(b *testing.B) {
+	validator := NewJSONSchemaValidator()
+	schema, data := largeSchema(20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := validator.ValidateConfiguration(context.Background(), "payments", schema, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
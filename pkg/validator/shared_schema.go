@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SharedSchemaPrefix is the URI prefix used to reference a shared schema fragment,
+// e.g. {"$ref": "shared://common/endpoint"}
+const SharedSchemaPrefix = "shared://"
+
+// sharedSchemaNamePattern matches shared schema names: slash-separated segments of
+// letters, digits, dots, underscores and dashes
+var sharedSchemaNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// SharedSchemaLoader loads the definition of a shared schema fragment by name
+type SharedSchemaLoader func(ctx context.Context, name string) (json.RawMessage, error)
+
+// IsValidSharedSchemaName reports whether name can be used for a shared schema fragment
+func IsValidSharedSchemaName(name string) bool {
+	return len(name) <= 128 && sharedSchemaNamePattern.MatchString(name)
+}
+
+// SharedSchemaRefs returns the names of the shared schema fragments a schema references
+// directly, sorted. References within the schema itself ("#...") are allowed; any other
+// reference is rejected, so that schemas are never loaded from the network or disk.
+func SharedSchemaRefs(schema json.RawMessage) ([]string, error) {
+	var document interface{}
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	if err := collectRefs(document, names); err != nil {
+		return nil, err
+	}
+
+	return sortedKeys(names), nil
+}
+
+// Keywords whose value is a subschema, an array of subschemas or an object of subschemas.
+// Other keywords hold plain values, such as enum, const, default or examples, in which
+// "$ref" is data rather than a reference.
+var (
+	subschemaKeywords = []string{
+		"items", "additionalItems", "contains", "not", "if", "then", "else",
+		"additionalProperties", "unevaluatedProperties", "unevaluatedItems", "propertyNames", "contentSchema",
+	}
+	subschemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+	subschemaMapKeywords   = []string{"properties", "patternProperties", "definitions", "$defs", "dependentSchemas", "dependencies"}
+)
+
+// collectRefs adds the shared schema names referenced by schema and its subschemas to names
+func collectRefs(schema interface{}, names map[string]bool) error {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if ref, ok := object["$ref"].(string); ok {
+		switch {
+		case strings.HasPrefix(ref, "#"):
+		case strings.HasPrefix(ref, SharedSchemaPrefix):
+			name := strings.TrimPrefix(ref, SharedSchemaPrefix)
+			if i := strings.Index(name, "#"); i >= 0 {
+				name = name[:i]
+			}
+			if !IsValidSharedSchemaName(name) {
+				return fmt.Errorf("invalid shared schema reference %q", ref)
+			}
+			names[name] = true
+		default:
+			return fmt.Errorf("unsupported reference %q: only local (#) and %s references are allowed", ref, SharedSchemaPrefix)
+		}
+	}
+
+	var subschemas []interface{}
+	for _, keyword := range subschemaKeywords {
+		if subschema, ok := object[keyword]; ok {
+			subschemas = append(subschemas, subschema)
+		}
+	}
+	for _, keyword := range subschemaArrayKeywords {
+		if items, ok := object[keyword].([]interface{}); ok {
+			subschemas = append(subschemas, items...)
+		}
+	}
+	for _, keyword := range subschemaMapKeywords {
+		if members, ok := object[keyword].(map[string]interface{}); ok {
+			for _, name := range sortedKeys(keySet(members)) {
+				subschemas = append(subschemas, members[name])
+			}
+		}
+	}
+
+	for _, subschema := range subschemas {
+		if err := collectRefs(subschema, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveSharedSchemas loads every shared schema fragment a schema references, directly
+// or through other fragments, keyed by name. It fails on unknown fragments and on
+// reference cycles between fragments.
+func ResolveSharedSchemas(ctx context.Context, schema json.RawMessage, load SharedSchemaLoader) (map[string]json.RawMessage, error) {
+	refs, err := SharedSchemaRefs(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]json.RawMessage{}
+	if len(refs) == 0 {
+		return resolved, nil
+	}
+	if load == nil {
+		return nil, fmt.Errorf("shared schema references are not supported by this validator")
+	}
+
+	// Depth-first walk; fragments on the current path are in visiting
+	visiting := map[string]bool{}
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visiting[name] {
+			return fmt.Errorf("shared schema reference cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		if _, ok := resolved[name]; ok {
+			return nil
+		}
+
+		fragment, err := load(ctx, name)
+		if err != nil {
+			return fmt.Errorf("unknown shared schema %q: %w", name, err)
+		}
+		children, err := SharedSchemaRefs(fragment)
+		if err != nil {
+			return fmt.Errorf("shared schema %q: %w", name, err)
+		}
+
+		visiting[name] = true
+		path = append(path, name)
+		for _, child := range children {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[name] = false
+
+		resolved[name] = fragment
+		return nil
+	}
+
+	for _, name := range refs {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
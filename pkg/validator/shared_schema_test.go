@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedSchemas returns a loader serving fragments from a map
+func sharedSchemas(fragments map[string]string) SharedSchemaLoader {
+	return func(ctx context.Context, name string) (json.RawMessage, error) {
+		fragment, ok := fragments[name]
+		if !ok {
+			return nil, fmt.Errorf("shared schema %s not found", name)
+		}
+		return json.RawMessage(fragment), nil
+	}
+}
+
+func TestJSONSchemaValidator_SharedSchemas(t *testing.T) {
+	validator := NewJSONSchemaValidatorWithSharedSchemas(sharedSchemas(map[string]string{
+		"common/endpoint":     `{"type":"object","properties":{"url":{"type":"string"},"retry":{"$ref":"shared://common/retry-policy"}},"required":["url"]}`,
+		"common/retry-policy": `{"type":"integer","maximum":5}`,
+		"cycle/a":             `{"properties":{"b":{"$ref":"shared://cycle/b"}}}`,
+		"cycle/b":             `{"properties":{"a":{"$ref":"shared://cycle/a"}}}`,
+		"recursive":           `{"properties":{"children":{"items":{"$ref":"#"}}}}`,
+	}))
+	schema := json.RawMessage(`{"type":"object","properties":{"api":{"$ref":"shared://common/endpoint"}}}`)
+
+	t.Run("ValidData", func(t *testing.T) {
+		err := validator.ValidateJSON(context.Background(), schema, json.RawMessage(`{"api":{"url":"https://example.com","retry":3}}`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("InvalidNestedData", func(t *testing.T) {
+		err := validator.ValidateJSON(context.Background(), schema, json.RawMessage(`{"api":{"retry":9}}`))
+		require.Error(t, err)
+		appErr, ok := err.(*errors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorCodeValidationFailed, appErr.Code)
+		assert.Len(t, appErr.Details, 2)
+	})
+
+	t.Run("UnknownSharedSchema", func(t *testing.T) {
+		err := validator.ValidateSchemaDefinition(context.Background(), json.RawMessage(`{"$ref":"shared://common/missing"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.(*errors.AppError).Details, "common/missing")
+	})
+
+	t.Run("ReferenceCycle", func(t *testing.T) {
+		err := validator.ValidateSchemaDefinition(context.Background(), json.RawMessage(`{"$ref":"shared://cycle/a"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.(*errors.AppError).Details, "cycle/a -> cycle/b -> cycle/a")
+	})
+
+	t.Run("LocalReferencesAllowed", func(t *testing.T) {
+		err := validator.ValidateSchemaDefinition(context.Background(), json.RawMessage(`{"$ref":"shared://recursive"}`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("NetworkReferencesRejected", func(t *testing.T) {
+		err := validator.ValidateSchemaDefinition(context.Background(), json.RawMessage(`{"$ref":"https://example.com/schema.json"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("NoLoader", func(t *testing.T) {
+		err := NewJSONSchemaValidator().ValidateSchemaDefinition(context.Background(), schema)
+		assert.Error(t, err)
+	})
+
+	t.Run("LoaderUsesCallerContext", func(t *testing.T) {
+		// Fragments are loaded within the call that needs them, so a cancelled request
+		// stops loading them
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cancellable := NewJSONSchemaValidatorWithSharedSchemas(func(ctx context.Context, name string) (json.RawMessage, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return json.RawMessage(`{"type":"string"}`), nil
+		})
+
+		err := cancellable.ValidateConfiguration(ctx, "payments", json.RawMessage(`{"$ref":"shared://common/name"}`), json.RawMessage(`"a"`))
+		require.Error(t, err)
+		assert.Contains(t, err.(*errors.AppError).Details, context.Canceled.Error())
+		assert.NoError(t, cancellable.ValidateConfiguration(context.Background(), "payments", json.RawMessage(`{"$ref":"shared://common/name"}`), json.RawMessage(`"a"`)), "failed compilations are not cached")
+	})
+}
+
+func TestSharedSchemaRefs(t *testing.T) {
+	refs, err := SharedSchemaRefs(json.RawMessage(`{
+		"properties": {
+			"a": {"$ref": "shared://common/endpoint"},
+			"b": {"items": {"$ref": "shared://common/retry-policy#/definitions/delay"}},
+			"c": {"$ref": "#/definitions/local"}
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common/endpoint", "common/retry-policy"}, refs)
+
+	_, err = SharedSchemaRefs(json.RawMessage(`{"$ref":"file:///etc/passwd"}`))
+	assert.Error(t, err)
+
+	// "$ref" in values rather than schemas is data
+	refs, err = SharedSchemaRefs(json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"link": {"type": "object", "default": {"$ref": "https://example.com/docs"}, "examples": [{"$ref": "#/x"}]},
+			"kind": {"enum": [{"$ref": "file:///etc/passwd"}], "const": {"$ref": "other"}}
+		},
+		"allOf": [{"$ref": "shared://common/endpoint"}],
+		"$defs": {"delay": {"anyOf": [{"$ref": "shared://common/retry-policy"}]}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common/endpoint", "common/retry-policy"}, refs)
+
+	assert.True(t, IsValidSharedSchemaName("common/retry-policy"))
+	assert.False(t, IsValidSharedSchemaName("common//endpoint"))
+	assert.False(t, IsValidSharedSchemaName("../endpoint"))
+}
@@ -0,0 +1,413 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// MaskedValue replaces the values of secret fields in validation errors
+const MaskedValue = "********"
+
+// secretFieldPattern matches property names whose values are masked in validation errors
+var secretFieldPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential)`)
+
+// keywordCodes maps the schema keywords that can fail validation to validation codes
+var keywordCodes = map[string]errors.ValidationCode{
+	"required":              errors.ValidationCodeRequired,
+	"type":                  errors.ValidationCodeInvalidType,
+	"minimum":               errors.ValidationCodeTooSmall,
+	"exclusiveMinimum":      errors.ValidationCodeTooSmall,
+	"maximum":               errors.ValidationCodeTooLarge,
+	"exclusiveMaximum":      errors.ValidationCodeTooLarge,
+	"multipleOf":            errors.ValidationCodeNotMultipleOf,
+	"minLength":             errors.ValidationCodeTooShort,
+	"maxLength":             errors.ValidationCodeTooLong,
+	"pattern":               errors.ValidationCodePatternMismatch,
+	"format":                errors.ValidationCodeInvalidFormat,
+	"enum":                  errors.ValidationCodeNotAllowed,
+	"const":                 errors.ValidationCodeNotAllowed,
+	"minItems":              errors.ValidationCodeTooFewItems,
+	"maxItems":              errors.ValidationCodeTooManyItems,
+	"uniqueItems":           errors.ValidationCodeDuplicateItems,
+	"additionalItems":       errors.ValidationCodeAdditionalItems,
+	"items":                 errors.ValidationCodeAdditionalItems,
+	"unevaluatedItems":      errors.ValidationCodeAdditionalItems,
+	"contains":              errors.ValidationCodeMissingContainedItem,
+	"minContains":           errors.ValidationCodeMissingContainedItem,
+	"maxContains":           errors.ValidationCodeTooManyItems,
+	"minProperties":         errors.ValidationCodeTooFewProperties,
+	"maxProperties":         errors.ValidationCodeTooManyProperties,
+	"additionalProperties":  errors.ValidationCodeAdditionalProperty,
+	"unevaluatedProperties": errors.ValidationCodeAdditionalProperty,
+	"propertyNames":         errors.ValidationCodeInvalidPropertyName,
+	"dependencies":          errors.ValidationCodeMissingDependency,
+	"dependentRequired":     errors.ValidationCodeMissingDependency,
+	"anyOf":                 errors.ValidationCodeSchemaMismatch,
+	"oneOf":                 errors.ValidationCodeSchemaMismatch,
+	"not":                   errors.ValidationCodeSchemaMismatch,
+	"then":                  errors.ValidationCodeSchemaMismatch,
+	"else":                  errors.ValidationCodeSchemaMismatch,
+}
+
+// constraintKeywords are the keywords whose value is reported as the expected value
+var constraintKeywords = map[string]bool{
+	"type": true, "minimum": true, "exclusiveMinimum": true, "maximum": true,
+	"exclusiveMaximum": true, "multipleOf": true, "minLength": true, "maxLength": true,
+	"pattern": true, "format": true, "enum": true, "const": true, "minItems": true,
+	"maxItems": true, "minContains": true, "maxContains": true, "minProperties": true,
+	"maxProperties": true,
+}
+
+// keywordAt returns the keyword a schema location ends in. Keywords that hold values
+// per property, such as dependentRequired, fail at locations like dependentRequired/a/0.
+func keywordAt(location string) string {
+	tokens := pointerTokens(strings.TrimPrefix(location, "#"))
+	if len(tokens) == 0 {
+		return ""
+	}
+	if len(tokens) >= 3 {
+		switch tokens[len(tokens)-3] {
+		case "dependentRequired", "dependencies":
+			return tokens[len(tokens)-3]
+		}
+	}
+	return tokens[len(tokens)-1]
+}
+
+// describeFailure converts a validation failure into validation errors with their JSON
+// Pointer, keyword, code, expected constraint and actual value. A failure concerning
+// several properties, such as missing required properties, yields one error per
+// property. Values of secret fields are masked.
+func (s *CompiledSchema) describeFailure(failure *jsonschema.ValidationError, document interface{}) []errors.ValidationError {
+	keyword := keywordAt(failure.KeywordLocation)
+	constraint, _ := s.lookup(failure.AbsoluteKeywordLocation)
+	instance, _ := resolvePointer(document, failure.InstanceLocation)
+	segments := pointerTokens(failure.InstanceLocation)
+
+	base := errors.ValidationError{Keyword: keyword, Code: errors.ValidationCodeInvalid}
+	if code, ok := keywordCodes[keyword]; ok {
+		base.Code = code
+	}
+
+	// A false schema rejects any value. Under additionalProperties and similar keywords
+	// the value is an unexpected property or item; elsewhere it is not allowed at all.
+	if failure.Message == "not allowed" {
+		if _, ok := keywordCodes[keyword]; !ok || keyword == "propertyNames" {
+			base.Keyword = "false"
+			base.Code = errors.ValidationCodeNotAllowed
+		}
+		return []errors.ValidationError{s.newError(base, segments, instance, nil, notAllowedReason(base.Code, segments))}
+	}
+
+	switch keyword {
+	case "required":
+		object, _ := instance.(map[string]interface{})
+		names, _ := constraint.([]interface{})
+		result := []errors.ValidationError{}
+		for _, name := range names {
+			name, _ := name.(string)
+			if _, ok := object[name]; !ok {
+				result = append(result, s.propertyError(base, segments, name, nil, nil, fmt.Sprintf("%s is required", name)))
+			}
+		}
+		return result
+
+	case "additionalProperties":
+		parent, _ := s.lookup(parentLocation(failure.AbsoluteKeywordLocation))
+		object, _ := instance.(map[string]interface{})
+		result := []errors.ValidationError{}
+		for _, name := range unexpectedProperties(parent, object) {
+			result = append(result, s.propertyError(base, segments, name, object[name], nil, fmt.Sprintf("Additional property %s is not allowed", name)))
+		}
+		return result
+
+	case "dependentRequired", "dependencies":
+		// The location ends in the keyword, the present property and an index
+		tokens := pointerTokens(strings.TrimPrefix(failure.KeywordLocation, "#"))
+		present := tokens[len(tokens)-2]
+		missing, _ := constraint.(string)
+		return []errors.ValidationError{s.propertyError(base, segments, missing, nil, present,
+			fmt.Sprintf("%s is required when %s is present", missing, present))}
+	}
+
+	var expected interface{}
+	if constraintKeywords[keyword] {
+		expected = plainValue(constraint)
+	}
+	reason := reasonFor(keyword, fieldPath(segments), expected, instance, failure.Message)
+
+	return []errors.ValidationError{s.newError(base, segments, instance, expected, reason)}
+}
+
+// newError completes a validation error for the value at a path. The values of secret
+// fields are masked, as are the allowed values of enum and const for them.
+func (s *CompiledSchema) newError(base errors.ValidationError, segments []string, actual, expected interface{}, reason string) errors.ValidationError {
+	result := base
+	result.Field = fieldPath(segments)
+	result.Pointer = pointerFromSegments(segments)
+	result.Reason = reason
+	result.Expected = expected
+	result.Actual = plainValue(scalarValue(actual))
+
+	if isSecretPath(s.documents[rootSchemaURL], segments) {
+		if result.Actual != nil {
+			result.Actual = MaskedValue
+		}
+		// The allowed values of a secret are secrets too, and the reason lists them
+		if result.Code == errors.ValidationCodeNotAllowed && result.Expected != nil {
+			result.Expected = MaskedValue
+			result.Reason = fmt.Sprintf("%s: value is not allowed", result.Field)
+		}
+	}
+
+	return result
+}
+
+// propertyError completes a validation error about a property of the object at a
+// path. The error points at the property but, as in earlier releases, its field is the
+// object's.
+func (s *CompiledSchema) propertyError(base errors.ValidationError, segments []string, property string, actual, expected interface{}, reason string) errors.ValidationError {
+	path := append(append([]string{}, segments...), property)
+	result := s.newError(base, path, actual, expected, reason)
+	result.Field = fieldPath(segments)
+	return result
+}
+
+// reasonFor describes a failed keyword. The wording is the service's own, so that
+// reasons stay stable across schema libraries and never quote the failing value.
+func reasonFor(keyword, field string, expected, actual interface{}, message string) string {
+	switch keyword {
+	case "type":
+		return fmt.Sprintf("Invalid type. Expected: %s, given: %s", typeNames(expected), jsonTypeOf(actual))
+	case "minimum":
+		return fmt.Sprintf("Must be greater than or equal to %v", expected)
+	case "exclusiveMinimum":
+		return fmt.Sprintf("Must be greater than %v", expected)
+	case "maximum":
+		return fmt.Sprintf("Must be less than or equal to %v", expected)
+	case "exclusiveMaximum":
+		return fmt.Sprintf("Must be less than %v", expected)
+	case "multipleOf":
+		return fmt.Sprintf("Must be a multiple of %v", expected)
+	case "minLength":
+		return fmt.Sprintf("String length must be greater than or equal to %v", expected)
+	case "maxLength":
+		return fmt.Sprintf("String length must be less than or equal to %v", expected)
+	case "pattern":
+		return fmt.Sprintf("Does not match pattern '%v'", expected)
+	case "format":
+		return fmt.Sprintf("Does not match format '%v'", expected)
+	case "enum":
+		return fmt.Sprintf("%s must be one of the following: %s", field, joinValues(expected))
+	case "const":
+		return fmt.Sprintf("%s does not match: %s", field, joinValues([]interface{}{expected}))
+	case "minItems":
+		return fmt.Sprintf("Array must have at least %v items", expected)
+	case "maxItems":
+		return fmt.Sprintf("Array must have at most %v items", expected)
+	case "uniqueItems":
+		return "Array items must be unique"
+	case "contains", "minContains":
+		return "Array does not contain enough matching items"
+	case "maxContains":
+		return "Array contains too many matching items"
+	case "minProperties":
+		return fmt.Sprintf("Must have at least %v properties", expected)
+	case "maxProperties":
+		return fmt.Sprintf("Must have at most %v properties", expected)
+	case "anyOf":
+		return "Must validate at least one schema (anyOf)"
+	case "oneOf":
+		return "Must validate one and only one schema (oneOf)"
+	case "not":
+		return "Must not validate the schema (not)"
+	}
+
+	if message == "" {
+		return "Invalid value"
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
+
+// notAllowedReason describes a value rejected by a false schema
+func notAllowedReason(code errors.ValidationCode, segments []string) string {
+	if len(segments) == 0 {
+		return "No value is allowed"
+	}
+	name := segments[len(segments)-1]
+	switch code {
+	case errors.ValidationCodeAdditionalProperty:
+		return fmt.Sprintf("Additional property %s is not allowed", name)
+	case errors.ValidationCodeAdditionalItems:
+		return fmt.Sprintf("Additional item %s is not allowed", name)
+	}
+	return fmt.Sprintf("%s is not allowed", fieldPath(segments))
+}
+
+// unexpectedProperties lists, sorted, the properties of an object that a schema
+// describes neither in properties nor in patternProperties
+func unexpectedProperties(schema interface{}, object map[string]interface{}) []string {
+	node, _ := schema.(map[string]interface{})
+	properties, _ := node["properties"].(map[string]interface{})
+	patterns, _ := node["patternProperties"].(map[string]interface{})
+
+	names := []string{}
+	for name := range object {
+		if _, ok := properties[name]; ok {
+			continue
+		}
+		matched := false
+		for pattern := range patterns {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parentLocation returns the location of the schema holding a keyword
+func parentLocation(location string) string {
+	if i := strings.LastIndex(location, "/"); i >= 0 {
+		return location[:i]
+	}
+	return location
+}
+
+// fieldPath returns the dotted field path for reference tokens
+func fieldPath(segments []string) string {
+	if len(segments) == 0 {
+		return RootField
+	}
+	return strings.Join(segments, ".")
+}
+
+// pointerFromSegments builds an RFC 6901 JSON Pointer from reference tokens
+func pointerFromSegments(segments []string) string {
+	var pointer strings.Builder
+	for _, segment := range segments {
+		pointer.WriteString("/")
+		pointer.WriteString(escapePointerToken(segment))
+	}
+	return pointer.String()
+}
+
+// plainValue converts the numbers the schema library parses into float64, as
+// encoding/json would parse them
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = plainValue(item)
+		}
+		return values
+	}
+	return value
+}
+
+// scalarValue returns a value for reporting, leaving out objects and arrays that could
+// be arbitrarily large
+func scalarValue(value interface{}) interface{} {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil
+	}
+	return value
+}
+
+// typeNames describes the value of a type keyword
+func typeNames(value interface{}) string {
+	if types, ok := value.([]interface{}); ok {
+		names := make([]string, 0, len(types))
+		for _, t := range types {
+			names = append(names, fmt.Sprint(t))
+		}
+		return strings.Join(names, "/")
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonTypeOf returns the JSON Schema type name of a value
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// joinValues renders values as a comma-separated list of JSON values
+func joinValues(value interface{}) string {
+	values, _ := value.([]interface{})
+	rendered := make([]string, 0, len(values))
+	for _, v := range values {
+		encoded, _ := json.Marshal(v)
+		rendered = append(rendered, string(encoded))
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// isSecretPath reports whether the value at a path holds a secret: a property whose
+// name looks like a credential, or whose schema is annotated "writeOnly": true. The
+// annotation is found by following properties and items; references are not followed.
+func isSecretPath(schema interface{}, segments []string) bool {
+	for _, segment := range segments {
+		if secretFieldPattern.MatchString(segment) {
+			return true
+		}
+	}
+
+	node, _ := schema.(map[string]interface{})
+	for _, segment := range segments {
+		if node == nil {
+			return false
+		}
+		if writeOnly, _ := node["writeOnly"].(bool); writeOnly {
+			return true
+		}
+
+		var next interface{}
+		if properties, ok := node["properties"].(map[string]interface{}); ok {
+			next = properties[segment]
+		}
+		if next == nil {
+			next = node["items"]
+		}
+		node, _ = next.(map[string]interface{})
+	}
+	if node != nil {
+		writeOnly, _ := node["writeOnly"].(bool)
+		return writeOnly
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validationErrors validates data and returns the reported validation errors
+func validationErrors(t *testing.T, schema, data string) []errors.ValidationError {
+	t.Helper()
+
+	return validationErrorsOf(t, NewJSONSchemaValidator().ValidateJSON(context.Background(), json.RawMessage(schema), json.RawMessage(data)))
+}
+
+func TestJSONSchemaValidator_ErrorDetails(t *testing.T) {
+	t.Run("Maximum", func(t *testing.T) {
+		details := validationErrors(t,
+			`{"type":"object","properties":{"limits":{"type":"object","properties":{"max":{"type":"integer","maximum":100}}}}}`,
+			`{"limits":{"max":500}}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "limits.max", details[0].Field)
+		assert.Equal(t, "/limits/max", details[0].Pointer)
+		assert.Equal(t, "maximum", details[0].Keyword)
+		assert.Equal(t, errors.ValidationCodeTooLarge, details[0].Code)
+		assert.Equal(t, float64(100), details[0].Expected)
+		assert.Equal(t, float64(500), details[0].Actual)
+	})
+
+	t.Run("RequiredPointsAtMissingProperty", func(t *testing.T) {
+		details := validationErrors(t, `{"type":"object","required":["name"]}`, `{}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "(root)", details[0].Field)
+		assert.Equal(t, "/name", details[0].Pointer)
+		assert.Equal(t, "required", details[0].Keyword)
+		assert.Equal(t, errors.ValidationCodeRequired, details[0].Code)
+		assert.Nil(t, details[0].Actual)
+	})
+
+	t.Run("ArrayItemsAndEscaping", func(t *testing.T) {
+		details := validationErrors(t,
+			`{"properties":{"a/b":{"type":"array","items":{"properties":{"port":{"type":"integer"}}}}}}`,
+			`{"a/b":[{"port":1},{"port":"80"}]}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "/a~1b/1/port", details[0].Pointer)
+		assert.Equal(t, "type", details[0].Keyword)
+		assert.Equal(t, errors.ValidationCodeInvalidType, details[0].Code)
+		assert.Equal(t, "integer", details[0].Expected)
+		assert.Equal(t, "80", details[0].Actual)
+	})
+
+	t.Run("AdditionalProperty", func(t *testing.T) {
+		details := validationErrors(t, `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":true}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "/b", details[0].Pointer)
+		assert.Equal(t, errors.ValidationCodeAdditionalProperty, details[0].Code)
+		assert.Equal(t, true, details[0].Actual)
+	})
+
+	t.Run("Pattern", func(t *testing.T) {
+		details := validationErrors(t, `{"properties":{"region":{"type":"string","pattern":"^[a-z]+-[0-9]$"}}}`, `{"region":"EU"}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "pattern", details[0].Keyword)
+		assert.Equal(t, errors.ValidationCodePatternMismatch, details[0].Code)
+		assert.Equal(t, "^[a-z]+-[0-9]$", details[0].Expected)
+		assert.Equal(t, "EU", details[0].Actual)
+	})
+}
+
+func TestJSONSchemaValidator_MasksSecrets(t *testing.T) {
+	t.Run("SecretName", func(t *testing.T) {
+		details := validationErrors(t,
+			`{"properties":{"db":{"properties":{"password":{"type":"string","minLength":12}}}}}`,
+			`{"db":{"password":"hunter2"}}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "/db/password", details[0].Pointer)
+		assert.Equal(t, MaskedValue, details[0].Actual)
+		assert.Equal(t, float64(12), details[0].Expected)
+		assert.NotContains(t, details[0].Reason, "hunter2")
+	})
+
+	t.Run("WriteOnly", func(t *testing.T) {
+		details := validationErrors(t,
+			`{"properties":{"signing":{"type":"string","writeOnly":true,"enum":["alpha","beta"]}}}`,
+			`{"signing":"gamma"}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, MaskedValue, details[0].Actual)
+		assert.Equal(t, MaskedValue, details[0].Expected)
+		assert.NotContains(t, details[0].Reason, "alpha")
+	})
+
+	t.Run("PlainField", func(t *testing.T) {
+		details := validationErrors(t, `{"properties":{"mode":{"enum":["a","b"]}}}`, `{"mode":"c"}`)
+
+		require.Len(t, details, 1)
+		assert.Equal(t, "c", details[0].Actual)
+		assert.Equal(t, []interface{}{"a", "b"}, details[0].Expected)
+	})
+}
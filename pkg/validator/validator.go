@@ -1,29 +1,38 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
+	stdErrors "errors"
 	"fmt"
-	"github.com/Titonu/configuration-management-service/pkg/errors"
 
-	"github.com/xeipuuv/gojsonschema"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
 )
 
 // Validator is an interface for JSON schema validation
 type Validator interface {
-	ValidateJSON(schema json.RawMessage, data json.RawMessage) error
-	ValidateSchemaDefinition(schema json.RawMessage) error
+	ValidateJSON(ctx context.Context, schema json.RawMessage, data json.RawMessage) error
+	ValidateSchemaDefinition(ctx context.Context, schema json.RawMessage) error
+}
+
+// CachingValidator is a Validator that caches the compiled schemas of configurations
+type CachingValidator interface {
+	Validator
+	ValidateConfiguration(ctx context.Context, name string, schema json.RawMessage, data json.RawMessage) error
+	InvalidateSchema(name string)
+	InvalidateAllSchemas()
 }
 
 // SchemaValidator handles JSON schema validation
 type SchemaValidator struct {
-	schemas    map[string]*gojsonschema.Schema
+	schemas    map[string]*CompiledSchema
 	schemaJSON map[string]json.RawMessage
 }
 
 // NewSchemaValidator creates a new schema validator
 func NewSchemaValidator() *SchemaValidator {
 	return &SchemaValidator{
-		schemas:    make(map[string]*gojsonschema.Schema),
+		schemas:    make(map[string]*CompiledSchema),
 		schemaJSON: make(map[string]json.RawMessage),
 	}
 }
@@ -31,8 +40,7 @@ func NewSchemaValidator() *SchemaValidator {
 // RegisterSchema adds a new schema for a configuration type
 func (v *SchemaValidator) RegisterSchema(configName string, schemaJSON []byte) error {
 	// Parse the schema
-	schemaLoader := gojsonschema.NewBytesLoader(schemaJSON)
-	schema, err := gojsonschema.NewSchema(schemaLoader)
+	schema, err := compileSchema(context.Background(), schemaJSON, nil, false)
 	if err != nil {
 		return fmt.Errorf("invalid schema for %s: %w", configName, err)
 	}
@@ -51,27 +59,22 @@ func (v *SchemaValidator) Validate(configName string, data json.RawMessage) ([]*
 		return nil, fmt.Errorf("no schema registered for configuration: %s", configName)
 	}
 
-	// Create a document loader for the data
-	documentLoader := gojsonschema.NewBytesLoader(data)
-
 	// Validate
-	result, err := schema.Validate(documentLoader)
-	if err != nil {
-		return nil, fmt.Errorf("validation error: %w", err)
+	err := schema.validate(data)
+	if err == nil {
+		return nil, nil
 	}
 
-	// If valid, return nil
-	if result.Valid() {
-		return nil, nil
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) || appErr.Code != errors.ErrorCodeValidationFailed {
+		return nil, fmt.Errorf("validation error: %w", err)
 	}
+	details, _ := appErr.Details.([]errors.ValidationError)
 
 	// Convert validation errors to our model
-	validationErrors := make([]*errors.ValidationError, 0, len(result.Errors()))
-	for _, err := range result.Errors() {
-		validationErrors = append(validationErrors, &errors.ValidationError{
-			Field:  err.Field(),
-			Reason: err.Description(),
-		})
+	validationErrors := make([]*errors.ValidationError, 0, len(details))
+	for i := range details {
+		validationErrors = append(validationErrors, &details[i])
 	}
 
 	return validationErrors, nil
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -113,6 +114,20 @@ func (suite *ConfigurationAPITestSuite) SetupSuite() {
 		schema.GET("/:name", configHandler.GetSchema)
 	}
 
+	// Archive routes - protected by auth middleware
+	archive := api.Group("")
+	archive.Use(suite.authMiddleware.Authenticate())
+	{
+		// Export every configuration with its full history
+		archive.GET("/export", configHandler.ExportArchive)
+
+		// Restore an exported archive
+		archive.POST("/import", configHandler.ImportArchive)
+
+		// Apply operations across configurations atomically
+		archive.POST("/batch", configHandler.ApplyBatch)
+	}
+
 	// Health check endpoint (no auth required)
 	suite.router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -166,6 +181,15 @@ func (suite *ConfigurationAPITestSuite) SetupTest() {
 		schema.GET("/:name", configHandler.GetSchema)
 	}
 
+	// Archive routes - protected by auth middleware
+	archive := api.Group("")
+	archive.Use(suite.authMiddleware.Authenticate())
+	{
+		archive.GET("/export", configHandler.ExportArchive)
+		archive.POST("/import", configHandler.ImportArchive)
+		archive.POST("/batch", configHandler.ApplyBatch)
+	}
+
 	// Health check endpoint (no auth required)
 	suite.router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -701,6 +725,177 @@ func (suite *ConfigurationAPITestSuite) TestAuthenticationValidAPIKey() {
 	assert.True(t, ok, "Response should contain schema data")
 }
 
+// TestExportImportRoundTrip tests restoring an exported archive into an empty database
+func (suite *ConfigurationAPITestSuite) TestExportImportRoundTrip() {
+	t := suite.T()
+
+	// Register a schema and write two versions of a configuration
+	suite.TestRegisterSchema()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/configurations", strings.NewReader(`{"name":"payment-config","data":{"max_limit":1000,"enabled":true}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/configurations/payment-config", strings.NewReader(`{"data":{"max_limit":2000,"enabled":false}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Export the database as a compressed archive
+	req = httptest.NewRequest("GET", "/api/v1/export?compression=gzip", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	archive := w.Body.Bytes()
+
+	// Start over with an empty database and restore the archive
+	suite.SetupTest()
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/import?dry_run=true", bytes.NewReader(archive))
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, false, report["applied"])
+	assert.Equal(t, float64(1), report["summary"].(map[string]interface{})["created"])
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/import", bytes.NewReader(archive))
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The restored configuration keeps its history and schema
+	req = httptest.NewRequest("GET", "/api/v1/configurations/payment-config/versions/1", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/v1/configurations/payment-config", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var config map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	assert.Equal(t, float64(2), config["version"])
+	assert.Equal(t, float64(2000), config["data"].(map[string]interface{})["max_limit"])
+
+	req = httptest.NewRequest("GET", "/api/v1/schemas/payment-config", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Importing the same archive again changes nothing
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/import", bytes.NewReader(archive))
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, float64(1), report["summary"].(map[string]interface{})["unchanged"])
+}
+
+// TestImportOverwriteKeepsImmutableTags tests that an overwrite import cannot drop an
+// immutable tag or the version it points at
+func (suite *ConfigurationAPITestSuite) TestImportOverwriteKeepsImmutableTags() {
+	t := suite.T()
+	ctx := context.Background()
+
+	// Export a configuration with one version
+	suite.TestRegisterSchema()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/configurations", strings.NewReader(`{"name":"payment-config","data":{"max_limit":1000,"enabled":true}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/v1/export", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	archive := w.Body.Bytes()
+
+	// Release a second version under an immutable tag
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/configurations/payment-config", strings.NewReader(`{"data":{"max_limit":2000,"enabled":false}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, err := suite.configUseCase.TagVersion(ctx, "payment-config", "release-2", 2, true)
+	assert.NoError(t, err)
+
+	// Overwriting with the older archive would remove the tagged version
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/import?mode=overwrite", bytes.NewReader(archive))
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "tags.release-2")
+
+	tagged, err := suite.configUseCase.GetConfigurationByTag(ctx, "payment-config", "release-2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, tagged.Version)
+		assert.JSONEq(t, `{"max_limit":2000,"enabled":false}`, string(tagged.Data))
+	}
+}
+
+// TestApplyBatch tests that a batch is applied entirely or not at all
+func (suite *ConfigurationAPITestSuite) TestApplyBatch() {
+	t := suite.T()
+
+	suite.TestRegisterSchema()
+
+	// The second operation violates the schema, so the first one is not applied either
+	body := `{"operations":[{"op":"create","name":"routing-config","data":{"region":"eu"}},{"op":"create","name":"payment-config","data":{"max_limit":1000}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/v1/configurations/routing-config", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// A valid batch applies every operation and records the changeset on each version
+	body = `{"changeset_id":"release-42","operations":[{"op":"create","name":"routing-config","data":{"region":"eu"}},{"op":"create","name":"payment-config","data":{"max_limit":1000,"enabled":true}},{"op":"update","name":"routing-config","data":{"region":"us"}}]}`
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/v1/configurations/routing-config", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.validAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var config map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	assert.Equal(t, float64(2), config["version"])
+	assert.Equal(t, "release-42", config["changeset_id"])
+}
+
 // TestConfigurationAPITestSuite runs the test suite
 func TestConfigurationAPITestSuite(t *testing.T) {
 	suite.Run(t, new(ConfigurationAPITestSuite))
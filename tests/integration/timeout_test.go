@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Titonu/configuration-management-service/internal/delivery/http/handler"
+	"github.com/Titonu/configuration-management-service/internal/delivery/http/middleware"
+	"github.com/Titonu/configuration-management-service/internal/domain/entity"
+	"github.com/Titonu/configuration-management-service/internal/domain/repository"
+	"github.com/Titonu/configuration-management-service/internal/repository/sqlite"
+	implUsecase "github.com/Titonu/configuration-management-service/internal/usecase"
+	"github.com/Titonu/configuration-management-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRepository is a repository whose configuration reads block until the request
+// context ends, like a query stuck behind a lock
+type blockingRepository struct {
+	repository.ConfigurationRepository
+}
+
+func (r *blockingRepository) GetConfiguration(ctx context.Context, name string) (*entity.Configuration, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// newBlockingRouter serves the configuration API on top of a blocking repository with
+// the given request timeout
+func newBlockingRouter(t *testing.T, timeout time.Duration) *gin.Engine {
+	repo, err := sqlite.NewConfigurationRepository(filepath.Join(t.TempDir(), "config.db"))
+	require.NoError(t, err)
+	configHandler := handler.NewConfigurationHandler(implUsecase.NewConfigurationUseCase(&blockingRepository{repo}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TimeoutMiddleware(timeout))
+	router.GET("/api/v1/configurations/:name", configHandler.GetConfiguration)
+	router.PUT("/api/v1/configurations/:name", configHandler.UpdateConfiguration)
+	return router
+}
+
+func TestRequestTimeout(t *testing.T) {
+	router := newBlockingRouter(t, 50*time.Millisecond)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/v1/configurations/payments", nil),
+		httptest.NewRequest("PUT", "/api/v1/configurations/payments", strings.NewReader(`{"data":{"max_limit":1000}}`)),
+	} {
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		start := time.Now()
+		router.ServeHTTP(w, req)
+
+		// The request is abandoned at its deadline and reported as timed out rather than
+		// as the not found or internal error the handler derived from the aborted read
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code, req.Method)
+		var response errors.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, errors.ErrorCodeRequestTimeout, response.Code, req.Method)
+	}
+}
+
+func TestRequestCancelled(t *testing.T) {
+	router := newBlockingRouter(t, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req := httptest.NewRequest("GET", "/api/v1/configurations/payments", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, handler.StatusClientClosedRequest, w.Code)
+	var response errors.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, errors.ErrorCodeRequestCancelled, response.Code)
+}